@@ -1,84 +1,531 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"log"
-	"net"
 	"net/http"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
 
+	"github.com/oklog/ulid/v2"
 	"github.com/vrischmann/hutil/v2"
 )
 
+const (
+	// defaultEntryTTL is the lifetime of an entry when neither the client
+	// nor the configuration specifies one.
+	defaultEntryTTL = 24 * time.Hour
+	// defaultMaxEntryTTL is the maximum lifetime of an entry when the configuration
+	// doesn't specify one.
+	defaultMaxEntryTTL = 7 * 24 * time.Hour
+
+	// defaultMaxEntrySize is the maximum size of an entry when the configuration
+	// doesn't specify one.
+	defaultMaxEntrySize = 64 << 20
+
+	// maxControlRequestSize is the maximum size of a request which doesn't carry content,
+	// like a move or list request.
+	maxControlRequestSize = 64 << 10
+)
+
 type serverConfig struct {
-	ListenAddr    string
-	PSKey         secretBoxKey
-	SignPublicKey publicKey
+	// ListenAddr is either host:port, unix:///path/to/socket or systemd to use the socket
+	// passed by systemd; systemd:name selects a socket by its FileDescriptorName.
+	ListenAddr string
+	// SocketMode is the permissions of the unix socket in octal; if empty it defaults to 0660.
+	SocketMode string `toml:",omitempty"`
+	// MetricsListenAddr is the address of the /metrics endpoint, in the same forms as ListenAddr.
+	// If empty the metrics aren't served. An unix socket gets the SocketMode permissions.
+	MetricsListenAddr string `toml:",omitempty"`
+
+	PSKey secretBoxKey
+	// RetiredPSKeys are the previous pre-shared keys, still accepted until they expire.
+	// They're added by the keys rotate psk subcommand.
+	RetiredPSKeys []retiredPSKey
+
+	// SignPublicKey is the key shared by clients which don't have a device name.
+	// It's optional if the devices file is set.
+	SignPublicKey publicKey `toml:",omitempty"`
+	// DevicesFile is the path of the registry of devices allowed to use the server.
+	// It's managed with the devices subcommands.
+	DevicesFile string
+
+	// DefaultTTL is the lifetime of an entry when the copy request doesn't have one.
+	DefaultTTL duration
+	// MaxTTL is the maximum lifetime of an entry, longer TTLs requested by clients are capped.
+	MaxTTL duration
+
+	Storage storageConfig
+	Limits  limitsConfig
+	TLS     tlsConfig
+}
+
+// retiredPSKey is a pre-shared key replaced by a new one.
+type retiredPSKey struct {
+	Key       secretBoxKey
+	ExpiresAt time.Time
+}
+
+func (c serverConfig) defaultTTL() time.Duration {
+	if c.DefaultTTL.Duration > 0 {
+		return c.DefaultTTL.Duration
+	}
+	return defaultEntryTTL
+}
+
+func (c serverConfig) maxTTL() time.Duration {
+	if c.MaxTTL.Duration > 0 {
+		return c.MaxTTL.Duration
+	}
+	return defaultMaxEntryTTL
+}
+
+// entryTTL returns the lifetime of a new entry given the TTL requested by the client.
+func (c serverConfig) entryTTL(requested time.Duration) time.Duration {
+	ttl := requested
+	if ttl <= 0 {
+		ttl = c.defaultTTL()
+	}
+	if max := c.maxTTL(); ttl > max {
+		ttl = max
+	}
+	return ttl
+}
+
+// limitsConfig controls how much data clients can store on the server.
+//
+// MaxEntrySize is the maximum size in bytes of a single entry; if zero it defaults to 64MiB.
+// MaxEntries and MaxTotalSize are the maximum number of entries and the maximum total size in bytes
+// of all entries in the store; if zero there is no limit. The upload sessions in progress count toward them.
+// MaxUploadSessions is the maximum number of upload sessions in progress; if zero it defaults to 16.
+type limitsConfig struct {
+	MaxEntrySize      int64
+	MaxEntries        int
+	MaxTotalSize      int64
+	MaxUploadSessions int
+}
+
+func (c limitsConfig) Validate() error {
+	if c.MaxEntrySize < 0 || c.MaxEntries < 0 || c.MaxTotalSize < 0 || c.MaxUploadSessions < 0 {
+		return fmt.Errorf("limits can't be negative")
+	}
+	return nil
+}
+
+func (c limitsConfig) maxEntrySize() int64 {
+	if c.MaxEntrySize > 0 {
+		return c.MaxEntrySize
+	}
+	return defaultMaxEntrySize
+}
+
+func (c limitsConfig) maxUploadSessions() int {
+	if c.MaxUploadSessions > 0 {
+		return c.MaxUploadSessions
+	}
+	return defaultMaxUploadSessions
+}
+
+// maxCopyRequestSize returns the maximum size of a copy request body with the biggest entry allowed.
+// The content is base64 encoded in the JSON payload which is itself sealed.
+func (c limitsConfig) maxCopyRequestSize() int64 {
+	return int64(base64.StdEncoding.EncodedLen(int(c.maxEntrySize()))) + maxControlRequestSize
+}
+
+func (c limitsConfig) quota() storeQuota {
+	return storeQuota{
+		MaxEntries: c.MaxEntries,
+		MaxSize:    c.MaxTotalSize,
+	}
+}
+
+// storageConfig controls where the server keeps the entries.
+//
+// Type can be either "memory" or "disk"; if empty it defaults to "memory".
+// Path is the root directory of the disk store and is required with the "disk" type.
+type storageConfig struct {
+	Type string
+	Path string
+}
+
+// uploadsDir returns the directory of the upload sessions files.
+// With the memory storage it's empty and the default temporary directory is used.
+func (c storageConfig) uploadsDir() string {
+	if c.Type == "disk" {
+		return filepath.Join(c.Path, "uploads")
+	}
+	return ""
+}
+
+func (c storageConfig) Validate() error {
+	switch c.Type {
+	case "", "memory":
+		return nil
+	case "disk":
+		if c.Path == "" {
+			return fmt.Errorf("storage path is required with the disk storage")
+		}
+		return nil
+	default:
+		return fmt.Errorf("storage type %q is invalid", c.Type)
+	}
 }
 
 func (c serverConfig) Validate() error {
-	if _, _, err := net.SplitHostPort(c.ListenAddr); err != nil {
+	if err := validateListenAddr(c.ListenAddr); err != nil {
 		return err
 	}
+	if _, err := parseSocketMode(c.SocketMode); err != nil {
+		return err
+	}
+	if c.MetricsListenAddr != "" {
+		if err := validateListenAddr(c.MetricsListenAddr); err != nil {
+			return fmt.Errorf("invalid metrics listen address. err: %v", err)
+		}
+		if c.MetricsListenAddr == c.ListenAddr {
+			return fmt.Errorf("the metrics listen address is the listen address, leave it empty to serve the metrics there")
+		}
+	}
 	if !c.PSKey.IsValid() {
 		return fmt.Errorf("ps key is invalid")
 	}
-	if !c.SignPublicKey.IsValid() {
+	for _, k := range c.RetiredPSKeys {
+		if !k.Key.IsValid() {
+			return fmt.Errorf("retired ps key is invalid")
+		}
+	}
+	if c.DevicesFile == "" && !c.SignPublicKey.IsValid() {
+		return fmt.Errorf("sign public key is invalid and there's no devices file")
+	}
+	if len(c.SignPublicKey) > 0 && !c.SignPublicKey.IsValid() {
 		return fmt.Errorf("sign public key is invalid")
 	}
+	if c.DefaultTTL.Duration < 0 || c.MaxTTL.Duration < 0 {
+		return fmt.Errorf("TTLs can't be negative")
+	}
+	if c.defaultTTL() > c.maxTTL() {
+		return fmt.Errorf("default TTL %s is greater than the max TTL %s", c.defaultTTL(), c.maxTTL())
+	}
+	if err := c.Storage.Validate(); err != nil {
+		return err
+	}
+	if err := c.Limits.Validate(); err != nil {
+		return err
+	}
+	if err := c.TLS.Validate(); err != nil {
+		return err
+	}
 	return nil
 }
 
 type apiHandler struct {
-	conf serverConfig
-	st   store
+	// confMu protects conf which is replaced when the configuration is reloaded,
+	// the handlers get it with config.
+	confMu sync.RWMutex
+	conf   serverConfig
+
+	st      store
+	uploads *uploadSessions
+	devices *deviceRegistry
+	events  *entryEvents
+	nonces  *nonceCache
+	pairing *pairingMailboxes
+	metrics *metrics
 }
 
-func newAPIHandler(conf serverConfig, st store) *apiHandler {
+func newAPIHandler(conf serverConfig, st store, uploads *uploadSessions, m *metrics) *apiHandler {
 	return &apiHandler{
-		conf: conf,
-		st:   st,
+		conf:    conf,
+		st:      st,
+		uploads: uploads,
+		devices: newDeviceRegistry(conf.DevicesFile),
+		events:  newEntryEvents(),
+		nonces:  newNonceCache(2 * maxClockSkew),
+		pairing: newPairingMailboxes(),
+		metrics: m,
 	}
 }
 
+// config returns the current configuration.
+//
+// A request must only call it once so that it sees a single configuration even if it's reloaded in the meantime.
+func (s *apiHandler) config() serverConfig {
+	s.confMu.RLock()
+	defer s.confMu.RUnlock()
+
+	return s.conf
+}
+
+// reload replaces the configuration with conf, the requests in progress keep using the previous one.
+//
+// The listener, the store and the TLS config are created from the configuration when the server starts
+// so the listen address, the storage and the TLS settings can't be reloaded: they're kept and a change is only logged.
+func (s *apiHandler) reload(conf serverConfig) error {
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	// NOTE(vincent): check the devices file before using it, a typo shouldn't lock every device out.
+	if err := newDeviceRegistry(conf.DevicesFile).Load(); err != nil {
+		return err
+	}
+
+	//
+
+	s.confMu.Lock()
+	defer s.confMu.Unlock()
+
+	old := s.conf
+
+	if conf.ListenAddr != old.ListenAddr || conf.SocketMode != old.SocketMode || conf.MetricsListenAddr != old.MetricsListenAddr {
+		log.Printf("the listen address changed, restart the server to use it")
+	}
+	if conf.Storage != old.Storage {
+		log.Printf("the storage changed, restart the server to use it")
+	}
+	if conf.TLS != old.TLS {
+		log.Printf("the TLS config changed, restart the server to use it")
+	}
+	conf.ListenAddr, conf.SocketMode, conf.MetricsListenAddr = old.ListenAddr, old.SocketMode, old.MetricsListenAddr
+	conf.Storage = old.Storage
+	conf.TLS = old.TLS
+
+	s.conf = conf
+	s.devices.SetPath(conf.DevicesFile)
+
+	return nil
+}
+
 func (s *apiHandler) handle(w http.ResponseWriter, req *http.Request, path string) {
-	head, tail := hutil.ShiftPath(path)
-	if head != "v1" {
-		http.Error(w, fmt.Sprintf("%q is not a valid version", head), http.StatusBadRequest)
+	w, rec := newStatusRecorder(w)
+	defer func(start time.Time) {
+		s.metrics.observeRequest(requestAction(path), rec.status(), time.Since(start))
+	}(time.Now())
+
+	version, tail := hutil.ShiftPath(path)
+	if version != apiVersion1 && version != apiVersion2 {
+		http.Error(w, fmt.Sprintf("%q is not a valid version", version), http.StatusBadRequest)
 		return
 	}
 
-	head, _ = hutil.ShiftPath(tail)
+	head, tail := hutil.ShiftPath(tail)
 
 	switch head {
-	case "copy":
-		s.handleCopy(w, req)
-	case "move":
-		s.handleMove(w, req)
-	case "paste":
-		s.handlePaste(w, req)
-	case "list":
-		s.handleList(w, req)
+	case "upload":
+		s.handleUpload(w, req, version, tail)
+	case actionCopy:
+		s.handleCopy(w, req, version)
+	case actionMove:
+		s.handleMove(w, req, version)
+	case actionPaste:
+		s.handlePaste(w, req, version)
+	case actionList:
+		s.handleList(w, req, version)
+	case actionRotateKey:
+		s.handleRotateKey(w, req, version)
+	case actionSubscribe:
+		s.handleSubscribe(w, req, version)
+	case actionPair:
+		s.handlePair(w, req, version, tail)
 	default:
 		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
 	}
 }
 
-func (s *apiHandler) handleCopy(w http.ResponseWriter, req *http.Request) {
+// openBox opens a box sealed by a client and returns the payload box it was sealed with;
+// the response must be sealed with the same box for the client to open it.
+//
+// Boxes sealed with a retired key are accepted until the key expires,
+// this gives some time to update the clients after rotating the key.
+//
+// A device authenticated with a client certificate doesn't seal its payloads,
+// they're only protected by TLS; see certDevice.
+func (s *apiHandler) openBox(conf serverConfig, req *http.Request, box []byte) ([]byte, payloadBox, bool) {
+	if req.TLS != nil && len(req.TLS.PeerCertificates) > 0 && conf.TLS.ClientCerts {
+		// NOTE(vincent): the certificate was checked during the handshake but the device
+		// may have been revoked since the connection was established.
+		device, err := s.certDevice(conf, req.TLS.PeerCertificates[0])
+		if err != nil {
+			log.Printf("client certificate refused. err: %v", err)
+			return nil, payloadBox{}, false
+		}
+		return box, payloadBox{clear: true, device: device}, true
+	}
+
+	if data, ok := secretBoxOpen(box, conf.PSKey); ok {
+		return data, payloadBox{psKey: conf.PSKey}, true
+	}
+
+	now := time.Now()
+	for _, k := range conf.RetiredPSKeys {
+		if now.After(k.ExpiresAt) {
+			continue
+		}
+		if data, ok := secretBoxOpen(box, k.Key); ok {
+			return data, payloadBox{psKey: k.Key}, true
+		}
+	}
+
+	s.metrics.boxOpenFailed()
+
+	return nil, payloadBox{}, false
+}
+
+// verifyRequest verifies the signature of a request for the action
+// and returns the name of the device which signed it.
+//
+// With the v1 API the signature only covers the payload and since there's no
+// envelope to name the device every known key is tried.
+// With the v2 API the signature covers the envelope and the payload; the envelope
+// must be for this action and version, must be fresh and must not have been seen before.
+//
+// On a connection authenticated with a client certificate the request must be signed
+// by the device of the certificate.
+func (s *apiHandler) verifyRequest(conf serverConfig, box payloadBox, version, action string, env *envelope, payload, signature []byte) (device string, err error) {
+	defer func() {
+		if err != nil {
+			s.metrics.signatureFailed(action)
+		}
+	}()
+
+	if version == apiVersion1 {
+		device, err := s.findSigner(conf, payload, signature)
+		if err == nil && box.clear && device != box.device {
+			return "", errCertDeviceMismatch
+		}
+		return device, err
+	}
+
+	if err := s.checkEnvelope(version, action, env); err != nil {
+		return "", err
+	}
+	if box.clear && env.Device != box.device {
+		return "", errCertDeviceMismatch
+	}
+
+	keys, err := s.deviceKeys(conf, env.Device)
+	if err != nil {
+		return "", err
+	}
+	if action == actionRotateKey {
+		// NOTE(vincent): the key retired by the last rotation is still accepted during the grace period
+		// but it can't rotate again, otherwise whoever holds it could replace the key of the owner.
+		keys = keys[:1]
+	}
+	if !verifyAny(keys, env.message(payload), signature) {
+		return "", errInvalidSignature
+	}
+
+	// Only remember the nonce once we know the request is genuine,
+	// otherwise anyone could burn nonces.
+	if !s.nonces.Add(env.Nonce, time.Now()) {
+		return "", errReplayedRequest
+	}
+
+	return env.Device, nil
+}
+
+// deviceKeys returns the public keys accepted for the device.
+// Without a name this is the SignPublicKey of the configuration, if any.
+func (s *apiHandler) deviceKeys(conf serverConfig, name string) ([]publicKey, error) {
+	if name == "" {
+		if !conf.SignPublicKey.IsValid() {
+			return nil, errUnknownDevice
+		}
+		return []publicKey{conf.SignPublicKey}, nil
+	}
+
+	d, err := s.devices.Lookup(name)
+	if err != nil {
+		return nil, err
+	}
+
+	return d.keys(time.Now()), nil
+}
+
+// verifyAny reports if the signature was made by one of the keys.
+func verifyAny(keys []publicKey, message, signature []byte) bool {
+	for _, key := range keys {
+		if key.IsValid() && verify(key, message, signature) {
+			return true
+		}
+	}
+	return false
+}
+
+// findSigner returns the name of the device whose key signed the payload.
+func (s *apiHandler) findSigner(conf serverConfig, payload, signature []byte) (string, error) {
+	if conf.SignPublicKey.IsValid() && verify(conf.SignPublicKey, payload, signature) {
+		return "", nil
+	}
+
+	devices, err := s.devices.Devices()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	for _, d := range devices {
+		if !d.isRevoked() && verifyAny(d.keys(now), payload, signature) {
+			return d.Name, nil
+		}
+	}
+
+	return "", errInvalidSignature
+}
+
+// checkEnvelope checks that the envelope is for this action and version and that it's fresh.
+//
+// This doesn't verify the signature so it can be done before reading a streamed request body.
+func (s *apiHandler) checkEnvelope(version, action string, env *envelope) error {
+	if env == nil {
+		return fmt.Errorf("envelope is missing")
+	}
+	if err := env.Validate(); err != nil {
+		return err
+	}
+	if env.Version != version || env.Action != action {
+		return fmt.Errorf("envelope is for %s/%s", env.Version, env.Action)
+	}
+
+	if skew := time.Since(env.Time()); skew > maxClockSkew || skew < -maxClockSkew {
+		return errStaleRequest
+	}
+
+	return nil
+}
+
+func (s *apiHandler) handleCopy(w http.ResponseWriter, req *http.Request, version string) {
+	conf := s.config()
+
 	if req.Method != http.MethodPost {
 		responseStatusCode(w, http.StatusMethodNotAllowed)
 		return
 	}
 
-	data, err := ioutil.ReadAll(req.Body)
-	if err != nil {
+	if version != apiVersion1 {
+		s.handleCopyStream(w, req, conf)
+		return
+	}
+
+	data, err := readRequestBody(req, conf.Limits.maxCopyRequestSize())
+	switch {
+	case err == errRequestTooLarge:
+		responseEntryTooLarge(w, conf.Limits.maxEntrySize())
+		return
+	case err != nil:
 		responseStatusCode(w, http.StatusInternalServerError)
 		return
 	}
-	defer req.Body.Close()
 
-	data, ok := secretBoxOpen(data, s.conf.PSKey)
+	data, box, ok := s.openBox(conf, req, data)
 	if !ok {
 		log.Printf("unable to open box")
 		responseStatusCode(w, http.StatusBadRequest)
@@ -101,42 +548,202 @@ func (s *apiHandler) handleCopy(w http.ResponseWriter, req *http.Request) {
 
 	//
 
-	validSignature := verify(s.conf.SignPublicKey, payload.Content, payload.Signature)
-	if !validSignature {
-		log.Printf("invalid signature")
-		responseString(w, "invalid signature", http.StatusBadRequest)
+	device, err := s.verifyRequest(conf, box, version, actionCopy, nil, payload.Content, payload.Signature)
+	if err != nil {
+		log.Printf("unable to verify copy request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	if max := conf.Limits.maxEntrySize(); int64(len(payload.Content)) > max {
+		responseEntryTooLarge(w, max)
+		return
+	}
+
+	ttl := conf.entryTTL(time.Duration(payload.TTL) * time.Second)
+
+	id, err := s.addEntry(bytes.NewReader(payload.Content), addOptions{
+		ExpiresAt: time.Now().Add(ttl),
+		Device:    device,
+		Quota:     conf.Limits.quota(),
+	})
+	switch {
+	case err == errQuotaExceeded:
+		log.Printf("unable to store payload, quota exceeded")
+		responseString(w, "the staging server is full", http.StatusInsufficientStorage)
+		return
+	case err != nil:
+		log.Printf("unable to store payload. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	respData := box.seal(id[:])
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(respData)
+}
+
+// handleCopyStream handles a copy request with the v2 API.
+//
+// The content is streamed to the store while it's read, the signature
+// in the trailer frame is verified once all content frames are read and
+// the store discards the entry if that fails.
+func (s *apiHandler) handleCopyStream(w http.ResponseWriter, req *http.Request, conf serverConfig) {
+	limits := conf.Limits
+
+	if req.ContentLength > limits.maxCopyRequestSize() {
+		responseEntryTooLarge(w, limits.maxEntrySize())
+		return
+	}
+
+	body := req.Body
+	defer body.Close()
+
+	data, err := readFrame(body, maxControlRequestSize)
+	if err != nil {
+		log.Printf("unable to read copy request header. err: %v", err)
+		responseString(w, "invalid copy request", http.StatusBadRequest)
+		return
+	}
+
+	data, box, ok := s.openBox(conf, req, data)
+	if !ok {
+		log.Printf("unable to open box")
+		responseStatusCode(w, http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	var header copyStreamHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		log.Printf("unable to unmarshal copy request header. err: %v", err)
+		responseString(w, "invalid copy request", http.StatusBadRequest)
+		return
+	}
+	if err := header.Validate(); err != nil {
+		log.Printf("copy request header invalid. err: %v", err)
+		responseString(w, "invalid copy request", http.StatusBadRequest)
 		return
 	}
 
-	// TODO(vincent): size limits and stuff
+	// Reject what we can before storing anything.
+
+	if err := s.checkEnvelope(apiVersion2, actionCopy, header.Envelope); err != nil {
+		s.metrics.signatureFailed(actionCopy)
+		log.Printf("unable to verify copy request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
 
-	id, err := s.st.Add(payload.Content)
+	// NOTE(vincent): the store checks the quota again when the entry is added,
+	// this only avoids reading the whole content when the store is already full.
+	usage, err := s.st.Usage()
 	if err != nil {
+		log.Printf("unable to get the store usage. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	if !limits.quota().allows(usage, 0) {
+		log.Printf("unable to store payload, quota exceeded")
+		responseString(w, "the staging server is full", http.StatusInsufficientStorage)
+		return
+	}
+
+	//
+
+	var (
+		hash      = sha256.New()
+		verifyErr error
+	)
+
+	content := newContentFramesReader(body, limits.maxEntrySize(), func() error {
+		data, err := readFrame(body, maxControlRequestSize)
+		if err != nil {
+			return fmt.Errorf("unable to read trailer. err: %v", err)
+		}
+
+		data, ok := box.open(data)
+		if !ok {
+			s.metrics.boxOpenFailed()
+			return fmt.Errorf("unable to open trailer box")
+		}
+
+		var trailer copyStreamTrailer
+		if err := json.Unmarshal(data, &trailer); err != nil {
+			return fmt.Errorf("unable to unmarshal trailer. err: %v", err)
+		}
+		if err := trailer.Validate(); err != nil {
+			return err
+		}
+
+		_, verifyErr = s.verifyRequest(conf, box, apiVersion2, actionCopy, header.Envelope, hash.Sum(nil), trailer.Signature)
+
+		return verifyErr
+	})
+
+	ttl := conf.entryTTL(time.Duration(header.TTL) * time.Second)
+
+	// NOTE(vincent): the device is only verified at the end of the content
+	// but if the signature is invalid the entry is never committed.
+	id, err := s.addEntry(io.TeeReader(content, hash), addOptions{
+		ExpiresAt: time.Now().Add(ttl),
+		Metadata:  header.Metadata,
+		Device:    header.Envelope.Device,
+		Quota:     limits.quota(),
+	})
+
+	// NOTE(vincent): the error returned by Add can come from the store or from the body,
+	// check the body first since that's the client's fault.
+
+	switch {
+	case content.Err() == errRequestTooLarge:
+		responseEntryTooLarge(w, limits.maxEntrySize())
+		return
+	case verifyErr != nil:
+		log.Printf("unable to verify copy request. err: %v", verifyErr)
+		responseString(w, verifyErr.Error(), http.StatusBadRequest)
+		return
+	case content.Err() != nil:
+		log.Printf("unable to read copy request. err: %v", content.Err())
+		responseString(w, "invalid copy request", http.StatusBadRequest)
+		return
+	case err == errQuotaExceeded:
+		log.Printf("unable to store payload, quota exceeded")
+		responseString(w, "the staging server is full", http.StatusInsufficientStorage)
+		return
+	case err != nil:
 		log.Printf("unable to store payload. err: %v", err)
 		responseString(w, "internal server error", http.StatusInternalServerError)
 		return
 	}
 
-	respData := secretBoxSeal(id[:], s.conf.PSKey)
+	respData := box.seal(id[:])
 
 	w.WriteHeader(http.StatusAccepted)
 	w.Write(respData)
 }
 
-func (s *apiHandler) handleMove(w http.ResponseWriter, req *http.Request) {
+func (s *apiHandler) handleMove(w http.ResponseWriter, req *http.Request, version string) {
+	conf := s.config()
+
 	if req.Method != http.MethodDelete {
 		responseStatusCode(w, http.StatusMethodNotAllowed)
 		return
 	}
 
-	data, err := ioutil.ReadAll(req.Body)
-	if err != nil {
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
 		responseStatusCode(w, http.StatusInternalServerError)
 		return
 	}
-	defer req.Body.Close()
 
-	data, ok := secretBoxOpen(data, s.conf.PSKey)
+	data, box, ok := s.openBox(conf, req, data)
 	if !ok {
 		log.Printf("unable to open box")
 		responseStatusCode(w, http.StatusBadRequest)
@@ -159,52 +766,47 @@ func (s *apiHandler) handleMove(w http.ResponseWriter, req *http.Request) {
 
 	//
 
-	validSignature := verify(s.conf.SignPublicKey, payload.ID[:], payload.Signature)
-	if !validSignature {
-		log.Printf("invalid signature")
-		responseString(w, "invalid signature", http.StatusBadRequest)
+	if _, err := s.verifyRequest(conf, box, version, actionMove, payload.Envelope, payload.ID[:], payload.Signature); err != nil {
+		log.Printf("unable to verify move request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	//
 
-	var content []byte
+	var (
+		info    entryInfo
+		content io.ReadCloser
+	)
 
 	if isEmptyULID(payload.ID) {
-		content, err = s.st.RemoveFirst()
+		info, content, err = s.st.RemoveFirst()
 	} else {
-		content, err = s.st.Remove(payload.ID)
+		info, content, err = s.st.Remove(payload.ID)
 	}
 
-	switch {
-	case err == errEntryNotFound:
-		responseStatusCode(w, http.StatusNotFound)
-		return
-	case err != nil:
-		log.Printf("unable to retrieve entry. err: %v", err)
-		responseString(w, "internal server error", http.StatusInternalServerError)
-		return
-	default:
-		respData := secretBoxSeal(content, s.conf.PSKey)
-		w.WriteHeader(http.StatusOK)
-		w.Write(respData)
-	}
+	s.writeEntry(w, version, isEmptyULID(payload.ID), 0, box, info, content, err)
 }
 
-func (s *apiHandler) handlePaste(w http.ResponseWriter, req *http.Request) {
+func (s *apiHandler) handlePaste(w http.ResponseWriter, req *http.Request, version string) {
+	conf := s.config()
+
 	if req.Method != http.MethodPost {
 		responseStatusCode(w, http.StatusMethodNotAllowed)
 		return
 	}
 
-	data, err := ioutil.ReadAll(req.Body)
-	if err != nil {
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
 		responseStatusCode(w, http.StatusInternalServerError)
 		return
 	}
-	defer req.Body.Close()
 
-	data, ok := secretBoxOpen(data, s.conf.PSKey)
+	data, box, ok := s.openBox(conf, req, data)
 	if !ok {
 		log.Printf("unable to open box")
 		responseStatusCode(w, http.StatusBadRequest)
@@ -227,24 +829,40 @@ func (s *apiHandler) handlePaste(w http.ResponseWriter, req *http.Request) {
 
 	//
 
-	validSignature := verify(s.conf.SignPublicKey, payload.ID[:], payload.Signature)
-	if !validSignature {
-		log.Printf("invalid signature")
-		responseString(w, "invalid signature", http.StatusBadRequest)
+	if _, err := s.verifyRequest(conf, box, version, actionPaste, payload.Envelope, payload.signedData(), payload.Signature); err != nil {
+		log.Printf("unable to verify paste request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
 	//
 
-	var content []byte
+	var (
+		info    entryInfo
+		content io.ReadCloser
+	)
 
 	if isEmptyULID(payload.ID) {
-		content, err = s.st.CopyFirst()
+		info, content, err = s.st.CopyFirst()
 	} else {
-		content, err = s.st.Copy(payload.ID)
+		info, content, err = s.st.Copy(payload.ID)
 	}
 
+	s.writeEntry(w, version, isEmptyULID(payload.ID), payload.Offset, box, info, content, err)
+}
+
+// writeEntry writes the response of a move or paste request.
+//
+// With the v1 API the content is sealed in a single box; there's no entry info
+// and asking for the oldest entry of an empty store returns an empty content.
+// With the v2 API the sealed entry header is followed by the content starting at offset
+// which is streamed from the store as is.
+func (s *apiHandler) writeEntry(w http.ResponseWriter, version string, first bool, offset int64, box payloadBox, info entryInfo, content io.ReadCloser, err error) {
 	switch {
+	case err == errEntryNotFound && first && version == apiVersion1:
+		w.WriteHeader(http.StatusOK)
+		w.Write(box.seal(nil))
+		return
 	case err == errEntryNotFound:
 		responseStatusCode(w, http.StatusNotFound)
 		return
@@ -252,25 +870,72 @@ func (s *apiHandler) handlePaste(w http.ResponseWriter, req *http.Request) {
 		log.Printf("unable to retrieve entry. err: %v", err)
 		responseString(w, "internal server error", http.StatusInternalServerError)
 		return
-	default:
-		respData := secretBoxSeal(content, s.conf.PSKey)
+	}
+	defer content.Close()
+
+	if version == apiVersion1 {
+		data, err := ioutil.ReadAll(content)
+		if err != nil {
+			log.Printf("unable to read entry. err: %v", err)
+			responseString(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
 		w.WriteHeader(http.StatusOK)
-		w.Write(respData)
+		w.Write(box.seal(data))
+		return
+	}
+
+	//
+
+	if offset > info.Size {
+		responseStatusCode(w, http.StatusRequestedRangeNotSatisfiable)
+		return
+	}
+	if err := skipContent(content, offset); err != nil {
+		log.Printf("unable to skip to the offset of the entry. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	data, err := json.Marshal(entryHeader{Entry: info, Offset: offset})
+	if err != nil {
+		log.Printf("unable to marshal entry header. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+	data = box.seal(data)
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(4+int64(len(data))+info.Size-offset, 10))
+	w.WriteHeader(http.StatusOK)
+
+	if err := writeFrame(w, data); err != nil {
+		log.Printf("unable to write entry header. err: %v", err)
+		return
+	}
+	if _, err := io.Copy(w, content); err != nil {
+		log.Printf("unable to write entry. err: %v", err)
 	}
 }
 
-func (s *apiHandler) handleList(w http.ResponseWriter, req *http.Request) {
+func (s *apiHandler) handleList(w http.ResponseWriter, req *http.Request, version string) {
+	conf := s.config()
+
 	if req.Method != http.MethodPost {
 		responseStatusCode(w, http.StatusMethodNotAllowed)
 		return
 	}
 
-	data, err := ioutil.ReadAll(req.Body)
-	if err != nil {
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
 		responseStatusCode(w, http.StatusInternalServerError)
 		return
 	}
-	defer req.Body.Close()
 
 	if len(data) == 0 {
 		log.Printf("no data in list request")
@@ -278,7 +943,7 @@ func (s *apiHandler) handleList(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	data, ok := secretBoxOpen(data, s.conf.PSKey)
+	data, box, ok := s.openBox(conf, req, data)
 	if !ok {
 		log.Printf("unable to open box")
 		responseStatusCode(w, http.StatusBadRequest)
@@ -301,12 +966,16 @@ func (s *apiHandler) handleList(w http.ResponseWriter, req *http.Request) {
 
 	//
 
-	// NOTE(vincent): since there's no content in a move request we sign the single byte L
-	// This might change in the future when we expand the protocol
-	validSignature := verify(s.conf.SignPublicKey, []byte("L"), payload.Signature)
-	if !validSignature {
-		log.Printf("invalid signature")
-		responseString(w, "invalid signature", http.StatusBadRequest)
+	// NOTE(vincent): since there's no content in a list request the v1 API signs the single byte L
+	// while the v2 API only signs the envelope.
+	var signedPayload []byte
+	if version == apiVersion1 {
+		signedPayload = []byte("L")
+	}
+
+	if _, err := s.verifyRequest(conf, box, version, actionList, payload.Envelope, signedPayload, payload.Signature); err != nil {
+		log.Printf("unable to verify list request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
 		return
 	}
 
@@ -317,8 +986,14 @@ func (s *apiHandler) handleList(w http.ResponseWriter, req *http.Request) {
 		return
 	}
 
-	var resp listResponse
-	resp.Entries = entries
+	var resp interface{} = listResponse{Entries: entries}
+	if version == apiVersion1 {
+		ids := make([]ulid.ULID, 0, len(entries))
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		resp = listResponseV1{Entries: ids}
+	}
 
 	content, err := json.Marshal(resp)
 	if err != nil {
@@ -329,8 +1004,25 @@ func (s *apiHandler) handleList(w http.ResponseWriter, req *http.Request) {
 
 	//
 
-	respData := secretBoxSeal(content, s.conf.PSKey)
+	respData := box.seal(content)
 
 	w.WriteHeader(http.StatusOK)
 	w.Write(respData)
 }
+
+// responseSealedJSON writes v as JSON sealed in the payload box.
+func responseSealedJSON(w http.ResponseWriter, box payloadBox, v interface{}, statusCode int) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("unable to marshal response. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(statusCode)
+	w.Write(box.seal(data))
+}
+
+func responseEntryTooLarge(w http.ResponseWriter, max int64) {
+	responseString(w, fmt.Sprintf("entry is too large, the maximum size is %s", formatSize(max)), http.StatusRequestEntityTooLarge)
+}
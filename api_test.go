@@ -1,11 +1,20 @@
 package main
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"encoding/json"
+	"io/ioutil"
+	"net/http"
 	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/BurntSushi/toml"
+	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 )
 
@@ -24,18 +33,125 @@ SignPublicKey = "GKlTcESb8Qm8KH+3wWoPWMf7DvVUWYzsKymvUKhhTo8="
 	require.NoError(t, conf.Validate())
 }
 
-func TestServerClient(t *testing.T) {
+func TestServerConfigDevices(t *testing.T) {
+	const data = `
+ListenAddr = "localhost:7568"
+PSKey = "vfHdOcFfBYP2xvuIJuk+JSBB1o9uCdbOMG7imn0riZk="
+DevicesFile = "/var/lib/apero/devices.toml"
+`
+
+	var conf serverConfig
+	md, err := toml.Decode(data, &conf)
+	require.NoError(t, err)
+
+	require.Empty(t, md.Undecoded())
+	require.NoError(t, conf.Validate())
+
+	// Either the devices file or the sign public key is required
+
+	conf.DevicesFile = ""
+	require.Error(t, conf.Validate())
+}
+
+func TestServerConfigStorage(t *testing.T) {
+	const data = `
+ListenAddr = "localhost:7568"
+PSKey = "vfHdOcFfBYP2xvuIJuk+JSBB1o9uCdbOMG7imn0riZk="
+SignPublicKey = "GKlTcESb8Qm8KH+3wWoPWMf7DvVUWYzsKymvUKhhTo8="
+
+[Storage]
+Type = "disk"
+Path = "/var/lib/apero"
+`
+
+	var conf serverConfig
+	md, err := toml.Decode(data, &conf)
+	require.NoError(t, err)
+
+	require.Empty(t, md.Undecoded())
+	require.NoError(t, conf.Validate())
+	require.Equal(t, "disk", conf.Storage.Type)
+	require.Equal(t, "/var/lib/apero", conf.Storage.Path)
+
+	conf.Storage.Path = ""
+	require.Error(t, conf.Validate())
+
+	conf.Storage.Type = "foobar"
+	require.Error(t, conf.Validate())
+}
+
+func TestServerConfigTTL(t *testing.T) {
+	const data = `
+ListenAddr = "localhost:7568"
+PSKey = "vfHdOcFfBYP2xvuIJuk+JSBB1o9uCdbOMG7imn0riZk="
+SignPublicKey = "GKlTcESb8Qm8KH+3wWoPWMf7DvVUWYzsKymvUKhhTo8="
+DefaultTTL = "1h"
+MaxTTL = "48h"
+`
+
+	var conf serverConfig
+	md, err := toml.Decode(data, &conf)
+	require.NoError(t, err)
+
+	require.Empty(t, md.Undecoded())
+	require.NoError(t, conf.Validate())
+
+	require.Equal(t, time.Hour, conf.entryTTL(0))
+	require.Equal(t, 10*time.Minute, conf.entryTTL(10*time.Minute))
+	require.Equal(t, 48*time.Hour, conf.entryTTL(100*time.Hour))
+
+	conf.DefaultTTL.Duration = 72 * time.Hour
+	require.Error(t, conf.Validate())
+
+	// Defaults are used when nothing is configured
+
+	var conf2 serverConfig
+	require.Equal(t, defaultEntryTTL, conf2.entryTTL(0))
+	require.Equal(t, defaultMaxEntryTTL, conf2.entryTTL(1000*time.Hour))
+}
+
+func TestServerConfigMetrics(t *testing.T) {
+	const data = `
+ListenAddr = "localhost:7568"
+MetricsListenAddr = "127.0.0.1:9568"
+PSKey = "vfHdOcFfBYP2xvuIJuk+JSBB1o9uCdbOMG7imn0riZk="
+SignPublicKey = "GKlTcESb8Qm8KH+3wWoPWMf7DvVUWYzsKymvUKhhTo8="
+`
+
+	var conf serverConfig
+	md, err := toml.Decode(data, &conf)
+	require.NoError(t, err)
+
+	require.Empty(t, md.Undecoded())
+	require.NoError(t, conf.Validate())
+
+	conf.MetricsListenAddr = "foobar"
+	require.Error(t, conf.Validate())
+
+	conf.MetricsListenAddr = conf.ListenAddr
+	require.Error(t, conf.Validate())
+}
+
+// newTestServerClient starts a server backed by a memory store and creates a client for it.
+// The caller must close the returned server.
+func newTestServerClient(t *testing.T) (*apiHandler, *client, *httptest.Server) {
 	publicKey, privateKey := mustKeyPair(t)
 
 	var conf serverConfig
 	conf.PSKey = newSecretBoxKey()
 	conf.SignPublicKey = publicKey
 
-	api := newAPIHandler(conf, newMemStore())
+	uploads, err := newUploadSessions("")
+	require.NoError(t, err)
+
+	m := newMetrics()
+	st, err := newStore(storageConfig{}, m)
+	require.NoError(t, err)
+
+	api := newAPIHandler(conf, st, uploads, m)
 	ui := newUIHandler(conf)
 
-	httpServer := httptest.NewServer(serverHandler(api, ui))
-	defer httpServer.Close()
+	httpServer := httptest.NewServer(newServerHandler(api, ui))
 
 	//
 
@@ -46,14 +162,15 @@ func TestServerClient(t *testing.T) {
 	clientConf.SignPublicKey = publicKey
 	clientConf.SignPrivateKey = privateKey
 
-	client := newClient(clientConf)
+	return api, newClient(clientConf), httpServer
+}
 
-	t.Run("copy", func(t *testing.T) {
-		content := []byte("hello")
-		signature := sign(clientConf.SignPrivateKey, content)
-		req := copyRequest{Signature: signature, Content: content}
+func TestServerClient(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
 
-		body, err := client.doCopy(req)
+	t.Run("copy", func(t *testing.T) {
+		id, err := client.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
 		require.NoError(t, err)
 
 		//
@@ -61,28 +178,93 @@ func TestServerClient(t *testing.T) {
 		entries, err := api.st.ListAll()
 		require.NoError(t, err)
 		require.NotEmpty(t, entries)
-		expID := entries[0]
+		require.Equal(t, entries[0].ID, id)
+
+		_, entry, err := api.st.RemoveFirst()
+		require.NoError(t, err)
+
+		require.Equal(t, "hello", mustReadEntry(t, entry))
+	})
+
+	t.Run("copy-large", func(t *testing.T) {
+		// The content spans multiple frames
+
+		content := bytes.Repeat([]byte("abcdefgh"), 3*secretStreamChunkSize/8+100)
+
+		id, err := client.doCopy(copyStreamHeader{}, bytes.NewReader(content))
+		require.NoError(t, err)
+
+		info, body, err := client.doMove(moveRequest{ID: id})
+		require.NoError(t, err)
+		require.Equal(t, id, info.ID)
+		require.Equal(t, int64(len(content)), info.Size)
+		require.Equal(t, string(content), mustReadEntry(t, body))
+	})
+
+	t.Run("copy-encrypted", func(t *testing.T) {
+		key := client.conf.EncryptKey
+		content := bytes.Repeat([]byte("a"), secretStreamChunkSize+1)
+
+		stream := secretStreamEncrypt(bytes.NewReader(content), key)
+		defer stream.Close()
+
+		_, err := client.doCopy(copyStreamHeader{}, stream)
+		require.NoError(t, err)
+
+		_, body, err := client.doMove(moveRequest{})
+		require.NoError(t, err)
+		defer body.Close()
+
+		r, err := newSecretStreamReader(body, key)
+		require.NoError(t, err)
+
+		plaintext, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.Equal(t, content, plaintext)
+	})
+
+	t.Run("copy-metadata", func(t *testing.T) {
+		metadata, err := sealMetadata(entryMetadata{Filename: "hello.txt"}, client.conf.EncryptKey)
+		require.NoError(t, err)
+
+		_, err = client.doCopy(copyStreamHeader{Metadata: metadata}, strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		body, err := client.doList(listRequest{})
+		require.NoError(t, err)
+
+		var resp listResponse
+		require.NoError(t, json.Unmarshal(body, &resp))
+		require.Len(t, resp.Entries, 1)
+
+		md, err := openMetadata(resp.Entries[0].Metadata, client.conf.EncryptKey)
+		require.NoError(t, err)
+		require.Equal(t, "hello.txt", md.Filename)
+
+		api.st.RemoveFirst() // cleanup for the next test
+	})
 
-		require.Equal(t, expID[:], body[:])
+	t.Run("copy-ttl", func(t *testing.T) {
+		_, err := client.doCopy(copyStreamHeader{TTL: 60}, strings.NewReader("hello"))
+		require.NoError(t, err)
 
-		entry, err := api.st.RemoveFirst()
+		entries, err := api.st.ListAll()
 		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.WithinDuration(t, time.Now().Add(time.Minute), entries[0].ExpiresAt, 5*time.Second)
 
-		require.Equal(t, content, entry)
+		api.st.RemoveFirst() // cleanup for the next test
 	})
 
 	t.Run("move-oldest", func(t *testing.T) {
-		_, err := api.st.Add([]byte("yoo"))
+		_, err := api.st.Add(strings.NewReader("yoo"), addOptions{})
 		require.NoError(t, err)
 
 		//
 
-		var req moveRequest
-		req.Signature = sign(clientConf.SignPrivateKey, req.ID[:])
-
-		body, err := client.doMove(req)
+		_, body, err := client.doMove(moveRequest{})
 		require.NoError(t, err)
-		require.Equal(t, []byte("yoo"), body)
+		require.Equal(t, "yoo", mustReadEntry(t, body))
 
 		//
 
@@ -92,88 +274,92 @@ func TestServerClient(t *testing.T) {
 	})
 
 	t.Run("move-specific", func(t *testing.T) {
-		oldestID, _ := api.st.Add([]byte("yoo"))
-		id, _ := api.st.Add([]byte("yezi"))
+		oldestID, _ := api.st.Add(strings.NewReader("yoo"), addOptions{})
+		id, _ := api.st.Add(strings.NewReader("yezi"), addOptions{})
 
 		//
 
-		req := moveRequest{
-			ID:        id,
-			Signature: sign(clientConf.SignPrivateKey, id[:]),
-		}
+		req := moveRequest{ID: id}
 
-		body, err := client.doMove(req)
+		info, body, err := client.doMove(req)
 		require.NoError(t, err)
-		require.Equal(t, []byte("yezi"), body)
+		require.Equal(t, id, info.ID)
+		require.Equal(t, "yezi", mustReadEntry(t, body))
 
 		//
 
 		entries, err := api.st.ListAll()
 		require.NoError(t, err)
-		require.Equal(t, entries[0], oldestID)
+		require.Equal(t, entries[0].ID, oldestID)
 
 		api.st.RemoveFirst() // cleanup for the next test
 	})
 
 	t.Run("paste-oldest", func(t *testing.T) {
-		id, _ := api.st.Add([]byte("yoo"))
+		id, _ := api.st.Add(strings.NewReader("yoo"), addOptions{})
 
 		//
 
-		var req pasteRequest
-		req.Signature = sign(clientConf.SignPrivateKey, req.ID[:])
-
-		body, err := client.doPaste(req)
+		_, body, err := client.doPaste(pasteRequest{})
 		require.NoError(t, err)
-		require.Equal(t, []byte("yoo"), body)
+		require.Equal(t, "yoo", mustReadEntry(t, body))
 
 		//
 
 		entries, err := api.st.ListAll()
 		require.NoError(t, err)
 		require.Equal(t, 1, len(entries))
-		require.Equal(t, id, entries[0])
+		require.Equal(t, id, entries[0].ID)
 
 		api.st.RemoveFirst() // cleanup for the next test
 	})
 
 	t.Run("paste-specific", func(t *testing.T) {
-		oldestID, _ := api.st.Add([]byte("yoo"))
-		id, _ := api.st.Add([]byte("yeoa"))
+		oldestID, _ := api.st.Add(strings.NewReader("yoo"), addOptions{})
+		id, _ := api.st.Add(strings.NewReader("yeoa"), addOptions{})
 
 		//
 
-		req := pasteRequest{
-			ID:        id,
-			Signature: sign(clientConf.SignPrivateKey, id[:]),
-		}
+		req := pasteRequest{ID: id}
 
-		body, err := client.doPaste(req)
+		info, body, err := client.doPaste(req)
 		require.NoError(t, err)
-		require.Equal(t, []byte("yeoa"), body)
+		require.Equal(t, id, info.ID)
+		require.Equal(t, "yeoa", mustReadEntry(t, body))
 
 		//
 
 		entries, err := api.st.ListAll()
 		require.NoError(t, err)
 		require.Equal(t, 2, len(entries))
-		require.Equal(t, oldestID, entries[0])
-		require.Equal(t, id, entries[1])
+		require.Equal(t, oldestID, entries[0].ID)
+		require.Equal(t, id, entries[1].ID)
 
 		api.st.RemoveFirst() // cleanup for the next test
 		api.st.RemoveFirst()
 	})
 
-	t.Run("list", func(t *testing.T) {
-		id1, _ := api.st.Add([]byte("foo1"))
-		id2, _ := api.st.Add([]byte("foo2"))
-		id3, _ := api.st.Add([]byte("foo3"))
+	t.Run("paste-expired", func(t *testing.T) {
+		id, _ := api.st.Add(strings.NewReader("yoo"), addOptions{ExpiresAt: time.Now().Add(-time.Second)})
 
 		//
 
-		req := listRequest{Signature: sign(clientConf.SignPrivateKey, []byte("L"))}
+		req := pasteRequest{ID: id}
+
+		_, _, err := client.doPaste(req)
+		require.Equal(t, errEntryNotFound, err)
+
+		api.st.RemoveExpired(time.Now()) // cleanup for the next test
+	})
+
+	t.Run("list", func(t *testing.T) {
+		id1, _ := api.st.Add(strings.NewReader("foo1"), addOptions{})
+		id2, _ := api.st.Add(strings.NewReader("foo2"), addOptions{})
+		id3, _ := api.st.Add(strings.NewReader("foo3"), addOptions{})
 
-		body, err := client.doList(req)
+		//
+
+		body, err := client.doList(listRequest{})
 		require.NoError(t, err)
 
 		var resp listResponse
@@ -181,12 +367,476 @@ func TestServerClient(t *testing.T) {
 		require.NoError(t, err)
 
 		require.Equal(t, 3, len(resp.Entries))
-		require.Equal(t, id1, resp.Entries[0])
-		require.Equal(t, id2, resp.Entries[1])
-		require.Equal(t, id3, resp.Entries[2])
+		require.Equal(t, id1, resp.Entries[0].ID)
+		require.Equal(t, id2, resp.Entries[1].ID)
+		require.Equal(t, id3, resp.Entries[2].ID)
+	})
+}
+
+func TestServerClientV1(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	priv := client.conf.SignPrivateKey
+
+	// v1 requests have no envelope and sign only the payload
+
+	t.Run("copy", func(t *testing.T) {
+		content := []byte("hello")
+		req := copyRequest{Signature: sign(priv, content), Content: content}
+
+		body, err := client.doRequest(req, http.MethodPost, http.StatusAccepted, "/api/v1/copy")
+		require.NoError(t, err)
+
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, entries[0].ID[:], body)
+	})
+
+	t.Run("list", func(t *testing.T) {
+		req := listRequest{Signature: sign(priv, []byte("L"))}
+
+		body, err := client.doRequest(req, http.MethodPost, http.StatusOK, "/api/v1/list")
+		require.NoError(t, err)
+
+		// v1 clients only get the IDs
+
+		var resp listResponseV1
+		err = json.Unmarshal(body, &resp)
+		require.NoError(t, err)
+		require.Len(t, resp.Entries, 1)
+
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		require.Equal(t, entries[0].ID, resp.Entries[0])
+		require.JSONEq(t, `{"entries":["`+entries[0].ID.String()+`"]}`, string(body))
+	})
+
+	t.Run("paste", func(t *testing.T) {
+		var req pasteRequest
+		req.Signature = sign(priv, req.ID[:])
+
+		body, err := client.doRequest(req, http.MethodPost, http.StatusOK, "/api/v1/paste")
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(body))
+	})
+
+	t.Run("move", func(t *testing.T) {
+		var req moveRequest
+		req.Signature = sign(priv, req.ID[:])
+
+		body, err := client.doRequest(req, http.MethodDelete, http.StatusOK, "/api/v1/move")
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(body))
+	})
+
+	t.Run("move-empty", func(t *testing.T) {
+		// v1 clients get an empty content when there's nothing in the store
+
+		var req moveRequest
+		req.Signature = sign(priv, req.ID[:])
+
+		body, err := client.doRequest(req, http.MethodDelete, http.StatusOK, "/api/v1/move")
+		require.NoError(t, err)
+		require.Empty(t, body)
+	})
+
+	t.Run("invalid-signature", func(t *testing.T) {
+		var req moveRequest
+		req.Signature = sign(priv, []byte("L"))
+
+		_, err := client.doRequest(req, http.MethodDelete, http.StatusOK, "/api/v1/move")
+		require.Error(t, err)
 	})
 }
 
+func TestServerLimits(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	api.conf.Limits = limitsConfig{
+		MaxEntrySize: 10,
+		MaxEntries:   2,
+	}
+
+	t.Run("entry-too-large", func(t *testing.T) {
+		_, err := client.doCopy(copyStreamHeader{}, strings.NewReader("foobarbazqux"))
+		require.EqualError(t, err, "the staging server refused the entry: entry is too large, the maximum size is 10B")
+	})
+
+	t.Run("body-too-large", func(t *testing.T) {
+		// The body is rejected before being decoded
+
+		body := bytes.Repeat([]byte("a"), int(api.conf.Limits.maxCopyRequestSize())+1)
+
+		resp, err := http.Post(httpServer.URL+"/api/v2/copy", "application/octet-stream", bytes.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode)
+	})
+
+	t.Run("store-full", func(t *testing.T) {
+		_, err := client.doCopy(copyStreamHeader{}, strings.NewReader("foo"))
+		require.NoError(t, err)
+		_, err = client.doCopy(copyStreamHeader{}, strings.NewReader("bar"))
+		require.NoError(t, err)
+
+		_, err = client.doCopy(copyStreamHeader{}, strings.NewReader("baz"))
+		require.EqualError(t, err, "the staging server refused the entry: the staging server is full")
+
+		// The store is checked before reading the content: a body without content
+		// would be invalid otherwise
+
+		env := newEnvelope(apiVersion2, actionCopy)
+		header, err := json.Marshal(copyStreamHeader{Envelope: &env})
+		require.NoError(t, err)
+
+		var body bytes.Buffer
+		require.NoError(t, writeFrame(&body, secretBoxSeal(header, client.conf.PSKey)))
+
+		resp, err := http.Post(httpServer.URL+"/api/v2/copy", "application/octet-stream", &body)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusInsufficientStorage, resp.StatusCode)
+	})
+}
+
+func TestServerReplay(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	priv := client.conf.SignPrivateKey
+
+	// send sends a raw request body and returns the status code
+	send := func(body []byte) int {
+		req, err := http.NewRequest(http.MethodDelete, httpServer.URL+"/api/v2/move", bytes.NewReader(body))
+		require.NoError(t, err)
+
+		resp, err := http.DefaultClient.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+	seal := func(req interface{}) []byte {
+		data, err := json.Marshal(req)
+		require.NoError(t, err)
+		return secretBoxSeal(data, client.conf.PSKey)
+	}
+
+	t.Run("replayed", func(t *testing.T) {
+		api.st.Add(strings.NewReader("foo"), addOptions{})
+		api.st.Add(strings.NewReader("bar"), addOptions{})
+
+		var req moveRequest
+		req.Envelope, req.Signature = signEnvelope(priv, apiVersion2, actionMove, req.ID[:])
+
+		body := seal(req)
+		require.Equal(t, http.StatusOK, send(body))
+		require.Equal(t, http.StatusBadRequest, send(body))
+
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+
+		api.st.RemoveFirst() // cleanup for the next test
+	})
+
+	t.Run("stale", func(t *testing.T) {
+		api.st.Add(strings.NewReader("foo"), addOptions{})
+
+		var req moveRequest
+
+		env := newEnvelope(apiVersion2, actionMove)
+		env.Timestamp = time.Now().Add(-2 * maxClockSkew).Unix()
+
+		req.Envelope = &env
+		req.Signature = sign(priv, env.message(req.ID[:]))
+
+		require.Equal(t, http.StatusBadRequest, send(seal(req)))
+	})
+
+	t.Run("wrong-action", func(t *testing.T) {
+		// A signed paste request can't be turned into a move request
+
+		var req moveRequest
+		req.Envelope, req.Signature = signEnvelope(priv, apiVersion2, actionPaste, req.ID[:])
+
+		require.Equal(t, http.StatusBadRequest, send(seal(req)))
+	})
+
+	t.Run("missing-envelope", func(t *testing.T) {
+		var req moveRequest
+		req.Signature = sign(priv, req.ID[:])
+
+		require.Equal(t, http.StatusBadRequest, send(seal(req)))
+	})
+
+	entries, err := api.st.ListAll()
+	require.NoError(t, err)
+	require.Len(t, entries, 1)
+}
+
+func TestServerCopyStream(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	priv := client.conf.SignPrivateKey
+
+	// send sends a raw request body and returns the status code
+	send := func(body []byte) int {
+		resp, err := http.Post(httpServer.URL+"/api/v2/copy", "application/octet-stream", bytes.NewReader(body))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		return resp.StatusCode
+	}
+	sealFrame := func(buf *bytes.Buffer, v interface{}) {
+		data, err := json.Marshal(v)
+		require.NoError(t, err)
+		require.NoError(t, writeFrame(buf, secretBoxSeal(data, client.conf.PSKey)))
+	}
+	// makeBody creates a copy request body for content with a signature over signed
+	makeBody := func(content, signed string) *bytes.Buffer {
+		env := newEnvelope(apiVersion2, actionCopy)
+		digest := sha256.Sum256([]byte(signed))
+
+		var buf bytes.Buffer
+		sealFrame(&buf, copyStreamHeader{Envelope: &env})
+		require.NoError(t, writeContentFrames(&buf, strings.NewReader(content)))
+		sealFrame(&buf, copyStreamTrailer{Signature: sign(priv, env.message(digest[:]))})
+
+		return &buf
+	}
+
+	t.Run("valid", func(t *testing.T) {
+		require.Equal(t, http.StatusAccepted, send(makeBody("foo", "foo").Bytes()))
+
+		_, entry, err := api.st.RemoveFirst()
+		require.NoError(t, err)
+		require.Equal(t, "foo", mustReadEntry(t, entry))
+	})
+
+	t.Run("tampered-content", func(t *testing.T) {
+		require.Equal(t, http.StatusBadRequest, send(makeBody("bar", "foo").Bytes()))
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		data := makeBody("foo", "foo").Bytes()
+
+		require.Equal(t, http.StatusBadRequest, send(data[:len(data)-10]))
+	})
+
+	t.Run("replayed", func(t *testing.T) {
+		body := makeBody("foo", "foo").Bytes()
+
+		require.Equal(t, http.StatusAccepted, send(body))
+		require.Equal(t, http.StatusBadRequest, send(body))
+
+		_, entry, err := api.st.RemoveFirst()
+		require.NoError(t, err)
+		entry.Close()
+	})
+
+	entries, err := api.st.ListAll()
+	require.NoError(t, err)
+	require.Empty(t, entries)
+}
+
+func TestServerDevices(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	// The server only knows devices
+
+	api.conf.SignPublicKey = nil
+	api.conf.DevicesFile = filepath.Join(dir, "devices.toml")
+	api.devices = newDeviceRegistry(api.conf.DevicesFile)
+
+	require.NoError(t, api.devices.Add("laptop", client.conf.SignPublicKey, time.Now()))
+
+	phonePub, phonePriv := mustKeyPair(t)
+	require.NoError(t, api.devices.Add("phone", phonePub, time.Now()))
+
+	client.conf.DeviceName = "laptop"
+
+	phoneConf := client.conf
+	phoneConf.DeviceName = "phone"
+	phoneConf.SignPublicKey = phonePub
+	phoneConf.SignPrivateKey = phonePriv
+	phone := newClient(phoneConf)
+
+	t.Run("copy", func(t *testing.T) {
+		id, err := client.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		id2, err := phone.doUpload(uploadStartRequest{}, strings.NewReader("world"))
+		require.NoError(t, err)
+
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		require.Len(t, entries, 2)
+		require.Equal(t, id, entries[0].ID)
+		require.Equal(t, "laptop", entries[0].Device)
+		require.Equal(t, id2, entries[1].ID)
+		require.Equal(t, "phone", entries[1].Device)
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		// v1 requests don't name the device, the server finds it with the signature
+
+		content := []byte("hello")
+		req := copyRequest{Signature: sign(phonePriv, content), Content: content}
+
+		body, err := client.doRequest(req, http.MethodPost, http.StatusAccepted, "/api/v1/copy")
+		require.NoError(t, err)
+
+		var id ulid.ULID
+		copy(id[:], body)
+
+		info, _, err := api.st.Remove(id)
+		require.NoError(t, err)
+		require.Equal(t, "phone", info.Device)
+	})
+
+	t.Run("wrong-key", func(t *testing.T) {
+		// The laptop can't sign requests for the phone
+
+		laptopConf := client.conf
+		laptopConf.DeviceName = "phone"
+		laptop := newClient(laptopConf)
+
+		_, err := laptop.doList(listRequest{})
+		require.Error(t, err)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		tabletConf := client.conf
+		tabletConf.DeviceName = "tablet"
+		tablet := newClient(tabletConf)
+
+		_, err := tablet.doList(listRequest{})
+		require.Error(t, err)
+
+		// Without a device name there's no key to verify with
+
+		tabletConf.DeviceName = ""
+		tablet = newClient(tabletConf)
+
+		_, err = tablet.doList(listRequest{})
+		require.Error(t, err)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		_, err := phone.doList(listRequest{})
+		require.NoError(t, err)
+
+		require.NoError(t, newDeviceRegistry(api.conf.DevicesFile).Revoke("phone", time.Now()))
+
+		_, err = phone.doList(listRequest{})
+		require.Error(t, err)
+
+		_, err = phone.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+		require.Error(t, err)
+
+		// v1 too
+
+		content := []byte("hello")
+		req := copyRequest{Signature: sign(phonePriv, content), Content: content}
+
+		_, err = client.doRequest(req, http.MethodPost, http.StatusAccepted, "/api/v1/copy")
+		require.Error(t, err)
+
+		// Other devices are unaffected
+
+		_, err = client.doList(listRequest{})
+		require.NoError(t, err)
+	})
+}
+
+func TestServerReload(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	client.retries = 0
+
+	conf := api.config()
+	conf.ListenAddr = "localhost:7568"
+	conf.PSKey = newSecretBoxKey()
+	conf.Limits.MaxEntrySize = 3
+	conf.TLS = tlsConfig{CertFile: "cert.pem", KeyFile: "key.pem"}
+
+	require.NoError(t, api.reload(conf))
+
+	// The keys and the limits are used right away
+
+	_, err := client.doList(listRequest{})
+	require.Error(t, err)
+
+	newConf := client.conf
+	newConf.PSKey = conf.PSKey
+	rotated := newClient(newConf)
+	rotated.retries = 0
+
+	_, err = rotated.doList(listRequest{})
+	require.NoError(t, err)
+
+	_, err = rotated.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+	require.Error(t, err)
+
+	// but not what's only used when the server starts
+
+	require.Equal(t, "", api.config().ListenAddr)
+	require.Equal(t, tlsConfig{}, api.config().TLS)
+
+	// An invalid config is rejected and the current one kept
+
+	invalid := conf
+	invalid.PSKey = newSecretBoxKey()
+	invalid.DefaultTTL = duration{-time.Hour}
+	require.Error(t, api.reload(invalid))
+
+	invalid = conf
+	invalid.PSKey = newSecretBoxKey()
+	invalid.DevicesFile = filepath.Join(dir, "invalid.toml")
+	require.NoError(t, ioutil.WriteFile(invalid.DevicesFile, []byte("foobar"), 0600))
+	require.Error(t, api.reload(invalid))
+
+	_, err = rotated.doList(listRequest{})
+	require.NoError(t, err)
+
+	// The devices file can be replaced
+
+	phonePub, phonePriv := mustKeyPair(t)
+
+	conf.DevicesFile = filepath.Join(dir, "devices.toml")
+	require.NoError(t, newDeviceRegistry(conf.DevicesFile).Add("phone", phonePub, time.Now()))
+
+	phoneConf := newConf
+	phoneConf.DeviceName = "phone"
+	phoneConf.SignPublicKey = phonePub
+	phoneConf.SignPrivateKey = phonePriv
+	phone := newClient(phoneConf)
+	phone.retries = 0
+
+	_, err = phone.doList(listRequest{})
+	require.Error(t, err)
+
+	require.NoError(t, api.reload(conf))
+
+	_, err = phone.doList(listRequest{})
+	require.NoError(t, err)
+}
+
 func mustKeyPair(t *testing.T) (publicKey, privateKey) {
 	pub, priv, err := generateKeyPair()
 	if err != nil {
@@ -0,0 +1,371 @@
+package main
+
+import (
+	"archive/tar"
+	"compress/gzip"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	// archiveTar and archiveTarGzip are the formats of the archives created by copy.
+	archiveTar     = "tar"
+	archiveTarGzip = "tar+gzip"
+)
+
+var errNotAnArchive = errors.New("entry is not an archive")
+
+// archiveFile is a file, directory or symlink to put in an archive.
+type archiveFile struct {
+	// path is the path on disk.
+	path string
+	// name is the path in the archive, relative and slash separated.
+	name string
+	fi   os.FileInfo
+}
+
+// collectArchiveFiles walks the paths and returns the files to archive.
+//
+// Each path is stored in the archive under its base name, a directory with all its content.
+// Symlinks are stored as is, not followed.
+func collectArchiveFiles(paths []string) ([]archiveFile, error) {
+	var (
+		files []archiveFile
+		roots = make(map[string]string)
+	)
+
+	for _, root := range paths {
+		root = filepath.Clean(root)
+
+		base := filepath.Base(root)
+		if base == "." || base == ".." || base == string(filepath.Separator) {
+			abs, err := filepath.Abs(root)
+			if err != nil {
+				return nil, err
+			}
+			base = filepath.Base(abs)
+		}
+
+		if other, ok := roots[base]; ok {
+			return nil, fmt.Errorf("%s and %s have the same name %s in the archive", other, root, base)
+		}
+		roots[base] = root
+
+		err := filepath.Walk(root, func(p string, fi os.FileInfo, err error) error {
+			if err != nil {
+				return err
+			}
+
+			rel, err := filepath.Rel(root, p)
+			if err != nil {
+				return err
+			}
+
+			files = append(files, archiveFile{
+				path: p,
+				name: path.Join(base, filepath.ToSlash(rel)),
+				fi:   fi,
+			})
+
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return files, nil
+}
+
+// archiveName returns the file name of an archive of the paths.
+func archiveName(paths []string, format string) string {
+	name := "archive"
+	if len(paths) == 1 {
+		if abs, err := filepath.Abs(paths[0]); err == nil {
+			name = filepath.Base(abs)
+		}
+	}
+
+	if format == archiveTarGzip {
+		return name + ".tar.gz"
+	}
+	return name + ".tar"
+}
+
+// isDirectory returns true if path is a directory.
+func isDirectory(path string) bool {
+	fi, err := os.Stat(path)
+	return err == nil && fi.IsDir()
+}
+
+// countArchiveFiles returns the number of regular files in the list.
+func countArchiveFiles(files []archiveFile) int {
+	var n int
+	for _, f := range files {
+		if f.fi.Mode().IsRegular() {
+			n++
+		}
+	}
+	return n
+}
+
+// writeArchive writes a tar archive of the files to w, compressed with gzip if format is archiveTarGzip.
+func writeArchive(w io.Writer, files []archiveFile, format string) error {
+	var gw *gzip.Writer
+	if format == archiveTarGzip {
+		gw = gzip.NewWriter(w)
+		w = gw
+	}
+
+	tw := tar.NewWriter(w)
+
+	for _, f := range files {
+		var link string
+		if f.fi.Mode()&os.ModeSymlink != 0 {
+			var err error
+			if link, err = os.Readlink(f.path); err != nil {
+				return err
+			}
+		}
+
+		hdr, err := tar.FileInfoHeader(f.fi, link)
+		if err != nil {
+			return fmt.Errorf("unable to archive %s. err: %v", f.path, err)
+		}
+		hdr.Name = f.name
+		if f.fi.IsDir() {
+			hdr.Name += "/"
+		}
+
+		// NOTE(vincent): user and group names mean nothing on the other device.
+		hdr.Uid, hdr.Gid, hdr.Uname, hdr.Gname = 0, 0, "", ""
+
+		if err := tw.WriteHeader(hdr); err != nil {
+			return err
+		}
+
+		if !f.fi.Mode().IsRegular() {
+			continue
+		}
+
+		if err := copyFileTo(tw, f.path); err != nil {
+			return fmt.Errorf("unable to archive %s. err: %v", f.path, err)
+		}
+	}
+
+	if err := tw.Close(); err != nil {
+		return err
+	}
+	if gw != nil {
+		return gw.Close()
+	}
+
+	return nil
+}
+
+func copyFileTo(w io.Writer, path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = io.Copy(w, f)
+	return err
+}
+
+// extractArchive extracts an archive created by writeArchive in dir and returns the number of files extracted.
+//
+// Extraction refuses anything which could write outside of dir: absolute paths, paths with ..,
+// symlinks pointing outside of dir and paths going through a symlink. Existing files are never overwritten.
+func extractArchive(r io.Reader, format string, dir string) (int, error) {
+	switch format {
+	case archiveTar:
+	case archiveTarGzip:
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return 0, fmt.Errorf("unable to read archive. err: %v", err)
+		}
+		defer gr.Close()
+		r = gr
+	default:
+		return 0, errNotAnArchive
+	}
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return 0, err
+	}
+
+	//
+
+	tr := tar.NewReader(r)
+
+	var n int
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("unable to read archive. err: %v", err)
+		}
+
+		name, err := archiveEntryPath(hdr.Name)
+		if err != nil {
+			return n, err
+		}
+		target := filepath.Join(dir, name)
+
+		if err := checkNoSymlink(dir, name); err != nil {
+			return n, err
+		}
+		if err := os.MkdirAll(filepath.Dir(target), 0755); err != nil {
+			return n, err
+		}
+
+		mode := os.FileMode(hdr.Mode).Perm()
+
+		switch hdr.Typeflag {
+		case tar.TypeDir:
+			if err := os.Mkdir(target, mode|0700); err != nil && !os.IsExist(err) {
+				return n, err
+			}
+
+		case tar.TypeReg, tar.TypeRegA:
+			if err := extractFile(tr, target, mode); err != nil {
+				return n, err
+			}
+			os.Chtimes(target, hdr.ModTime, hdr.ModTime)
+			n++
+
+		case tar.TypeSymlink:
+			if !isLocalSymlink(dir, name, hdr.Linkname) {
+				return n, fmt.Errorf("symlink %s points outside of the archive", hdr.Name)
+			}
+
+			if err := os.Symlink(hdr.Linkname, target); err != nil {
+				return n, err
+			}
+
+		default:
+			return n, fmt.Errorf("unsupported file type for %s in archive", hdr.Name)
+		}
+	}
+
+	return n, nil
+}
+
+func extractFile(r io.Reader, path string, mode os.FileMode) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, mode)
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+
+	return f.Close()
+}
+
+// archiveEntryPath returns the path of an archive entry relative to the extraction directory.
+func archiveEntryPath(name string) (string, error) {
+	if path.IsAbs(name) || strings.Contains(name, `\`) {
+		return "", fmt.Errorf("invalid path %s in archive", name)
+	}
+
+	p := filepath.FromSlash(path.Clean(name))
+	if !isLocalPath(p) || p == "." {
+		return "", fmt.Errorf("invalid path %s in archive", name)
+	}
+
+	return p, nil
+}
+
+// isLocalPath returns true if the relative path p stays in its base directory.
+func isLocalPath(p string) bool {
+	p = filepath.Clean(p)
+	return !filepath.IsAbs(p) && p != ".." && !strings.HasPrefix(p, ".."+string(filepath.Separator))
+}
+
+// isLocalSymlink returns true if a symlink name in dir pointing to linkname can't resolve outside of dir.
+//
+// Going up with .. from a symlink could leave dir even if the symlink itself points inside it,
+// for example b -> a/.. with a -> . so .. is only allowed after directories which exist and aren't symlinks.
+// Anything else is checked lexically: every symlink extracted points inside dir.
+func isLocalSymlink(dir, name, linkname string) bool {
+	if path.IsAbs(linkname) || filepath.IsAbs(linkname) || strings.Contains(linkname, `\`) {
+		return false
+	}
+
+	p := filepath.Dir(name)
+	for _, part := range strings.Split(linkname, "/") {
+		switch part {
+		case "", ".":
+			continue
+		case "..":
+			if !isRealDir(dir, p) {
+				return false
+			}
+			p = filepath.Join(p, part)
+		default:
+			p = filepath.Join(p, part)
+		}
+
+		if !isLocalPath(p) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isRealDir returns true if the relative path p in dir is a directory and none of its components is a symlink.
+func isRealDir(dir, p string) bool {
+	if p == "." {
+		return true
+	}
+
+	for _, part := range strings.Split(p, string(filepath.Separator)) {
+		dir = filepath.Join(dir, part)
+
+		fi, err := os.Lstat(dir)
+		if err != nil || !fi.IsDir() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// checkNoSymlink returns an error if one of the parent directories of name in dir is a symlink,
+// writing through it could end up outside of dir.
+func checkNoSymlink(dir, name string) error {
+	parts := strings.Split(filepath.Dir(name), string(filepath.Separator))
+
+	p := dir
+	for _, part := range parts {
+		if part == "." {
+			continue
+		}
+		p = filepath.Join(p, part)
+
+		fi, err := os.Lstat(p)
+		switch {
+		case os.IsNotExist(err):
+			return nil
+		case err != nil:
+			return err
+		case fi.Mode()&os.ModeSymlink != 0:
+			return fmt.Errorf("path %s in archive goes through a symlink", name)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestArchive(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	src := filepath.Join(dir, "src")
+
+	mustWriteFile := func(path, content string, mode os.FileMode) {
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, ioutil.WriteFile(path, []byte(content), mode))
+		require.NoError(t, os.Chmod(path, mode))
+	}
+
+	mustWriteFile(filepath.Join(src, "photos", "a.png"), "a", 0644)
+	mustWriteFile(filepath.Join(src, "photos", "2020", "b.png"), "b", 0600)
+	mustWriteFile(filepath.Join(src, "photos", "run.sh"), "#!/bin/sh", 0755)
+	mustWriteFile(filepath.Join(src, "notes.txt"), "notes", 0644)
+	require.NoError(t, os.Symlink("a.png", filepath.Join(src, "photos", "latest.png")))
+
+	for _, format := range []string{archiveTar, archiveTarGzip} {
+		format := format
+		t.Run(format, func(t *testing.T) {
+			files, err := collectArchiveFiles([]string{filepath.Join(src, "photos") + "/", filepath.Join(src, "notes.txt")})
+			require.NoError(t, err)
+			require.Equal(t, 4, countArchiveFiles(files))
+
+			var buf bytes.Buffer
+			require.NoError(t, writeArchive(&buf, files, format))
+
+			dst := filepath.Join(dir, "dst-"+format)
+
+			n, err := extractArchive(&buf, format, dst)
+			require.NoError(t, err)
+			require.Equal(t, 4, n)
+
+			for name, expected := range map[string]string{
+				"photos/a.png":      "a",
+				"photos/2020/b.png": "b",
+				"photos/run.sh":     "#!/bin/sh",
+				"notes.txt":         "notes",
+			} {
+				data, err := ioutil.ReadFile(filepath.Join(dst, filepath.FromSlash(name)))
+				require.NoError(t, err)
+				require.Equal(t, expected, string(data))
+			}
+
+			fi, err := os.Stat(filepath.Join(dst, "photos", "2020", "b.png"))
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+			fi, err = os.Stat(filepath.Join(dst, "photos", "run.sh"))
+			require.NoError(t, err)
+			require.Equal(t, os.FileMode(0755), fi.Mode().Perm())
+
+			link, err := os.Readlink(filepath.Join(dst, "photos", "latest.png"))
+			require.NoError(t, err)
+			require.Equal(t, "a.png", link)
+		})
+	}
+
+	t.Run("existing-file", func(t *testing.T) {
+		files, err := collectArchiveFiles([]string{filepath.Join(src, "notes.txt")})
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, writeArchive(&buf, files, archiveTar))
+
+		_, err = extractArchive(&buf, archiveTar, filepath.Join(dir, "dst-tar"))
+		require.Error(t, err)
+	})
+
+	t.Run("same-name", func(t *testing.T) {
+		mustWriteFile(filepath.Join(src, "other", "notes.txt"), "other notes", 0644)
+
+		_, err := collectArchiveFiles([]string{filepath.Join(src, "notes.txt"), filepath.Join(src, "other", "notes.txt")})
+		require.Error(t, err)
+	})
+
+	t.Run("not-an-archive", func(t *testing.T) {
+		_, err := extractArchive(bytes.NewReader(nil), "", dir)
+		require.Equal(t, errNotAnArchive, err)
+	})
+}
+
+func TestExtractArchiveUnsafe(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	outside := filepath.Join(dir, "outside")
+	require.NoError(t, os.Mkdir(outside, 0755))
+
+	testCases := []struct {
+		name    string
+		headers []tar.Header
+	}{
+		{"parent", []tar.Header{
+			{Name: "../evil.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"nested-parent", []tar.Header{
+			{Name: "a/../../evil.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"absolute", []tar.Header{
+			{Name: filepath.Join(outside, "evil.txt"), Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"symlink-absolute", []tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: outside},
+		}},
+		{"symlink-parent", []tar.Header{
+			{Name: "a/link", Typeflag: tar.TypeSymlink, Linkname: "../../outside"},
+		}},
+		{"symlink-through-symlink", []tar.Header{
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "b", Typeflag: tar.TypeSymlink, Linkname: "a/.."},
+		}},
+		{"symlink-through-later-symlink", []tar.Header{
+			{Name: "b", Typeflag: tar.TypeSymlink, Linkname: "a/.."},
+			{Name: "a", Typeflag: tar.TypeSymlink, Linkname: "."},
+		}},
+		{"through-symlink", []tar.Header{
+			{Name: "link", Typeflag: tar.TypeSymlink, Linkname: "."},
+			{Name: "link/evil.txt", Typeflag: tar.TypeReg, Mode: 0644},
+		}},
+		{"hard-link", []tar.Header{
+			{Name: "link", Typeflag: tar.TypeLink, Linkname: "/etc/passwd"},
+		}},
+	}
+
+	for i, tc := range testCases {
+		tc := tc
+		dst := filepath.Join(dir, "dst", strconv.Itoa(i))
+
+		t.Run(tc.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			tw := tar.NewWriter(&buf)
+			for _, hdr := range tc.headers {
+				hdr := hdr
+				require.NoError(t, tw.WriteHeader(&hdr))
+			}
+			require.NoError(t, tw.Close())
+
+			_, err := extractArchive(&buf, archiveTar, dst)
+			require.Error(t, err)
+
+			_, err = os.Lstat(filepath.Join(outside, "evil.txt"))
+			require.True(t, os.IsNotExist(err))
+			_, err = os.Lstat(filepath.Join(dir, "dst", "evil.txt"))
+			require.True(t, os.IsNotExist(err))
+		})
+	}
+}
+
+func TestArchiveName(t *testing.T) {
+	require.Equal(t, "photos.tar", archiveName([]string{"/home/vincent/photos/"}, archiveTar))
+	require.Equal(t, "photos.tar.gz", archiveName([]string{"/home/vincent/photos"}, archiveTarGzip))
+	require.Equal(t, "archive.tar", archiveName([]string{"a.txt", "b.txt"}, archiveTar))
+}
@@ -1,110 +1,717 @@
 package main
 
 import (
+	"bufio"
 	"bytes"
+	"crypto/sha256"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/oklog/ulid/v2"
 )
 
 type clientConfig struct {
+	// Endpoint is the URL of the server, or unix:///path/to/socket for a server listening on an unix socket.
 	Endpoint       string
 	PSKey          secretBoxKey
 	EncryptKey     secretBoxKey
 	SignPublicKey  publicKey
 	SignPrivateKey privateKey
+
+	// DeviceName is the name of this device in the server registry.
+	// If empty the server verifies requests with its SignPublicKey.
+	DeviceName string `toml:",omitempty"`
+
+	// RetiredEncryptKeys are the previous encryption keys, used to decrypt
+	// the entries created before the key was rotated.
+	RetiredEncryptKeys []secretBoxKey `toml:",omitempty"`
+
+	// CertFingerprint is the SHA256 fingerprint of the server certificate, hex encoded.
+	// If set the certificate must match it, this is how a self-signed certificate is trusted.
+	CertFingerprint string `toml:",omitempty"`
+
+	// ClientCert enables the authentication with a client certificate of the sign key. The payloads are
+	// then only protected by TLS and the pre-shared key isn't needed; the server must have client certificates enabled.
+	ClientCert bool `toml:",omitempty"`
+	// ClientCertFile is the client certificate issued by the client CA of the server, PEM encoded.
+	// If empty a self-signed certificate is used.
+	ClientCertFile string `toml:",omitempty"`
+
+	// Clipboard configures the system clipboard used by copy and paste with -clipboard.
+	Clipboard *clipboardConfig `toml:",omitempty"`
+}
+
+// encryptKeys returns the encryption key followed by the retired ones.
+func (c clientConfig) encryptKeys() []secretBoxKey {
+	return append([]secretBoxKey{c.EncryptKey}, c.RetiredEncryptKeys...)
 }
 
 func (c clientConfig) Validate() error {
-	if _, err := url.Parse(c.Endpoint); err != nil {
+	u, err := url.Parse(c.Endpoint)
+	if err != nil {
 		return err
 	}
+	if socket, ok := unixEndpointSocket(c.Endpoint); ok && socket == "" {
+		return fmt.Errorf("endpoint %q has no socket path", c.Endpoint)
+	}
+	if c.CertFingerprint != "" {
+		if _, err := parseCertFingerprint(c.CertFingerprint); err != nil {
+			return err
+		}
+		if u.Scheme != "https" {
+			return fmt.Errorf("a certificate fingerprint requires an https endpoint")
+		}
+	}
+	if c.ClientCert && u.Scheme != "https" {
+		return fmt.Errorf("a client certificate requires an https endpoint")
+	}
+	if c.ClientCertFile != "" && !c.ClientCert {
+		return fmt.Errorf("the client certificate file requires the client certificate to be enabled")
+	}
 	if !c.PSKey.IsValid() {
 		return fmt.Errorf("ps key is invalid")
 	}
 	if !c.EncryptKey.IsValid() {
 		return fmt.Errorf("encrypt key is invalid")
 	}
+	for _, key := range c.RetiredEncryptKeys {
+		if !key.IsValid() {
+			return fmt.Errorf("retired encrypt key is invalid")
+		}
+	}
 	if !c.SignPrivateKey.IsValid() {
 		return fmt.Errorf("sign public key is invalid")
 	}
 	if !c.SignPublicKey.IsValid() {
 		return fmt.Errorf("sign public key is invalid")
 	}
+	if c.DeviceName != "" && !isValidDeviceName(c.DeviceName) {
+		return errInvalidDeviceName
+	}
 	return nil
 }
 
+const (
+	// defaultClientRetries is how many times a request failing because of
+	// a network error is retried.
+	defaultClientRetries = 5
+	// defaultClientRetryDelay is the delay before the first retry, it grows linearly with each retry.
+	defaultClientRetryDelay = time.Second
+)
+
 type client struct {
 	conf       clientConfig
-	httpClient http.Client
+	httpClient *http.Client
+	box        payloadBox
+
+	retries    int
+	retryDelay time.Duration
 }
 
 func newClient(conf clientConfig) *client {
+	httpClient, err := newClientHTTPClient(conf)
+	if err != nil {
+		// NOTE(vincent): Validate rejects an invalid fingerprint, if it wasn't called or if the
+		// client certificate is unusable fail closed instead of trusting any certificate or
+		// sending the payloads without the certificate.
+		httpClient = &http.Client{
+			Transport: errorTransport{err},
+		}
+	}
+
 	return &client{
 		conf:       conf,
-		httpClient: http.Client{},
+		httpClient: httpClient,
+		box:        payloadBox{psKey: conf.PSKey, clear: conf.ClientCert},
+		retries:    defaultClientRetries,
+		retryDelay: defaultClientRetryDelay,
+	}
+}
+
+// transportError is an error which happened while talking to the server,
+// as opposed to an error returned by the server. These are worth retrying.
+type transportError struct {
+	msg string
+	err error
+}
+
+func (e *transportError) Error() string {
+	return fmt.Sprintf("%s. err: %v", e.msg, e.err)
+}
+
+// retry calls fn until it succeeds, fails with an error which isn't a transportError
+// or has been retried c.retries times.
+func (c *client) retry(fn func() error) error {
+	for attempt := 1; ; attempt++ {
+		err := fn()
+		if _, ok := err.(*transportError); !ok || attempt > c.retries {
+			return err
+		}
+
+		time.Sleep(time.Duration(attempt) * c.retryDelay)
 	}
 }
 
 func (c *client) makeURL(path string) string {
-	return c.conf.Endpoint + path
+	return endpointURL(c.conf.Endpoint, path)
 }
 
-func (c *client) doCopy(req copyRequest) ([]byte, error) {
-	return c.doRequest(req, http.MethodPost, http.StatusAccepted, "/api/v1/copy")
+// The following methods sign the request with a new envelope before sending it.
+
+// doCopy streams the content to the server and returns the ID of the new entry.
+//
+// The content is sent in frames as it's read (see frame.go), the signature
+// is sent in the trailer once the whole content has been read.
+func (c *client) doCopy(hdr copyStreamHeader, content io.Reader) (ulid.ULID, error) {
+	env := newEnvelope(apiVersion2, actionCopy)
+	env.Device = c.conf.DeviceName
+	hdr.Envelope = &env
+
+	pr, pw := io.Pipe()
+	go func() {
+		pw.CloseWithError(c.writeCopyBody(pw, hdr, content))
+	}()
+	defer pr.Close()
+
+	body, err := c.send(http.MethodPost, http.StatusAccepted, "/api/v2/copy", pr)
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	var id ulid.ULID
+	if len(body) != len(id) {
+		return ulid.ULID{}, fmt.Errorf("invalid entry id in response")
+	}
+	copy(id[:], body)
+
+	return id, nil
 }
-func (c *client) doMove(req moveRequest) ([]byte, error) {
-	return c.doRequest(req, http.MethodDelete, http.StatusOK, "/api/v1/move")
+
+func (c *client) writeCopyBody(w io.Writer, hdr copyStreamHeader, content io.Reader) error {
+	data, err := json.Marshal(hdr)
+	if err != nil {
+		return err
+	}
+	if err := writeFrame(w, c.box.seal(data)); err != nil {
+		return err
+	}
+
+	//
+
+	hash := sha256.New()
+	if err := writeContentFrames(w, io.TeeReader(content, hash)); err != nil {
+		return err
+	}
+
+	//
+
+	trailer := copyStreamTrailer{
+		Signature: sign(c.conf.SignPrivateKey, hdr.Envelope.message(hash.Sum(nil))),
+	}
+
+	data, err = json.Marshal(trailer)
+	if err != nil {
+		return err
+	}
+
+	return writeFrame(w, c.box.seal(data))
 }
-func (c *client) doPaste(req pasteRequest) ([]byte, error) {
-	return c.doRequest(req, http.MethodPost, http.StatusOK, "/api/v1/paste")
+
+// doMove moves an entry out of the server.
+// The caller must close the returned reader which yields the content as stored.
+func (c *client) doMove(req moveRequest) (entryInfo, io.ReadCloser, error) {
+	req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionMove, req.ID[:])
+	return c.doEntryRequest(req, http.MethodDelete, "/api/v2/move")
 }
-func (c *client) doList(req listRequest) ([]byte, error) {
-	return c.doRequest(req, http.MethodPost, http.StatusOK, "/api/v1/list")
+
+// doPaste copies an entry from the server.
+// The caller must close the returned reader which yields the content as stored.
+func (c *client) doPaste(req pasteRequest) (entryInfo, io.ReadCloser, error) {
+	req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionPaste, req.signedData())
+	return c.doEntryRequest(req, http.MethodPost, "/api/v2/paste")
 }
 
-func (c *client) doRequest(req interface{}, method string, expCode int, path string) ([]byte, error) {
-	data, err := json.Marshal(req)
+// doResumablePaste is like doPaste but the returned reader requests the rest
+// of the entry again when the download is interrupted by a network error.
+func (c *client) doResumablePaste(req pasteRequest) (entryInfo, io.ReadCloser, error) {
+	var (
+		info entryInfo
+		body io.ReadCloser
+	)
+
+	err := c.retry(func() (err error) {
+		info, body, err = c.doPaste(req)
+		return err
+	})
 	if err != nil {
-		return nil, err
+		return entryInfo{}, nil, err
+	}
+
+	r := &resumingReader{
+		c:      c,
+		info:   info,
+		offset: req.Offset,
+		body:   body,
+	}
+
+	return info, r, nil
+}
+
+// resumingReader reads the content of an entry and resumes the download
+// where it stopped after a network error.
+type resumingReader struct {
+	c      *client
+	info   entryInfo
+	offset int64
+	body   io.ReadCloser
+
+	// failures is the number of failures since the last successful read.
+	failures int
+}
+
+func (r *resumingReader) Read(p []byte) (int, error) {
+	for {
+		n, err := r.body.Read(p)
+		r.offset += int64(n)
+
+		if err == io.EOF && r.offset < r.info.Size {
+			err = io.ErrUnexpectedEOF
+		}
+
+		switch {
+		case err == nil || err == io.EOF:
+			r.failures = 0
+			return n, err
+		case n > 0:
+			// NOTE(vincent): the error is returned again by the next read
+			r.failures = 0
+			return n, nil
+		}
+
+		r.failures++
+		if r.failures > r.c.retries {
+			return 0, err
+		}
+
+		if err := r.resume(); err != nil {
+			return 0, err
+		}
 	}
+}
+
+func (r *resumingReader) resume() error {
+	r.body.Close()
 
-	ciphertext := secretBoxSeal(data, c.conf.PSKey)
+	return r.c.retry(func() error {
+		req := pasteRequest{ID: r.info.ID, Offset: r.offset}
+
+		_, body, err := r.c.doPaste(req)
+		if err != nil {
+			return err
+		}
+		r.body = body
+
+		return nil
+	})
+}
+
+func (r *resumingReader) Close() error {
+	return r.body.Close()
+}
+
+// doUpload uploads the content with an upload session and returns the ID of the new entry.
+//
+// The content is sent in chunks, each chunk is retried after a network error
+// so a flaky connection doesn't mean sending the whole content again.
+// Content which fits in a single chunk is sent with doCopy instead, a session isn't worth it.
+func (c *client) doUpload(req uploadStartRequest, content io.Reader) (ulid.ULID, error) {
+	head := make([]byte, uploadChunkSize)
+
+	n, err := io.ReadFull(content, head)
+	switch {
+	case err == io.EOF || err == io.ErrUnexpectedEOF:
+		return c.doCopy(copyStreamHeader{TTL: req.TTL, Metadata: req.Metadata}, bytes.NewReader(head[:n]))
+	case err != nil:
+		return ulid.ULID{}, err
+	}
+
+	// NOTE(vincent): the session is lost if the server restarts or if it expires; the chunks already
+	// sent are kept in a temporary file so that the upload can start again with a new session.
+	spool, err := ioutil.TempFile("", "apero-upload")
+	if err != nil {
+		return ulid.ULID{}, fmt.Errorf("unable to create upload spool file. err: %v", err)
+	}
+	defer func() {
+		spool.Close()
+		os.Remove(spool.Name())
+	}()
+
+	u := &upload{
+		c:       c,
+		req:     req,
+		content: io.MultiReader(bytes.NewReader(head), content),
+		spool:   spool,
+	}
+
+	for restarts := 0; ; restarts++ {
+		id, err := u.run()
+		if err != errUploadNotFound || restarts >= c.retries {
+			return id, err
+		}
+	}
+}
+
+// upload is the state of doUpload.
+type upload struct {
+	c       *client
+	req     uploadStartRequest
+	content io.Reader
+
+	// spool holds the size bytes already read from content.
+	spool *os.File
+	size  int64
+}
+
+// Read reads the content and appends what it read to the spool file.
+func (u *upload) Read(p []byte) (int, error) {
+	n, err := u.content.Read(p)
+	if n > 0 {
+		if _, werr := u.spool.WriteAt(p[:n], u.size); werr != nil {
+			return 0, fmt.Errorf("unable to write upload spool file. err: %v", werr)
+		}
+		u.size += int64(n)
+	}
+	return n, err
+}
+
+// run sends the content with a new session: what's in the spool file first, then the rest of the content.
+func (u *upload) run() (ulid.ULID, error) {
+	c := u.c
+
+	var start uploadStartResponse
+
+	err := c.retry(func() error {
+		req := u.req
+		req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionUploadStart, req.Metadata)
+
+		body, err := c.doRequest(req, http.MethodPost, http.StatusOK, "/api/v2/upload/start")
+		if err != nil {
+			return err
+		}
+
+		return json.Unmarshal(body, &start)
+	})
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+	if start.ChunkSize <= 0 || start.ChunkSize > uploadChunkSize {
+		return ulid.ULID{}, fmt.Errorf("invalid chunk size %d", start.ChunkSize)
+	}
 
 	//
 
-	hreq, err := http.NewRequest(method, c.makeURL(path), bytes.NewReader(ciphertext))
+	var (
+		content = io.MultiReader(io.NewSectionReader(u.spool, 0, u.size), u)
+		buf     = make([]byte, start.ChunkSize)
+		chunks  int64
+	)
+
+	for {
+		n, rerr := io.ReadFull(content, buf)
+		if rerr != nil && rerr != io.EOF && rerr != io.ErrUnexpectedEOF {
+			return ulid.ULID{}, rerr
+		}
+
+		if n > 0 {
+			err := c.retry(func() error {
+				return c.putChunk(start.ID, chunks, buf[:n])
+			})
+			if err != nil {
+				return ulid.ULID{}, err
+			}
+			chunks++
+		}
+
+		if rerr != nil {
+			break
+		}
+	}
+
+	// NOTE(vincent): finishing is idempotent, if the response is lost the same entry ID is returned again.
+
+	var id ulid.ULID
+
+	err = c.retry(func() error {
+		freq := uploadFinishRequest{ID: start.ID, Chunks: chunks}
+		freq.Envelope, freq.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionUploadFinish, uploadMessage(start.ID, chunks, nil))
+
+		body, err := c.doRequest(freq, http.MethodPost, http.StatusAccepted, "/api/v2/upload/finish")
+		switch {
+		case err == errEntryNotFound:
+			return errUploadNotFound
+		case err != nil:
+			return err
+		case len(body) != len(id):
+			return fmt.Errorf("invalid entry id in response")
+		}
+
+		copy(id[:], body)
+
+		return nil
+	})
+
+	return id, err
+}
+
+func (c *client) putChunk(id ulid.ULID, index int64, chunk []byte) error {
+	digest := sha256.Sum256(chunk)
+
+	hdr := uploadChunkHeader{ID: id, Index: index}
+	hdr.Envelope, hdr.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionUploadChunk, uploadMessage(id, index, digest[:]))
+
+	data, err := json.Marshal(hdr)
+	if err != nil {
+		return err
+	}
+
+	var buf bytes.Buffer
+	if err := writeFrame(&buf, c.box.seal(data)); err != nil {
+		return err
+	}
+	if err := writeFrame(&buf, chunk); err != nil {
+		return err
+	}
+
+	//
+
+	body, err := c.send(http.MethodPut, http.StatusOK, "/api/v2/upload/chunk", &buf)
+	switch {
+	case err == errEntryNotFound:
+		return errUploadNotFound
+	case err != nil:
+		return err
+	}
+
+	var status uploadStatus
+	if err := json.Unmarshal(body, &status); err != nil {
+		return fmt.Errorf("unable to unmarshal upload status. err: %v", err)
+	}
+	if status.Chunks <= index {
+		return fmt.Errorf("the staging server didn't acknowledge chunk %d", index)
+	}
+
+	return nil
+}
+
+func (c *client) doList(req listRequest) ([]byte, error) {
+	req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionList, nil)
+	return c.doRequest(req, http.MethodPost, http.StatusOK, "/api/v2/list")
+}
+
+// doRotateKey replaces the signing key of the device with pub.
+// The request is signed with the current key, priv proves the device owns the new one.
+func (c *client) doRotateKey(pub publicKey, priv privateKey) (rotateKeyResponse, error) {
+	req := rotateKeyRequest{PublicKey: pub}
+	req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionRotateKey, pub)
+	req.Proof = sign(priv, req.Envelope.message(pub))
+
+	body, err := c.doRequest(req, http.MethodPost, http.StatusOK, "/api/v2/rotate-key")
+	if err != nil {
+		return rotateKeyResponse{}, err
+	}
+
+	var resp rotateKeyResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return rotateKeyResponse{}, fmt.Errorf("unable to unmarshal response")
+	}
+
+	return resp, nil
+}
+
+// doPoll waits up to timeout for entries created after the entry with the ID after.
+// If after is empty it waits for entries created from now on.
+func (c *client) doPoll(after ulid.ULID, timeout time.Duration) ([]entryInfo, error) {
+	req := subscribeRequest{After: after, Timeout: int64(timeout.Seconds())}
+	req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionSubscribe, after[:])
+
+	body, err := c.doRequest(req, http.MethodPost, http.StatusOK, "/api/v2/subscribe")
 	if err != nil {
 		return nil, err
 	}
+
+	var resp subscribeResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return nil, fmt.Errorf("unable to unmarshal response")
+	}
+
+	return resp.Entries, nil
+}
+
+// doWatch calls fn with the entries created after the entry with the ID after, as the server
+// sends them. If after is empty only the entries created from now on are sent.
+//
+// It returns when the connection is closed or if fn returns an error.
+func (c *client) doWatch(after ulid.ULID, fn func(entryInfo) error) error {
+	req := subscribeRequest{After: after}
+	req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionSubscribe, after[:])
+
+	data, err := json.Marshal(req)
+	if err != nil {
+		return err
+	}
+
+	hreq, err := http.NewRequest(http.MethodPost, c.makeURL("/api/v2/subscribe"), bytes.NewReader(c.box.seal(data)))
+	if err != nil {
+		return err
+	}
 	hreq.Header.Set("Content-Type", "application/octet-stream")
+	hreq.Header.Set("Accept", "text/event-stream")
 
 	resp, err := c.httpClient.Do(hreq)
 	if err != nil {
-		return nil, fmt.Errorf("unable to copy to staging server. body=%q err: %v", maybeReadHTTPResponseBody(resp), err)
+		return &transportError{"unable to reach the staging server", err}
 	}
-	if resp.StatusCode == http.StatusNotFound {
-		return nil, errEntryNotFound
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("invalid status code %s. body=%q", resp.Status, maybeReadHTTPResponseBody(resp))
 	}
-	if resp.StatusCode != expCode {
-		return nil, fmt.Errorf("invalid status code %s. body=%q", resp.Status, maybeReadHTTPResponseBody(resp))
+	if typ := resp.Header.Get("Content-Type"); typ != "text/event-stream" {
+		return fmt.Errorf("invalid content type %q, the server doesn't support event streams", typ)
+	}
+
+	// Only the data of the events matter, see handleSubscribe
+
+	scanner := bufio.NewScanner(resp.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "data: ") {
+			continue
+		}
+
+		box, err := base64.StdEncoding.DecodeString(line[len("data: "):])
+		if err != nil {
+			return fmt.Errorf("invalid event data. err: %v", err)
+		}
+		data, ok := c.box.open(box)
+		if !ok {
+			return fmt.Errorf("unable to open event box")
+		}
+
+		var info entryInfo
+		if err := json.Unmarshal(data, &info); err != nil {
+			return fmt.Errorf("unable to unmarshal event. err: %v", err)
+		}
+
+		if err := fn(info); err != nil {
+			return err
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return &transportError{"unable to read event stream", err}
+	}
+
+	return &transportError{"event stream closed", io.EOF}
+}
+
+func (c *client) doEntryRequest(req interface{}, method string, path string) (entryInfo, io.ReadCloser, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return entryInfo{}, nil, err
+	}
+
+	resp, err := c.do(method, http.StatusOK, path, bytes.NewReader(c.box.seal(data)))
+	if err != nil {
+		return entryInfo{}, nil, err
 	}
 
 	//
 
-	body, err := readHTTPResponseBody(resp)
+	data, err = readFrame(resp.Body, maxControlRequestSize)
+	if err != nil {
+		resp.Body.Close()
+		return entryInfo{}, nil, &transportError{"unable to read entry header", err}
+	}
+
+	data, opened := c.box.open(data)
+	if !opened {
+		resp.Body.Close()
+		return entryInfo{}, nil, fmt.Errorf("unable to open response box")
+	}
+
+	var hdr entryHeader
+	if err := json.Unmarshal(data, &hdr); err != nil {
+		resp.Body.Close()
+		return entryInfo{}, nil, fmt.Errorf("unable to unmarshal entry header. err: %v", err)
+	}
+
+	return hdr.Entry, resp.Body, nil
+}
+
+func (c *client) doRequest(req interface{}, method string, expCode int, path string) ([]byte, error) {
+	data, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext := c.box.seal(data)
+
+	return c.send(method, expCode, path, bytes.NewReader(ciphertext))
+}
+
+// send sends the body and returns the opened response body.
+func (c *client) send(method string, expCode int, path string, body io.Reader) ([]byte, error) {
+	resp, err := c.do(method, expCode, path, body)
 	if err != nil {
-		return nil, fmt.Errorf("unable to read response body. err: %v", err)
+		return nil, err
 	}
 
-	body, opened := secretBoxOpen(body, c.conf.PSKey)
+	data, err := readHTTPResponseBody(resp)
+	if err != nil {
+		return nil, &transportError{"unable to read response body", err}
+	}
+
+	data, opened := c.box.open(data)
 	if !opened {
 		return nil, fmt.Errorf("unable to open response box")
 	}
 
-	return body, nil
+	return data, nil
+}
+
+// do sends the body and checks the response status code.
+// The caller must close the response body.
+func (c *client) do(method string, expCode int, path string, body io.Reader) (*http.Response, error) {
+	hreq, err := http.NewRequest(method, c.makeURL(path), body)
+	if err != nil {
+		return nil, err
+	}
+	hreq.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := c.httpClient.Do(hreq)
+	if err != nil {
+		return nil, &transportError{"unable to reach the staging server", err}
+	}
+	if resp.StatusCode == http.StatusNotFound {
+		resp.Body.Close()
+		return nil, errEntryNotFound
+	}
+	if resp.StatusCode == http.StatusRequestEntityTooLarge || resp.StatusCode == http.StatusInsufficientStorage {
+		return nil, fmt.Errorf("the staging server refused the entry: %s", maybeReadHTTPResponseBody(resp))
+	}
+	if resp.StatusCode != expCode {
+		return nil, fmt.Errorf("invalid status code %s. body=%q", resp.Status, maybeReadHTTPResponseBody(resp))
+	}
+
+	return resp, nil
 }
 
 func maybeReadHTTPResponseBody(resp *http.Response) string {
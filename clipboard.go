@@ -0,0 +1,280 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+var (
+	errNoClipboard    = errors.New("no clipboard backend found, install xclip, xsel or wl-clipboard or configure a command")
+	errEmptyClipboard = errors.New("clipboard is empty")
+)
+
+// textContentType is the MIME type of the text in the clipboard.
+const textContentType = "text/plain; charset=utf-8"
+
+// clipboard reads and writes the system clipboard.
+type clipboard interface {
+	// Read returns the content of the clipboard and its MIME type.
+	Read() ([]byte, string, error)
+	// Write replaces the content of the clipboard with content of the given MIME type.
+	Write(content []byte, contentType string) error
+}
+
+// clipboardConfig configures the access to the system clipboard.
+type clipboardConfig struct {
+	// Backend is one of xclip, xsel, wl-clipboard, pbcopy or command.
+	// If empty the backend is chosen based on the environment and the commands installed.
+	Backend string `toml:",omitempty"`
+
+	// ReadCommand and WriteCommand are the commands of the command backend.
+	// ReadCommand prints the content of the clipboard, WriteCommand reads the new content on its stdin;
+	// the MIME type of the content written is in the APERO_CONTENT_TYPE environment variable.
+	ReadCommand  []string `toml:",omitempty"`
+	WriteCommand []string `toml:",omitempty"`
+
+	// SyncMaxSize is the maximum size of the content synchronized by clipboard-sync, 10MiB if zero.
+	SyncMaxSize int64
+	// SyncContentTypes are the MIME types synchronized by clipboard-sync, like text/plain or image/*.
+	// If empty every type is synchronized.
+	SyncContentTypes []string `toml:",omitempty"`
+}
+
+// newClipboard returns the clipboard configured.
+//
+// lookPath and getenv are exec.LookPath and os.Getenv, goos is runtime.GOOS.
+func newClipboard(conf *clipboardConfig, lookPath func(string) (string, error), getenv func(string) string, goos string) (clipboard, error) {
+	if conf == nil {
+		conf = &clipboardConfig{}
+	}
+
+	backend := conf.Backend
+	if backend == "" && len(conf.ReadCommand) > 0 {
+		backend = "command"
+	}
+
+	installed := func(names ...string) bool {
+		for _, name := range names {
+			if _, err := lookPath(name); err != nil {
+				return false
+			}
+		}
+		return true
+	}
+
+	if backend == "" {
+		switch {
+		case goos == "darwin" && installed("pbcopy", "pbpaste"):
+			backend = "pbcopy"
+		case getenv("WAYLAND_DISPLAY") != "" && installed("wl-copy", "wl-paste"):
+			backend = "wl-clipboard"
+		case installed("xclip"):
+			backend = "xclip"
+		case installed("xsel"):
+			backend = "xsel"
+		default:
+			return nil, errNoClipboard
+		}
+	}
+
+	//
+
+	var cb *commandClipboard
+
+	switch backend {
+	case "xclip":
+		cb = &commandClipboard{
+			name:  "xclip",
+			types: []string{"xclip", "-selection", "clipboard", "-o", "-t", "TARGETS"},
+			read: func(contentType string) []string {
+				if isTextContentType(contentType) {
+					return []string{"xclip", "-selection", "clipboard", "-o"}
+				}
+				return []string{"xclip", "-selection", "clipboard", "-o", "-t", contentType}
+			},
+			write: func(contentType string) []string {
+				if isTextContentType(contentType) {
+					return []string{"xclip", "-selection", "clipboard", "-i"}
+				}
+				return []string{"xclip", "-selection", "clipboard", "-i", "-t", contentType}
+			},
+		}
+
+	case "xsel":
+		cb = &commandClipboard{
+			name:     "xsel",
+			read:     fixedCommand("xsel", "--clipboard", "--output"),
+			write:    fixedCommand("xsel", "--clipboard", "--input"),
+			textOnly: true,
+		}
+
+	case "wl-clipboard":
+		cb = &commandClipboard{
+			name:  "wl-clipboard",
+			types: []string{"wl-paste", "--list-types"},
+			read: func(contentType string) []string {
+				if isTextContentType(contentType) {
+					return []string{"wl-paste", "--no-newline", "--type", "text"}
+				}
+				return []string{"wl-paste", "--no-newline", "--type", contentType}
+			},
+			write: func(contentType string) []string {
+				if isTextContentType(contentType) {
+					return []string{"wl-copy", "--type", "text/plain"}
+				}
+				return []string{"wl-copy", "--type", contentType}
+			},
+		}
+
+	case "pbcopy":
+		cb = &commandClipboard{
+			name:     "pbcopy",
+			read:     fixedCommand("pbpaste"),
+			write:    fixedCommand("pbcopy"),
+			textOnly: true,
+		}
+
+	case "command":
+		if len(conf.ReadCommand) == 0 || len(conf.WriteCommand) == 0 {
+			return nil, errors.New("the command clipboard backend needs both ReadCommand and WriteCommand")
+		}
+
+		cb = &commandClipboard{
+			name:   "command",
+			read:   fixedCommand(conf.ReadCommand...),
+			write:  fixedCommand(conf.WriteCommand...),
+			detect: true,
+		}
+
+	default:
+		return nil, fmt.Errorf("unknown clipboard backend %q", backend)
+	}
+
+	if !installed(cb.read("")[0], cb.write("")[0]) {
+		return nil, fmt.Errorf("clipboard backend %s is not installed", backend)
+	}
+
+	return cb, nil
+}
+
+// commandClipboard is a clipboard accessed by running commands.
+type commandClipboard struct {
+	name string
+
+	// types is the command listing the MIME types available in the clipboard, one per line.
+	// If nil the clipboard is assumed to hold text, or the type is detected from the content if detect is true.
+	types  []string
+	detect bool
+
+	// read and write return the command reading or writing content of the given MIME type.
+	read  func(contentType string) []string
+	write func(contentType string) []string
+
+	// textOnly is true if the backend can't hold anything else than text.
+	textOnly bool
+}
+
+func fixedCommand(args ...string) func(string) []string {
+	return func(string) []string { return args }
+}
+
+func (c *commandClipboard) Read() ([]byte, string, error) {
+	contentType := textContentType
+
+	if c.types != nil {
+		var buf bytes.Buffer
+		if err := c.run(c.types, nil, &buf, ""); err != nil {
+			return nil, "", err
+		}
+		contentType = pickClipboardType(strings.Split(buf.String(), "\n"))
+	}
+
+	var buf bytes.Buffer
+	if err := c.run(c.read(contentType), nil, &buf, ""); err != nil {
+		return nil, "", err
+	}
+	if buf.Len() == 0 {
+		return nil, "", errEmptyClipboard
+	}
+
+	if c.detect {
+		contentType = http.DetectContentType(buf.Bytes())
+	}
+
+	return buf.Bytes(), contentType, nil
+}
+
+func (c *commandClipboard) Write(content []byte, contentType string) error {
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	if c.textOnly && !isTextContentType(contentType) {
+		return fmt.Errorf("clipboard backend %s can only hold text, not %s", c.name, contentType)
+	}
+
+	// NOTE(vincent): X11 clipboard tools fork a process which serves the content until
+	// something else is copied. That process inherits stdout and stderr, so they must not be pipes
+	// or running the command would never finish.
+	return c.run(c.write(contentType), bytes.NewReader(content), nil, contentType)
+}
+
+func (c *commandClipboard) run(args []string, stdin io.Reader, stdout io.Writer, contentType string) error {
+	cmd := exec.Command(args[0], args[1:]...)
+	cmd.Stdin = stdin
+	cmd.Stdout = stdout
+	cmd.Stderr = os.Stderr
+	if contentType != "" {
+		cmd.Env = append(os.Environ(), "APERO_CONTENT_TYPE="+contentType)
+	}
+
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("unable to run %s. err: %v", args[0], err)
+	}
+
+	return nil
+}
+
+// pickClipboardType returns the MIME type to read among the types available in the clipboard.
+//
+// Images are preferred since applications usually offer a text alternative to an image,
+// the other types are read as text.
+func pickClipboardType(types []string) string {
+	var image string
+	for _, typ := range types {
+		typ = strings.TrimSpace(typ)
+
+		switch {
+		case typ == "image/png":
+			return typ
+		case image == "" && strings.HasPrefix(typ, "image/"):
+			image = typ
+		}
+	}
+
+	if image != "" {
+		return image
+	}
+	return textContentType
+}
+
+func isTextContentType(contentType string) bool {
+	return contentType == "" || strings.HasPrefix(contentType, "text/")
+}
+
+// pasteToClipboard writes the content to the clipboard.
+// If the content type is unknown it's detected from the content.
+func pasteToClipboard(cb clipboard, r io.Reader, contentType string) error {
+	content, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+
+	return cb.Write(content, contentType)
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// defaultClipboardSyncMaxSize is the maximum size of the content synchronized
+// when the configuration doesn't have one.
+const defaultClipboardSyncMaxSize = 10 << 20
+
+// clipboardSyncer keeps the local clipboard and the staging server in sync.
+//
+// It remembers the hash of the content it last read from or wrote to the clipboard;
+// this way an entry applied from another device isn't copied back to the server
+// and an entry copied from this device isn't applied again when it comes back.
+type clipboardSyncer struct {
+	client *client
+	cb     clipboard
+	ttl    time.Duration
+	host   string
+
+	maxSize      int64
+	contentTypes []string
+
+	mu   sync.Mutex
+	last [sha256.Size]byte
+}
+
+func newClipboardSyncer(c *client, cb clipboard, ttl time.Duration) *clipboardSyncer {
+	s := &clipboardSyncer{
+		client:  c,
+		cb:      cb,
+		ttl:     ttl,
+		maxSize: defaultClipboardSyncMaxSize,
+	}
+	s.host, _ = os.Hostname()
+
+	if conf := c.conf.Clipboard; conf != nil {
+		if conf.SyncMaxSize > 0 {
+			s.maxSize = conf.SyncMaxSize
+		}
+		s.contentTypes = conf.SyncContentTypes
+	}
+
+	return s
+}
+
+// Init remembers the current content of the clipboard so that it's not copied on start.
+func (s *clipboardSyncer) Init() error {
+	content, _, err := s.cb.Read()
+	switch {
+	case err == errEmptyClipboard:
+		return nil
+	case err != nil:
+		return err
+	}
+
+	s.mu.Lock()
+	s.last = sha256.Sum256(content)
+	s.mu.Unlock()
+
+	return nil
+}
+
+// allowed returns true if a content of this type and size can be synchronized.
+func (s *clipboardSyncer) allowed(contentType string, size int64) bool {
+	if size > s.maxSize {
+		return false
+	}
+	return len(s.contentTypes) == 0 || matchContentType(s.contentTypes, contentType)
+}
+
+// SyncLocal copies the content of the clipboard to the staging server if it changed.
+// It returns the ID of the new entry and true if something was copied.
+func (s *clipboardSyncer) SyncLocal() (ulid.ULID, bool, error) {
+	s.mu.Lock()
+
+	content, contentType, err := s.cb.Read()
+	switch {
+	case err == errEmptyClipboard:
+		s.mu.Unlock()
+		return ulid.ULID{}, false, nil
+	case err != nil:
+		s.mu.Unlock()
+		return ulid.ULID{}, false, err
+	}
+
+	hash := sha256.Sum256(content)
+	if hash == s.last {
+		s.mu.Unlock()
+		return ulid.ULID{}, false, nil
+	}
+	if !s.allowed(contentType, int64(len(content))) {
+		s.last = hash
+		s.mu.Unlock()
+		return ulid.ULID{}, false, nil
+	}
+	previous := s.last
+
+	s.mu.Unlock()
+
+	//
+
+	key := s.client.conf.EncryptKey
+
+	metadata, err := sealMetadata(entryMetadata{
+		ContentType: contentType,
+		Size:        int64(len(content)),
+		Host:        s.host,
+	}, key)
+	if err != nil {
+		return ulid.ULID{}, false, err
+	}
+
+	ciphertext := secretStreamEncrypt(bytes.NewReader(content), key)
+	defer ciphertext.Close()
+
+	req := uploadStartRequest{
+		TTL:      int64(s.ttl.Seconds()),
+		Metadata: metadata,
+	}
+
+	id, err := s.client.doUpload(req, ciphertext)
+	if err != nil {
+		return ulid.ULID{}, false, err
+	}
+
+	// Only remember the content once it's on the server so that a failed upload is retried.
+	// If a remote entry was applied in the meantime the clipboard holds that one instead.
+	s.mu.Lock()
+	if s.last == previous {
+		s.last = hash
+	}
+	s.mu.Unlock()
+
+	return id, true, nil
+}
+
+// ApplyRemote writes the content of an entry copied from another device to the clipboard.
+// It returns true if the clipboard was changed.
+func (s *clipboardSyncer) ApplyRemote(entry entryInfo) (bool, error) {
+	deviceName := s.client.conf.DeviceName
+	if entry.Device != "" && entry.Device == deviceName {
+		return false, nil
+	}
+
+	md, key, err := openMetadataWithKeys(entry.Metadata, s.client.conf.encryptKeys())
+	if err != nil {
+		return false, nil
+	}
+	if md.ContentType != "" && !s.allowed(md.ContentType, md.Size) {
+		return false, nil
+	}
+
+	//
+
+	_, body, err := s.client.doResumablePaste(pasteRequest{ID: entry.ID})
+	if err != nil {
+		return false, err
+	}
+	defer body.Close()
+
+	plaintext, err := newSecretStreamReader(body, key)
+	if err != nil {
+		return false, fmt.Errorf("unable to decipher content. err: %v", err)
+	}
+
+	// NOTE(vincent): the size in the metadata is unknown for content copied from stdin.
+	content, err := ioutil.ReadAll(io.LimitReader(plaintext, s.maxSize+1))
+	if err != nil {
+		return false, fmt.Errorf("unable to decipher content. err: %v", err)
+	}
+	if len(content) == 0 || int64(len(content)) > s.maxSize {
+		return false, nil
+	}
+
+	contentType := md.ContentType
+	if contentType == "" {
+		contentType = http.DetectContentType(content)
+	}
+	if !s.allowed(contentType, int64(len(content))) {
+		return false, nil
+	}
+
+	//
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	hash := sha256.Sum256(content)
+	if hash == s.last {
+		return false, nil
+	}
+
+	if err := s.cb.Write(content, contentType); err != nil {
+		return false, err
+	}
+	s.last = hash
+
+	return true, nil
+}
+
+// matchContentType returns true if the MIME type matches one of the patterns,
+// which are either a MIME type or a wildcard like image/*.
+func matchContentType(patterns []string, contentType string) bool {
+	typ, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		switch {
+		case pattern == "*/*" || pattern == typ:
+			return true
+		case strings.HasSuffix(pattern, "/*") && strings.HasPrefix(typ, strings.TrimSuffix(pattern, "*")):
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestClipboardSync(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	laptopConf := client.conf
+	laptopConf.Clipboard = &clipboardConfig{SyncMaxSize: 10, SyncContentTypes: []string{"text/*"}}
+	laptopClipboard := &memoryClipboard{content: []byte("before"), contentType: textContentType}
+	laptop := newClipboardSyncer(newClient(laptopConf), laptopClipboard, 0)
+
+	phoneClipboard := &memoryClipboard{}
+	phone := newClipboardSyncer(client, phoneClipboard, 0)
+
+	require.NoError(t, laptop.Init())
+	require.NoError(t, phone.Init())
+
+	lastEntry := func() entryInfo {
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		require.NotEmpty(t, entries)
+		return entries[len(entries)-1]
+	}
+
+	t.Run("init", func(t *testing.T) {
+		// The content present on start isn't copied
+
+		_, copied, err := laptop.SyncLocal()
+		require.NoError(t, err)
+		require.False(t, copied)
+
+		_, copied, err = phone.SyncLocal()
+		require.NoError(t, err)
+		require.False(t, copied)
+	})
+
+	t.Run("copy", func(t *testing.T) {
+		laptopClipboard.content = []byte("hello")
+
+		id, copied, err := laptop.SyncLocal()
+		require.NoError(t, err)
+		require.True(t, copied)
+
+		entry := lastEntry()
+		require.Equal(t, id, entry.ID)
+
+		md, err := openMetadata(entry.Metadata, client.conf.EncryptKey)
+		require.NoError(t, err)
+		require.Equal(t, textContentType, md.ContentType)
+		require.Equal(t, int64(5), md.Size)
+
+		// Nothing changed
+
+		_, copied, err = laptop.SyncLocal()
+		require.NoError(t, err)
+		require.False(t, copied)
+	})
+
+	t.Run("apply", func(t *testing.T) {
+		entry := lastEntry()
+
+		applied, err := phone.ApplyRemote(entry)
+		require.NoError(t, err)
+		require.True(t, applied)
+		require.Equal(t, "hello", string(phoneClipboard.content))
+		require.Equal(t, textContentType, phoneClipboard.contentType)
+
+		// The content applied isn't echoed back
+
+		_, copied, err := phone.SyncLocal()
+		require.NoError(t, err)
+		require.False(t, copied)
+
+		// Neither is the entry applied to the device which copied it
+
+		applied, err = laptop.ApplyRemote(entry)
+		require.NoError(t, err)
+		require.False(t, applied)
+	})
+
+	t.Run("filters", func(t *testing.T) {
+		png := []byte("\x89PNG\x0d\x0a\x1a\x0a")
+
+		laptopClipboard.content, laptopClipboard.contentType = png, "image/png"
+
+		_, copied, err := laptop.SyncLocal()
+		require.NoError(t, err)
+		require.False(t, copied, "the type isn't synchronized")
+
+		laptopClipboard.content, laptopClipboard.contentType = []byte(strings.Repeat("a", 11)), textContentType
+
+		_, copied, err = laptop.SyncLocal()
+		require.NoError(t, err)
+		require.False(t, copied, "the content is too large")
+
+		// Same for the remote entries
+
+		phoneClipboard.content, phoneClipboard.contentType = png, "image/png"
+
+		_, copied, err = phone.SyncLocal()
+		require.NoError(t, err)
+		require.True(t, copied)
+
+		applied, err := laptop.ApplyRemote(lastEntry())
+		require.NoError(t, err)
+		require.False(t, applied)
+		require.Equal(t, textContentType, laptopClipboard.contentType)
+	})
+
+	t.Run("unknown-type", func(t *testing.T) {
+		// An entry copied from stdin without metadata
+
+		_, err := client.doCopy(copyStreamHeader{}, secretStreamEncrypt(strings.NewReader("world"), client.conf.EncryptKey))
+		require.NoError(t, err)
+
+		applied, err := laptop.ApplyRemote(lastEntry())
+		require.NoError(t, err)
+		require.True(t, applied)
+		require.Equal(t, "world", string(laptopClipboard.content))
+		require.Equal(t, textContentType, laptopClipboard.contentType)
+	})
+
+	t.Run("failed-upload", func(t *testing.T) {
+		// The content is copied again once the server accepts it
+
+		laptopClipboard.content = []byte("again")
+
+		api.conf.Limits.MaxEntries = 1
+
+		_, _, err := laptop.SyncLocal()
+		require.Error(t, err)
+
+		api.conf.Limits.MaxEntries = 0
+
+		_, copied, err := laptop.SyncLocal()
+		require.NoError(t, err)
+		require.True(t, copied)
+	})
+}
+
+func TestMatchContentType(t *testing.T) {
+	require.True(t, matchContentType([]string{"text/plain"}, "text/plain; charset=utf-8"))
+	require.True(t, matchContentType([]string{"image/png", "text/*"}, "text/html"))
+	require.True(t, matchContentType([]string{"*/*"}, "application/pdf"))
+	require.False(t, matchContentType([]string{"image/*"}, "text/plain"))
+	require.False(t, matchContentType([]string{"text/plain"}, "text/html"))
+	require.False(t, matchContentType([]string{"text/*"}, "invalid type"))
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewClipboard(t *testing.T) {
+	lookPath := func(installed ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, v := range installed {
+				if v == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", exec.ErrNotFound
+		}
+	}
+	getenv := func(env map[string]string) func(string) string {
+		return func(key string) string { return env[key] }
+	}
+	x11 := getenv(map[string]string{"DISPLAY": ":0"})
+	wayland := getenv(map[string]string{"WAYLAND_DISPLAY": "wayland-0"})
+
+	testCases := []struct {
+		name     string
+		conf     *clipboardConfig
+		lookPath func(string) (string, error)
+		getenv   func(string) string
+		goos     string
+		backend  string
+		err      string
+	}{
+		{"wayland", nil, lookPath("wl-copy", "wl-paste", "xclip"), wayland, "linux", "wl-clipboard", ""},
+		{"xwayland", nil, lookPath("xclip"), wayland, "linux", "xclip", ""},
+		{"xclip", nil, lookPath("xclip", "xsel"), x11, "linux", "xclip", ""},
+		{"xsel", nil, lookPath("xsel"), x11, "linux", "xsel", ""},
+		{"darwin", nil, lookPath("pbcopy", "pbpaste"), x11, "darwin", "pbcopy", ""},
+		{"none", nil, lookPath(), x11, "linux", "", errNoClipboard.Error()},
+		{"explicit", &clipboardConfig{Backend: "xsel"}, lookPath("xclip", "xsel"), x11, "linux", "xsel", ""},
+		{"not-installed", &clipboardConfig{Backend: "xsel"}, lookPath("xclip"), x11, "linux", "", "not installed"},
+		{"unknown", &clipboardConfig{Backend: "foo"}, lookPath("xclip"), x11, "linux", "", "unknown"},
+		{"command", &clipboardConfig{ReadCommand: []string{"xclip"}, WriteCommand: []string{"xsel"}}, lookPath("xclip", "xsel"), x11, "linux", "command", ""},
+		{"command-incomplete", &clipboardConfig{Backend: "command", ReadCommand: []string{"xclip"}}, lookPath("xclip"), x11, "linux", "", "WriteCommand"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			cb, err := newClipboard(tc.conf, tc.lookPath, tc.getenv, tc.goos)
+			if tc.err != "" {
+				require.Error(t, err)
+				require.Contains(t, err.Error(), tc.err)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tc.backend, cb.(*commandClipboard).name)
+		})
+	}
+}
+
+func TestCommandClipboard(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	// A fake clipboard keeping its content and type in files
+
+	contentPath := filepath.Join(dir, "content")
+	typePath := filepath.Join(dir, "type")
+
+	conf := &clipboardConfig{
+		ReadCommand:  []string{"sh", "-c", "cat " + contentPath},
+		WriteCommand: []string{"sh", "-c", `cat > ` + contentPath + ` && printf %s "$APERO_CONTENT_TYPE" > ` + typePath},
+	}
+
+	cb, err := newClipboard(conf, exec.LookPath, os.Getenv, "linux")
+	require.NoError(t, err)
+
+	t.Run("empty", func(t *testing.T) {
+		require.NoError(t, ioutil.WriteFile(contentPath, nil, 0600))
+
+		_, _, err := cb.Read()
+		require.Equal(t, errEmptyClipboard, err)
+	})
+
+	t.Run("text", func(t *testing.T) {
+		require.NoError(t, pasteToClipboard(cb, strings.NewReader("hello"), ""))
+
+		typ, err := ioutil.ReadFile(typePath)
+		require.NoError(t, err)
+		require.Equal(t, textContentType, string(typ))
+
+		content, contentType, err := cb.Read()
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(content))
+		require.Equal(t, textContentType, contentType)
+	})
+
+	t.Run("image", func(t *testing.T) {
+		png := []byte("\x89PNG\x0d\x0a\x1a\x0afoobar")
+
+		require.NoError(t, pasteToClipboard(cb, bytes.NewReader(png), "image/png"))
+
+		typ, err := ioutil.ReadFile(typePath)
+		require.NoError(t, err)
+		require.Equal(t, "image/png", string(typ))
+
+		content, contentType, err := cb.Read()
+		require.NoError(t, err)
+		require.Equal(t, png, content)
+		require.Equal(t, "image/png", contentType)
+	})
+
+	t.Run("failure", func(t *testing.T) {
+		cb := &commandClipboard{
+			name:  "command",
+			read:  fixedCommand("sh", "-c", "exit 1"),
+			write: fixedCommand("sh", "-c", "exit 1"),
+		}
+
+		_, _, err := cb.Read()
+		require.Error(t, err)
+		require.Error(t, cb.Write([]byte("hello"), textContentType))
+	})
+}
+
+func TestCommandClipboardTypes(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+
+	// The fake command prints the type it's asked for
+
+	cb := &commandClipboard{
+		name:  "fake",
+		types: []string{"sh", "-c", `printf 'TARGETS\nUTF8_STRING\nimage/png\n'`},
+		read: func(contentType string) []string {
+			return []string{"sh", "-c", "printf %s '" + contentType + "'"}
+		},
+		write: fixedCommand("sh", "-c", "cat >/dev/null"),
+	}
+
+	content, contentType, err := cb.Read()
+	require.NoError(t, err)
+	require.Equal(t, "image/png", contentType)
+	require.Equal(t, "image/png", string(content))
+
+	// A text only backend refuses images
+
+	cb.textOnly = true
+	require.Error(t, cb.Write([]byte("\x89PNG\x0d\x0a\x1a\x0a"), "image/png"))
+	require.NoError(t, cb.Write([]byte("hello"), textContentType))
+}
+
+func TestPickClipboardType(t *testing.T) {
+	require.Equal(t, textContentType, pickClipboardType(nil))
+	require.Equal(t, textContentType, pickClipboardType([]string{"TARGETS", "UTF8_STRING", "text/html"}))
+	require.Equal(t, "image/jpeg", pickClipboardType([]string{"text/html", "image/jpeg", "image/bmp"}))
+	require.Equal(t, "image/png", pickClipboardType([]string{"image/jpeg", " image/png "}))
+}
+
+type memoryClipboard struct {
+	content     []byte
+	contentType string
+}
+
+func (c *memoryClipboard) Read() ([]byte, string, error) {
+	if len(c.content) == 0 {
+		return nil, "", errEmptyClipboard
+	}
+	return c.content, c.contentType, nil
+}
+
+func (c *memoryClipboard) Write(content []byte, contentType string) error {
+	c.content, c.contentType = content, contentType
+	return nil
+}
+
+func TestPasteToClipboard(t *testing.T) {
+	var cb memoryClipboard
+
+	require.NoError(t, pasteToClipboard(&cb, strings.NewReader("hello"), "text/markdown"))
+	require.Equal(t, "hello", string(cb.content))
+	require.Equal(t, "text/markdown", cb.contentType)
+}
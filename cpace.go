@@ -0,0 +1,199 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdh"
+	crypto_rand "crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+)
+
+// This file implements CPace over X25519, the password-authenticated key exchange used to pair devices.
+//
+// Both sides know a low entropy password and hash it to a generator G of the curve. Each side sends
+// Y = y*G for a random scalar y and both compute the same point K = ya*yb*G.
+// An attacker in the middle gets a single guess of the password per exchange, it can't test other
+// guesses offline from what it has seen.
+//
+// All the group operations are X25519 scalar multiplications done by crypto/ecdh, the only arithmetic
+// done here is the Elligator 2 map from the hash of the password to G.
+//
+// See https://datatracker.ietf.org/doc/draft-irtf-cfrg-cpace/ and https://www.rfc-editor.org/rfc/rfc9380.html#section-6.7.1.
+
+const (
+	cpaceSideA = 'a'
+	cpaceSideB = 'b'
+
+	cpaceDSI = "apero CPace255"
+)
+
+var (
+	// curve25519P is the field prime 2^255 - 19 and curve25519A the A coefficient of the curve.
+	curve25519P = new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 255), big.NewInt(19))
+	curve25519A = big.NewInt(486662)
+
+	errCPaceInvalidMessage = errors.New("invalid CPace message")
+)
+
+// cpaceGenerator returns the X25519 encoding of the generator derived from the password.
+//
+// NOTE(vincent): math/big isn't constant time, the generator only depends on the password
+// which is a pairing code used once.
+func cpaceGenerator(password []byte) []byte {
+	var buf bytes.Buffer
+	for _, p := range [][]byte{[]byte(cpaceDSI), password} {
+		writeLengthPrefixed(&buf, p)
+	}
+	h := sha512.Sum512(buf.Bytes())
+
+	// The field element is the first 32 bytes of the hash, little endian, without the top bit
+
+	var le [32]byte
+	copy(le[:], h[:32])
+	le[31] &= 0x7f
+
+	u := elligator2(new(big.Int).SetBytes(reverseBytes(le[:])))
+
+	var res [32]byte
+	u.FillBytes(res[:])
+
+	return reverseBytes(res[:])
+}
+
+// elligator2 maps the field element r to the u coordinate of a point of curve25519,
+// map_to_curve_elligator2 of RFC 9380 with Z = 2.
+func elligator2(r *big.Int) *big.Int {
+	p := curve25519P
+	one := big.NewInt(1)
+
+	// tv1 = Z * r², 0 if it's -1
+	tv1 := new(big.Int).Mul(r, r)
+	tv1.Lsh(tv1, 1)
+	tv1.Mod(tv1, p)
+	if new(big.Int).Add(tv1, one).Cmp(p) == 0 {
+		tv1.SetInt64(0)
+	}
+
+	// x1 = -A / (1 + tv1)
+	x1 := new(big.Int).Add(tv1, one)
+	x1.ModInverse(x1, p)
+	x1.Mul(x1, curve25519A)
+	x1.Neg(x1)
+	x1.Mod(x1, p)
+
+	// gx1 = x1³ + A*x1² + x1
+	gx1 := new(big.Int).Add(x1, curve25519A)
+	gx1.Mul(gx1, x1)
+	gx1.Add(gx1, one)
+	gx1.Mul(gx1, x1)
+	gx1.Mod(gx1, p)
+
+	if big.Jacobi(gx1, p) >= 0 {
+		return x1
+	}
+
+	// x2 = -x1 - A
+	x2 := new(big.Int).Add(x1, curve25519A)
+	x2.Neg(x2)
+	return x2.Mod(x2, p)
+}
+
+// cpace is one side of a CPace exchange.
+type cpace struct {
+	side byte
+	priv *ecdh.PrivateKey
+	msg  []byte
+}
+
+// newCPace starts an exchange as the side with the password and returns the message to send to the other side.
+func newCPace(side byte, password []byte) (*cpace, []byte, error) {
+	scalar := make([]byte, 32)
+	if _, err := crypto_rand.Read(scalar); err != nil {
+		return nil, nil, fmt.Errorf("unable to read random data. err: %v", err)
+	}
+
+	return newCPaceWithScalar(side, password, scalar)
+}
+
+// newCPaceWithScalar is newCPace with the secret scalar given, it's only used directly by tests.
+func newCPaceWithScalar(side byte, password []byte, scalar []byte) (*cpace, []byte, error) {
+	if side != cpaceSideA && side != cpaceSideB {
+		return nil, nil, fmt.Errorf("invalid CPace side %q", side)
+	}
+
+	priv, err := ecdh.X25519().NewPrivateKey(scalar)
+	if err != nil {
+		return nil, nil, err
+	}
+	g, err := ecdh.X25519().NewPublicKey(cpaceGenerator(password))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Y = y*G, which is the ECDH of the scalar with the generator
+
+	msg, err := priv.ECDH(g)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to compute CPace message. err: %v", err)
+	}
+
+	s := &cpace{
+		side: side,
+		priv: priv,
+		msg:  msg,
+	}
+
+	return s, s.msg, nil
+}
+
+// Finish computes the shared key from the message of the other side.
+//
+// Both sides get the same key only if they used the same password; this must be
+// confirmed before using the key, for example with a MAC of a known message.
+func (s *cpace) Finish(peerMsg []byte) ([]byte, error) {
+	peer, err := ecdh.X25519().NewPublicKey(peerMsg)
+	if err != nil {
+		return nil, errCPaceInvalidMessage
+	}
+
+	// NOTE(vincent): ECDH fails if the result is the identity, a peer sending a point of low order gets nothing.
+	k, err := s.priv.ECDH(peer)
+	if err != nil {
+		return nil, errCPaceInvalidMessage
+	}
+
+	// The key is bound to both messages
+
+	msgA, msgB := s.msg, peerMsg
+	if s.side == cpaceSideB {
+		msgA, msgB = peerMsg, s.msg
+	}
+
+	var transcript bytes.Buffer
+	for _, p := range [][]byte{[]byte(cpaceDSI + "_ISK"), k, msgA, msgB} {
+		writeLengthPrefixed(&transcript, p)
+	}
+
+	key := sha512.Sum512(transcript.Bytes())
+
+	return key[:], nil
+}
+
+// writeLengthPrefixed writes p prefixed with its length as a 64 bit big endian integer.
+func writeLengthPrefixed(buf *bytes.Buffer, p []byte) {
+	var size [8]byte
+	binary.BigEndian.PutUint64(size[:], uint64(len(p)))
+	buf.Write(size[:])
+	buf.Write(p)
+}
+
+func reverseBytes(b []byte) []byte {
+	res := make([]byte, len(b))
+	for i := range b {
+		res[len(b)-1-i] = b[i]
+	}
+	return res
+}
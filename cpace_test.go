@@ -0,0 +1,150 @@
+package main
+
+import (
+	"crypto/ecdh"
+	"encoding/hex"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// isOnCurve25519 returns true if u is the u coordinate of a point of curve25519 and not of its twist.
+func isOnCurve25519(u *big.Int) bool {
+	p := curve25519P
+
+	// u³ + A*u² + u
+	v := new(big.Int).Add(u, curve25519A)
+	v.Mul(v, u)
+	v.Add(v, big.NewInt(1))
+	v.Mul(v, u)
+	v.Mod(v, p)
+
+	return big.Jacobi(v, p) >= 0
+}
+
+func TestElligator2(t *testing.T) {
+	for _, r := range []int64{0, 1, 2, 3, 42, 1 << 40} {
+		u := elligator2(big.NewInt(r))
+		require.True(t, u.Cmp(curve25519P) < 0)
+		require.True(t, isOnCurve25519(u), "r=%d", r)
+	}
+
+	// A point of the twist is never returned
+
+	require.True(t, isOnCurve25519(elligator2(new(big.Int).Sub(curve25519P, big.NewInt(1)))))
+}
+
+func TestCPaceGenerator(t *testing.T) {
+	g := cpaceGenerator([]byte("42-apple-river"))
+	require.Len(t, g, 32)
+	require.True(t, isOnCurve25519(new(big.Int).SetBytes(reverseBytes(g))))
+
+	require.Equal(t, g, cpaceGenerator([]byte("42-apple-river")))
+	require.NotEqual(t, g, cpaceGenerator([]byte("42-apple-rival")))
+}
+
+func TestCPaceVectors(t *testing.T) {
+	// Like the test vectors of RFC 9382 the scalars are fixed, the values are computed
+	// with this implementation and checked against separate X25519 computations.
+
+	mustHex := func(s string) []byte {
+		b, err := hex.DecodeString(s)
+		require.NoError(t, err)
+		return b
+	}
+
+	var (
+		password = []byte("42-apple-river")
+		ya       = mustHex("a0a1a2a3a4a5a6a7a8a9aaabacadaeafb0b1b2b3b4b5b6b7b8b9babbbcbdbebf")
+		yb       = mustHex("c0c1c2c3c4c5c6c7c8c9cacbcccdcecfd0d1d2d3d4d5d6d7d8d9dadbdcdddedf")
+
+		g    = mustHex("33118c9f75f2ce1d37d1dba83cc4020f629445883c950979b3b95a5cedadce71")
+		msgA = mustHex("9c2cfe907b735a98f679035a8f834445873afe15c03e321678fc32e8feaf4c1a")
+		msgB = mustHex("c0c00ede563aff79d60daee402558a7ccdbd459554addf439d2844b416cd1069")
+		key  = mustHex("fed7f6167e5bbd10e25ec4d84da9eb245dad1e8fcd4baf544620193ad23fe12a" +
+			"ff95e9dec5c5363a23688799f6fed316010c945ab3f3d254517d65970143622c")
+	)
+
+	require.Equal(t, g, cpaceGenerator(password))
+
+	a, ownMsgA, err := newCPaceWithScalar(cpaceSideA, password, ya)
+	require.NoError(t, err)
+	require.Equal(t, msgA, ownMsgA)
+
+	b, ownMsgB, err := newCPaceWithScalar(cpaceSideB, password, yb)
+	require.NoError(t, err)
+	require.Equal(t, msgB, ownMsgB)
+
+	keyA, err := a.Finish(msgB)
+	require.NoError(t, err)
+	require.Equal(t, key, keyA)
+
+	keyB, err := b.Finish(msgA)
+	require.NoError(t, err)
+	require.Equal(t, key, keyB)
+
+	// K = ya*Yb = yb*Ya
+
+	x25519 := func(scalar, point []byte) []byte {
+		priv, err := ecdh.X25519().NewPrivateKey(scalar)
+		require.NoError(t, err)
+		pub, err := ecdh.X25519().NewPublicKey(point)
+		require.NoError(t, err)
+		res, err := priv.ECDH(pub)
+		require.NoError(t, err)
+		return res
+	}
+
+	require.Equal(t, msgA, x25519(ya, g))
+	require.Equal(t, msgB, x25519(yb, g))
+	require.Equal(t, x25519(ya, msgB), x25519(yb, msgA))
+}
+
+func TestCPace(t *testing.T) {
+	exchange := func(t *testing.T, passwordA, passwordB string) ([]byte, []byte) {
+		a, msgA, err := newCPace(cpaceSideA, []byte(passwordA))
+		require.NoError(t, err)
+		b, msgB, err := newCPace(cpaceSideB, []byte(passwordB))
+		require.NoError(t, err)
+
+		keyA, err := a.Finish(msgB)
+		require.NoError(t, err)
+		keyB, err := b.Finish(msgA)
+		require.NoError(t, err)
+
+		return keyA, keyB
+	}
+
+	t.Run("same-password", func(t *testing.T) {
+		keyA, keyB := exchange(t, "42-apple-river", "42-apple-river")
+		require.Equal(t, keyA, keyB)
+		require.Len(t, keyA, 64)
+
+		// Each exchange gets a new key
+		keyC, _ := exchange(t, "42-apple-river", "42-apple-river")
+		require.NotEqual(t, keyA, keyC)
+	})
+
+	t.Run("different-password", func(t *testing.T) {
+		keyA, keyB := exchange(t, "42-apple-river", "42-apple-rival")
+		require.NotEqual(t, keyA, keyB)
+	})
+
+	t.Run("invalid-message", func(t *testing.T) {
+		a, msgA, err := newCPace(cpaceSideA, []byte("42-apple-river"))
+		require.NoError(t, err)
+
+		_, err = a.Finish(msgA[:10])
+		require.Equal(t, errCPaceInvalidMessage, err)
+
+		// A point of low order
+		_, err = a.Finish(make([]byte, 32))
+		require.Equal(t, errCPaceInvalidMessage, err)
+	})
+
+	t.Run("invalid-side", func(t *testing.T) {
+		_, _, err := newCPace('c', []byte("42-apple-river"))
+		require.Error(t, err)
+	})
+}
@@ -1,13 +1,17 @@
 package main
 
 import (
+	"bytes"
 	"crypto/ed25519"
 	crypto_rand "crypto/rand"
 	"encoding"
 	"encoding/base64"
+	"encoding/binary"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"log"
 
 	"golang.org/x/crypto/nacl/secretbox"
@@ -102,11 +106,13 @@ func (k privateKey) IsValid() bool {
 }
 
 // MarshalText implements encoding.TextMarshaler
-func (k privateKey) MarshalText() (res []byte, err error) {
-	priv := ed25519.PrivateKey(k)
-	base64.StdEncoding.Encode(res, priv.Seed())
+func (k privateKey) MarshalText() ([]byte, error) {
+	seed := ed25519.PrivateKey(k).Seed()
 
-	return
+	res := make([]byte, base64.StdEncoding.EncodedLen(len(seed)))
+	base64.StdEncoding.Encode(res, seed)
+
+	return res, nil
 }
 
 // UnmarshalText implements encoding.TextUnmarshaler
@@ -207,6 +213,259 @@ func secretBoxOpen(box []byte, key secretBoxKey) ([]byte, bool) {
 	return secretbox.Open(nil, box, &nonce, (*[32]byte)(&key))
 }
 
+const (
+	// secretStreamMagic identifies the streaming format.
+	// Content sealed with secretBoxSeal starts with a random nonce instead.
+	secretStreamMagic = "aperoST1"
+
+	// secretStreamChunkSize is the size of the plaintext of a chunk.
+	// Every chunk except the last one has exactly this size.
+	secretStreamChunkSize = 64 << 10
+
+	secretStreamPrefixSize = 16
+
+	// secretStreamFinalFlag is set in the chunk counter of the last chunk.
+	secretStreamFinalFlag = 1 << 63
+)
+
+var (
+	errStreamTruncated = errors.New("stream is truncated")
+	errStreamCorrupted = errors.New("stream is corrupted")
+)
+
+// The streaming format splits the plaintext in chunks which are sealed independently
+// with secretbox, so that a stream can be encrypted and decrypted without being in memory.
+//
+// A stream looks like this:
+//
+//	[magic: 8 bytes][nonce prefix: 16 bytes][chunk 0]...[chunk N]
+//
+// The nonce of chunk i is the nonce prefix followed by i as a big endian uint64, with
+// the most significant bit set for the last chunk. Since every chunk is authenticated
+// with its position reordering chunks is detected; since the last chunk is marked and
+// always shorter than secretStreamChunkSize (it may be empty) truncation is detected too.
+
+func secretStreamNonce(prefix [secretStreamPrefixSize]byte, counter uint64, final bool) *[24]byte {
+	var nonce [24]byte
+	copy(nonce[:], prefix[:])
+	if final {
+		counter |= secretStreamFinalFlag
+	}
+	binary.BigEndian.PutUint64(nonce[secretStreamPrefixSize:], counter)
+	return &nonce
+}
+
+// secretStreamWriter encrypts everything written to it using the streaming format.
+// Close must be called to write the last chunk; it doesn't close the underlying writer.
+type secretStreamWriter struct {
+	w       io.Writer
+	key     secretBoxKey
+	prefix  [secretStreamPrefixSize]byte
+	counter uint64
+
+	buf    []byte
+	sealed []byte
+	err    error
+}
+
+func newSecretStreamWriter(w io.Writer, key secretBoxKey) (*secretStreamWriter, error) {
+	sw := &secretStreamWriter{
+		w:      w,
+		key:    key,
+		buf:    make([]byte, 0, secretStreamChunkSize),
+		sealed: make([]byte, 0, secretStreamChunkSize+secretbox.Overhead),
+	}
+	if _, err := io.ReadFull(crypto_rand.Reader, sw.prefix[:]); err != nil {
+		return nil, err
+	}
+
+	if _, err := io.WriteString(w, secretStreamMagic); err != nil {
+		return nil, err
+	}
+	if _, err := w.Write(sw.prefix[:]); err != nil {
+		return nil, err
+	}
+
+	return sw, nil
+}
+
+func (sw *secretStreamWriter) Write(p []byte) (int, error) {
+	if sw.err != nil {
+		return 0, sw.err
+	}
+
+	var n int
+	for len(p) > 0 {
+		// Only flush full chunks here so that the last chunk is always shorter
+		k := copy(sw.buf[len(sw.buf):cap(sw.buf)], p)
+		sw.buf = sw.buf[:len(sw.buf)+k]
+		p = p[k:]
+		n += k
+
+		if len(sw.buf) == secretStreamChunkSize {
+			if err := sw.flush(false); err != nil {
+				return n, err
+			}
+		}
+	}
+
+	return n, nil
+}
+
+// Close writes the last chunk.
+func (sw *secretStreamWriter) Close() error {
+	if sw.err != nil {
+		return sw.err
+	}
+	if err := sw.flush(true); err != nil {
+		return err
+	}
+	sw.err = errors.New("stream writer is closed")
+	return nil
+}
+
+func (sw *secretStreamWriter) flush(final bool) error {
+	nonce := secretStreamNonce(sw.prefix, sw.counter, final)
+
+	sw.sealed = secretbox.Seal(sw.sealed[:0], sw.buf, nonce, (*[32]byte)(&sw.key))
+	sw.buf = sw.buf[:0]
+	sw.counter++
+
+	if _, err := sw.w.Write(sw.sealed); err != nil {
+		sw.err = err
+		return err
+	}
+
+	return nil
+}
+
+// secretStreamReader decrypts a stream written by secretStreamWriter.
+//
+// Read returns the plaintext of a chunk only once the chunk is authenticated, however
+// since the stream is not verified as a whole before the plaintext is returned
+// a caller must always check that Read eventually returns io.EOF.
+type secretStreamReader struct {
+	r       io.Reader
+	key     secretBoxKey
+	prefix  [secretStreamPrefixSize]byte
+	counter uint64
+
+	sealed []byte
+	buf    []byte
+	plain  []byte
+	done   bool
+	err    error
+}
+
+// newSecretStreamReader returns a reader decrypting the content of r.
+//
+// The content can be either in the streaming format or sealed with secretBoxSeal;
+// in the latter case the whole content is read and decrypted at once.
+func newSecretStreamReader(r io.Reader, key secretBoxKey) (io.Reader, error) {
+	magic := make([]byte, len(secretStreamMagic))
+
+	n, err := io.ReadFull(r, magic)
+	switch {
+	case err == io.ErrUnexpectedEOF || err == io.EOF:
+		return nil, errStreamTruncated
+	case err != nil:
+		return nil, err
+	}
+
+	if string(magic[:n]) != secretStreamMagic {
+		rest, err := ioutil.ReadAll(r)
+		if err != nil {
+			return nil, err
+		}
+
+		plaintext, ok := secretBoxOpen(append(magic, rest...), key)
+		if !ok {
+			return nil, errStreamCorrupted
+		}
+		return bytes.NewReader(plaintext), nil
+	}
+
+	sr := &secretStreamReader{
+		r:      r,
+		key:    key,
+		sealed: make([]byte, secretStreamChunkSize+secretbox.Overhead),
+		buf:    make([]byte, 0, secretStreamChunkSize),
+	}
+	if _, err := io.ReadFull(r, sr.prefix[:]); err != nil {
+		return nil, errStreamTruncated
+	}
+
+	return sr, nil
+}
+
+func (sr *secretStreamReader) Read(p []byte) (int, error) {
+	for len(sr.plain) == 0 {
+		if sr.err != nil {
+			return 0, sr.err
+		}
+		if sr.done {
+			return 0, io.EOF
+		}
+
+		sr.err = sr.readChunk()
+	}
+
+	n := copy(p, sr.plain)
+	sr.plain = sr.plain[n:]
+
+	return n, nil
+}
+
+func (sr *secretStreamReader) readChunk() error {
+	n, err := io.ReadFull(sr.r, sr.sealed)
+
+	var final bool
+	switch {
+	case err == io.EOF:
+		// No more chunks but the last one was never seen
+		return errStreamTruncated
+	case err == io.ErrUnexpectedEOF:
+		// Only the last chunk can be shorter
+		final = true
+	case err != nil:
+		return err
+	}
+
+	nonce := secretStreamNonce(sr.prefix, sr.counter, final)
+
+	plain, ok := secretbox.Open(sr.buf[:0], sr.sealed[:n], nonce, (*[32]byte)(&sr.key))
+	if !ok {
+		return errStreamCorrupted
+	}
+
+	sr.plain = plain
+	sr.counter++
+	sr.done = final
+
+	return nil
+}
+
+// secretStreamEncrypt returns a reader of the content of r encrypted using the streaming format.
+// The encryption happens in a goroutine as the reader is consumed; closing the reader stops it.
+func secretStreamEncrypt(r io.Reader, key secretBoxKey) io.ReadCloser {
+	pr, pw := io.Pipe()
+
+	go func() {
+		sw, err := newSecretStreamWriter(pw, key)
+		if err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		if _, err := io.Copy(sw, r); err != nil {
+			pw.CloseWithError(err)
+			return
+		}
+		pw.CloseWithError(sw.Close())
+	}()
+
+	return pr
+}
+
 func getNonce() [24]byte {
 	var nonce [24]byte
 	if _, err := io.ReadFull(crypto_rand.Reader, nonce[:]); err != nil {
@@ -1,11 +1,16 @@
 package main
 
 import (
+	"bytes"
+	crypto_rand "crypto/rand"
 	"encoding/json"
+	"io"
+	"io/ioutil"
 	"testing"
 
 	"github.com/BurntSushi/toml"
 	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/nacl/secretbox"
 )
 
 func TestKeyPairString(t *testing.T) {
@@ -92,6 +97,16 @@ func TestKeyPairUnmarshalText(t *testing.T) {
 			require.NoError(t, err)
 			require.Empty(t, md.Undecoded())
 		})
+
+		t.Run("marshal", func(t *testing.T) {
+			data, err := priv.MarshalText()
+			require.NoError(t, err)
+
+			var key privateKey
+			err = (&key).UnmarshalText(data)
+			require.NoError(t, err)
+			require.Equal(t, priv, key)
+		})
 	})
 }
 
@@ -114,3 +129,128 @@ func TestSecretBox(t *testing.T) {
 	require.True(t, ok, "expected to open the box")
 	require.Equal(t, data, decrypted)
 }
+
+func TestSecretStream(t *testing.T) {
+	k := newSecretBoxKey()
+
+	encrypt := func(data []byte) []byte {
+		var buf bytes.Buffer
+
+		sw, err := newSecretStreamWriter(&buf, k)
+		require.NoError(t, err)
+		_, err = sw.Write(data)
+		require.NoError(t, err)
+		require.NoError(t, sw.Close())
+
+		return buf.Bytes()
+	}
+	decrypt := func(data []byte) ([]byte, error) {
+		sr, err := newSecretStreamReader(bytes.NewReader(data), k)
+		if err != nil {
+			return nil, err
+		}
+		return ioutil.ReadAll(sr)
+	}
+
+	header := len(secretStreamMagic) + secretStreamPrefixSize
+	sealedChunkSize := secretStreamChunkSize + secretbox.Overhead
+
+	t.Run("roundtrip", func(t *testing.T) {
+		sizes := []int{
+			0, 1, 1000,
+			secretStreamChunkSize - 1, secretStreamChunkSize, secretStreamChunkSize + 1,
+			3*secretStreamChunkSize + 500,
+		}
+
+		for _, size := range sizes {
+			data := make([]byte, size)
+			_, err := io.ReadFull(crypto_rand.Reader, data)
+			require.NoError(t, err)
+
+			stream := encrypt(data)
+
+			// The last chunk is always shorter than a full chunk
+			nbChunks := size/secretStreamChunkSize + 1
+			require.Equal(t, header+size+nbChunks*secretbox.Overhead, len(stream))
+
+			decrypted, err := decrypt(stream)
+			require.NoError(t, err, "size %d", size)
+			require.Equal(t, data, decrypted, "size %d", size)
+		}
+	})
+
+	t.Run("secretstreamencrypt", func(t *testing.T) {
+		data := bytes.Repeat([]byte("a"), 2*secretStreamChunkSize+30)
+
+		r := secretStreamEncrypt(bytes.NewReader(data), k)
+		defer r.Close()
+
+		sr, err := newSecretStreamReader(r, k)
+		require.NoError(t, err)
+
+		decrypted, err := ioutil.ReadAll(sr)
+		require.NoError(t, err)
+		require.Equal(t, data, decrypted)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		stream := encrypt(bytes.Repeat([]byte("a"), 2*secretStreamChunkSize+30))
+
+		// Cut at a chunk boundary
+		_, err := decrypt(stream[:header+2*sealedChunkSize])
+		require.Equal(t, errStreamTruncated, err)
+
+		// Cut inside a chunk
+		_, err = decrypt(stream[:header+sealedChunkSize+10])
+		require.Equal(t, errStreamCorrupted, err)
+
+		// Cut inside the header
+		_, err = decrypt(stream[:5])
+		require.Equal(t, errStreamTruncated, err)
+	})
+
+	t.Run("reordered", func(t *testing.T) {
+		stream := encrypt(bytes.Repeat([]byte("a"), 3*secretStreamChunkSize))
+
+		chunk1 := stream[header : header+sealedChunkSize]
+		chunk2 := stream[header+sealedChunkSize : header+2*sealedChunkSize]
+
+		var reordered []byte
+		reordered = append(reordered, stream[:header]...)
+		reordered = append(reordered, chunk2...)
+		reordered = append(reordered, chunk1...)
+		reordered = append(reordered, stream[header+2*sealedChunkSize:]...)
+
+		_, err := decrypt(reordered)
+		require.Equal(t, errStreamCorrupted, err)
+	})
+
+	t.Run("appended", func(t *testing.T) {
+		stream := encrypt([]byte("foobar"))
+		stream = append(stream, encrypt([]byte("barbaz"))[header:]...)
+
+		_, err := decrypt(stream)
+		require.Error(t, err)
+	})
+
+	t.Run("wrong-key", func(t *testing.T) {
+		stream := encrypt([]byte("foobar"))
+
+		sr, err := newSecretStreamReader(bytes.NewReader(stream), newSecretBoxKey())
+		require.NoError(t, err)
+
+		_, err = ioutil.ReadAll(sr)
+		require.Equal(t, errStreamCorrupted, err)
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		data := []byte("foobar")
+
+		decrypted, err := decrypt(secretBoxSeal(data, k))
+		require.NoError(t, err)
+		require.Equal(t, data, decrypted)
+
+		_, err = decrypt(secretBoxSeal(data, newSecretBoxKey()))
+		require.Equal(t, errStreamCorrupted, err)
+	})
+}
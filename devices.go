@@ -0,0 +1,327 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+var (
+	errUnknownDevice     = errors.New("unknown device")
+	errDeviceRevoked     = errors.New("device revoked")
+	errDeviceExists      = errors.New("device already exists")
+	errInvalidDeviceName = errors.New("invalid device name")
+)
+
+// device is a device allowed to use the server.
+type device struct {
+	Name      string
+	PublicKey publicKey
+	AddedAt   time.Time
+	// RevokedAt is when the device was revoked, zero if it's not.
+	RevokedAt time.Time
+
+	// PreviousPublicKey is the key replaced by the last rotation, accepted until PreviousKeyExpiresAt.
+	PreviousPublicKey    publicKey `toml:",omitempty"`
+	PreviousKeyExpiresAt time.Time
+}
+
+func (d device) isRevoked() bool {
+	return !d.RevokedAt.IsZero()
+}
+
+// keys returns the public keys of the device accepted at now.
+func (d device) keys(now time.Time) []publicKey {
+	keys := []publicKey{d.PublicKey}
+	if d.PreviousPublicKey.IsValid() && now.Before(d.PreviousKeyExpiresAt) {
+		keys = append(keys, d.PreviousPublicKey)
+	}
+	return keys
+}
+
+// deviceRegistry holds the public keys of the devices allowed to use the server.
+//
+// The registry is persisted in a TOML file which is managed with the devices
+// subcommands while the server is running; the server reloads the file
+// when it changes so a revoked device is locked out right away.
+// Changes are made while holding a lock on the file path + ".lock" so that
+// two processes changing the registry at the same time don't lose a change.
+type deviceRegistry struct {
+	path string
+
+	mu      sync.Mutex
+	devices []device
+	// fi is the file info of the file last read, used to know if it changed.
+	fi os.FileInfo
+}
+
+type deviceRegistryFile struct {
+	Devices []device
+}
+
+// newDeviceRegistry creates a registry backed by the file at path.
+// Nothing is read until the registry is used; a missing file is an empty registry.
+func newDeviceRegistry(path string) *deviceRegistry {
+	return &deviceRegistry{path: path}
+}
+
+// Load reads the registry file if it changed since the last time.
+func (r *deviceRegistry) Load() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.load()
+}
+
+// load reads the registry file if it changed since the last time.
+// The caller must hold the lock.
+func (r *deviceRegistry) load() error {
+	if r.path == "" {
+		return nil
+	}
+
+	fi, err := os.Stat(r.path)
+	switch {
+	case os.IsNotExist(err):
+		r.devices = nil
+		r.fi = nil
+		return nil
+	case err != nil:
+		return err
+	}
+
+	// NOTE(vincent): the registry is always saved to a new file which is renamed,
+	// comparing the files catches changes made within the resolution of the modification time.
+	if r.fi != nil && os.SameFile(fi, r.fi) && fi.ModTime().Equal(r.fi.ModTime()) && fi.Size() == r.fi.Size() {
+		return nil
+	}
+
+	var file deviceRegistryFile
+	if _, err := toml.DecodeFile(r.path, &file); err != nil {
+		return fmt.Errorf("unable to decode devices file %s. err: %v", r.path, err)
+	}
+
+	r.devices = file.Devices
+	r.fi = fi
+
+	return nil
+}
+
+// SetPath makes the registry use the file at path, it's read the next time the registry is used.
+func (r *deviceRegistry) SetPath(path string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if path == r.path {
+		return
+	}
+
+	r.path = path
+	r.devices = nil
+	r.fi = nil
+}
+
+// Lookup returns the device with this name.
+// If the device exists but is revoked errDeviceRevoked is returned.
+func (r *deviceRegistry) Lookup(name string) (device, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(); err != nil {
+		return device{}, err
+	}
+
+	for _, d := range r.devices {
+		if d.Name != name {
+			continue
+		}
+		if d.isRevoked() {
+			return device{}, errDeviceRevoked
+		}
+		return d, nil
+	}
+
+	return device{}, errUnknownDevice
+}
+
+// Devices returns all devices, including the revoked ones.
+func (r *deviceRegistry) Devices() ([]device, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.load(); err != nil {
+		return nil, err
+	}
+
+	devices := make([]device, len(r.devices))
+	copy(devices, r.devices)
+
+	return devices, nil
+}
+
+// Add adds a new device and saves the registry.
+func (r *deviceRegistry) Add(name string, key publicKey, now time.Time) error {
+	if !isValidDeviceName(name) {
+		return errInvalidDeviceName
+	}
+	if !key.IsValid() {
+		return fmt.Errorf("public key is invalid")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unlock, err := r.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	for _, d := range r.devices {
+		if d.Name == name {
+			return errDeviceExists
+		}
+	}
+
+	devices := append(r.devices[:len(r.devices):len(r.devices)], device{
+		Name:      name,
+		PublicKey: key,
+		AddedAt:   now.UTC().Truncate(time.Second),
+	})
+
+	return r.save(devices)
+}
+
+// Revoke revokes a device and saves the registry.
+//
+// A revoked device is kept in the registry so that its name can't be reused
+// by mistake and so that the entries it created can still be attributed.
+func (r *deviceRegistry) Revoke(name string, now time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unlock, err := r.lockFile()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	if err := r.load(); err != nil {
+		return err
+	}
+
+	devices := make([]device, len(r.devices))
+	copy(devices, r.devices)
+
+	for i := range devices {
+		if devices[i].Name != name {
+			continue
+		}
+		if devices[i].isRevoked() {
+			return errDeviceRevoked
+		}
+
+		devices[i].RevokedAt = now.UTC().Truncate(time.Second)
+
+		return r.save(devices)
+	}
+
+	return errUnknownDevice
+}
+
+// Rotate replaces the public key of a device and saves the registry.
+// The previous key is still accepted for keyRotationGracePeriod, Rotate returns until when.
+func (r *deviceRegistry) Rotate(name string, key publicKey, now time.Time) (time.Time, error) {
+	if !key.IsValid() {
+		return time.Time{}, fmt.Errorf("public key is invalid")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	unlock, err := r.lockFile()
+	if err != nil {
+		return time.Time{}, err
+	}
+	defer unlock()
+
+	if err := r.load(); err != nil {
+		return time.Time{}, err
+	}
+
+	devices := make([]device, len(r.devices))
+	copy(devices, r.devices)
+
+	for i := range devices {
+		if devices[i].Name != name {
+			continue
+		}
+		if devices[i].isRevoked() {
+			return time.Time{}, errDeviceRevoked
+		}
+
+		devices[i].PreviousPublicKey = devices[i].PublicKey
+		devices[i].PreviousKeyExpiresAt = now.UTC().Add(keyRotationGracePeriod).Truncate(time.Second)
+		devices[i].PublicKey = key
+
+		return devices[i].PreviousKeyExpiresAt, r.save(devices)
+	}
+
+	return time.Time{}, errUnknownDevice
+}
+
+// lockFile takes the lock of the registry file shared with the other processes changing it,
+// the registry must be loaded once it's held. It returns the function releasing the lock.
+// The caller must hold the lock.
+func (r *deviceRegistry) lockFile() (func(), error) {
+	if r.path == "" {
+		return func() {}, nil
+	}
+
+	unlock, err := lockFile(r.path + ".lock")
+	if err != nil {
+		return nil, fmt.Errorf("unable to lock devices file %s. err: %v", r.path, err)
+	}
+	return unlock, nil
+}
+
+// save atomically replaces the registry file with devices.
+// The caller must hold the lock.
+func (r *deviceRegistry) save(devices []device) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(deviceRegistryFile{Devices: devices}); err != nil {
+		return err
+	}
+
+	if err := writeFileAtomic(r.path, buf.Bytes(), 0600); err != nil {
+		return err
+	}
+
+	return r.load()
+}
+
+// isValidDeviceName reports if name can be used as a device name: it must not be empty,
+// must be at most 64 characters and only contain letters, digits, '-', '_' and '.'.
+func isValidDeviceName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-' || c == '_' || c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
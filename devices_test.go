@@ -0,0 +1,174 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDeviceRegistry(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "devices.toml")
+	now := time.Now()
+
+	pub, _ := mustKeyPair(t)
+	pub2, _ := mustKeyPair(t)
+
+	registry := newDeviceRegistry(path)
+
+	t.Run("missing-file", func(t *testing.T) {
+		devices, err := registry.Devices()
+		require.NoError(t, err)
+		require.Empty(t, devices)
+
+		_, err = registry.Lookup("laptop")
+		require.Equal(t, errUnknownDevice, err)
+	})
+
+	t.Run("add", func(t *testing.T) {
+		require.NoError(t, registry.Add("laptop", pub, now))
+		require.NoError(t, registry.Add("phone", pub2, now))
+
+		d, err := registry.Lookup("laptop")
+		require.NoError(t, err)
+		require.Equal(t, pub, d.PublicKey)
+		require.False(t, d.isRevoked())
+
+		require.Equal(t, errDeviceExists, registry.Add("laptop", pub2, now))
+		require.Equal(t, errInvalidDeviceName, registry.Add("my laptop", pub2, now))
+		require.Error(t, registry.Add("tablet", nil, now))
+	})
+
+	t.Run("reload", func(t *testing.T) {
+		// Another process, like the devices subcommands, sees the changes
+
+		registry2 := newDeviceRegistry(path)
+
+		devices, err := registry2.Devices()
+		require.NoError(t, err)
+		require.Len(t, devices, 2)
+		require.Equal(t, "laptop", devices[0].Name)
+		require.Equal(t, "phone", devices[1].Name)
+
+		require.NoError(t, registry2.Revoke("phone", now))
+
+		_, err = registry.Lookup("phone")
+		require.Equal(t, errDeviceRevoked, err)
+	})
+
+	t.Run("revoke", func(t *testing.T) {
+		require.Equal(t, errDeviceRevoked, registry.Revoke("phone", now))
+		require.Equal(t, errUnknownDevice, registry.Revoke("tablet", now))
+
+		// A revoked device stays in the registry and its name can't be reused
+
+		devices, err := registry.Devices()
+		require.NoError(t, err)
+		require.Len(t, devices, 2)
+		require.True(t, devices[1].isRevoked())
+
+		require.Equal(t, errDeviceExists, registry.Add("phone", pub2, now))
+	})
+
+	t.Run("rotate", func(t *testing.T) {
+		pub3, _ := mustKeyPair(t)
+
+		expiresAt, err := registry.Rotate("laptop", pub3, now)
+		require.NoError(t, err)
+		require.Equal(t, now.UTC().Add(keyRotationGracePeriod).Truncate(time.Second), expiresAt)
+
+		d, err := registry.Lookup("laptop")
+		require.NoError(t, err)
+		require.Equal(t, pub3, d.PublicKey)
+		require.Equal(t, []publicKey{pub3, pub}, d.keys(now))
+		require.Equal(t, []publicKey{pub3}, d.keys(expiresAt.Add(time.Second)))
+
+		_, err = registry.Rotate("phone", pub3, now)
+		require.Equal(t, errDeviceRevoked, err)
+		_, err = registry.Rotate("tablet", pub3, now)
+		require.Equal(t, errUnknownDevice, err)
+	})
+
+	t.Run("invalid-file", func(t *testing.T) {
+		path := filepath.Join(dir, "invalid.toml")
+		require.NoError(t, ioutil.WriteFile(path, []byte("Devices = 1"), 0600))
+
+		_, err := newDeviceRegistry(path).Lookup("laptop")
+		require.Error(t, err)
+	})
+}
+
+func TestDeviceRegistryConcurrentAdd(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "devices.toml")
+
+	// Each registry is like another apero devices add process, no device must be lost
+
+	const (
+		registries = 8
+		n          = 10
+	)
+
+	pub, _ := mustKeyPair(t)
+
+	var wg sync.WaitGroup
+	for i := 0; i < registries; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			r := newDeviceRegistry(path)
+			for j := 0; j < n; j++ {
+				require.NoError(t, r.Add(fmt.Sprintf("device%d-%d", i, j), pub, time.Now()))
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	devices, err := newDeviceRegistry(path).Devices()
+	require.NoError(t, err)
+	require.Len(t, devices, registries*n)
+}
+
+func TestDeviceName(t *testing.T) {
+	require.True(t, isValidDeviceName("laptop"))
+	require.True(t, isValidDeviceName("vincent-phone.home_2"))
+	require.False(t, isValidDeviceName(""))
+	require.False(t, isValidDeviceName("my laptop"))
+	require.False(t, isValidDeviceName("laptop/1"))
+	require.False(t, isValidDeviceName(strings.Repeat("a", 65)))
+}
+
+func TestPrintDevices(t *testing.T) {
+	pub, _ := mustKeyPair(t)
+	now := time.Now()
+
+	devices := []device{
+		{Name: "laptop", PublicKey: pub, AddedAt: now},
+		{Name: "phone", PublicKey: pub, AddedAt: now, RevokedAt: now},
+	}
+
+	var buf bytes.Buffer
+	printDevices(&buf, devices)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 3)
+
+	require.Contains(t, lines[0], "PUBLIC KEY")
+	require.Contains(t, lines[1], "laptop")
+	require.Contains(t, lines[1], pub.String())
+	require.Contains(t, lines[1], "active")
+	require.Contains(t, lines[2], "revoked")
+}
@@ -0,0 +1,595 @@
+package main
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// diskStore is a store which persists every entry in its own file on disk.
+//
+// The layout of the root directory is:
+//
+//	entries/<ulid>  committed entries
+//	tmp/            entries being written
+//
+// An entry is first written to the tmp directory, synced and then renamed into
+// the entries directory. Since a rename is atomic an entry is either completely
+// there or not at all; anything left in the tmp directory when the store is opened
+// is a half-written entry from a crash and is discarded.
+//
+// A removed entry stays in the entries directory until its content has been read completely,
+// this way an entry being moved when the server crashes is still there when the store is opened again.
+type diskStore struct {
+	entriesDir string
+	tmpDir     string
+
+	mu      sync.Mutex
+	entries []entryInfo
+	closed  bool
+
+	metrics *metrics
+}
+
+// diskStoreHeader is the header written at the beginning of each entry file.
+//
+// On disk an entry file looks like this:
+//
+//	[header length: uint32 big endian][header: JSON][content]
+type diskStoreHeader struct {
+	ContentLength int64     `json:"content_length"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Metadata      []byte    `json:"metadata,omitempty"`
+	Device        string    `json:"device,omitempty"`
+}
+
+// maxDiskStoreHeaderLength is a sanity check to avoid allocating huge buffers
+// when reading a corrupted entry file.
+const maxDiskStoreHeaderLength = 1 << 20
+
+func newDiskStore(root string) (*diskStore, error) {
+	s := &diskStore{
+		entriesDir: filepath.Join(root, "entries"),
+		tmpDir:     filepath.Join(root, "tmp"),
+		entries:    make([]entryInfo, 0, 32),
+	}
+
+	if err := os.MkdirAll(s.entriesDir, 0700); err != nil {
+		return nil, err
+	}
+
+	// Anything left in the tmp directory was never committed.
+	if err := os.RemoveAll(s.tmpDir); err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(s.tmpDir, 0700); err != nil {
+		return nil, err
+	}
+
+	if err := s.load(); err != nil {
+		return nil, err
+	}
+
+	return s, nil
+}
+
+// load populates the index with the entries found on disk.
+// Entries which can't be read or are truncated are removed.
+func (s *diskStore) load() error {
+	files, err := ioutil.ReadDir(s.entriesDir)
+	if err != nil {
+		return err
+	}
+
+	for _, fi := range files {
+		path := filepath.Join(s.entriesDir, fi.Name())
+
+		id, err := ulid.ParseStrict(fi.Name())
+		if err != nil || !fi.Mode().IsRegular() {
+			log.Printf("ignoring unknown file %q in store", path)
+			continue
+		}
+
+		hdr, err := checkDiskStoreEntry(path, fi.Size())
+		if err != nil {
+			log.Printf("removing invalid entry %s. err: %v", id, err)
+			if err := os.Remove(path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		s.entries = append(s.entries, entryInfo{
+			ID:        id,
+			ExpiresAt: hdr.ExpiresAt,
+			Size:      hdr.ContentLength,
+			Metadata:  hdr.Metadata,
+			Device:    hdr.Device,
+		})
+	}
+
+	sort.Slice(s.entries, func(i, j int) bool {
+		return s.entries[i].ID.Compare(s.entries[j].ID) < 0
+	})
+
+	return syncDir(s.entriesDir)
+}
+
+// checkDiskStoreEntry checks the entry file is complete and returns its header.
+func checkDiskStoreEntry(path string, size int64) (diskStoreHeader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return diskStoreHeader{}, err
+	}
+	defer f.Close()
+
+	hdr, hdrLen, err := readDiskStoreHeader(f)
+	if err != nil {
+		return hdr, err
+	}
+
+	if exp := hdrLen + hdr.ContentLength; exp != size {
+		return hdr, fmt.Errorf("entry is truncated, expected %d bytes, got %d", exp, size)
+	}
+
+	return hdr, nil
+}
+
+// readDiskStoreHeader reads the header of an entry file.
+// It returns the header and its total length on disk.
+func readDiskStoreHeader(r io.Reader) (diskStoreHeader, int64, error) {
+	var hdr diskStoreHeader
+
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return hdr, 0, fmt.Errorf("unable to read header length. err: %v", err)
+	}
+
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	if n > maxDiskStoreHeaderLength {
+		return hdr, 0, fmt.Errorf("header length %d is too big", n)
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		return hdr, 0, fmt.Errorf("unable to read header. err: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &hdr); err != nil {
+		return hdr, 0, fmt.Errorf("unable to unmarshal header. err: %v", err)
+	}
+
+	return hdr, int64(len(lenBuf)) + int64(n), nil
+}
+
+// diskStoreHeaderPadding is the room reserved in the header for the content length.
+//
+// The header is written before the content, when the content length is not yet known;
+// once the content is written the header is rewritten in place with the content length.
+// This works because JSON ignores the trailing spaces used as padding.
+const diskStoreHeaderPadding = 20
+
+// marshalDiskStoreHeader marshals the header padded with spaces to size bytes.
+// If size is zero the header is padded with diskStoreHeaderPadding spaces instead.
+func marshalDiskStoreHeader(hdr diskStoreHeader, size int) ([]byte, error) {
+	data, err := json.Marshal(hdr)
+	if err != nil {
+		return nil, err
+	}
+
+	if size == 0 {
+		size = len(data) + diskStoreHeaderPadding
+	}
+	if len(data) > size {
+		return nil, fmt.Errorf("header is too large")
+	}
+
+	padded := make([]byte, 4+size)
+	binary.BigEndian.PutUint32(padded[:4], uint32(size))
+	n := copy(padded[4:], data)
+	for i := 4 + n; i < len(padded); i++ {
+		padded[i] = ' '
+	}
+
+	return padded, nil
+}
+
+func (s *diskStore) entryPath(id ulid.ULID) string {
+	return filepath.Join(s.entriesDir, id.String())
+}
+
+// writeTmpEntry writes a new entry file with the content in the tmp directory.
+// It returns the path of the file and the size of the content.
+//
+// If reading the content fails the error is returned unchanged.
+func (s *diskStore) writeTmpEntry(hdr diskStoreHeader, content io.Reader) (path string, size int64, err error) {
+	f, err := ioutil.TempFile(s.tmpDir, "entry")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	hdrData, err := marshalDiskStoreHeader(hdr, 0)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := f.Write(hdrData); err != nil {
+		return "", 0, err
+	}
+
+	// Copy the content
+
+	buf := make([]byte, 32*1024)
+	for {
+		n, rerr := content.Read(buf)
+		if n > 0 {
+			if _, err := f.Write(buf[:n]); err != nil {
+				return "", 0, err
+			}
+			size += int64(n)
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return "", 0, rerr
+		}
+	}
+
+	// Rewrite the header with the content length
+
+	hdr.ContentLength = size
+	newHdrData, err := marshalDiskStoreHeader(hdr, len(hdrData)-4)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err := f.WriteAt(newHdrData, 0); err != nil {
+		return "", 0, err
+	}
+
+	if err := f.Sync(); err != nil {
+		return "", 0, err
+	}
+	if err := f.Close(); err != nil {
+		return "", 0, err
+	}
+
+	return f.Name(), size, nil
+}
+
+// diskStoreEntryReader reads the content of an entry file.
+type diskStoreEntryReader struct {
+	*io.SectionReader
+
+	f *os.File
+	// removed is called on close for a removed entry with true if the content was read completely.
+	removed func(read bool) error
+}
+
+func (r *diskStoreEntryReader) Close() error {
+	err := r.f.Close()
+	if r.removed != nil {
+		pos, _ := r.Seek(0, io.SeekCurrent)
+		if err2 := r.removed(pos == r.Size()); err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+// openEntry opens the entry file at path and returns a reader of its content.
+func openEntry(path string) (*diskStoreEntryReader, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+
+	hdr, hdrLen, err := readDiskStoreHeader(f)
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+
+	return &diskStoreEntryReader{
+		SectionReader: io.NewSectionReader(f, hdrLen, hdr.ContentLength),
+		f:             f,
+	}, nil
+}
+
+// first returns the position of the oldest entry not expired or -1.
+// The caller must hold the lock.
+func (s *diskStore) first() int {
+	now := time.Now()
+	for i, entry := range s.entries {
+		if !entry.isExpired(now) {
+			return i
+		}
+	}
+	return -1
+}
+
+// position returns the position of the entry if it exists and is not expired, or -1.
+// The caller must hold the lock.
+func (s *diskStore) position(id ulid.ULID) int {
+	i := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].ID.Compare(id) >= 0
+	})
+	if i < len(s.entries) && s.entries[i].ID == id && !s.entries[i].isExpired(time.Now()) {
+		return i
+	}
+	return -1
+}
+
+func (s *diskStore) Add(content io.Reader, opts addOptions) (ulid.ULID, error) {
+	hdr := diskStoreHeader{
+		ExpiresAt: opts.ExpiresAt,
+		Metadata:  opts.Metadata,
+		Device:    opts.Device,
+	}
+
+	s.mu.Lock()
+	usage := s.usage()
+	s.mu.Unlock()
+
+	if !opts.Quota.allows(usage, 0) {
+		return ulid.ULID{}, errQuotaExceeded
+	}
+
+	// Write the content without holding the lock since it can take a while
+
+	path, size, err := s.writeTmpEntry(hdr, opts.Quota.limit(content, usage))
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+	defer os.Remove(path) // no-op if the entry is committed
+
+	//
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return ulid.ULID{}, errStoreClosed
+	}
+
+	info := entryInfo{
+		ID:        newULID(),
+		ExpiresAt: opts.ExpiresAt,
+		Size:      size,
+		Metadata:  opts.Metadata,
+		Device:    opts.Device,
+	}
+
+	if !opts.Quota.allows(s.usage(), info.Size) {
+		return ulid.ULID{}, errQuotaExceeded
+	}
+
+	if err := os.Rename(path, s.entryPath(info.ID)); err != nil {
+		return ulid.ULID{}, fmt.Errorf("unable to commit entry. err: %v", err)
+	}
+	if err := syncDir(s.entriesDir); err != nil {
+		return ulid.ULID{}, fmt.Errorf("unable to commit entry. err: %v", err)
+	}
+
+	s.insert(info)
+	s.metrics.entriesAdded(1, info.Size)
+
+	if opts.Committed != nil {
+		opts.Committed(info)
+	}
+
+	return info.ID, nil
+}
+
+func (s *diskStore) CopyFirst() (entryInfo, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.copy(s.first())
+}
+
+func (s *diskStore) Copy(id ulid.ULID) (entryInfo, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.copy(s.position(id))
+}
+
+// copy opens the entry at position pos in the index.
+// The caller must hold the lock.
+func (s *diskStore) copy(pos int) (entryInfo, io.ReadCloser, error) {
+	if pos < 0 {
+		return entryInfo{}, nil, errEntryNotFound
+	}
+
+	info := s.entries[pos]
+
+	r, err := openEntry(s.entryPath(info.ID))
+	if err != nil {
+		return info, nil, err
+	}
+
+	return info, r, nil
+}
+
+func (s *diskStore) RemoveFirst() (entryInfo, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.remove(s.first())
+}
+
+func (s *diskStore) Remove(id ulid.ULID) (entryInfo, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.remove(s.position(id))
+}
+
+// remove removes the entry at position pos from the index and opens it.
+// The caller must hold the lock.
+//
+// The entry file is only deleted once the returned reader is closed after reading the
+// whole content. If the reader is closed before that the entry is put back in the index,
+// and if the server crashes before that the entry is found again when the store is opened.
+func (s *diskStore) remove(pos int) (entryInfo, io.ReadCloser, error) {
+	if pos < 0 {
+		return entryInfo{}, nil, errEntryNotFound
+	}
+
+	info := s.entries[pos]
+
+	r, err := openEntry(s.entryPath(info.ID))
+	if err != nil {
+		return info, nil, err
+	}
+	r.removed = func(read bool) error {
+		if !read {
+			s.restore(info)
+			return nil
+		}
+		if err := os.Remove(s.entryPath(info.ID)); err != nil {
+			return err
+		}
+		return syncDir(s.entriesDir)
+	}
+
+	s.entries = append(s.entries[:pos], s.entries[pos+1:]...)
+	s.metrics.entriesAdded(-1, -info.Size)
+
+	return info, r, nil
+}
+
+// restore puts back in the index an entry removed but not read.
+func (s *diskStore) restore(info entryInfo) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.insert(info)
+	s.metrics.entriesAdded(1, info.Size)
+}
+
+// insert adds the entry to the index, keeping it sorted by ID.
+// The caller must hold the lock.
+func (s *diskStore) insert(info entryInfo) {
+	// IDs are monotonic so this is almost always an append, however the clock
+	// might have gone backwards since the entries on disk were created.
+	pos := sort.Search(len(s.entries), func(i int) bool {
+		return s.entries[i].ID.Compare(info.ID) > 0
+	})
+	s.entries = append(s.entries, entryInfo{})
+	copy(s.entries[pos+1:], s.entries[pos:])
+	s.entries[pos] = info
+}
+
+func (s *diskStore) ListAll() ([]entryInfo, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+
+	entries := make([]entryInfo, 0, len(s.entries))
+	for _, entry := range s.entries {
+		if entry.isExpired(now) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// usage computes the usage of the store.
+// The caller must hold the lock.
+func (s *diskStore) usage() storeUsage {
+	var usage storeUsage
+	for _, entry := range s.entries {
+		usage.Entries++
+		usage.Size += entry.Size
+	}
+	return usage
+}
+
+func (s *diskStore) Usage() (storeUsage, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.usage(), nil
+}
+
+func (s *diskStore) RemoveExpired(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var size int64
+
+	entries := s.entries[:0]
+	for _, entry := range s.entries {
+		if !entry.isExpired(now) {
+			entries = append(entries, entry)
+			continue
+		}
+
+		if err := os.Remove(s.entryPath(entry.ID)); err != nil && !os.IsNotExist(err) {
+			// Keep the index consistent with what's on disk
+			entries = append(entries, entry)
+			log.Printf("unable to remove expired entry %s. err: %v", entry.ID, err)
+			continue
+		}
+		size += entry.Size
+	}
+
+	n := len(s.entries) - len(entries)
+	s.entries = entries
+	s.metrics.entriesEvicted(n, size)
+
+	if n > 0 {
+		return n, syncDir(s.entriesDir)
+	}
+	return n, nil
+}
+
+// Close waits for the entries being committed and flushes the entries directory,
+// no entry can be added afterwards.
+//
+// Every commit is already durable, this is only a last chance to flush if syncing the directory failed.
+func (s *diskStore) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil
+	}
+	s.closed = true
+
+	return syncDir(s.entriesDir)
+}
+
+// syncDir flushes the directory entries of dir to disk.
+// This is necessary for a rename or a remove to be durable.
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer d.Close()
+
+	return d.Sync()
+}
+
+var _ store = (*diskStore)(nil)
@@ -0,0 +1,275 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestDiskStore(t *testing.T) {
+	root := mustTempDir(t)
+	defer os.RemoveAll(root)
+
+	testStore(t, func(t *testing.T) store {
+		dir, err := ioutil.TempDir(root, "store")
+		require.NoError(t, err)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		return s
+	})
+}
+
+func TestDiskStoreRecovery(t *testing.T) {
+	t.Run("reopen", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		id, err := s.Add(strings.NewReader("foo"), addOptions{})
+		require.NoError(t, err)
+		id2, err := s.Add(strings.NewReader("bar"), addOptions{})
+		require.NoError(t, err)
+
+		// Entries must still be there after reopening the store
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		ids, err := s.ListAll()
+		require.NoError(t, err)
+		require.Len(t, ids, 2)
+		require.Equal(t, id, ids[0].ID)
+		require.Equal(t, id2, ids[1].ID)
+
+		_, data, err := s.RemoveFirst()
+		require.NoError(t, err)
+		require.Equal(t, "foo", mustReadEntry(t, data))
+
+		// A removed entry must not come back
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		ids, err = s.ListAll()
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+		require.Equal(t, id2, ids[0].ID)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		id, err := s.Add(strings.NewReader("foo"), addOptions{})
+		require.NoError(t, err)
+
+		require.NoError(t, s.Close())
+		require.NoError(t, s.Close())
+
+		_, err = s.Add(strings.NewReader("bar"), addOptions{})
+		require.Equal(t, errStoreClosed, err)
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		ids, err := s.ListAll()
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+		require.Equal(t, id, ids[0].ID)
+	})
+
+	t.Run("reopen-info", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		expiresAt := time.Now().Add(time.Hour).Round(time.Second)
+
+		id, err := s.Add(strings.NewReader("foo"), addOptions{ExpiresAt: expiresAt, Metadata: []byte("meta")})
+		require.NoError(t, err)
+		_, err = s.Add(strings.NewReader("bar"), addOptions{ExpiresAt: time.Now().Add(-time.Hour)})
+		require.NoError(t, err)
+
+		// The expiration times must survive reopening the store
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		entries, err := s.ListAll()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, id, entries[0].ID)
+		require.True(t, expiresAt.Equal(entries[0].ExpiresAt))
+		require.Equal(t, "meta", string(entries[0].Metadata))
+
+		n, err := s.RemoveExpired(time.Now())
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		files, err := ioutil.ReadDir(s.entriesDir)
+		require.NoError(t, err)
+		require.Len(t, files, 1)
+	})
+
+	t.Run("removed-while-reading", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		_, err = s.Add(strings.NewReader("foo"), addOptions{})
+		require.NoError(t, err)
+
+		// A removed entry can still be read until it's closed
+
+		_, data, err := s.RemoveFirst()
+		require.NoError(t, err)
+
+		ids, err := s.ListAll()
+		require.NoError(t, err)
+		require.Empty(t, ids)
+
+		require.Equal(t, "foo", mustReadEntry(t, data))
+
+		files, err := ioutil.ReadDir(s.tmpDir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("removed-not-read", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		id, err := s.Add(strings.NewReader("foo"), addOptions{})
+		require.NoError(t, err)
+
+		// A removed entry whose content wasn't read completely is put back
+
+		_, data, err := s.RemoveFirst()
+		require.NoError(t, err)
+		require.NoError(t, data.Close())
+
+		ids, err := s.ListAll()
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+		require.Equal(t, id, ids[0].ID)
+
+		// and it's still there if the server crashes while it's read
+
+		_, _, err = s.RemoveFirst()
+		require.NoError(t, err)
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		_, data, err = s.RemoveFirst()
+		require.NoError(t, err)
+		require.Equal(t, "foo", mustReadEntry(t, data))
+
+		files, err := ioutil.ReadDir(s.entriesDir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+	})
+
+	t.Run("half-written", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		// Simulate a crash while writing an entry
+
+		err = ioutil.WriteFile(filepath.Join(s.tmpDir, newULID().String()), []byte("garbage"), 0600)
+		require.NoError(t, err)
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		files, err := ioutil.ReadDir(s.tmpDir)
+		require.NoError(t, err)
+		require.Empty(t, files)
+
+		ids, err := s.ListAll()
+		require.NoError(t, err)
+		require.Empty(t, ids)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		id, err := s.Add(strings.NewReader("foobar"), addOptions{})
+		require.NoError(t, err)
+		id2, err := s.Add(strings.NewReader("barbaz"), addOptions{})
+		require.NoError(t, err)
+
+		// Truncate the first entry, it must be removed when reopening the store
+
+		path := s.entryPath(id)
+		fi, err := os.Stat(path)
+		require.NoError(t, err)
+		require.NoError(t, os.Truncate(path, fi.Size()-2))
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		ids, err := s.ListAll()
+		require.NoError(t, err)
+		require.Len(t, ids, 1)
+		require.Equal(t, id2, ids[0].ID)
+
+		_, err = os.Stat(path)
+		require.True(t, os.IsNotExist(err))
+	})
+
+	t.Run("unknown-file", func(t *testing.T) {
+		dir := mustTempDir(t)
+		defer os.RemoveAll(dir)
+
+		s, err := newDiskStore(dir)
+		require.NoError(t, err)
+
+		// Files which are not entries must be left alone
+
+		path := filepath.Join(s.entriesDir, "README")
+		require.NoError(t, ioutil.WriteFile(path, []byte("hello"), 0600))
+
+		s, err = newDiskStore(dir)
+		require.NoError(t, err)
+
+		ids, err := s.ListAll()
+		require.NoError(t, err)
+		require.Empty(t, ids)
+
+		_, err = os.Stat(path)
+		require.NoError(t, err)
+	})
+}
+
+func mustTempDir(t *testing.T) string {
+	dir, err := ioutil.TempDir("", "apero")
+	require.NoError(t, err)
+	return dir
+}
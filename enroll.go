@@ -0,0 +1,248 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/tyler-smith/go-bip39"
+)
+
+const (
+	// provisioningTimeout is how long enroll waits for the provisioning server.
+	provisioningTimeout = 30 * time.Second
+
+	// maxProvisioningConfigSize is the maximum size of the config served by apero provision.
+	maxProvisioningConfigSize = 64 << 10
+)
+
+// fetchProvisioningConfig fetches the client config served by apero provision at rawURL
+// and decrypts it with the provisioning code.
+func fetchProvisioningConfig(httpClient *http.Client, rawURL string, code string) (clientConfig, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return clientConfig{}, fmt.Errorf("invalid provisioning URL %q", rawURL)
+	}
+
+	// NOTE(vincent): the URL can only be fetched once, check the code before burning it with a typo.
+	if _, err := parseProvisioningCode(code); err != nil {
+		return clientConfig{}, err
+	}
+
+	resp, err := httpClient.Get(u.String())
+	if err != nil {
+		return clientConfig{}, fmt.Errorf("unable to fetch the provisioning config. err: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return clientConfig{}, fmt.Errorf("unable to fetch the provisioning config. status: %s", resp.Status)
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxProvisioningConfigSize+1))
+	if err != nil {
+		return clientConfig{}, fmt.Errorf("unable to fetch the provisioning config. err: %v", err)
+	}
+	if len(data) > maxProvisioningConfigSize {
+		return clientConfig{}, errors.New("the provisioning config is too large")
+	}
+
+	return openProvisioningConfig(data, code)
+}
+
+// parseProvisioningKey parses a key as shown by apero provision,
+// either a BIP39 mnemonic sentence or an hex string which may be split by spaces.
+func parseProvisioningKey(s string) ([]byte, error) {
+	compact := strings.Join(strings.Fields(s), "")
+	if len(compact) == 2*secretBoxKeySize {
+		if key, err := hex.DecodeString(compact); err == nil {
+			return key, nil
+		}
+	}
+
+	mnemonic := strings.ToLower(strings.Join(strings.Fields(s), " "))
+
+	key, err := bip39.EntropyFromMnemonic(mnemonic)
+	if err != nil {
+		return nil, fmt.Errorf("neither a valid mnemonic nor hex. err: %v", err)
+	}
+	if len(key) != secretBoxKeySize {
+		return nil, fmt.Errorf("invalid key size %d", len(key))
+	}
+
+	return key, nil
+}
+
+// promptLine prints label to w and reads a single line from sc.
+//
+// The scanner buffers what it reads so the same one must be used for all the prompts on a reader.
+func promptLine(sc *bufio.Scanner, w io.Writer, label string) (string, error) {
+	fmt.Fprintf(w, "%s: ", label)
+
+	if !sc.Scan() {
+		if err := sc.Err(); err != nil {
+			return "", err
+		}
+		return "", io.ErrUnexpectedEOF
+	}
+
+	return strings.TrimSpace(sc.Text()), nil
+}
+
+// readProvisioningConfig prompts for the endpoint and the keys shown by apero provision
+// and rebuilds the client config from them.
+func readProvisioningConfig(r io.Reader, w io.Writer) (clientConfig, error) {
+	var conf clientConfig
+
+	sc := bufio.NewScanner(r)
+	promptKey := func(label string) ([]byte, error) {
+		s, err := promptLine(sc, w, label)
+		if err != nil {
+			return nil, err
+		}
+
+		key, err := parseProvisioningKey(s)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s. err: %v", strings.ToLower(label), err)
+		}
+		return key, nil
+	}
+
+	//
+
+	endpoint, err := promptLine(sc, w, "Endpoint")
+	if err != nil {
+		return conf, err
+	}
+	conf.Endpoint = endpoint
+
+	// NOTE(vincent): a server with a self-signed certificate needs its fingerprint, a server
+	// with a certificate from a public CA doesn't.
+	if strings.HasPrefix(endpoint, "https://") {
+		fingerprint, err := promptLine(sc, w, "Certificate fingerprint (empty if none)")
+		if err != nil {
+			return conf, err
+		}
+		conf.CertFingerprint = fingerprint
+	}
+
+	key, err := promptKey("Pre-shared key")
+	if err != nil {
+		return conf, err
+	}
+	copy(conf.PSKey[:], key)
+
+	key, err = promptKey("Encryption key")
+	if err != nil {
+		return conf, err
+	}
+	copy(conf.EncryptKey[:], key)
+
+	key, err = promptKey("Sign public key")
+	if err != nil {
+		return conf, err
+	}
+	conf.SignPublicKey = publicKey(key)
+
+	key, err = promptKey("Sign private key")
+	if err != nil {
+		return conf, err
+	}
+	conf.SignPrivateKey = privateKey(ed25519.NewKeyFromSeed(key))
+
+	return conf, nil
+}
+
+// checkEnrollConfig verifies a config obtained from apero provision before it's written.
+func checkEnrollConfig(conf clientConfig) error {
+	u, err := url.Parse(conf.Endpoint)
+	switch {
+	case err != nil:
+		return fmt.Errorf("invalid endpoint %q", conf.Endpoint)
+	case u.Scheme == "unix":
+	case (u.Scheme != "http" && u.Scheme != "https") || u.Host == "":
+		return fmt.Errorf("invalid endpoint %q", conf.Endpoint)
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	pub := ed25519.PrivateKey(conf.SignPrivateKey).Public().(ed25519.PublicKey)
+	if !bytes.Equal(pub, conf.SignPublicKey) {
+		return errors.New("the sign public key doesn't match the sign private key")
+	}
+
+	return nil
+}
+
+func runEnroll(args []string) error {
+	var (
+		conf clientConfig
+		err  error
+	)
+
+	switch {
+	case *enrollMnemonic && len(args) == 0:
+		fmt.Fprintln(os.Stderr, "Enter the endpoint and the keys shown by apero provision, as mnemonics or hex.")
+		conf, err = readProvisioningConfig(os.Stdin, os.Stderr)
+	case !*enrollMnemonic && len(args) == 1 && strings.HasPrefix(args[0], compactConfigPrefix):
+		conf, err = decodeCompactConfig(args[0])
+	case !*enrollMnemonic && len(args) == 1:
+		code := *enrollCode
+		if code == "" {
+			code, err = promptLine(bufio.NewScanner(os.Stdin), os.Stderr, "Provisioning code")
+			if err != nil {
+				return err
+			}
+		}
+		conf, err = fetchProvisioningConfig(&http.Client{Timeout: provisioningTimeout}, args[0], code)
+	default:
+		return errors.New("usage: apero enroll <provisioning url | config> or apero enroll -mnemonic")
+	}
+	if err != nil {
+		return err
+	}
+
+	return saveEnrolledConfig(conf, *globalConfig, *enrollDeviceName, *enrollForce, os.Stdout)
+}
+
+// saveEnrolledConfig checks the config obtained from another device and writes it to path.
+// The device name is replaced with deviceName if not empty.
+func saveEnrolledConfig(conf clientConfig, path string, deviceName string, force bool, w io.Writer) error {
+	// NOTE(vincent): the clipboard configuration is specific to the provisioning device.
+	conf.Clipboard = nil
+	if deviceName != "" {
+		conf.DeviceName = deviceName
+	}
+
+	if err := checkEnrollConfig(conf); err != nil {
+		return fmt.Errorf("invalid provisioning config. err: %v", err)
+	}
+
+	//
+
+	if _, err := os.Stat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+	if err := writeConfig(path, conf); err != nil {
+		return fmt.Errorf("unable to write config. err: %v", err)
+	}
+
+	fmt.Fprintf(w, "enrolled with %s, config written to %s\n", conf.Endpoint, path)
+
+	return nil
+}
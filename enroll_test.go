@@ -0,0 +1,147 @@
+package main
+
+import (
+	"bytes"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newEnrollTestConfig(t *testing.T) clientConfig {
+	pub, priv, err := generateKeyPair()
+	require.NoError(t, err)
+
+	return clientConfig{
+		Endpoint:       "http://192.168.1.10:7568",
+		PSKey:          newSecretBoxKey(),
+		EncryptKey:     newSecretBoxKey(),
+		SignPublicKey:  pub,
+		SignPrivateKey: priv,
+		DeviceName:     "laptop",
+	}
+}
+
+func TestFetchProvisioningConfig(t *testing.T) {
+	conf := newEnrollTestConfig(t)
+	code := newProvisioningCode()
+
+	payload, err := sealProvisioningConfig(conf, code)
+	require.NoError(t, err)
+
+	// Served like apero provision does
+	handler := newProvisioningHandler("/abcd", payload, time.Now().Add(time.Minute))
+	httpServer := httptest.NewServer(handler)
+	defer httpServer.Close()
+
+	_, err = fetchProvisioningConfig(httpServer.Client(), httpServer.URL+"/foobar", code)
+	require.Error(t, err)
+
+	_, err = fetchProvisioningConfig(httpServer.Client(), "192.168.1.10:5000/abcd", code)
+	require.Error(t, err)
+
+	// An invalid code is rejected without fetching the config
+	_, err = fetchProvisioningConfig(httpServer.Client(), httpServer.URL+"/abcd", "abcd")
+	require.Error(t, err)
+
+	got, err := fetchProvisioningConfig(httpServer.Client(), httpServer.URL+"/abcd", strings.ToLower(code))
+	require.NoError(t, err)
+	require.NoError(t, checkEnrollConfig(got))
+	require.Equal(t, conf, got)
+
+	// Single use
+	_, err = fetchProvisioningConfig(httpServer.Client(), httpServer.URL+"/abcd", code)
+	require.Error(t, err)
+}
+
+func TestParseProvisioningKey(t *testing.T) {
+	key := newSecretBoxKey()
+	mnemonic := keyToMnemonic(key[:])
+	hexKey := hex.EncodeToString(key[:])
+
+	testCases := []struct {
+		name  string
+		input string
+	}{
+		{"mnemonic", mnemonic},
+		{"mnemonic-formatting", "  " + strings.ToUpper(strings.Replace(mnemonic, " ", "\t ", -1)) + "\n"},
+		{"hex", hexKey},
+		{"hex-groups", hexKey[:16] + " " + hexKey[16:32] + " " + hexKey[32:]},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := parseProvisioningKey(tc.input)
+			require.NoError(t, err)
+			require.Equal(t, key[:], got)
+		})
+	}
+
+	t.Run("invalid", func(t *testing.T) {
+		words := strings.Fields(mnemonic)
+		words[0], words[1] = words[1], words[0]
+
+		for _, input := range []string{
+			strings.Join(words, " "),
+			strings.Join(words[:12], " "),
+			"foo bar",
+			hexKey[:62],
+		} {
+			_, err := parseProvisioningKey(input)
+			require.Error(t, err, input)
+		}
+	})
+}
+
+func TestReadProvisioningConfig(t *testing.T) {
+	conf := newEnrollTestConfig(t)
+
+	input := fmt.Sprintf("%s\n%s\n%s\n%s\n%s\n",
+		conf.Endpoint,
+		keyToMnemonic(conf.PSKey[:]),
+		hex.EncodeToString(conf.EncryptKey[:]),
+		keyToMnemonic(conf.SignPublicKey),
+		keyToMnemonic(conf.SignPrivateKey[:32]),
+	)
+
+	var prompts bytes.Buffer
+	got, err := readProvisioningConfig(strings.NewReader(input), &prompts)
+	require.NoError(t, err)
+	require.NoError(t, checkEnrollConfig(got))
+	require.Contains(t, prompts.String(), "Sign private key: ")
+
+	conf.DeviceName = ""
+	require.Equal(t, conf, got)
+
+	t.Run("fingerprint", func(t *testing.T) {
+		fingerprint := certFingerprint([]byte("foobar"))
+		input := strings.Replace(input, "http://", "https://", 1)
+		input = strings.Replace(input, "\n", "\n"+fingerprint+"\n", 1)
+
+		var prompts bytes.Buffer
+		got, err := readProvisioningConfig(strings.NewReader(input), &prompts)
+		require.NoError(t, err)
+		require.NoError(t, checkEnrollConfig(got))
+		require.Contains(t, prompts.String(), "Certificate fingerprint")
+		require.Equal(t, fingerprint, got.CertFingerprint)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		_, err := readProvisioningConfig(strings.NewReader(conf.Endpoint+"\n"), ioutil.Discard)
+		require.Error(t, err)
+	})
+
+	t.Run("mismatched-keys", func(t *testing.T) {
+		other, _, err := generateKeyPair()
+		require.NoError(t, err)
+
+		got.SignPublicKey = other
+		require.Error(t, checkEnrollConfig(got))
+	})
+}
@@ -0,0 +1,190 @@
+package main
+
+import (
+	crypto_rand "crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	apiVersion1 = "v1"
+	apiVersion2 = "v2"
+
+	actionCopy  = "copy"
+	actionMove  = "move"
+	actionPaste = "paste"
+	actionList  = "list"
+
+	actionUploadStart  = "upload-start"
+	actionUploadChunk  = "upload-chunk"
+	actionUploadFinish = "upload-finish"
+
+	actionRotateKey = "rotate-key"
+	actionSubscribe = "subscribe"
+	actionPair      = "pair"
+
+	// envelopeNonceSize is the size of the random nonce of an envelope.
+	envelopeNonceSize = 16
+
+	// maxClockSkew is the maximum difference allowed between the timestamp
+	// of an envelope and the server clock.
+	maxClockSkew = 5 * time.Minute
+)
+
+var (
+	errInvalidSignature = errors.New("invalid signature")
+	errStaleRequest     = errors.New("stale request")
+	errReplayedRequest  = errors.New("replayed request")
+)
+
+// envelope binds a signed request to an action, an API version,
+// a point in time and a random nonce.
+//
+// The signature of a request covers the envelope and the request payload (see message)
+// which means a captured request can't be used for another action, can't be used
+// after maxClockSkew and can't be replayed while it's still fresh since the server
+// remembers the nonces it has seen.
+//
+// Device is the name of the device which signed the request, the server uses it
+// to find the public key to verify the signature with. It can be empty if the server
+// has a single SignPublicKey.
+type envelope struct {
+	Action    string `json:"action"`
+	Version   string `json:"version"`
+	Timestamp int64  `json:"timestamp"`
+	Nonce     []byte `json:"nonce"`
+	Device    string `json:"device,omitempty"`
+}
+
+// newEnvelope creates an envelope for the action with the current time and a new random nonce.
+func newEnvelope(version, action string) envelope {
+	env := envelope{
+		Action:    action,
+		Version:   version,
+		Timestamp: time.Now().Unix(),
+		Nonce:     make([]byte, envelopeNonceSize),
+	}
+	if _, err := crypto_rand.Read(env.Nonce); err != nil {
+		log.Fatalf("unable to read random data. err=%v", err)
+	}
+	return env
+}
+
+// Validate validates the envelope parameters.
+func (e envelope) Validate() error {
+	if e.Action == "" {
+		return fmt.Errorf("Action is empty")
+	}
+	if e.Version == "" {
+		return fmt.Errorf("Version is empty")
+	}
+	if len(e.Nonce) != envelopeNonceSize {
+		return fmt.Errorf("Nonce size is invalid")
+	}
+	return nil
+}
+
+// Time returns the timestamp of the envelope as a time.Time.
+func (e envelope) Time() time.Time {
+	return time.Unix(e.Timestamp, 0)
+}
+
+// message returns the data to sign for a request with this envelope and the payload.
+//
+// Every variable length field is prefixed by its length so that
+// two different envelopes can never produce the same message.
+func (e envelope) message(payload []byte) []byte {
+	const prefix = "apero request"
+
+	buf := make([]byte, 0, len(prefix)+len(e.Version)+len(e.Action)+len(e.Nonce)+len(e.Device)+len(payload)+48)
+	buf = append(buf, prefix...)
+	buf = appendLengthPrefixed(buf, []byte(e.Version))
+	buf = appendLengthPrefixed(buf, []byte(e.Action))
+
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], uint64(e.Timestamp))
+	buf = append(buf, tmp[:]...)
+
+	buf = appendLengthPrefixed(buf, e.Nonce)
+	// NOTE(vincent): the device is only part of the message when set so that
+	// envelopes without one are signed like before.
+	if e.Device != "" {
+		buf = appendLengthPrefixed(buf, []byte(e.Device))
+	}
+	buf = appendLengthPrefixed(buf, payload)
+
+	return buf
+}
+
+func appendLengthPrefixed(buf []byte, data []byte) []byte {
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], uint64(len(data)))
+
+	buf = append(buf, tmp[:]...)
+	return append(buf, data...)
+}
+
+// signEnvelope creates a new envelope for the action and signs it with the payload.
+func signEnvelope(priv privateKey, version, action string, payload []byte) (*envelope, []byte) {
+	return signDeviceEnvelope(priv, "", version, action, payload)
+}
+
+// signDeviceEnvelope is like signEnvelope but the envelope names the device owning priv.
+func signDeviceEnvelope(priv privateKey, device, version, action string, payload []byte) (*envelope, []byte) {
+	env := newEnvelope(version, action)
+	env.Device = device
+	return &env, sign(priv, env.message(payload))
+}
+
+// nonceCache remembers the nonces seen recently.
+//
+// A nonce only needs to be remembered as long as the timestamp of its envelope
+// is considered fresh, after that the request is rejected anyway.
+type nonceCache struct {
+	ttl time.Duration
+
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	nextPrune time.Time
+}
+
+func newNonceCache(ttl time.Duration) *nonceCache {
+	return &nonceCache{
+		ttl:    ttl,
+		nonces: make(map[string]time.Time),
+	}
+}
+
+// Add records the nonce and reports if it wasn't already known.
+func (c *nonceCache) Add(nonce []byte, now time.Time) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if now.After(c.nextPrune) {
+		c.prune(now)
+		c.nextPrune = now.Add(c.ttl)
+	}
+
+	key := string(nonce)
+
+	if expiresAt, ok := c.nonces[key]; ok && now.Before(expiresAt) {
+		return false
+	}
+	c.nonces[key] = now.Add(c.ttl)
+
+	return true
+}
+
+// prune removes the nonces which are no longer needed.
+// The caller must hold the lock.
+func (c *nonceCache) prune(now time.Time) {
+	for nonce, expiresAt := range c.nonces {
+		if !now.Before(expiresAt) {
+			delete(c.nonces, nonce)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package main
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestEnvelopeMessage(t *testing.T) {
+	env := newEnvelope(apiVersion2, actionMove)
+	require.NoError(t, env.Validate())
+	require.Len(t, env.Nonce, envelopeNonceSize)
+
+	// Changing any field must change the message
+
+	msg := env.message([]byte("payload"))
+
+	env2 := env
+	env2.Action = actionPaste
+	require.NotEqual(t, msg, env2.message([]byte("payload")))
+
+	env2 = env
+	env2.Version = apiVersion1
+	require.NotEqual(t, msg, env2.message([]byte("payload")))
+
+	env2 = env
+	env2.Timestamp++
+	require.NotEqual(t, msg, env2.message([]byte("payload")))
+
+	env2 = newEnvelope(apiVersion2, actionMove)
+	require.NotEqual(t, msg, env2.message([]byte("payload")))
+
+	require.NotEqual(t, msg, env.message([]byte("payloae")))
+}
+
+func TestNonceCache(t *testing.T) {
+	c := newNonceCache(time.Minute)
+
+	now := time.Now()
+	nonce := []byte("foobar")
+
+	require.True(t, c.Add(nonce, now))
+	require.False(t, c.Add(nonce, now.Add(30*time.Second)))
+	require.True(t, c.Add([]byte("barbaz"), now.Add(30*time.Second)))
+
+	// After the TTL the nonce is forgotten
+
+	require.True(t, c.Add(nonce, now.Add(2*time.Minute)))
+	require.Len(t, c.nonces, 1)
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+const (
+	// defaultSubscribeTimeout is how long a long-poll request waits for a new entry
+	// when the request doesn't have a timeout.
+	defaultSubscribeTimeout = 30 * time.Second
+	// maxSubscribeTimeout is the maximum time a long-poll request waits for a new entry.
+	maxSubscribeTimeout = 5 * time.Minute
+
+	// eventsKeepAliveInterval is how often a comment is sent on an idle event stream
+	// so that proxies don't close it.
+	eventsKeepAliveInterval = 30 * time.Second
+
+	// entryEventsBufferSize is the number of events a subscriber can lag behind
+	// before being dropped.
+	entryEventsBufferSize = 64
+)
+
+// entryEvents notifies the subscribers of the new entries.
+//
+// A subscriber which doesn't keep up is dropped and its channel closed; that's not a problem
+// since a client subscribes again with the ID of the last entry it got and
+// the entries it missed are taken from the store.
+type entryEvents struct {
+	mu          sync.Mutex
+	subscribers map[chan entryInfo]struct{}
+}
+
+func newEntryEvents() *entryEvents {
+	return &entryEvents{
+		subscribers: make(map[chan entryInfo]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving the new entries and a function to unsubscribe.
+func (e *entryEvents) Subscribe() (<-chan entryInfo, func()) {
+	ch := make(chan entryInfo, entryEventsBufferSize)
+
+	e.mu.Lock()
+	e.subscribers[ch] = struct{}{}
+	e.mu.Unlock()
+
+	return ch, func() {
+		e.mu.Lock()
+		defer e.mu.Unlock()
+
+		if _, ok := e.subscribers[ch]; ok {
+			delete(e.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// Publish sends the entry to all subscribers.
+func (e *entryEvents) Publish(info entryInfo) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for ch := range e.subscribers {
+		select {
+		case ch <- info:
+		default:
+			delete(e.subscribers, ch)
+			close(ch)
+		}
+	}
+}
+
+// addEntry adds an entry to the store and notifies the subscribers.
+//
+// The entry is published while the store is locked: subscribers get the entries in the order
+// of their IDs, which streamEvents relies on.
+func (s *apiHandler) addEntry(content io.Reader, opts addOptions) (ulid.ULID, error) {
+	opts.Committed = s.events.Publish
+
+	return s.st.Add(content, opts)
+}
+
+// handleSubscribe handles a subscription to the new entries.
+//
+// If the client accepts text/event-stream the entries are sent as server-sent events
+// for as long as the connection is open, otherwise it's a long-poll request which
+// returns as soon as there's at least one entry or when the timeout expires.
+//
+// The sealed request is always in the body of a POST request, never in the URL,
+// so that it doesn't end up in the logs of proxies.
+func (s *apiHandler) handleSubscribe(w http.ResponseWriter, req *http.Request, version string) {
+	conf := s.config()
+
+	if version == apiVersion1 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	if req.Method != http.MethodPost {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		responseStatusCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	data, box, ok := s.openBox(conf, req, data)
+	if !ok {
+		log.Printf("unable to open box")
+		responseStatusCode(w, http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	var payload subscribeRequest
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Printf("unable to unmarshal subscribe request payload. err: %v", err)
+		responseString(w, "invalid subscribe request", http.StatusBadRequest)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		log.Printf("subscribe request payload invalid. err: %v", err)
+		responseString(w, "invalid subscribe request", http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	device, err := s.verifyRequest(conf, box, apiVersion2, actionSubscribe, payload.Envelope, payload.After[:], payload.Signature)
+	if err != nil {
+		log.Printf("unable to verify subscribe request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	// Subscribe before looking at the store so that no entry is missed in between
+
+	events, unsubscribe := s.events.Subscribe()
+	defer unsubscribe()
+
+	var backlog []entryInfo
+	if !isEmptyULID(payload.After) {
+		backlog, err = s.entriesAfter(payload.After)
+		if err != nil {
+			log.Printf("unable to list entries. err: %v", err)
+			responseString(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if strings.Contains(req.Header.Get("Accept"), "text/event-stream") && ok {
+		s.streamEvents(w, flusher, req, box, device, backlog, events)
+		return
+	}
+
+	//
+
+	if len(backlog) > 0 {
+		responseSealedJSON(w, box, subscribeResponse{Entries: backlog}, http.StatusOK)
+		return
+	}
+
+	timeout := time.Duration(payload.Timeout) * time.Second
+	switch {
+	case timeout <= 0:
+		timeout = defaultSubscribeTimeout
+	case timeout > maxSubscribeTimeout:
+		timeout = maxSubscribeTimeout
+	}
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	var resp subscribeResponse
+
+	select {
+	case info, ok := <-events:
+		if ok {
+			resp.Entries = append(resp.Entries, info)
+		}
+	case <-timer.C:
+	case <-req.Context().Done():
+		return
+	}
+
+	responseSealedJSON(w, box, resp, http.StatusOK)
+}
+
+// streamEvents sends the backlog and then the new entries as server-sent events
+// until the client goes away or the device is revoked.
+//
+// Every event has the entry ID as its ID and the entry info sealed in the payload box,
+// base64 encoded, as its data.
+func (s *apiHandler) streamEvents(w http.ResponseWriter, flusher http.Flusher, req *http.Request, box payloadBox, device string, backlog []entryInfo, events <-chan entryInfo) {
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	var last ulid.ULID
+
+	send := func(info entryInfo) error {
+		// NOTE(vincent): the backlog and the events can overlap
+		if info.ID.Compare(last) <= 0 {
+			return nil
+		}
+
+		if device != "" {
+			if _, err := s.devices.Lookup(device); err != nil {
+				return err
+			}
+		}
+
+		data, err := json.Marshal(info)
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintf(w, "id: %s\nevent: entry\ndata: %s\n\n", info.ID, base64.StdEncoding.EncodeToString(box.seal(data)))
+		if err != nil {
+			return err
+		}
+		flusher.Flush()
+
+		last = info.ID
+
+		return nil
+	}
+
+	for _, info := range backlog {
+		if err := send(info); err != nil {
+			log.Printf("stopping event stream. err: %v", err)
+			return
+		}
+	}
+
+	ticker := time.NewTicker(eventsKeepAliveInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case info, ok := <-events:
+			if !ok {
+				return
+			}
+			if err := send(info); err != nil {
+				log.Printf("stopping event stream. err: %v", err)
+				return
+			}
+
+		case <-ticker.C:
+			if device != "" {
+				if _, err := s.devices.Lookup(device); err != nil {
+					log.Printf("stopping event stream. err: %v", err)
+					return
+				}
+			}
+
+			if _, err := io.WriteString(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			flusher.Flush()
+
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// entriesAfter returns the entries created after the entry with the given ID.
+func (s *apiHandler) entriesAfter(id ulid.ULID) ([]entryInfo, error) {
+	entries, err := s.st.ListAll()
+	if err != nil {
+		return nil, err
+	}
+
+	var res []entryInfo
+	for _, entry := range entries {
+		if entry.ID.Compare(id) > 0 {
+			res = append(res, entry)
+		}
+	}
+
+	return res, nil
+}
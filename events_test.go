@@ -0,0 +1,273 @@
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntryEvents(t *testing.T) {
+	events := newEntryEvents()
+
+	ch, unsubscribe := events.Subscribe()
+	ch2, unsubscribe2 := events.Subscribe()
+	defer unsubscribe2()
+
+	info := entryInfo{ID: newULID()}
+	events.Publish(info)
+
+	require.Equal(t, info, <-ch)
+	require.Equal(t, info, <-ch2)
+
+	unsubscribe()
+	_, ok := <-ch
+	require.False(t, ok)
+
+	// A subscriber which doesn't keep up is dropped
+
+	for i := 0; i < entryEventsBufferSize+1; i++ {
+		events.Publish(info)
+	}
+
+	n := 0
+	for range ch2 {
+		n++
+	}
+	require.Equal(t, entryEventsBufferSize, n)
+}
+
+func TestAddEntryOrder(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	// Entries added concurrently are published in the order of their IDs
+
+	for _, conf := range []storageConfig{{}, {Type: "disk", Path: dir}} {
+		t.Run(conf.Type, func(t *testing.T) {
+			st, err := newStore(conf, nil)
+			require.NoError(t, err)
+			defer st.Close()
+
+			api := &apiHandler{st: st, events: newEntryEvents()}
+
+			ch, unsubscribe := api.events.Subscribe()
+			defer unsubscribe()
+
+			const n = entryEventsBufferSize
+
+			var wg sync.WaitGroup
+			for i := 0; i < n; i++ {
+				wg.Add(1)
+				go func() {
+					defer wg.Done()
+
+					_, err := api.addEntry(strings.NewReader("hello"), addOptions{})
+					require.NoError(t, err)
+				}()
+			}
+			wg.Wait()
+
+			var last ulid.ULID
+			for i := 0; i < n; i++ {
+				info := <-ch
+				require.True(t, info.ID.Compare(last) > 0, "entry %d is out of order", i)
+				require.Equal(t, int64(5), info.Size)
+				last = info.ID
+			}
+		})
+	}
+}
+
+func TestServerSubscribe(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	copyEntry := func() ulid.ULID {
+		metadata, err := sealMetadata(entryMetadata{Filename: "foo.txt"}, client.conf.EncryptKey)
+		require.NoError(t, err)
+
+		id, err := client.doCopy(copyStreamHeader{Metadata: metadata}, strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		return id
+	}
+
+	first := copyEntry()
+
+	t.Run("poll-timeout", func(t *testing.T) {
+		// Without after only new entries are returned
+
+		entries, err := client.doPoll(ulid.ULID{}, time.Second)
+		require.NoError(t, err)
+		require.Empty(t, entries)
+	})
+
+	t.Run("poll-backlog", func(t *testing.T) {
+		second := copyEntry()
+
+		entries, err := client.doPoll(first, time.Second)
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, second, entries[0].ID)
+	})
+
+	t.Run("poll-new", func(t *testing.T) {
+		type result struct {
+			entries []entryInfo
+			err     error
+		}
+		results := make(chan result)
+
+		go func() {
+			entries, err := client.doPoll(ulid.ULID{}, 10*time.Second)
+			results <- result{entries, err}
+		}()
+
+		waitForSubscribers(t, api.events, 1)
+		id := copyEntry()
+
+		res := <-results
+		require.NoError(t, res.err)
+		require.Len(t, res.entries, 1)
+		require.Equal(t, id, res.entries[0].ID)
+		require.Equal(t, int64(len("hello")), res.entries[0].Size)
+
+		md, err := openMetadata(res.entries[0].Metadata, client.conf.EncryptKey)
+		require.NoError(t, err)
+		require.Equal(t, "foo.txt", md.Filename)
+	})
+
+	t.Run("watch", func(t *testing.T) {
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+
+		// The stream starts with the entries after the second one
+
+		ids := make(chan ulid.ULID, 10)
+		errStop := errors.New("stop")
+
+		errs := make(chan error)
+		go func() {
+			n := 0
+			errs <- client.doWatch(entries[1].ID, func(info entryInfo) error {
+				ids <- info.ID
+				n++
+				if n == 3 {
+					return errStop
+				}
+				return nil
+			})
+		}()
+
+		require.Equal(t, entries[2].ID, <-ids)
+
+		waitForSubscribers(t, api.events, 1)
+		id := copyEntry()
+		id2 := copyEntry()
+
+		require.Equal(t, errStop, <-errs)
+		require.Equal(t, id, <-ids)
+		require.Equal(t, id2, <-ids)
+	})
+
+	t.Run("get", func(t *testing.T) {
+		// The sealed request is never accepted in the URL
+
+		var after ulid.ULID
+		req := subscribeRequest{After: after}
+		req.Envelope, req.Signature = signEnvelope(client.conf.SignPrivateKey, apiVersion2, actionSubscribe, after[:])
+
+		data, err := json.Marshal(req)
+		require.NoError(t, err)
+
+		query := base64.URLEncoding.EncodeToString(secretBoxSeal(data, client.conf.PSKey))
+
+		hreq, err := http.NewRequest(http.MethodGet, httpServer.URL+"/api/v2/subscribe?request="+query, nil)
+		require.NoError(t, err)
+		hreq.Header.Set("Accept", "text/event-stream")
+
+		resp, err := http.DefaultClient.Do(hreq)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		require.Equal(t, http.StatusMethodNotAllowed, resp.StatusCode)
+	})
+
+	t.Run("invalid-signature", func(t *testing.T) {
+		var after ulid.ULID
+		req := subscribeRequest{After: after}
+		req.Envelope, req.Signature = signEnvelope(client.conf.SignPrivateKey, apiVersion2, actionSubscribe, []byte("foo"))
+
+		_, err := client.doRequest(req, http.MethodPost, http.StatusOK, "/api/v2/subscribe")
+		require.Error(t, err)
+	})
+
+	t.Run("v1", func(t *testing.T) {
+		_, err := client.doRequest(subscribeRequest{}, http.MethodPost, http.StatusOK, "/api/v1/subscribe")
+		require.Equal(t, errEntryNotFound, err)
+	})
+}
+
+func TestServerSubscribeRevoked(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	api.conf.DevicesFile = filepath.Join(dir, "devices.toml")
+	api.devices = newDeviceRegistry(api.conf.DevicesFile)
+
+	require.NoError(t, api.devices.Add("phone", client.conf.SignPublicKey, time.Now()))
+
+	watcherConf := client.conf
+	watcherConf.DeviceName = "phone"
+	watcher := newClient(watcherConf)
+
+	// The server SignPublicKey is used for the copies
+	api.conf.SignPublicKey = client.conf.SignPublicKey
+
+	var got []entryInfo
+	errs := make(chan error)
+	go func() {
+		errs <- watcher.doWatch(ulid.ULID{}, func(info entryInfo) error {
+			got = append(got, info)
+			return nil
+		})
+	}()
+
+	waitForSubscribers(t, api.events, 1)
+	require.NoError(t, newDeviceRegistry(api.conf.DevicesFile).Revoke("phone", time.Now()))
+
+	_, err := client.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	err = <-errs
+	require.IsType(t, &transportError{}, err)
+	require.Empty(t, got)
+}
+
+func waitForSubscribers(t *testing.T, events *entryEvents, n int) {
+	deadline := time.Now().Add(5 * time.Second)
+	for time.Now().Before(deadline) {
+		events.mu.Lock()
+		count := len(events.subscribers)
+		events.mu.Unlock()
+
+		if count >= n {
+			return
+		}
+		time.Sleep(time.Millisecond)
+	}
+	t.Fatalf("expected %d subscribers", n)
+}
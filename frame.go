@@ -0,0 +1,161 @@
+package main
+
+import (
+	"encoding/binary"
+	"errors"
+	"io"
+)
+
+// The v2 API streams content in request and response bodies using frames.
+// A frame is a byte slice prefixed by its length as a big endian uint32.
+//
+// A copy request body looks like this:
+//
+//	[header frame][content frame]...[empty frame][trailer frame]
+//
+// and a move or paste response body looks like this:
+//
+//	[header frame][content]
+//
+// Header and trailer frames are sealed with the pre-shared key like v1 payloads.
+
+const (
+	// maxContentFrameSize is the maximum size of a content frame.
+	maxContentFrameSize = 1 << 20
+)
+
+var (
+	errFrameTooLarge = errors.New("frame too large")
+	errContentEmpty  = errors.New("content is empty")
+)
+
+// writeFrame writes p as a single frame.
+func writeFrame(w io.Writer, p []byte) error {
+	var lenBuf [4]byte
+	binary.BigEndian.PutUint32(lenBuf[:], uint32(len(p)))
+
+	if _, err := w.Write(lenBuf[:]); err != nil {
+		return err
+	}
+	_, err := w.Write(p)
+	return err
+}
+
+// readFrame reads a single frame of at most max bytes.
+func readFrame(r io.Reader, max int) ([]byte, error) {
+	var lenBuf [4]byte
+	if _, err := io.ReadFull(r, lenBuf[:]); err != nil {
+		return nil, err
+	}
+
+	n := binary.BigEndian.Uint32(lenBuf[:])
+	if int64(n) > int64(max) {
+		return nil, errFrameTooLarge
+	}
+
+	data := make([]byte, n)
+	if _, err := io.ReadFull(r, data); err != nil {
+		if err == io.EOF {
+			err = io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	return data, nil
+}
+
+// writeContentFrames writes the content of r as content frames followed by the empty frame.
+func writeContentFrames(w io.Writer, r io.Reader) error {
+	buf := make([]byte, secretStreamChunkSize)
+	for {
+		n, err := r.Read(buf)
+		if n > 0 {
+			if err := writeFrame(w, buf[:n]); err != nil {
+				return err
+			}
+		}
+		if err == io.EOF {
+			return writeFrame(w, nil)
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// contentFramesReader reads the content frames of a body until the empty frame.
+//
+// The content must not be empty, entries can't be.
+//
+// Once the empty frame is read end is called and its error, if any, is returned
+// instead of io.EOF. This is where the rest of the body can be read and verified.
+type contentFramesReader struct {
+	r   io.Reader
+	max int64
+	end func() error
+
+	n   int64
+	buf []byte
+	err error
+}
+
+// newContentFramesReader creates a reader of at most max bytes of content.
+// If there are more errRequestTooLarge is returned.
+func newContentFramesReader(r io.Reader, max int64, end func() error) *contentFramesReader {
+	return &contentFramesReader{
+		r:   r,
+		max: max,
+		end: end,
+	}
+}
+
+func (r *contentFramesReader) Read(p []byte) (int, error) {
+	for len(r.buf) == 0 {
+		if r.err != nil {
+			return 0, r.err
+		}
+		r.err = r.readFrame()
+	}
+
+	n := copy(p, r.buf)
+	r.buf = r.buf[n:]
+
+	return n, nil
+}
+
+func (r *contentFramesReader) readFrame() error {
+	data, err := readFrame(r.r, maxContentFrameSize)
+	switch {
+	case err == io.EOF:
+		return io.ErrUnexpectedEOF
+	case err != nil:
+		return err
+	}
+
+	if len(data) == 0 {
+		if r.n == 0 {
+			return errContentEmpty
+		}
+		if err := r.end(); err != nil {
+			return err
+		}
+		return io.EOF
+	}
+
+	r.n += int64(len(data))
+	if r.n > r.max {
+		return errRequestTooLarge
+	}
+
+	r.buf = data
+
+	return nil
+}
+
+// Err returns the error which stopped the reader, or nil if it stopped at the end of the content.
+func (r *contentFramesReader) Err() error {
+	if r.err == io.EOF {
+		return nil
+	}
+	return r.err
+}
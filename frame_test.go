@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFrame(t *testing.T) {
+	var buf bytes.Buffer
+	require.NoError(t, writeFrame(&buf, []byte("foobar")))
+	require.NoError(t, writeFrame(&buf, nil))
+
+	data, err := readFrame(&buf, 10)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", string(data))
+
+	data, err = readFrame(&buf, 10)
+	require.NoError(t, err)
+	require.Empty(t, data)
+
+	_, err = readFrame(&buf, 10)
+	require.Equal(t, io.EOF, err)
+
+	// Limits
+
+	require.NoError(t, writeFrame(&buf, []byte("foobar")))
+	_, err = readFrame(&buf, 5)
+	require.Equal(t, errFrameTooLarge, err)
+
+	// Truncated
+
+	buf.Reset()
+	require.NoError(t, writeFrame(&buf, []byte("foobar")))
+	buf.Truncate(buf.Len() - 1)
+
+	_, err = readFrame(&buf, 10)
+	require.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestContentFramesReader(t *testing.T) {
+	content := strings.Repeat("foobar", secretStreamChunkSize/3)
+
+	t.Run("roundtrip", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeContentFrames(&buf, strings.NewReader(content)))
+		buf.WriteString("trailer")
+
+		var ended bool
+		r := newContentFramesReader(&buf, int64(len(content)), func() error {
+			ended = true
+			return nil
+		})
+
+		data, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.NoError(t, r.Err())
+		require.Equal(t, content, string(data))
+		require.True(t, ended)
+
+		// The rest of the body is left untouched
+
+		require.Equal(t, "trailer", buf.String())
+	})
+
+	t.Run("too-large", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeContentFrames(&buf, strings.NewReader(content)))
+
+		r := newContentFramesReader(&buf, int64(len(content)-1), func() error { return nil })
+
+		_, err := ioutil.ReadAll(r)
+		require.Equal(t, errRequestTooLarge, err)
+		require.Equal(t, errRequestTooLarge, r.Err())
+	})
+
+	t.Run("end-error", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeContentFrames(&buf, strings.NewReader("foo")))
+
+		errEnd := errors.New("end")
+		r := newContentFramesReader(&buf, 10, func() error { return errEnd })
+
+		_, err := ioutil.ReadAll(r)
+		require.Equal(t, errEnd, err)
+		require.Equal(t, errEnd, r.Err())
+	})
+
+	t.Run("empty", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeContentFrames(&buf, strings.NewReader("")))
+
+		r := newContentFramesReader(&buf, 10, func() error { return nil })
+
+		_, err := ioutil.ReadAll(r)
+		require.Equal(t, errContentEmpty, err)
+	})
+
+	t.Run("truncated", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, writeFrame(&buf, []byte("foo")))
+
+		r := newContentFramesReader(&buf, 10, func() error { return nil })
+
+		_, err := ioutil.ReadAll(r)
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+	})
+}
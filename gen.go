@@ -1,3 +1,3 @@
 package main
 
-//go:generate statik -include "*.html,*.js,*.css" -f -src ui
+//go:generate statik -include "*.html,*.js,*.css,*.png,*.webmanifest" -f -src ui
@@ -1,7 +1,15 @@
 package main
 
 import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
 	"net/http"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 )
@@ -11,6 +19,28 @@ func isEmptyULID(id ulid.ULID) bool {
 	return id == emptyID
 }
 
+var errRequestTooLarge = errors.New("request too large")
+
+// readRequestBody reads the whole request body if it's not larger than max bytes.
+// Otherwise it stops reading as soon as possible and returns errRequestTooLarge.
+func readRequestBody(req *http.Request, max int64) ([]byte, error) {
+	defer req.Body.Close()
+
+	if req.ContentLength > max {
+		return nil, errRequestTooLarge
+	}
+
+	data, err := ioutil.ReadAll(io.LimitReader(req.Body, max+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > max {
+		return nil, errRequestTooLarge
+	}
+
+	return data, nil
+}
+
 func responseStatusCode(w http.ResponseWriter, code int) {
 	w.WriteHeader(code)
 	w.Write([]byte(http.StatusText(code)))
@@ -20,3 +50,85 @@ func responseString(w http.ResponseWriter, s string, code int) {
 	w.WriteHeader(code)
 	w.Write([]byte(s))
 }
+
+// duration is a time.Duration which can be decoded from a TOML string like "24h".
+type duration struct {
+	time.Duration
+}
+
+// MarshalText implements encoding.TextMarshaler
+func (d duration) MarshalText() ([]byte, error) {
+	return []byte(d.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler
+func (d *duration) UnmarshalText(p []byte) error {
+	var err error
+	d.Duration, err = time.ParseDuration(string(p))
+	return err
+}
+
+// formatSize formats a size in bytes in a human readable way, for example "12.5MiB".
+func formatSize(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%dB", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit && exp < 4; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f%ciB", float64(n)/float64(div), "KMGTP"[exp])
+}
+
+// skipContent skips the first n bytes of r, without reading them if r is seekable.
+func skipContent(r io.Reader, n int64) error {
+	if n == 0 {
+		return nil
+	}
+
+	if seeker, ok := r.(io.Seeker); ok {
+		_, err := seeker.Seek(n, io.SeekCurrent)
+		return err
+	}
+
+	_, err := io.CopyN(ioutil.Discard, r, n)
+	return err
+}
+
+// writeFileAtomic writes data to a temporary file in the directory of path and renames it to path,
+// this way path either has its previous content or data, never something in between.
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return createFileAtomic(path, bytes.NewReader(data), perm)
+}
+
+// createFileAtomic is like writeFileAtomic with the content read from r.
+// If reading r fails path is left untouched.
+func createFileAtomic(path string, r io.Reader, perm os.FileMode) error {
+	f, err := ioutil.TempFile(filepath.Dir(path), "."+filepath.Base(path))
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name()) // no-op once renamed
+
+	if _, err := io.Copy(f, r); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Chmod(perm); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(f.Name(), path)
+}
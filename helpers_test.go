@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bytes"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadRequestBody(t *testing.T) {
+	req := httptest.NewRequest("POST", "/", bytes.NewReader([]byte("foobar")))
+	data, err := readRequestBody(req, 6)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", string(data))
+
+	req = httptest.NewRequest("POST", "/", bytes.NewReader([]byte("foobar")))
+	_, err = readRequestBody(req, 5)
+	require.Equal(t, errRequestTooLarge, err)
+
+	// Without a Content-Length the body is still limited
+
+	req = httptest.NewRequest("POST", "/", bytes.NewReader([]byte("foobar")))
+	req.ContentLength = -1
+	_, err = readRequestBody(req, 5)
+	require.Equal(t, errRequestTooLarge, err)
+}
+
+func TestFormatSize(t *testing.T) {
+	testCases := []struct {
+		n   int64
+		exp string
+	}{
+		{0, "0B"},
+		{1023, "1023B"},
+		{1024, "1.0KiB"},
+		{1536, "1.5KiB"},
+		{64 << 20, "64.0MiB"},
+		{3 << 30, "3.0GiB"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.exp, formatSize(tc.n))
+	}
+}
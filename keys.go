@@ -0,0 +1,219 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"net/http"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+)
+
+// keyRotationGracePeriod is how long a key is still accepted after being rotated, by default.
+//
+// The pre-shared key is rotated on the server, which accepts the previous key until the end
+// of the grace period; the clients must be updated during that time.
+//
+// The signing key of a device is rotated by the device itself: it sends the new public key
+// in a request signed with the current one and the server accepts both until the end of the grace period.
+//
+// The encryption key is never seen by the server. Clients keep the previous keys to decrypt
+// the entries created before the rotation and the entries are encrypted again with the new key.
+const keyRotationGracePeriod = 24 * time.Hour
+
+// rotatePSKey replaces the pre-shared key of the server configuration with a new one.
+// The previous key is retired until now+grace, retired keys already expired are removed.
+func rotatePSKey(conf *serverConfig, grace time.Duration, now time.Time) secretBoxKey {
+	retired := []retiredPSKey{{Key: conf.PSKey, ExpiresAt: now.UTC().Add(grace).Truncate(time.Second)}}
+	for _, k := range conf.RetiredPSKeys {
+		if now.Before(k.ExpiresAt) {
+			retired = append(retired, k)
+		}
+	}
+
+	conf.PSKey = newSecretBoxKey()
+	conf.RetiredPSKeys = retired
+
+	return conf.PSKey
+}
+
+// rotateEncryptKey replaces the encryption key of the client configuration with a new one.
+// The previous key is retired, it's still used to decrypt the entries.
+func rotateEncryptKey(conf *clientConfig) secretBoxKey {
+	conf.RetiredEncryptKeys = append([]secretBoxKey{conf.EncryptKey}, conf.RetiredEncryptKeys...)
+	conf.EncryptKey = newSecretBoxKey()
+
+	return conf.EncryptKey
+}
+
+// reencryptEntries encrypts again with the current encryption key the entries
+// encrypted with a retired key.
+//
+// An entry can't be modified so each one is copied to a new entry which expires at the same time,
+// then removed. Entries without metadata, created by old clients, are skipped since
+// there's no way to know which key encrypted them; they'll expire eventually.
+func reencryptEntries(c *client, conf clientConfig, now time.Time) (reencrypted, skipped int, err error) {
+	body, err := c.doList(listRequest{})
+	if err != nil {
+		return 0, 0, err
+	}
+
+	var resp listResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return 0, 0, fmt.Errorf("unable to unmarshal response")
+	}
+
+	for _, entry := range resp.Entries {
+		if len(entry.Metadata) == 0 {
+			skipped++
+			continue
+		}
+		if _, err := openMetadata(entry.Metadata, conf.EncryptKey); err == nil {
+			continue
+		}
+
+		md, key, err := openMetadataWithKeys(entry.Metadata, conf.RetiredEncryptKeys)
+		if err != nil {
+			skipped++
+			continue
+		}
+
+		if _, err := reencryptEntry(c, entry, md, key, conf.EncryptKey, now); err != nil {
+			return reencrypted, skipped, fmt.Errorf("unable to encrypt entry %s again. err: %v", entry.ID, err)
+		}
+		reencrypted++
+	}
+
+	return reencrypted, skipped, nil
+}
+
+// reencryptEntry copies the entry encrypted with oldKey to a new entry encrypted with newKey
+// and removes it. It returns the ID of the new entry.
+func reencryptEntry(c *client, entry entryInfo, md entryMetadata, oldKey, newKey secretBoxKey, now time.Time) (ulid.ULID, error) {
+	_, body, err := c.doResumablePaste(pasteRequest{ID: entry.ID})
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+	defer body.Close()
+
+	plaintext, err := newSecretStreamReader(body, oldKey)
+	if err != nil {
+		return ulid.ULID{}, fmt.Errorf("unable to decipher content. err: %v", err)
+	}
+
+	metadata, err := sealMetadata(md, newKey)
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	content := secretStreamEncrypt(plaintext, newKey)
+	defer content.Close()
+
+	// Keep the same expiration time, as close as the TTL in seconds allows.
+	var ttl int64
+	if !entry.ExpiresAt.IsZero() {
+		ttl = int64(math.Ceil(entry.ExpiresAt.Sub(now).Seconds()))
+		if ttl < 1 {
+			ttl = 1
+		}
+	}
+
+	id, err := c.doUpload(uploadStartRequest{TTL: ttl, Metadata: metadata}, content)
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	// Only remove the entry once the new one exists
+
+	_, old, err := c.doMove(moveRequest{ID: entry.ID})
+	switch {
+	case err == errEntryNotFound:
+		// Removed or expired in the meantime
+	case err != nil:
+		return id, err
+	default:
+		old.Close()
+	}
+
+	return id, nil
+}
+
+func (s *apiHandler) handleRotateKey(w http.ResponseWriter, req *http.Request, version string) {
+	conf := s.config()
+
+	if version == apiVersion1 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+	if req.Method != http.MethodPost {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		responseStatusCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	data, box, ok := s.openBox(conf, req, data)
+	if !ok {
+		log.Printf("unable to open box")
+		responseStatusCode(w, http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	var payload rotateKeyRequest
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Printf("unable to unmarshal rotate key request payload. err: %v", err)
+		responseString(w, "invalid rotate key request", http.StatusBadRequest)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		log.Printf("rotate key request payload invalid. err: %v", err)
+		responseString(w, "invalid rotate key request", http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	device, err := s.verifyRequest(conf, box, apiVersion2, actionRotateKey, payload.Envelope, payload.PublicKey, payload.Signature)
+	if err != nil {
+		log.Printf("unable to verify rotate key request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if device == "" {
+		responseString(w, "only a device in the registry can rotate its key", http.StatusBadRequest)
+		return
+	}
+	if !verify(payload.PublicKey, payload.Envelope.message(payload.PublicKey), payload.Proof) {
+		log.Printf("unable to verify rotate key request proof")
+		responseString(w, "invalid proof", http.StatusBadRequest)
+		return
+	}
+
+	expiresAt, err := s.devices.Rotate(device, payload.PublicKey, time.Now())
+	switch {
+	case err == errDeviceRevoked || err == errUnknownDevice:
+		// Revoked or removed since the request was verified
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	case err != nil:
+		log.Printf("unable to rotate key of device %q. err: %v", device, err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("rotated key of device %q", device)
+
+	responseSealedJSON(w, box, rotateKeyResponse{PreviousKeyExpiresAt: expiresAt}, http.StatusOK)
+}
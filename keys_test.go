@@ -0,0 +1,245 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRotatePSKey(t *testing.T) {
+	now := time.Now()
+
+	var conf serverConfig
+	conf.PSKey = newSecretBoxKey()
+	conf.RetiredPSKeys = []retiredPSKey{
+		{Key: newSecretBoxKey(), ExpiresAt: now.Add(-time.Minute)},
+		{Key: newSecretBoxKey(), ExpiresAt: now.Add(time.Minute)},
+	}
+
+	previous := conf.PSKey
+	stillValid := conf.RetiredPSKeys[1]
+
+	key := rotatePSKey(&conf, time.Hour, now)
+	require.Equal(t, key, conf.PSKey)
+	require.NotEqual(t, previous, key)
+
+	// The expired key is gone
+
+	require.Len(t, conf.RetiredPSKeys, 2)
+	require.Equal(t, previous, conf.RetiredPSKeys[0].Key)
+	require.Equal(t, now.UTC().Add(time.Hour).Truncate(time.Second), conf.RetiredPSKeys[0].ExpiresAt)
+	require.Equal(t, stillValid, conf.RetiredPSKeys[1])
+}
+
+func TestServerRetiredPSKey(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	oldKey := api.conf.PSKey
+	rotatePSKey(&api.conf, time.Hour, time.Now())
+
+	t.Run("retired", func(t *testing.T) {
+		// The client still has the old key, the response is sealed with it
+
+		require.Equal(t, oldKey, client.conf.PSKey)
+
+		_, err := client.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		_, err = client.doList(listRequest{})
+		require.NoError(t, err)
+	})
+
+	t.Run("new", func(t *testing.T) {
+		conf := client.conf
+		conf.PSKey = api.conf.PSKey
+
+		_, err := newClient(conf).doList(listRequest{})
+		require.NoError(t, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		api.conf.RetiredPSKeys[0].ExpiresAt = time.Now().Add(-time.Second)
+
+		_, err := client.doList(listRequest{})
+		require.Error(t, err)
+	})
+}
+
+func TestServerRotateKey(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	api.conf.DevicesFile = filepath.Join(dir, "devices.toml")
+	api.devices = newDeviceRegistry(api.conf.DevicesFile)
+
+	require.NoError(t, api.devices.Add("laptop", client.conf.SignPublicKey, time.Now()))
+	client.conf.DeviceName = "laptop"
+
+	oldConf := client.conf
+
+	t.Run("rotate", func(t *testing.T) {
+		pub, priv := mustKeyPair(t)
+
+		resp, err := client.doRotateKey(pub, priv)
+		require.NoError(t, err)
+		require.True(t, resp.PreviousKeyExpiresAt.After(time.Now().Add(keyRotationGracePeriod-time.Minute)))
+
+		d, err := api.devices.Lookup("laptop")
+		require.NoError(t, err)
+		require.Equal(t, pub, d.PublicKey)
+
+		// Both keys are accepted during the grace period
+
+		_, err = client.doList(listRequest{})
+		require.NoError(t, err)
+
+		newConf := client.conf
+		newConf.SignPublicKey = pub
+		newConf.SignPrivateKey = priv
+
+		_, err = newClient(newConf).doList(listRequest{})
+		require.NoError(t, err)
+
+		// Not after
+
+		require.Empty(t, d.keys(time.Now().Add(keyRotationGracePeriod + time.Minute))[1:])
+
+		client.conf = newConf
+	})
+
+	t.Run("retired-key", func(t *testing.T) {
+		// The key replaced by the rotation is still accepted but it can't rotate again
+
+		d, err := api.devices.Lookup("laptop")
+		require.NoError(t, err)
+
+		_, err = newClient(oldConf).doList(listRequest{})
+		require.NoError(t, err)
+
+		pub, priv := mustKeyPair(t)
+
+		_, err = newClient(oldConf).doRotateKey(pub, priv)
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "400 Bad Request")
+
+		d2, err := api.devices.Lookup("laptop")
+		require.NoError(t, err)
+		require.Equal(t, d, d2)
+	})
+
+	t.Run("invalid-proof", func(t *testing.T) {
+		pub, _ := mustKeyPair(t)
+		_, otherPriv := mustKeyPair(t)
+
+		_, err := client.doRotateKey(pub, otherPriv)
+		require.Error(t, err)
+	})
+
+	t.Run("no-device", func(t *testing.T) {
+		// Clients using the SignPublicKey of the server can't rotate it
+
+		conf := client.conf
+		conf.DeviceName = ""
+		api.conf.SignPublicKey = conf.SignPublicKey
+		defer func() { api.conf.SignPublicKey = nil }()
+
+		pub, priv := mustKeyPair(t)
+
+		_, err := newClient(conf).doRotateKey(pub, priv)
+		require.Error(t, err)
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		require.NoError(t, newDeviceRegistry(api.conf.DevicesFile).Revoke("laptop", time.Now()))
+
+		pub, priv := mustKeyPair(t)
+
+		_, err := client.doRotateKey(pub, priv)
+		require.Error(t, err)
+	})
+}
+
+func TestReencryptEntries(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	oldKey := client.conf.EncryptKey
+
+	copyEntry := func(content string, ttl time.Duration) {
+		metadata, err := sealMetadata(entryMetadata{Filename: content, Size: int64(len(content))}, client.conf.EncryptKey)
+		require.NoError(t, err)
+
+		_, err = client.doCopy(copyStreamHeader{TTL: int64(ttl.Seconds()), Metadata: metadata}, secretStreamEncrypt(strings.NewReader(content), client.conf.EncryptKey))
+		require.NoError(t, err)
+	}
+
+	copyEntry("foo", time.Hour)
+	copyEntry("bar", 2*time.Hour)
+
+	// An entry without metadata from an old client
+
+	v1Content := []byte("baz")
+	req := copyRequest{Signature: sign(client.conf.SignPrivateKey, v1Content), Content: v1Content}
+	_, err := client.doRequest(req, http.MethodPost, http.StatusAccepted, "/api/v1/copy")
+	require.NoError(t, err)
+
+	//
+
+	newKey := rotateEncryptKey(&client.conf)
+	require.Equal(t, []secretBoxKey{oldKey}, client.conf.RetiredEncryptKeys)
+
+	// An entry created after the rotation isn't touched
+
+	copyEntry("qux", time.Hour)
+
+	before, err := api.st.ListAll()
+	require.NoError(t, err)
+
+	reencrypted, skipped, err := reencryptEntries(client, client.conf, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 2, reencrypted)
+	require.Equal(t, 1, skipped)
+
+	//
+
+	body, err := client.doList(listRequest{})
+	require.NoError(t, err)
+
+	var resp listResponse
+	require.NoError(t, json.Unmarshal(body, &resp))
+	require.Len(t, resp.Entries, 4)
+
+	require.Equal(t, before[2].ID, resp.Entries[0].ID, "the entry without metadata is untouched")
+	require.Equal(t, before[3].ID, resp.Entries[1].ID, "the entry with the new key is untouched")
+
+	for i, name := range []string{"foo", "bar"} {
+		entry := resp.Entries[2+i]
+
+		md, err := openMetadata(entry.Metadata, newKey)
+		require.NoError(t, err)
+		require.Equal(t, name, md.Filename)
+		require.WithinDuration(t, before[i].ExpiresAt, entry.ExpiresAt, time.Second)
+
+		_, content, err := client.doPaste(pasteRequest{ID: entry.ID})
+		require.NoError(t, err)
+
+		plaintext, err := newSecretStreamReader(content, newKey)
+		require.NoError(t, err)
+
+		data, err := ioutil.ReadAll(plaintext)
+		require.NoError(t, err)
+		require.Equal(t, name, string(data))
+		content.Close()
+	}
+}
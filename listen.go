@@ -0,0 +1,304 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// unixAddrPrefix starts the listen address of an unix socket and the endpoint of a server listening on one.
+	unixAddrPrefix = "unix://"
+
+	// systemdListenAddr is the listen address to use the socket passed by systemd.
+	// A specific socket can be selected with its FileDescriptorName as in systemd:apero.
+	systemdListenAddr = "systemd"
+
+	// defaultSocketMode is the permissions of the unix socket when the configuration doesn't specify them.
+	defaultSocketMode = 0660
+
+	// systemdFirstFD is the first file descriptor passed by systemd, after stdin, stdout and stderr.
+	systemdFirstFD = 3
+)
+
+var errNoSystemdListener = errors.New("no socket passed by systemd")
+
+// validateListenAddr checks that addr is either host:port, unix:///path/to/socket or systemd[:name].
+func validateListenAddr(addr string) error {
+	switch {
+	case strings.HasPrefix(addr, unixAddrPrefix):
+		if strings.TrimPrefix(addr, unixAddrPrefix) == "" {
+			return fmt.Errorf("listen address %q has no socket path", addr)
+		}
+		return nil
+	case isSystemdListenAddr(addr):
+		return nil
+	}
+
+	_, _, err := net.SplitHostPort(addr)
+	return err
+}
+
+// parseSocketMode parses the permissions of the unix socket, written in octal.
+// If s is empty it returns defaultSocketMode.
+func parseSocketMode(s string) (os.FileMode, error) {
+	if s == "" {
+		return defaultSocketMode, nil
+	}
+
+	mode, err := strconv.ParseUint(s, 8, 32)
+	if err != nil || mode > 0777 {
+		return 0, fmt.Errorf("socket mode %q is invalid", s)
+	}
+
+	return os.FileMode(mode), nil
+}
+
+// listen creates the listener of the server for its listen address and the listener of the metrics endpoint,
+// which is nil if the metrics are served on the listen address.
+func listen(conf serverConfig) (net.Listener, net.Listener, error) {
+	addrs := []string{conf.ListenAddr}
+	if conf.MetricsListenAddr != "" {
+		addrs = append(addrs, conf.MetricsListenAddr)
+	}
+
+	// NOTE(vincent): the sockets passed by systemd can only be taken once, select them all at the same time.
+
+	var names []string
+	for _, addr := range addrs {
+		if isSystemdListenAddr(addr) {
+			names = append(names, strings.TrimPrefix(strings.TrimPrefix(addr, systemdListenAddr), ":"))
+		}
+	}
+
+	var inherited []net.Listener
+	if len(names) > 0 {
+		listeners, err := systemdListeners(os.Getenv, systemdFirstFD)
+		if err != nil {
+			return nil, nil, err
+		}
+		if inherited, err = selectSystemdListeners(listeners, names); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	//
+
+	listeners := make([]net.Listener, 0, len(addrs))
+	closeAll := func() {
+		for _, ln := range listeners {
+			ln.Close()
+		}
+		for _, ln := range inherited {
+			ln.Close()
+		}
+	}
+
+	for _, addr := range addrs {
+		switch {
+		case strings.HasPrefix(addr, unixAddrPrefix):
+			mode, err := parseSocketMode(conf.SocketMode)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			ln, err := listenUnix(strings.TrimPrefix(addr, unixAddrPrefix), mode)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			listeners = append(listeners, ln)
+
+		case isSystemdListenAddr(addr):
+			listeners = append(listeners, inherited[0])
+			inherited = inherited[1:]
+
+		default:
+			ln, err := net.Listen("tcp", addr)
+			if err != nil {
+				closeAll()
+				return nil, nil, err
+			}
+			listeners = append(listeners, ln)
+		}
+	}
+
+	if len(listeners) == 1 {
+		return listeners[0], nil, nil
+	}
+	return listeners[0], listeners[1], nil
+}
+
+func isSystemdListenAddr(addr string) bool {
+	return addr == systemdListenAddr || strings.HasPrefix(addr, systemdListenAddr+":")
+}
+
+// listenUnix listens on the unix socket at path and sets its permissions.
+//
+// A socket left behind by a previous run is removed but any other file is left alone.
+func listenUnix(path string, mode os.FileMode) (net.Listener, error) {
+	fi, err := os.Lstat(path)
+	switch {
+	case err == nil && fi.Mode()&os.ModeSocket == 0:
+		return nil, fmt.Errorf("%s exists and isn't a socket", path)
+	case err == nil:
+		if err := os.Remove(path); err != nil {
+			return nil, fmt.Errorf("unable to remove stale socket. err: %v", err)
+		}
+	case !os.IsNotExist(err):
+		return nil, err
+	}
+
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	// NOTE(vincent): the socket is created with the permissions allowed by the umask,
+	// there's a short window before they're restricted. Put the socket in a directory
+	// only the server and the reverse proxy can access to close it.
+	if err := os.Chmod(path, mode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("unable to set the socket permissions. err: %v", err)
+	}
+
+	return ln, nil
+}
+
+// systemdListener is a listener passed by systemd with socket activation.
+type systemdListener struct {
+	net.Listener
+	name string
+}
+
+// systemdListeners returns the listeners passed by systemd, as described in sd_listen_fds(3).
+//
+// The environment variables are only for this process: they're cleared so that
+// the processes started by apero don't inherit them.
+func systemdListeners(getenv func(string) string, firstFD int) ([]systemdListener, error) {
+	defer func() {
+		os.Unsetenv("LISTEN_PID")
+		os.Unsetenv("LISTEN_FDS")
+		os.Unsetenv("LISTEN_FDNAMES")
+	}()
+
+	pid, err := strconv.Atoi(getenv("LISTEN_PID"))
+	if err != nil || pid != os.Getpid() {
+		return nil, errNoSystemdListener
+	}
+
+	n, err := strconv.Atoi(getenv("LISTEN_FDS"))
+	if err != nil || n <= 0 {
+		return nil, errNoSystemdListener
+	}
+
+	var names []string
+	if s := getenv("LISTEN_FDNAMES"); s != "" {
+		names = strings.Split(s, ":")
+	}
+
+	//
+
+	listeners := make([]systemdListener, 0, n)
+	for i := 0; i < n; i++ {
+		name := "LISTEN_FD_" + strconv.Itoa(firstFD+i)
+		if i < len(names) {
+			name = names[i]
+		}
+
+		f := os.NewFile(uintptr(firstFD+i), name)
+
+		// NOTE(vincent): FileListener duplicates the file descriptor, the original one can be closed.
+		ln, err := net.FileListener(f)
+		f.Close()
+		if err != nil {
+			for _, l := range listeners {
+				l.Close()
+			}
+			return nil, fmt.Errorf("file descriptor %d passed by systemd isn't a listening socket. err: %v", firstFD+i, err)
+		}
+
+		listeners = append(listeners, systemdListener{Listener: ln, name: name})
+	}
+
+	return listeners, nil
+}
+
+// selectSystemdListeners returns the listeners with these names, in the same order.
+// An empty name selects the only listener. The other listeners are closed.
+func selectSystemdListeners(listeners []systemdListener, names []string) ([]net.Listener, error) {
+	selected := make([]net.Listener, len(names))
+	used := make([]bool, len(listeners))
+
+	var err error
+	for i, name := range names {
+		for j, l := range listeners {
+			if !used[j] && (l.name == name || (name == "" && len(listeners) == 1)) {
+				selected[i] = l.Listener
+				used[j] = true
+				break
+			}
+		}
+
+		switch {
+		case selected[i] != nil:
+		case name == "":
+			err = fmt.Errorf("systemd passed %d sockets, select one with systemd:<name>", len(listeners))
+		default:
+			err = fmt.Errorf("no socket named %q passed by systemd", name)
+		}
+		if err != nil {
+			break
+		}
+	}
+
+	for j, l := range listeners {
+		if !used[j] || err != nil {
+			l.Close()
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return selected, nil
+}
+
+// unixEndpointSocket returns the path of the socket of an unix:// endpoint.
+func unixEndpointSocket(endpoint string) (string, bool) {
+	if !strings.HasPrefix(endpoint, unixAddrPrefix) {
+		return "", false
+	}
+	return strings.TrimPrefix(endpoint, unixAddrPrefix), true
+}
+
+// endpointURL returns the URL of path on the server at endpoint.
+//
+// The URL of a server listening on an unix socket has a placeholder host,
+// the HTTP client returned by newUnixHTTPClient connects to the socket whatever the host.
+func endpointURL(endpoint, path string) string {
+	if _, ok := unixEndpointSocket(endpoint); ok {
+		return "http://unix" + path
+	}
+	return endpoint + path
+}
+
+// newUnixHTTPClient returns a HTTP client connecting to the unix socket at path.
+func newUnixHTTPClient(path string) *http.Client {
+	var dialer net.Dialer
+
+	return &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				return dialer.DialContext(ctx, "unix", path)
+			},
+			IdleConnTimeout: 90 * time.Second,
+		},
+	}
+}
@@ -0,0 +1,177 @@
+// +build !windows
+
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateListenAddr(t *testing.T) {
+	for _, addr := range []string{"localhost:7568", ":7568", "unix:///run/apero/apero.sock", "unix://apero.sock", "systemd", "systemd:apero"} {
+		require.NoError(t, validateListenAddr(addr), addr)
+	}
+	for _, addr := range []string{"", "localhost", "unix://", "/run/apero/apero.sock", "systemdfoo"} {
+		require.Error(t, validateListenAddr(addr), addr)
+	}
+}
+
+func TestParseSocketMode(t *testing.T) {
+	mode, err := parseSocketMode("")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0660), mode)
+
+	mode, err = parseSocketMode("0600")
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), mode)
+
+	for _, s := range []string{"foobar", "0888", "01777", "-1"} {
+		_, err := parseSocketMode(s)
+		require.Error(t, err, s)
+	}
+}
+
+func TestListenUnix(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "apero.sock")
+
+	ln, metricsLn, err := listen(serverConfig{ListenAddr: "unix://" + path, SocketMode: "0600"})
+	require.NoError(t, err)
+	require.Nil(t, metricsLn)
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0600), fi.Mode().Perm())
+
+	// A stale socket is replaced
+
+	ln.(*net.UnixListener).SetUnlinkOnClose(false)
+	require.NoError(t, ln.Close())
+
+	ln, err = listenUnix(path, 0660)
+	require.NoError(t, err)
+	require.NoError(t, ln.Close())
+
+	// but not another file
+
+	path = filepath.Join(dir, "foobar")
+	require.NoError(t, ioutil.WriteFile(path, []byte("foobar"), 0600))
+
+	_, err = listenUnix(path, 0660)
+	require.Error(t, err)
+}
+
+func TestListenMetrics(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "metrics.sock")
+
+	ln, metricsLn, err := listen(serverConfig{ListenAddr: "127.0.0.1:0", MetricsListenAddr: "unix://" + path})
+	require.NoError(t, err)
+	defer ln.Close()
+	defer metricsLn.Close()
+
+	require.Equal(t, "tcp", ln.Addr().Network())
+	require.Equal(t, path, metricsLn.Addr().String())
+
+	// Nothing is left listening if an address can't be used
+
+	file := filepath.Join(dir, "foobar")
+	require.NoError(t, ioutil.WriteFile(file, []byte("foobar"), 0600))
+
+	_, _, err = listen(serverConfig{ListenAddr: "unix://" + filepath.Join(dir, "apero.sock"), MetricsListenAddr: "unix://" + file})
+	require.Error(t, err)
+
+	_, err = os.Stat(filepath.Join(dir, "apero.sock"))
+	require.True(t, os.IsNotExist(err))
+}
+
+func TestServerClientUnix(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	api, client, plainServer := newTestServerClient(t)
+	plainServer.Close()
+
+	path := filepath.Join(dir, "apero.sock")
+
+	ln, err := listenUnix(path, 0600)
+	require.NoError(t, err)
+
+	srv := &http.Server{Handler: serverHandler(api, newUIHandler(api.conf))}
+	go srv.Serve(ln)
+	defer srv.Close()
+
+	conf := client.conf
+	conf.Endpoint = "unix://" + path
+	require.NoError(t, conf.Validate())
+	require.NoError(t, checkEnrollConfig(conf))
+
+	c := newClient(conf)
+
+	id, err := c.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	_, body, err := c.doMove(moveRequest{ID: id})
+	require.NoError(t, err)
+	defer body.Close()
+
+	data, err := ioutil.ReadAll(body)
+	require.NoError(t, err)
+	require.Equal(t, "hello", string(data))
+}
+
+func TestSystemdListeners(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer ln.Close()
+
+	f, err := ln.(*net.TCPListener).File()
+	require.NoError(t, err)
+	defer f.Close()
+
+	// NOTE(vincent): systemdListeners closes the file descriptors it's given.
+	fd, err := syscall.Dup(int(f.Fd()))
+	require.NoError(t, err)
+
+	env := map[string]string{
+		"LISTEN_PID":     strconv.Itoa(os.Getpid()),
+		"LISTEN_FDS":     "1",
+		"LISTEN_FDNAMES": "apero",
+	}
+	getenv := func(key string) string { return env[key] }
+
+	listeners, err := systemdListeners(getenv, fd)
+	require.NoError(t, err)
+	require.Len(t, listeners, 1)
+	require.Equal(t, "apero", listeners[0].name)
+	require.Equal(t, ln.Addr().String(), listeners[0].Addr().String())
+
+	inherited, err := selectSystemdListeners(listeners, []string{"apero"})
+	require.NoError(t, err)
+	require.Len(t, inherited, 1)
+	defer inherited[0].Close()
+
+	_, err = selectSystemdListeners(listeners[:0], []string{""})
+	require.Error(t, err)
+	_, err = selectSystemdListeners(listeners[:0], []string{"foobar"})
+	require.Error(t, err)
+
+	// Only for this process
+
+	env["LISTEN_PID"] = "1"
+	_, err = systemdListeners(getenv, fd)
+	require.Equal(t, errNoSystemdListener, err)
+}
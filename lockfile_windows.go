@@ -0,0 +1,35 @@
+// +build windows
+
+package main
+
+import (
+	"os"
+	"time"
+)
+
+// staleLockAge is the age after which a lock file is assumed to be left over by a dead process.
+// The locks are only held while a small file is rewritten so this is plenty.
+const staleLockAge = 30 * time.Second
+
+// lockFile takes an exclusive lock on the file at path and returns the function releasing the lock.
+//
+// NOTE(vincent): syscall doesn't have LockFileEx, the lock is the existence of the file instead.
+func lockFile(path string) (func(), error) {
+	for {
+		f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE|os.O_EXCL, 0600)
+		if err == nil {
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if fi, err := os.Stat(path); err == nil && time.Since(fi.ModTime()) > staleLockAge {
+			os.Remove(path)
+			continue
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -1,18 +1,29 @@
 package main
 
 import (
-	crypto_rand "crypto/rand"
+	"bufio"
+	"bytes"
+	"context"
 	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"flag"
 	"fmt"
 	"html/template"
+	"io"
 	"io/ioutil"
 	"log"
 	"net"
 	"net/http"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path/filepath"
+	"runtime"
+	"strconv"
+	"strings"
+	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"rischmann.fr/apero/internal/ui"
@@ -48,17 +59,78 @@ var (
 	globalFlags  = flag.NewFlagSet("apero", flag.ExitOnError)
 	globalConfig = globalFlags.String("config", os.Getenv("HOME")+"/.apero.toml", "Configuration file to use")
 
-	copyFlags  = flag.NewFlagSet("copy", flag.ExitOnError)
-	moveFlags  = flag.NewFlagSet("move", flag.ExitOnError)
-	pasteFlags = flag.NewFlagSet("paste", flag.ExitOnError)
-	listFlags  = flag.NewFlagSet("list", flag.ExitOnError)
-	serveFlags = flag.NewFlagSet("serve", flag.ExitOnError)
+	copyFlags         = flag.NewFlagSet("copy", flag.ExitOnError)
+	copyTTL           = copyFlags.Duration("ttl", 0, "Lifetime of the entry in the staging server. If zero the server default is used")
+	copyName          = copyFlags.String("name", "", "File name of the entry. Defaults to the base name of the file copied")
+	copyNote          = copyFlags.String("note", "", "Note attached to the entry")
+	copyClip          = copyFlags.Bool("clipboard", false, "Copy the content of the system clipboard")
+	copyCompress      = copyFlags.Bool("compress", false, "Compress the archive with gzip when copying a directory or several files")
+	moveFlags         = flag.NewFlagSet("move", flag.ExitOnError)
+	moveClip          = moveFlags.Bool("clipboard", false, "Write the entry to the system clipboard instead of stdout")
+	moveExtract       = moveFlags.String("extract", "", "Extract the archive entry in this directory")
+	moveOutput        = moveFlags.String("o", "", "Write the entry to this file, or in this directory with its original file name")
+	moveOriginalName  = moveFlags.Bool("O", false, "Write the entry to a file in the current directory with its original file name")
+	moveForce         = moveFlags.Bool("force", false, "Overwrite the file written with -o or -O if it exists")
+	pasteFlags        = flag.NewFlagSet("paste", flag.ExitOnError)
+	pasteClip         = pasteFlags.Bool("clipboard", false, "Write the entry to the system clipboard instead of stdout")
+	pasteExtract      = pasteFlags.String("extract", "", "Extract the archive entry in this directory")
+	pasteOutput       = pasteFlags.String("o", "", "Write the entry to this file, or in this directory with its original file name")
+	pasteOriginalName = pasteFlags.Bool("O", false, "Write the entry to a file in the current directory with its original file name")
+	pasteForce        = pasteFlags.Bool("force", false, "Overwrite the file written with -o or -O if it exists")
+	listFlags         = flag.NewFlagSet("list", flag.ExitOnError)
+	watchFlags        = flag.NewFlagSet("watch", flag.ExitOnError)
+	watchPaste        = watchFlags.Bool("paste", false, "Paste the new entries from other devices in the directory given with -dir")
+	watchDir          = watchFlags.String("dir", ".", "Directory where the entries are pasted")
+	watchPoll         = watchFlags.Bool("poll", false, "Use long-polling instead of an event stream, for networks which buffer responses")
+	serveFlags        = flag.NewFlagSet("serve", flag.ExitOnError)
+	serveGrace        = serveFlags.Duration("shutdown-timeout", defaultShutdownTimeout, "How long to wait for the requests in progress when stopping")
+
+	clipboardSyncFlags    = flag.NewFlagSet("clipboard-sync", flag.ExitOnError)
+	clipboardSyncInterval = clipboardSyncFlags.Duration("interval", time.Second, "How often the clipboard is checked for changes")
+	clipboardSyncTTL      = clipboardSyncFlags.Duration("ttl", 0, "Lifetime of the entries copied from the clipboard. If zero the server default is used")
+	clipboardSyncPoll     = clipboardSyncFlags.Bool("poll", false, "Use long-polling instead of an event stream, for networks which buffer responses")
 
 	genconfigFlags        = flag.NewFlagSet("genconfig", flag.ExitOnError)
 	genconfigClientConfig = genconfigFlags.String("client-config", "./client.toml", "File path for the client config")
 	genconfigServerConfig = genconfigFlags.String("server-config", "./server.toml", "File path for the server config")
-
-	provisionFlags = flag.NewFlagSet("provision", flag.ExitOnError)
+	genconfigStoragePath  = genconfigFlags.String("storage-path", "./data", "Directory where the server stores the entries")
+	genconfigDevicesFile  = genconfigFlags.String("devices-file", "./devices.toml", "File path for the devices registry of the server")
+	genconfigDeviceName   = genconfigFlags.String("device-name", "", "Name of the device of the client config. Defaults to the hostname")
+	genconfigTLSCert      = genconfigFlags.String("tls-cert", "./cert.pem", "File path for the self-signed TLS certificate of the server")
+	genconfigTLSKey       = genconfigFlags.String("tls-key", "./key.pem", "File path for the TLS key of the server")
+	genconfigNoTLS        = genconfigFlags.Bool("no-tls", false, "Don't generate a TLS certificate, the server uses plain HTTP")
+
+	devicesFlags       = flag.NewFlagSet("devices", flag.ExitOnError)
+	devicesAddFlags    = flag.NewFlagSet("add", flag.ExitOnError)
+	devicesListFlags   = flag.NewFlagSet("list", flag.ExitOnError)
+	devicesRevokeFlags = flag.NewFlagSet("revoke", flag.ExitOnError)
+
+	keysFlags                    = flag.NewFlagSet("keys", flag.ExitOnError)
+	keysRotateFlags              = flag.NewFlagSet("rotate", flag.ExitOnError)
+	keysRotatePSKFlags           = flag.NewFlagSet("psk", flag.ExitOnError)
+	keysRotatePSKGrace           = keysRotatePSKFlags.Duration("grace", keyRotationGracePeriod, "How long the previous key is still accepted")
+	keysRotateEncryptFlags       = flag.NewFlagSet("encrypt", flag.ExitOnError)
+	keysRotateEncryptNoReencrypt = keysRotateEncryptFlags.Bool("no-reencrypt", false, "Don't encrypt the existing entries again with the new key")
+	keysRotateSignFlags          = flag.NewFlagSet("sign", flag.ExitOnError)
+
+	provisionFlags    = flag.NewFlagSet("provision", flag.ExitOnError)
+	provisionTTL      = provisionFlags.Duration("ttl", defaultProvisioningTTL, "How long the provisioning URL can be used")
+	provisionTerminal = provisionFlags.Bool("terminal", false, "Show the QR code of the provisioning URL in the terminal instead of opening the provisioning UI")
+	provisionShowConf = provisionFlags.Bool("show-config", false, "With -terminal, also show a QR code of the config. It contains the keys in clear")
+
+	enrollFlags      = flag.NewFlagSet("enroll", flag.ExitOnError)
+	enrollMnemonic   = enrollFlags.Bool("mnemonic", false, "Type the endpoint and the keys shown by apero provision instead of fetching them")
+	enrollDeviceName = enrollFlags.String("device-name", "", "Name of this device in the server registry. Defaults to the one of the provisioning config")
+	enrollCode       = enrollFlags.String("code", "", "Provisioning code shown by apero provision. Asked for if not given")
+	enrollForce      = enrollFlags.Bool("force", false, "Overwrite the config file if it exists")
+
+	pairFlags = flag.NewFlagSet("pair", flag.ExitOnError)
+
+	joinFlags       = flag.NewFlagSet("join", flag.ExitOnError)
+	joinEndpoint    = joinFlags.String("endpoint", "", "URL of the staging server")
+	joinFingerprint = joinFlags.String("fingerprint", "", "Fingerprint of the staging server certificate, if it's self-signed")
+	joinDeviceName  = joinFlags.String("device-name", "", "Name of this device in the server registry")
+	joinForce       = joinFlags.Bool("force", false, "Overwrite the config file if it exists")
 )
 
 func runCopy(args []string) error {
@@ -66,57 +138,858 @@ func runCopy(args []string) error {
 	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
 		return fmt.Errorf("invalid toml config. err=%v", err)
 	}
-	if err := conf.Validate(); err != nil {
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	if len(args) < 1 && !*copyClip {
+		return errors.New("need at least one path to copy")
+	}
+
+	md := entryMetadata{
+		Size: -1,
+		Note: *copyNote,
+	}
+	md.Host, _ = os.Hostname()
+
+	var input io.Reader
+	switch {
+	case *copyClip:
+		cb, err := newClipboard(conf.Clipboard, exec.LookPath, os.Getenv, runtime.GOOS)
+		if err != nil {
+			return err
+		}
+
+		content, contentType, err := cb.Read()
+		if err != nil {
+			return err
+		}
+
+		input = bytes.NewReader(content)
+		md.ContentType = contentType
+		md.Size = int64(len(content))
+
+	case args[0] == "-":
+		input = os.Stdin
+
+	case len(args) > 1 || isDirectory(args[0]):
+		files, err := collectArchiveFiles(args)
+		if err != nil {
+			return err
+		}
+
+		format := archiveTar
+		md.ContentType = "application/x-tar"
+		if *copyCompress {
+			format = archiveTarGzip
+			md.ContentType = "application/gzip"
+		}
+
+		md.Filename = archiveName(args, format)
+		md.Archive = format
+		md.Files = countArchiveFiles(files)
+
+		pr, pw := io.Pipe()
+		defer pr.Close()
+
+		go func() {
+			pw.CloseWithError(writeArchive(pw, files, format))
+		}()
+
+		input = pr
+
+	default:
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+
+		fi, err := f.Stat()
+		if err != nil {
+			return err
+		}
+
+		input = f
+		md.Filename = filepath.Base(args[0])
+		md.Size = fi.Size()
+		md.Mode = uint32(fi.Mode().Perm())
+	}
+	if *copyName != "" {
+		md.Filename = *copyName
+	}
+
+	// Peek at the content to guess its type
+
+	br := bufio.NewReader(input)
+	head, _ := br.Peek(512)
+
+	if md.ContentType == "" {
+		md.ContentType = detectContentType(md.Filename, head)
+	}
+
+	metadata, err := sealMetadata(md, conf.EncryptKey)
+	if err != nil {
+		return err
+	}
+
+	//
+
+	content := secretStreamEncrypt(br, conf.EncryptKey)
+	defer content.Close()
+
+	//
+
+	client := newClient(conf)
+
+	req := uploadStartRequest{
+		TTL:      int64(copyTTL.Seconds()),
+		Metadata: metadata,
+	}
+
+	id, err := client.doUpload(req, content)
+	if err != nil {
+		return err
+	}
+
+	fmt.Printf("id: %s\n", id)
+
+	return nil
+}
+
+// pasteOptions are the options of move and paste.
+type pasteOptions struct {
+	// clipboard writes the entry to the system clipboard.
+	clipboard bool
+	// extract is the directory where an archive entry is extracted.
+	extract string
+	// output is the file where the entry is written, or the directory if it's one.
+	output string
+	// originalName writes the entry to a file in the current directory named after the original file.
+	originalName bool
+	// force allows overwriting an existing file.
+	force bool
+}
+
+func (o pasteOptions) toStdout() bool {
+	return !o.clipboard && o.extract == "" && o.output == "" && !o.originalName
+}
+
+// Validate returns an error if more than one destination is given.
+func (o pasteOptions) Validate(action string) error {
+	var n int
+	for _, set := range []bool{o.clipboard, o.extract != "", o.output != "", o.originalName} {
+		if set {
+			n++
+		}
+	}
+	if n > 1 {
+		return fmt.Errorf("usage: apero %s [-o path | -O | -clipboard | -extract dir] [-force] [entry id]", strings.TrimPrefix(action, "/"))
+	}
+
+	return nil
+}
+
+func doRunMoveOrPaste(args []string, action string, opts pasteOptions) error {
+	if err := opts.Validate(action); err != nil {
+		return err
+	}
+
+	var conf clientConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		return fmt.Errorf("invalid toml config. err=%v", err)
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	var id ulid.ULID
+	if len(args) > 0 {
+		var err error
+		id, err = ulid.Parse(args[0])
+		if err != nil {
+			return err
+		}
+	}
+
+	//
+
+	client := newClient(conf)
+
+	var (
+		info entryInfo
+		body io.ReadCloser
+		err  error
+	)
+
+	// NOTE(vincent): unless the entry goes to stdout it's only removed from the server once it's written.
+	fetch := action
+	if !opts.toStdout() {
+		fetch = "/paste"
+	}
+
+	switch fetch {
+	case "/move":
+		info, body, err = client.doMove(moveRequest{ID: id})
+	case "/paste":
+		info, body, err = client.doResumablePaste(pasteRequest{ID: id})
+	}
+	switch {
+	case err == errEntryNotFound && isEmptyULID(id):
+		return errors.New("nothing in the staging server")
+	case err != nil:
+		return err
+	}
+	defer body.Close()
+
+	// The entry might have been encrypted with a retired key
+
+	md, key, err := openMetadataWithKeys(info.Metadata, conf.encryptKeys())
+	if err != nil {
+		key = conf.EncryptKey
+	}
+
+	plaintext, err := newSecretStreamReader(body, key)
+	if err != nil {
+		return fmt.Errorf("unable to decipher content. err: %v", err)
+	}
+
+	switch {
+	case opts.toStdout():
+		if _, err := io.Copy(os.Stdout, plaintext); err != nil {
+			return fmt.Errorf("unable to decipher content. err: %v", err)
+		}
+		return nil
+
+	case opts.extract != "":
+		if md.Archive == "" {
+			return errNotAnArchive
+		}
+
+		n, err := extractArchive(plaintext, md.Archive, opts.extract)
+		if err != nil {
+			return fmt.Errorf("unable to extract archive. err: %v", err)
+		}
+		fmt.Printf("extracted %d files to %s\n", n, opts.extract)
+
+	case opts.clipboard:
+		cb, err := newClipboard(conf.Clipboard, exec.LookPath, os.Getenv, runtime.GOOS)
+		if err != nil {
+			return err
+		}
+
+		if err := pasteToClipboard(cb, plaintext, md.ContentType); err != nil {
+			return fmt.Errorf("unable to write to the clipboard. err: %v", err)
+		}
+
+	default:
+		path := opts.output
+		if opts.originalName || isDirectory(path) {
+			path = filepath.Join(path, entryFileName(info, md))
+		}
+
+		if err := pasteToFile(plaintext, path, md, opts.force); err != nil {
+			return err
+		}
+		fmt.Printf("pasted to %s\n", path)
+	}
+
+	if action == "/move" {
+		_, body, err := client.doMove(moveRequest{ID: info.ID})
+		if err != nil {
+			return err
+		}
+		body.Close()
+	}
+
+	return nil
+}
+
+func runMove(args []string) error {
+	return doRunMoveOrPaste(args, "/move", pasteOptions{
+		clipboard:    *moveClip,
+		extract:      *moveExtract,
+		output:       *moveOutput,
+		originalName: *moveOriginalName,
+		force:        *moveForce,
+	})
+}
+
+func runPaste(args []string) error {
+	return doRunMoveOrPaste(args, "/paste", pasteOptions{
+		clipboard:    *pasteClip,
+		extract:      *pasteExtract,
+		output:       *pasteOutput,
+		originalName: *pasteOriginalName,
+		force:        *pasteForce,
+	})
+}
+
+func runList(args []string) error {
+	var conf clientConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		return fmt.Errorf("invalid toml config. err=%v", err)
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	//
+
+	client := newClient(conf)
+
+	body, err := client.doList(listRequest{})
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return errors.New("nothing in the staging server")
+	}
+
+	//
+
+	var resp listResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return fmt.Errorf("unable to unmarshal response")
+	}
+
+	if len(resp.Entries) == 0 {
+		fmt.Println("no entries")
+		return nil
+	}
+
+	printEntries(os.Stdout, resp.Entries, conf.encryptKeys(), time.Now())
+
+	return nil
+}
+
+// maxWatchRetryDelay is the maximum delay before reconnecting to the server after an error.
+const maxWatchRetryDelay = 30 * time.Second
+
+func runWatch(args []string) error {
+	var conf clientConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		return fmt.Errorf("invalid toml config. err=%v", err)
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	//
+
+	client := newClient(conf)
+	keys := conf.encryptKeys()
+
+	handle := func(entry entryInfo) {
+		md, key, err := openMetadataWithKeys(entry.Metadata, keys)
+		if err != nil {
+			md, key = entryMetadata{Filename: "<unreadable>", Size: -1}, conf.EncryptKey
+		}
+
+		size := "-"
+		if md.Size >= 0 {
+			size = formatSize(md.Size)
+		}
+
+		fmt.Printf("%s  %s  %s  %s  %s\n", entry.ID, orDash(md.Filename), orDash(md.ContentType), size, orDash(entry.Device))
+
+		// NOTE(vincent): pasting the entries copied from this device is pointless.
+		if !*watchPaste || (entry.Device != "" && entry.Device == conf.DeviceName) {
+			return
+		}
+
+		path, err := pasteToDir(client, entry, md, key, *watchDir)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "unable to paste entry %s. err: %v\n", entry.ID, err)
+			return
+		}
+		fmt.Printf("pasted to %s\n", path)
+	}
+
+	// Only the entries created from now on are interesting
+
+	after, err := latestEntryID(client)
+	if err != nil {
+		return err
+	}
+
+	return watchEntries(client, after, *watchPoll, handle)
+}
+
+// latestEntryID returns the ID of the newest entry in the staging server, or an empty ID if there's none.
+func latestEntryID(c *client) (ulid.ULID, error) {
+	body, err := c.doList(listRequest{})
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	var resp listResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return ulid.ULID{}, fmt.Errorf("unable to unmarshal response")
+	}
+	if n := len(resp.Entries); n > 0 {
+		return resp.Entries[n-1].ID, nil
+	}
+
+	return ulid.ULID{}, nil
+}
+
+// watchEntries calls handle for every entry created after the given ID, reconnecting to the server after network errors.
+// It only returns when the server rejects the requests.
+func watchEntries(c *client, after ulid.ULID, poll bool, handle func(entryInfo)) error {
+	var delay time.Duration
+	for {
+		start := time.Now()
+
+		var err error
+		if poll {
+			var entries []entryInfo
+			entries, err = c.doPoll(after, defaultSubscribeTimeout)
+			for _, entry := range entries {
+				handle(entry)
+				after = entry.ID
+			}
+		} else {
+			err = c.doWatch(after, func(entry entryInfo) error {
+				handle(entry)
+				after = entry.ID
+				return nil
+			})
+		}
+
+		if _, ok := err.(*transportError); err != nil && !ok {
+			return err
+		}
+		if err == nil || time.Since(start) > maxWatchRetryDelay {
+			delay = 0
+		}
+		if err == nil {
+			continue
+		}
+
+		delay = 2*delay + time.Second
+		if delay > maxWatchRetryDelay {
+			delay = maxWatchRetryDelay
+		}
+
+		fmt.Fprintf(os.Stderr, "%v, reconnecting in %s\n", err, delay)
+		time.Sleep(delay)
+	}
+}
+
+func runClipboardSync(args []string) error {
+	var conf clientConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		return fmt.Errorf("invalid toml config. err=%v", err)
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	cb, err := newClipboard(conf.Clipboard, exec.LookPath, os.Getenv, runtime.GOOS)
+	if err != nil {
+		return err
+	}
+
+	//
+
+	client := newClient(conf)
+
+	syncer := newClipboardSyncer(client, cb, *clipboardSyncTTL)
+	if err := syncer.Init(); err != nil {
+		return err
+	}
+
+	after, err := latestEntryID(client)
+	if err != nil {
+		return err
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- watchEntries(client, after, *clipboardSyncPoll, func(entry entryInfo) {
+			applied, err := syncer.ApplyRemote(entry)
+			switch {
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "unable to apply entry %s to the clipboard. err: %v\n", entry.ID, err)
+			case applied:
+				fmt.Printf("applied entry %s to the clipboard\n", entry.ID)
+			}
+		})
+	}()
+
+	ticker := time.NewTicker(*clipboardSyncInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case err := <-errCh:
+			return err
+
+		case <-ticker.C:
+			// NOTE(vincent): clipboard tools fail while the session is locked for example, this is not fatal.
+			id, copied, err := syncer.SyncLocal()
+			switch {
+			case err != nil:
+				fmt.Fprintf(os.Stderr, "unable to copy the clipboard. err: %v\n", err)
+			case copied:
+				fmt.Printf("copied the clipboard as %s\n", id)
+			}
+		}
+	}
+}
+
+// pasteToDir pastes the entry in a new file in dir named after the entry and returns its path.
+func pasteToDir(c *client, entry entryInfo, md entryMetadata, key secretBoxKey, dir string) (string, error) {
+	name := entryFileName(entry, md)
+
+	path := filepath.Join(dir, name)
+	if _, err := os.Stat(path); err == nil {
+		path = filepath.Join(dir, name+"."+entry.ID.String())
+	}
+
+	_, body, err := c.doResumablePaste(pasteRequest{ID: entry.ID})
+	if err != nil {
+		return "", err
+	}
+	defer body.Close()
+
+	plaintext, err := newSecretStreamReader(body, key)
+	if err != nil {
+		return "", fmt.Errorf("unable to decipher content. err: %v", err)
+	}
+
+	return path, createFileAtomic(path, plaintext, md.fileMode())
+}
+
+// entryFileName returns the name of the file to write the entry to: the base name of
+// the original file or the entry ID if there's none.
+func entryFileName(entry entryInfo, md entryMetadata) string {
+	name := filepath.Base(md.Filename)
+	if md.Filename == "" || name == "." || name == ".." || name == string(filepath.Separator) {
+		return entry.ID.String()
+	}
+	return name
+}
+
+// pasteToFile atomically writes the content to path with the permissions of the original file if they're known.
+// It refuses to overwrite an existing file unless force is true.
+func pasteToFile(r io.Reader, path string, md entryMetadata, force bool) error {
+	if _, err := os.Lstat(path); err == nil && !force {
+		return fmt.Errorf("%s already exists, use -force to overwrite it", path)
+	}
+
+	if err := createFileAtomic(path, r, md.fileMode()); err != nil {
+		return fmt.Errorf("unable to write %s. err: %v", path, err)
+	}
+
+	return nil
+}
+
+// printEntries prints a table of the entries with their metadata decrypted with one of the keys.
+func printEntries(w io.Writer, entries []entryInfo, keys []secretBoxKey, now time.Time) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "ID\tNAME\tTYPE\tSIZE\tFILES\tHOST\tDEVICE\tCREATED\tEXPIRES IN\tNOTE")
+	for _, entry := range entries {
+		md, _, err := openMetadataWithKeys(entry.Metadata, keys)
+		if err != nil {
+			md = entryMetadata{Filename: "<unreadable>", Size: -1}
+		}
+
+		size := "-"
+		if md.Size >= 0 {
+			size = formatSize(md.Size)
+		}
+
+		files := "-"
+		if md.Archive != "" {
+			files = strconv.Itoa(md.Files)
+		}
+
+		expiresIn := "never"
+		if !entry.ExpiresAt.IsZero() {
+			expiresIn = entry.ExpiresAt.Sub(now).Round(time.Second).String()
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\t%s\n",
+			entry.ID,
+			orDash(md.Filename),
+			orDash(md.ContentType),
+			size,
+			files,
+			orDash(md.Host),
+			orDash(entry.Device),
+			ulid.Time(entry.ID.Time()).Local().Format("2006-01-02 15:04:05"),
+			expiresIn,
+			md.Note,
+		)
+	}
+
+	tw.Flush()
+}
+
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func serverHandler(api *apiHandler, ui *uiHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, req *http.Request) {
+		head, tail := hutil.ShiftPath(req.URL.Path)
+		switch head {
+		case "api":
+			api.handle(w, req, tail)
+		default:
+			ui.handle(w, req, req.URL.Path)
+		}
+	}
+}
+
+// newServerHandler returns the handler of the server: the API and the web UI.
+// The metrics are never served by it, only on their own listener.
+func newServerHandler(api *apiHandler, ui *uiHandler) http.Handler {
+	return logRequests(serverHandler(api, ui))
+}
+
+// reapInterval is how often the server removes the expired entries.
+const reapInterval = time.Minute
+
+func runServe(args []string) error {
+	var conf serverConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		fatalf("invalid toml config. err=%v", err)
+	}
+	if err := conf.Validate(); err != nil {
+		fatal(err)
+	}
+
+	//
+
+	m := newMetrics()
+
+	st, err := newStore(conf.Storage, m)
+	if err != nil {
+		return fmt.Errorf("unable to create store. err: %v", err)
+	}
+
+	stopReaper := make(chan struct{})
+	go runReaper(st, reapInterval, stopReaper)
+
+	uploads, err := newUploadSessions(conf.Storage.uploadsDir())
+	if err != nil {
+		return fmt.Errorf("unable to create upload sessions. err: %v", err)
+	}
+
+	api := newAPIHandler(conf, st, uploads, m)
+	if err := api.devices.Load(); err != nil {
+		return err
+	}
+	ui := newUIHandler(conf)
+
+	ln, metricsLn, err := listen(conf)
+	if err != nil {
+		return fmt.Errorf("unable to listen. err: %v", err)
+	}
+	log.Printf("listening on %s", ln.Addr())
+
+	// The metrics are only served on their own listener, without TLS
+
+	if metricsLn != nil {
+		metricsMux := http.NewServeMux()
+		metricsMux.Handle("/metrics", m.handler())
+
+		metricsSrv := &http.Server{Handler: metricsMux}
+		go func() {
+			if err := metricsSrv.Serve(metricsLn); err != http.ErrServerClosed {
+				log.Printf("unable to serve metrics. err: %v", err)
+			}
+		}()
+		defer metricsSrv.Close()
+
+		log.Printf("serving metrics on %s", metricsLn.Addr())
+	}
+
+	srv := &http.Server{Handler: newServerHandler(api, ui)}
+
+	if conf.TLS.enabled() {
+		fingerprint, err := certFileFingerprint(conf.TLS.CertFile)
+		if err != nil {
+			return fmt.Errorf("unable to read TLS certificate. err: %v", err)
+		}
+		log.Printf("serving with TLS, certificate fingerprint %s", fingerprint)
+
+		tlsConf, err := newServerTLSConfig(conf.TLS, api)
+		if err != nil {
+			return err
+		}
+		if conf.TLS.ClientCerts {
+			log.Printf("devices can authenticate with client certificates")
+		}
+
+		srv.TLSConfig = tlsConf
+	}
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGINT, syscall.SIGTERM, syscall.SIGHUP)
+	defer signal.Stop(signals)
+
+	err = serveUntilSignal(srv, ln, signals, *serveGrace, func() error {
+		var conf serverConfig
+		if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+			return fmt.Errorf("invalid toml config. err: %v", err)
+		}
+		return api.reload(conf)
+	})
+
+	// The requests are done, nothing uses the store anymore
+
+	close(stopReaper)
+	uploads.Close()
+	if err := st.Close(); err != nil {
+		log.Printf("unable to close the store. err: %v", err)
+	}
+
+	return err
+}
+
+func runGenconfig(args []string) error {
+	pub, priv, err := generateKeyPair()
+	if err != nil {
+		fatal(err)
+	}
+
+	deviceName := *genconfigDeviceName
+	if deviceName == "" {
+		deviceName = defaultDeviceName()
+	}
+
+	// The server certificate is self-signed, the client pins its fingerprint
+
+	var (
+		endpoint    = "http://localhost:7568"
+		tlsConf     tlsConfig
+		fingerprint string
+	)
+	if !*genconfigNoTLS {
+		hosts := []string{"localhost", "127.0.0.1", "::1"}
+		if hostname, err := os.Hostname(); err == nil {
+			hosts = append(hosts, hostname)
+		}
+
+		certPEM, keyPEM, err := generateSelfSignedCert(hosts, time.Now())
+		if err != nil {
+			return err
+		}
+		if err := writeFileAtomic(*genconfigTLSCert, certPEM, 0644); err != nil {
+			return fmt.Errorf("unable to write TLS certificate. err: %v", err)
+		}
+		if err := writeFileAtomic(*genconfigTLSKey, keyPEM, 0600); err != nil {
+			return fmt.Errorf("unable to write TLS key. err: %v", err)
+		}
+
+		fingerprint, err = certFileFingerprint(*genconfigTLSCert)
+		if err != nil {
+			return err
+		}
+
+		endpoint = "https://localhost:7568"
+		tlsConf = tlsConfig{CertFile: *genconfigTLSCert, KeyFile: *genconfigTLSKey}
+	}
+
+	//
+
+	clientConf := clientConfig{
+		Endpoint:        endpoint,
+		PSKey:           newSecretBoxKey(),
+		EncryptKey:      newSecretBoxKey(),
+		SignPublicKey:   pub,
+		SignPrivateKey:  priv,
+		DeviceName:      deviceName,
+		CertFingerprint: fingerprint,
+	}
+	f, err := os.OpenFile(*genconfigClientConfig, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+	if err != nil {
+		return err
+	}
+	if err := toml.NewEncoder(f).Encode(clientConf); err != nil {
 		return err
 	}
+	f.Close()
 
-	if len(args) < 1 {
-		return errors.New("need at least one path to copy")
-	}
+	//
 
-	var (
-		data []byte
-		err  error
-	)
-	switch {
-	case args[0] == "-":
-		data, err = ioutil.ReadAll(os.Stdin)
-	default:
-		data, err = ioutil.ReadFile(args[0])
+	serverConf := serverConfig{
+		ListenAddr:  "localhost:7568",
+		PSKey:       clientConf.PSKey,
+		DevicesFile: *genconfigDevicesFile,
+		Storage: storageConfig{
+			Type: "disk",
+			Path: *genconfigStoragePath,
+		},
+		TLS: tlsConf,
 	}
+	f, err = os.OpenFile(*genconfigServerConfig, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
 	if err != nil {
 		return err
 	}
+	if err := toml.NewEncoder(f).Encode(serverConf); err != nil {
+		return err
+	}
+	f.Close()
 
 	//
 
-	ciphertext := secretBoxSeal(data, conf.EncryptKey)
-	signature := sign(conf.SignPrivateKey, ciphertext)
+	// Like the config files the devices registry is replaced
 
-	//
+	if err := os.Remove(serverConf.DevicesFile); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	if err := newDeviceRegistry(serverConf.DevicesFile).Add(deviceName, pub, time.Now()); err != nil {
+		return fmt.Errorf("unable to add device %q. err: %v", deviceName, err)
+	}
 
-	client := newClient(conf)
+	return nil
+}
 
-	req := copyRequest{
-		Signature: signature,
-		Content:   ciphertext,
+// defaultDeviceName returns a device name derived from the hostname.
+func defaultDeviceName() string {
+	hostname, err := os.Hostname()
+	if err != nil || !isValidDeviceName(hostname) {
+		return "default"
 	}
+	return hostname
+}
 
-	body, err := client.doCopy(req)
-	if err != nil {
-		return err
+// loadDeviceRegistry returns the devices registry of the server config.
+func loadDeviceRegistry() (*deviceRegistry, error) {
+	var conf serverConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		return nil, fmt.Errorf("invalid toml config. err=%v", err)
+	}
+	if conf.DevicesFile == "" {
+		return nil, errors.New("the server config has no devices file")
 	}
 
-	var id ulid.ULID
-	copy(id[:], body)
+	registry := newDeviceRegistry(conf.DevicesFile)
 
-	fmt.Printf("id: %s\n", id)
+	return registry, registry.Load()
+}
 
-	return nil
+// writeConfig atomically replaces the configuration file at path with conf.
+func writeConfig(path string, conf interface{}) error {
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(conf); err != nil {
+		return err
+	}
+
+	return writeFileAtomic(path, buf.Bytes(), 0600)
 }
 
-func doRunMoveOrPaste(args []string, action string) error {
-	var conf clientConfig
+func runKeysRotatePSK(args []string) error {
+	var conf serverConfig
 	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
 		return fmt.Errorf("invalid toml config. err=%v", err)
 	}
@@ -124,63 +997,50 @@ func doRunMoveOrPaste(args []string, action string) error {
 		return err
 	}
 
-	var id ulid.ULID
-	if len(args) > 0 {
-		var err error
-		id, err = ulid.Parse(args[0])
-		if err != nil {
-			return err
-		}
-	}
+	key := rotatePSKey(&conf, *keysRotatePSKGrace, time.Now())
 
-	//
+	if err := writeConfig(*globalConfig, conf); err != nil {
+		return fmt.Errorf("unable to write config. err: %v", err)
+	}
 
-	client := newClient(conf)
+	fmt.Printf("the new pre-shared key is %s\n\n", key)
+	fmt.Printf("Reload the server with SIGHUP to use it and put it in the PSKey of every client config.\n")
+	fmt.Printf("The previous key is accepted until %s.\n", conf.RetiredPSKeys[0].ExpiresAt.Local().Format("2006-01-02 15:04:05"))
 
-	var (
-		body []byte
-		err  error
-	)
+	return nil
+}
 
-	switch action {
-	case "/move":
-		body, err = client.doMove(moveRequest{
-			ID:        id,
-			Signature: sign(conf.SignPrivateKey, id[:]),
-		})
-	case "/paste":
-		body, err = client.doPaste(pasteRequest{
-			ID:        id,
-			Signature: sign(conf.SignPrivateKey, id[:]),
-		})
+func runKeysRotateEncrypt(args []string) error {
+	var conf clientConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		return fmt.Errorf("invalid toml config. err=%v", err)
 	}
-	if err != nil {
+	if err := conf.Validate(); err != nil {
 		return err
 	}
 
-	if len(body) == 0 {
-		return errors.New("nothing in the staging server")
-	}
+	// Save the new key before touching the entries so that it can't be lost
 
-	plaintext, ok := secretBoxOpen(body, conf.EncryptKey)
-	if !ok {
-		return fmt.Errorf("unable to decipher content")
+	key := rotateEncryptKey(&conf)
+
+	if err := writeConfig(*globalConfig, conf); err != nil {
+		return fmt.Errorf("unable to write config. err: %v", err)
 	}
 
-	os.Stdout.Write(plaintext)
+	fmt.Printf("the new encryption key is %s\n\n", key)
+	fmt.Printf("Put it in the EncryptKey of the other devices and add the previous one to their RetiredEncryptKeys.\n")
 
-	return nil
-}
+	if *keysRotateEncryptNoReencrypt {
+		return nil
+	}
 
-func runMove(args []string) error {
-	return doRunMoveOrPaste(args, "/move")
-}
+	reencrypted, skipped, err := reencryptEntries(newClient(conf), conf, time.Now())
+	fmt.Printf("%d entries encrypted again with the new key, %d skipped\n", reencrypted, skipped)
 
-func runPaste(args []string) error {
-	return doRunMoveOrPaste(args, "/paste")
+	return err
 }
 
-func runList(args []string) error {
+func runKeysRotateSign(args []string) error {
 	var conf clientConfig
 	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
 		return fmt.Errorf("invalid toml config. err=%v", err)
@@ -188,121 +1048,145 @@ func runList(args []string) error {
 	if err := conf.Validate(); err != nil {
 		return err
 	}
+	if conf.DeviceName == "" {
+		return errors.New("only a device in the server registry can rotate its key, the config has no DeviceName")
+	}
 
-	//
-
-	client := newClient(conf)
-
-	body, err := client.doList(listRequest{
-		Signature: sign(conf.SignPrivateKey, []byte("L")),
-	})
+	pub, priv, err := generateKeyPair()
 	if err != nil {
 		return err
 	}
-	if len(body) == 0 {
-		return errors.New("nothing in the staging server")
-	}
-
-	//
 
-	var resp listResponse
-	if err := json.Unmarshal(body, &resp); err != nil {
-		return fmt.Errorf("unable to unmarshal response")
+	resp, err := newClient(conf).doRotateKey(pub, priv)
+	if err != nil {
+		return err
 	}
 
-	if len(resp.Entries) == 0 {
-		fmt.Println("no entries")
-		return nil
-	}
+	conf.SignPublicKey = pub
+	conf.SignPrivateKey = priv
 
-	fmt.Printf("entries:\n")
-	for _, entry := range resp.Entries {
-		fmt.Printf("%s (time: %s)\n", entry, ulid.Time(entry.Time()).UTC().Format(time.RFC3339))
+	if err := writeConfig(*globalConfig, conf); err != nil {
+		// NOTE(vincent): the server already has the new key, don't lose it.
+		fmt.Printf("unable to write config, put these keys in it:\n\n")
+		toml.NewEncoder(os.Stdout).Encode(struct {
+			SignPublicKey  publicKey
+			SignPrivateKey privateKey
+		}{pub, priv})
+		return err
 	}
 
+	fmt.Printf("the signing key of device %q is rotated\n", conf.DeviceName)
+	fmt.Printf("The previous key is accepted until %s.\n", resp.PreviousKeyExpiresAt.Local().Format("2006-01-02 15:04:05"))
+
 	return nil
 }
 
-func serverHandler(api *apiHandler, ui *uiHandler) http.HandlerFunc {
-	return func(w http.ResponseWriter, req *http.Request) {
-		head, tail := hutil.ShiftPath(req.URL.Path)
-		switch head {
-		case "api":
-			api.handle(w, req, tail)
-		default:
-			ui.handle(w, req, req.URL.Path)
-		}
+func runDevicesAdd(args []string) error {
+	if len(args) < 1 || len(args) > 2 {
+		return errors.New("usage: apero devices add <name> [public key]")
 	}
-}
+	name := args[0]
 
-func runServe(args []string) error {
-	var conf serverConfig
-	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
-		fatalf("invalid toml config. err=%v", err)
-	}
-	if err := conf.Validate(); err != nil {
-		fatal(err)
+	registry, err := loadDeviceRegistry()
+	if err != nil {
+		return err
 	}
 
-	//
+	// Without a public key a new key pair is generated for the device
+	// and the private key is printed so it can be put in the client config.
 
-	// TODO(vincent): configure this based on conf
-	api := newAPIHandler(conf, newMemStore())
-	ui := newUIHandler(conf)
+	var (
+		pub  publicKey
+		priv privateKey
+	)
+	if len(args) == 2 {
+		if err := pub.UnmarshalText([]byte(args[1])); err != nil {
+			return fmt.Errorf("invalid public key. err: %v", err)
+		}
+	} else {
+		pub, priv, err = generateKeyPair()
+		if err != nil {
+			return err
+		}
+	}
 
-	var chain hutil.Chain
-	chain.Use(hutil.NewLoggingMiddleware(func(req *http.Request, statusCode int, responseSize int, elapsed time.Duration) {
-		log.Printf("[%3d] %s %d %s", statusCode, req.URL.Path, responseSize, elapsed)
-	}))
+	if err := registry.Add(name, pub, time.Now()); err != nil {
+		return fmt.Errorf("unable to add device %q. err: %v", name, err)
+	}
 
-	mux := http.NewServeMux()
-	mux.HandleFunc("/style.css", func(w http.ResponseWriter, req *http.Request) {
-		http.ServeFile(w, req, "./ui/style.css")
-	})
-	mux.HandleFunc("/", serverHandler(api, ui))
+	if priv == nil {
+		fmt.Printf("device %q added\n", name)
+		return nil
+	}
 
-	return http.ListenAndServe(conf.ListenAddr, chain.Handler(mux))
+	fmt.Printf("device %q added, put this in its client config:\n\n", name)
+
+	return toml.NewEncoder(os.Stdout).Encode(struct {
+		DeviceName     string
+		SignPublicKey  publicKey
+		SignPrivateKey privateKey
+	}{name, pub, priv})
 }
 
-func runGenconfig(args []string) error {
-	pub, priv, err := generateKeyPair()
+func runDevicesList(args []string) error {
+	registry, err := loadDeviceRegistry()
 	if err != nil {
-		fatal(err)
+		return err
 	}
 
-	//
-
-	clientConf := clientConfig{
-		Endpoint:       "http://localhost:7568",
-		PSKey:          newSecretBoxKey(),
-		EncryptKey:     newSecretBoxKey(),
-		SignPublicKey:  pub,
-		SignPrivateKey: priv,
-	}
-	f, err := os.OpenFile(*genconfigClientConfig, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+	devices, err := registry.Devices()
 	if err != nil {
 		return err
 	}
-	if err := toml.NewEncoder(f).Encode(clientConf); err != nil {
-		return err
+
+	if len(devices) == 0 {
+		fmt.Println("no devices")
+		return nil
 	}
-	f.Close()
 
-	//
+	printDevices(os.Stdout, devices)
 
-	serverConf := serverConfig{
-		ListenAddr:    "localhost:7568",
-		PSKey:         clientConf.PSKey,
-		SignPublicKey: clientConf.SignPublicKey,
+	return nil
+}
+
+// printDevices prints a table of the devices.
+func printDevices(w io.Writer, devices []device) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "NAME\tPUBLIC KEY\tADDED\tSTATUS")
+	for _, d := range devices {
+		status := "active"
+		if d.isRevoked() {
+			status = "revoked on " + d.RevokedAt.Local().Format("2006-01-02 15:04:05")
+		}
+
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			d.Name,
+			d.PublicKey,
+			d.AddedAt.Local().Format("2006-01-02 15:04:05"),
+			status,
+		)
 	}
-	f, err = os.OpenFile(*genconfigServerConfig, os.O_CREATE|os.O_TRUNC|os.O_RDWR, 0600)
+
+	tw.Flush()
+}
+
+func runDevicesRevoke(args []string) error {
+	if len(args) != 1 {
+		return errors.New("usage: apero devices revoke <name>")
+	}
+	name := args[0]
+
+	registry, err := loadDeviceRegistry()
 	if err != nil {
 		return err
 	}
-	if err := toml.NewEncoder(f).Encode(serverConf); err != nil {
-		return err
+
+	if err := registry.Revoke(name, time.Now()); err != nil {
+		return fmt.Errorf("unable to revoke device %q. err: %v", name, err)
 	}
-	f.Close()
+
+	fmt.Printf("device %q revoked\n", name)
 
 	return nil
 }
@@ -315,14 +1199,6 @@ func keyToMnemonic(key []byte) string {
 	return s
 }
 
-func randomString() string {
-	var data [4]byte
-	if _, err := crypto_rand.Read(data[:]); err != nil {
-		log.Fatal(err)
-	}
-	return hex.EncodeToString(data[:])
-}
-
 func lanIPAddress() (string, error) {
 	ifaces, err := net.Interfaces()
 	if err != nil {
@@ -363,6 +1239,10 @@ func lanIPAddress() (string, error) {
 }
 
 func runProvision(args []string) error {
+	if *provisionTTL <= 0 {
+		return fmt.Errorf("invalid ttl %s, it must be positive", *provisionTTL)
+	}
+
 	var conf clientConfig
 	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
 		return err
@@ -372,10 +1252,15 @@ func runProvision(args []string) error {
 	}
 
 	// Convert the client config into the provisioning data
+	// NOTE(vincent): the clipboard configuration is specific to this device, it's not shared.
+	conf.Clipboard = nil
 
 	var data struct {
 		ProvisioningURL string
+		Code            string
+		ExpiresAt       string
 		Endpoint        string
+		CertFingerprint string
 
 		Hex struct {
 			PSKey          string
@@ -389,20 +1274,39 @@ func runProvision(args []string) error {
 			SignPublicKey  string
 			SignPrivateKey string
 		}
+		QRCode struct {
+			ProvisioningURL template.HTML
+			Config          template.HTML
+		}
 	}
 
-	// Create a unique URL for LAN provisioning
-	localProvisioningPath := randomString()
+	// NOTE(vincent): only the path of the client certificate is in the config, not the certificate.
+	if conf.ClientCertFile != "" {
+		fmt.Printf("the client certificate %s isn't provisioned, copy it to the new device\n", conf.ClientCertFile)
+	}
+
+	// Create a unique URL for LAN provisioning and the code encrypting the config served there
+	localProvisioningPath := "/" + newProvisioningToken()
 	ip, err := lanIPAddress()
 	if err != nil {
 		return fmt.Errorf("unable to get LAN IP address. err: %v", err)
 	}
 
-	data.ProvisioningURL = "http://" + ip + ":5000/" + localProvisioningPath
+	code := newProvisioningCode()
+	payload, err := sealProvisioningConfig(conf, code)
+	if err != nil {
+		return err
+	}
+	expiresAt := time.Now().Add(*provisionTTL)
+
+	data.ProvisioningURL = "http://" + ip + ":5000" + localProvisioningPath
+	data.Code = code
+	data.ExpiresAt = expiresAt.Format("15:04:05")
 
 	//
 
 	data.Endpoint = conf.Endpoint
+	data.CertFingerprint = conf.CertFingerprint
 
 	data.Hex.PSKey = hex.EncodeToString(conf.PSKey[:])
 	data.Hex.EncryptKey = hex.EncodeToString(conf.EncryptKey[:])
@@ -413,8 +1317,30 @@ func runProvision(args []string) error {
 	data.Mnemonic.SignPublicKey = keyToMnemonic(conf.SignPublicKey[:])
 	data.Mnemonic.SignPrivateKey = keyToMnemonic(conf.SignPrivateKey[:32])
 
-	// Prepare the HTTP server
+	// The config QR code can be scanned by a phone, it's the same as the keys shown in clear.
+
+	urlQRCode, err := encodeQRCode([]byte(data.ProvisioningURL), qrLevelM)
+	if err != nil {
+		return fmt.Errorf("unable to encode provisioning URL QR code. err: %v", err)
+	}
+	configQRCode, err := encodeQRCode([]byte(encodeCompactConfig(conf)), qrLevelM)
+	if err != nil {
+		return fmt.Errorf("unable to encode config QR code. err: %v", err)
+	}
+
+	data.QRCode.ProvisioningURL = template.HTML(urlQRCode.SVG())
+	data.QRCode.Config = template.HTML(configQRCode.SVG())
+
+	// Prepare the HTTP servers
 	//
+	// The LAN server only serves the encrypted config, the UI showing the keys and the code
+	// is only reachable from this machine.
+
+	provisioning := newProvisioningHandler(localProvisioningPath, payload, expiresAt)
+	lanServer := &http.Server{
+		Addr:    ":5000",
+		Handler: provisioning,
+	}
 
 	fm := template.FuncMap{
 		"isalpha": func(r rune) bool { return r == ' ' || (r >= 'a' && r <= 'z') },
@@ -422,74 +1348,149 @@ func runProvision(args []string) error {
 		"addone":  func(i int) int { return i + 1 },
 	}
 
-	http.HandleFunc("/"+localProvisioningPath, func(w http.ResponseWriter, req *http.Request) {
-		data, err := json.Marshal(conf)
-		if err != nil {
-			log.Printf("unable to marshal provisioning config. err: %v", err)
-			http.Error(w, "unable to marhsal provisioning config", http.StatusInternalServerError)
-		}
-
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusOK)
-		w.Write(data)
-	})
-
-	// setup the ui
-
-	http.HandleFunc("/provisioning.css", ui.ServeFile("/provisioning.css"))
-	http.HandleFunc("/provisioning.js", ui.ServeFile("/provisioning.js"))
-	http.HandleFunc("/", func(w http.ResponseWriter, req *http.Request) {
+	// NOTE(vincent): like the provisioning URL the UI is under a random path, any local user
+	// or web page open in a browser of this machine can reach it.
+	uiPath := "/" + newProvisioningToken() + "/"
+	uiHandler := newProvisioningUIHandler(uiPath, func(w http.ResponseWriter, req *http.Request) {
 		tmpl := ui.ParseTemplate(fm, "/partials/provisioning-key.html", "/provisioning.html")
 		if err := tmpl.Execute(w, data); err != nil {
-			log.Fatal(err)
+			log.Printf("unable to execute template. err: %v", err)
 		}
 	})
+	uiServer := &http.Server{Handler: uiHandler}
+
+	// Run until the config is fetched or the URL expires
 
+	errCh := make(chan error, 2)
 	go func() {
-		if err := browser.OpenURL("http://localhost:5000"); err != nil {
-			log.Fatal(err)
-		}
+		errCh <- lanServer.ListenAndServe()
 	}()
 
-	return http.ListenAndServe(":5000", nil)
+	if *provisionTerminal {
+		// NOTE(vincent): on a headless server the QR codes are shown in the terminal instead of the UI.
+		// The config isn't encrypted, unlike what's served at the provisioning URL, so it's only
+		// shown when asked for: the output of a terminal can end up in a log or a scrollback buffer.
+		if *provisionShowConf {
+			fmt.Println("Config, scan it with a phone or give it to apero enroll:")
+			if err := configQRCode.WriteTerminal(os.Stdout); err != nil {
+				lanServer.Close()
+				return err
+			}
+		}
+		fmt.Println("Provisioning URL:")
+		if err := urlQRCode.WriteTerminal(os.Stdout); err != nil {
+			lanServer.Close()
+			return err
+		}
+
+		fmt.Printf("provisioning URL: %s\n", data.ProvisioningURL)
+		fmt.Printf("provisioning code: %s\n", data.Code)
+		fmt.Printf("expires at %s\n", data.ExpiresAt)
+	} else {
+		uiListener, err := net.Listen("tcp", "127.0.0.1:0")
+		if err != nil {
+			lanServer.Close()
+			return fmt.Errorf("unable to listen for the provisioning UI. err: %v", err)
+		}
+		go func() {
+			errCh <- uiServer.Serve(uiListener)
+		}()
+
+		uiURL := "http://" + uiListener.Addr().String() + uiPath
+		go func() {
+			if err := browser.OpenURL(uiURL); err != nil {
+				log.Printf("unable to open the browser, open %s. err: %v", uiURL, err)
+			}
+		}()
+
+		fmt.Printf("provisioning URL: %s\n", data.ProvisioningURL)
+		fmt.Printf("provisioning code: %s\n", data.Code)
+		fmt.Printf("expires at %s, keys shown at %s\n", data.ExpiresAt, uiURL)
+	}
+
+	timer := time.NewTimer(time.Until(expiresAt))
+	defer timer.Stop()
+
+	select {
+	case <-provisioning.Done():
+		fmt.Println("provisioning config fetched, stopping")
+	case <-timer.C:
+		err = errors.New("provisioning URL expired before the config was fetched")
+	case err = <-errCh:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	lanServer.Shutdown(ctx)
+	uiServer.Shutdown(ctx)
+
+	if err == http.ErrServerClosed {
+		err = nil
+	}
+
+	return err
 }
 
 func main() {
 	copyCommand := &ffcli.Command{
 		Name:      "copy",
-		Usage:     "apero copy <file path>",
+		Usage:     "apero copy [-ttl duration] [-clipboard] [-compress] <path>...",
 		FlagSet:   copyFlags,
 		ShortHelp: "copy a file to the staging server",
 		LongHelp: `Copy a file to the staging server.
 
 If the path given is - it will read from stdin.
+A directory or several paths are copied as a single tar archive, compressed with gzip with -compress;
+paste and move extract it with -extract.
+With -clipboard it copies the content of the system clipboard instead, keeping its type (text or image).
 
 This command will print an ID which can be further used with move and paste.
+
+The entry expires after the duration given with -ttl, or the server default.
 `,
 		Exec: runCopy,
 	}
 
 	moveCommand := &ffcli.Command{
 		Name:      "move",
-		Usage:     "apero move [entry id]",
+		Usage:     "apero move [-o path | -O | -clipboard | -extract dir] [-force] [entry id]",
 		FlagSet:   moveFlags,
 		ShortHelp: "move an entry from the staging server to here",
 		LongHelp: `Move an entry from the staging server to here.
 
 Without an argument it moves the oldest entry.
-With an argument it moves the specific entry if it exists.`,
+With an argument it moves the specific entry if it exists.
+
+With -o the entry is written to the file given, or in the directory given with its original file name.
+With -O it's written in the current directory with its original file name.
+Existing files are only overwritten with -force.
+
+With -clipboard the entry is written to the system clipboard instead of stdout.
+With -extract an archive entry is extracted in the directory given.
+Only one of -o, -O, -clipboard and -extract can be used.
+
+Except when writing to stdout the entry is only removed once it's written.`,
 		Exec: runMove,
 	}
 
 	pasteCommand := &ffcli.Command{
 		Name:      "paste",
-		Usage:     "apero paste [entry id]",
+		Usage:     "apero paste [-o path | -O | -clipboard | -extract dir] [-force] [entry id]",
 		FlagSet:   pasteFlags,
 		ShortHelp: "paste an entry from the staging server to here",
 		LongHelp: `Paste an entry from the staging server to here.
 
 Without an argument it pastes the oldest entry.
-With an argument it pastes the specific entry if it exists.`,
+With an argument it pastes the specific entry if it exists.
+
+With -o the entry is written to the file given, or in the directory given with its original file name.
+With -O it's written in the current directory with its original file name.
+Existing files are only overwritten with -force.
+
+With -clipboard the entry is written to the system clipboard instead of stdout.
+With -extract an archive entry is extracted in the directory given.
+Only one of -o, -O, -clipboard and -extract can be used.`,
 		Exec: runPaste,
 	}
 
@@ -501,12 +1502,48 @@ With an argument it pastes the specific entry if it exists.`,
 		Exec:      runList,
 	}
 
+	watchCommand := &ffcli.Command{
+		Name:      "watch",
+		Usage:     "apero watch [-paste] [-dir path] [-poll]",
+		FlagSet:   watchFlags,
+		ShortHelp: "print the new entries as they're copied to the staging server",
+		LongHelp: `Print the new entries as they're copied to the staging server.
+
+With -paste the entries copied from other devices are also pasted
+in the directory given with -dir, named after their original file name.`,
+		Exec: runWatch,
+	}
+
+	clipboardSyncCommand := &ffcli.Command{
+		Name:      "clipboard-sync",
+		Usage:     "apero clipboard-sync [-interval duration] [-ttl duration] [-poll]",
+		FlagSet:   clipboardSyncFlags,
+		ShortHelp: "keep the system clipboard in sync with the other devices",
+		LongHelp: `Keep the system clipboard in sync with the other devices.
+
+The clipboard is checked every -interval and its content is copied to the staging server when it changes.
+The entries copied from other devices are written to the clipboard.
+
+Only the content matching SyncContentTypes and SyncMaxSize of the Clipboard configuration is synchronized.`,
+		Exec: runClipboardSync,
+	}
+
 	serveCommand := &ffcli.Command{
 		Name:      "serve",
 		Usage:     "apero serve [flags]",
 		FlagSet:   serveFlags,
 		ShortHelp: "serve requests to clients",
-		Exec:      runServe,
+		LongHelp: `Serve requests to clients.
+
+On SIGINT or SIGTERM the server stops accepting connections and waits up to -shutdown-timeout
+for the requests in progress before stopping.
+
+On SIGHUP the configuration is reloaded: the keys, the TTLs, the limits and the devices file
+are used right away by the new requests. The listen addresses, the storage and the TLS settings
+can't be reloaded, changing them requires a restart.
+
+The metrics are served on /metrics only if MetricsListenAddr is set, never on ListenAddr.`,
+		Exec: runServe,
 	}
 
 	genconfigCommand := &ffcli.Command{
@@ -517,16 +1554,173 @@ With an argument it pastes the specific entry if it exists.`,
 		LongHelp: `generate configuration files for client and server.
 The path can be changed with a flag:
 
-    apero genconfig -client-config=/tmp/client.toml -server-config=/tmp/server.toml`,
+    apero genconfig -client-config=/tmp/client.toml -server-config=/tmp/server.toml
+
+A self-signed TLS certificate is generated for the server, unless -no-tls is given,
+and its fingerprint is pinned in the client config.`,
 		Exec: runGenconfig,
 	}
 
+	devicesCommand := &ffcli.Command{
+		Name:      "devices",
+		Usage:     "apero devices <add|list|revoke> [args...]",
+		FlagSet:   devicesFlags,
+		ShortHelp: "manage the devices allowed to use the server",
+		LongHelp: `Manage the devices allowed to use the server.
+
+The global -config flag must point to the server config, the devices are
+stored in its devices file. A running server picks up the changes right away.`,
+		Subcommands: []*ffcli.Command{
+			{
+				Name:      "add",
+				Usage:     "apero devices add <name> [public key]",
+				FlagSet:   devicesAddFlags,
+				ShortHelp: "add a device",
+				LongHelp: `Add a device.
+
+Without a public key a new key pair is generated and printed.`,
+				Exec: runDevicesAdd,
+			},
+			{
+				Name:      "list",
+				Usage:     "apero devices list",
+				FlagSet:   devicesListFlags,
+				ShortHelp: "list the devices",
+				Exec:      runDevicesList,
+			},
+			{
+				Name:      "revoke",
+				Usage:     "apero devices revoke <name>",
+				FlagSet:   devicesRevokeFlags,
+				ShortHelp: "revoke a device",
+				Exec:      runDevicesRevoke,
+			},
+		},
+		Exec: func(args []string) error {
+			return errors.New("specify a subcommand")
+		},
+	}
+
+	keysCommand := &ffcli.Command{
+		Name:      "keys",
+		Usage:     "apero keys rotate <psk|encrypt|sign>",
+		FlagSet:   keysFlags,
+		ShortHelp: "manage the keys",
+		Subcommands: []*ffcli.Command{
+			{
+				Name:      "rotate",
+				Usage:     "apero keys rotate <psk|encrypt|sign>",
+				FlagSet:   keysRotateFlags,
+				ShortHelp: "replace a key with a new one",
+				Subcommands: []*ffcli.Command{
+					{
+						Name:      "psk",
+						Usage:     "apero keys rotate psk [-grace duration]",
+						FlagSet:   keysRotatePSKFlags,
+						ShortHelp: "replace the pre-shared key of the server",
+						LongHelp: `Replace the pre-shared key of the server.
+
+The global -config flag must point to the server config.
+The previous key is still accepted for the duration given with -grace.`,
+						Exec: runKeysRotatePSK,
+					},
+					{
+						Name:      "encrypt",
+						Usage:     "apero keys rotate encrypt [-no-reencrypt]",
+						FlagSet:   keysRotateEncryptFlags,
+						ShortHelp: "replace the encryption key of the client",
+						LongHelp: `Replace the encryption key of the client.
+
+The previous key is kept to decrypt the existing entries, unless -no-reencrypt
+is given the entries are encrypted again with the new key.`,
+						Exec: runKeysRotateEncrypt,
+					},
+					{
+						Name:      "sign",
+						Usage:     "apero keys rotate sign",
+						FlagSet:   keysRotateSignFlags,
+						ShortHelp: "replace the signing key of the device",
+						LongHelp: `Replace the signing key of the device.
+
+The new public key is sent to the server which accepts the previous key for a day.`,
+						Exec: runKeysRotateSign,
+					},
+				},
+				Exec: func(args []string) error {
+					return errors.New("specify a key to rotate")
+				},
+			},
+		},
+		Exec: func(args []string) error {
+			return errors.New("specify a subcommand")
+		},
+	}
+
 	provisionCommand := &ffcli.Command{
 		Name:      "provision",
-		Usage:     "apero provision",
+		Usage:     "apero provision [-ttl duration] [-terminal [-show-config]]",
 		FlagSet:   provisionFlags,
 		ShortHelp: "launch the provisioning UI",
-		Exec:      runProvision,
+		LongHelp: `Launch the provisioning UI.
+
+The UI shows the keys of the client config and is only reachable from this machine.
+The config is also served on the LAN at a random provisioning URL, encrypted with the provisioning code
+shown in the UI: the URL can be fetched only once, before it expires after the duration given with -ttl.
+The command stops once the config has been fetched.
+
+The UI also shows QR codes of the provisioning URL and of the config itself, the latter can be given
+to apero enroll. With -terminal the QR code of the provisioning URL is shown in the terminal and the UI
+isn't started, for headless machines; the QR code of the config, which contains the keys in clear,
+is only shown with -show-config.`,
+		Exec: runProvision,
+	}
+
+	enrollCommand := &ffcli.Command{
+		Name:      "enroll",
+		Usage:     "apero enroll [-device-name name] [-force] [-code code] <provisioning url | config> | -mnemonic",
+		FlagSet:   enrollFlags,
+		ShortHelp: "write the client config of a device provisioned with apero provision",
+		LongHelp: `Write the client config of a device provisioned with apero provision.
+
+With a provisioning URL the config is fetched from the provisioning server and decrypted with the
+provisioning code, given with -code or asked for.
+A config starting with apero://config is the one shown in a QR code by apero provision.
+With -mnemonic the endpoint and the keys shown on the provisioning page are typed instead,
+either as the mnemonic sentences or as the hex strings.
+
+The config is written to the path given with -config, an existing file is only overwritten with -force.`,
+		Exec: runEnroll,
+	}
+
+	pairCommand := &ffcli.Command{
+		Name:      "pair",
+		Usage:     "apero pair",
+		FlagSet:   pairFlags,
+		ShortHelp: "send the client config to a new device with a pairing code",
+		LongHelp: `Send the client config to a new device with a pairing code.
+
+This opens a pairing on the staging server and prints a short code like 42-word-word,
+the new device joins it with apero join. The devices check they have the same code
+with a password-authenticated key exchange through the server, then the config
+is sent encrypted with the key derived from it.
+
+The code can only be used once and expires after 10 minutes.`,
+		Exec: runPair,
+	}
+
+	joinCommand := &ffcli.Command{
+		Name:      "join",
+		Usage:     "apero join -endpoint <url> -device-name <name> [-fingerprint fingerprint] [-force] <code>",
+		FlagSet:   joinFlags,
+		ShortHelp: "write the client config sent by apero pair",
+		LongHelp: `Write the client config sent by apero pair.
+
+The code is the one printed by apero pair on the other device.
+The config is written to the path given with -config, an existing file is only overwritten with -force.
+
+Only the keys shared by all the devices are sent, this device gets a new sign key:
+it must be added to the server registry with the apero devices add command printed.`,
+		Exec: runJoin,
 	}
 
 	root := &ffcli.Command{
@@ -534,7 +1728,7 @@ The path can be changed with a flag:
 		FlagSet:     globalFlags,
 		Options:     []ff.Option{ff.WithEnvVarPrefix("APERO")},
 		LongHelp:    `Run a staging server or communicate with one`,
-		Subcommands: []*ffcli.Command{copyCommand, moveCommand, pasteCommand, listCommand, serveCommand, genconfigCommand, provisionCommand},
+		Subcommands: []*ffcli.Command{copyCommand, moveCommand, pasteCommand, listCommand, watchCommand, clipboardSyncCommand, serveCommand, genconfigCommand, devicesCommand, keysCommand, provisionCommand, enrollCommand, pairCommand, joinCommand},
 		Exec: func(args []string) error {
 			return errors.New("specify a subcommand")
 		},
@@ -0,0 +1,82 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPasteToFile(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "run.sh")
+	md := entryMetadata{Filename: "run.sh", Mode: 0750}
+
+	require.NoError(t, pasteToFile(strings.NewReader("#!/bin/sh"), path, md, false))
+
+	data, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh", string(data))
+
+	fi, err := os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0750), fi.Mode().Perm())
+
+	// The file isn't overwritten without force
+
+	err = pasteToFile(strings.NewReader("foobar"), path, md, false)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "-force")
+
+	data, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "#!/bin/sh", string(data))
+
+	require.NoError(t, pasteToFile(strings.NewReader("foobar"), path, entryMetadata{}, true))
+
+	data, err = ioutil.ReadFile(path)
+	require.NoError(t, err)
+	require.Equal(t, "foobar", string(data))
+
+	fi, err = os.Stat(path)
+	require.NoError(t, err)
+	require.Equal(t, os.FileMode(0644), fi.Mode().Perm())
+
+	// No temporary file is left behind
+
+	files, err := ioutil.ReadDir(dir)
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+}
+
+func TestEntryFileName(t *testing.T) {
+	entry := entryInfo{ID: newULID()}
+
+	require.Equal(t, "report.pdf", entryFileName(entry, entryMetadata{Filename: "report.pdf"}))
+	require.Equal(t, "passwd", entryFileName(entry, entryMetadata{Filename: "../../etc/passwd"}))
+	require.Equal(t, entry.ID.String(), entryFileName(entry, entryMetadata{}))
+	require.Equal(t, entry.ID.String(), entryFileName(entry, entryMetadata{Filename: ".."}))
+}
+
+func TestPasteOptionsValidate(t *testing.T) {
+	require.NoError(t, pasteOptions{}.Validate("/paste"))
+	require.NoError(t, pasteOptions{output: "foo", force: true}.Validate("/paste"))
+	require.NoError(t, pasteOptions{clipboard: true}.Validate("/move"))
+
+	testCases := []pasteOptions{
+		{output: "foo", originalName: true},
+		{output: "foo", clipboard: true},
+		{originalName: true, extract: "dir"},
+		{clipboard: true, extract: "dir"},
+	}
+	for _, opts := range testCases {
+		err := opts.Validate("/move")
+		require.Error(t, err)
+		require.True(t, strings.HasPrefix(err.Error(), "usage: apero move "))
+	}
+}
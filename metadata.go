@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// maxEntryMetadataSize is the maximum size of the sealed metadata of an entry.
+const maxEntryMetadataSize = 4 << 10
+
+var errInvalidMetadata = errors.New("invalid metadata")
+
+// entryMetadata describes the content of an entry.
+//
+// Clients seal it with the encryption key before sending it with the content,
+// this way the server can return it in a list but never sees it in clear.
+type entryMetadata struct {
+	// Filename is the base name of the file copied, empty when copying from stdin.
+	Filename string `json:"filename,omitempty"`
+	// ContentType is the MIME type of the content.
+	ContentType string `json:"content_type,omitempty"`
+	// Size is the size of the plaintext content, or -1 if it wasn't known when copying.
+	Size int64 `json:"size"`
+	// Host is the hostname of the device which copied the entry.
+	Host string `json:"host,omitempty"`
+	// Note is an optional free text note.
+	Note string `json:"note,omitempty"`
+	// Archive is the format of the content when it's an archive of several files, tar or tar+gzip.
+	Archive string `json:"archive,omitempty"`
+	// Files is the number of files in the archive.
+	Files int `json:"files,omitempty"`
+	// Mode is the permission bits of the file copied, zero if unknown.
+	Mode uint32 `json:"mode,omitempty"`
+}
+
+// fileMode returns the permission bits of the file to create for the entry.
+func (md entryMetadata) fileMode() os.FileMode {
+	if md.Mode == 0 {
+		return 0644
+	}
+	return os.FileMode(md.Mode).Perm()
+}
+
+// sealMetadata seals the metadata with the encryption key.
+func sealMetadata(md entryMetadata, key secretBoxKey) ([]byte, error) {
+	data, err := json.Marshal(md)
+	if err != nil {
+		return nil, err
+	}
+
+	box := secretBoxSeal(data, key)
+	if len(box) > maxEntryMetadataSize {
+		return nil, errors.New("metadata is too large")
+	}
+
+	return box, nil
+}
+
+// openMetadata opens metadata sealed with sealMetadata.
+// Entries created without metadata have an empty one, in this case the size is unknown.
+func openMetadata(box []byte, key secretBoxKey) (entryMetadata, error) {
+	if len(box) == 0 {
+		return entryMetadata{Size: -1}, nil
+	}
+
+	data, ok := secretBoxOpen(box, key)
+	if !ok {
+		return entryMetadata{}, errInvalidMetadata
+	}
+
+	var md entryMetadata
+	if err := json.Unmarshal(data, &md); err != nil {
+		return entryMetadata{}, errInvalidMetadata
+	}
+
+	return md, nil
+}
+
+// openMetadataWithKeys opens metadata with the first of the keys which works and returns it.
+// Entries created without metadata can't tell which key was used, the first key is returned.
+func openMetadataWithKeys(box []byte, keys []secretBoxKey) (entryMetadata, secretBoxKey, error) {
+	if len(box) == 0 {
+		return entryMetadata{Size: -1}, keys[0], nil
+	}
+
+	for _, key := range keys {
+		md, err := openMetadata(box, key)
+		if err == nil {
+			return md, key, nil
+		}
+	}
+
+	return entryMetadata{}, secretBoxKey{}, errInvalidMetadata
+}
+
+// detectContentType guesses the MIME type of a content from its file name
+// or from its first bytes if the name doesn't help.
+func detectContentType(filename string, head []byte) string {
+	if ext := filepath.Ext(filename); ext != "" {
+		if typ := mime.TypeByExtension(ext); typ != "" {
+			return typ
+		}
+	}
+	return http.DetectContentType(head)
+}
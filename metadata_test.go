@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetadata(t *testing.T) {
+	key := newSecretBoxKey()
+
+	md := entryMetadata{
+		Filename:    "screenshot.png",
+		ContentType: "image/png",
+		Size:        2048,
+		Host:        "laptop",
+		Note:        "for the bug report",
+	}
+
+	box, err := sealMetadata(md, key)
+	require.NoError(t, err)
+	require.NotContains(t, string(box), "screenshot")
+
+	md2, err := openMetadata(box, key)
+	require.NoError(t, err)
+	require.Equal(t, md, md2)
+
+	// Wrong key
+
+	_, err = openMetadata(box, newSecretBoxKey())
+	require.Equal(t, errInvalidMetadata, err)
+
+	// No metadata
+
+	md2, err = openMetadata(nil, key)
+	require.NoError(t, err)
+	require.Equal(t, int64(-1), md2.Size)
+
+	// Too large
+
+	_, err = sealMetadata(entryMetadata{Note: strings.Repeat("a", maxEntryMetadataSize)}, key)
+	require.Error(t, err)
+}
+
+func TestDetectContentType(t *testing.T) {
+	require.Equal(t, "application/pdf", detectContentType("report.pdf", nil))
+	require.Equal(t, "image/png", detectContentType("", []byte("\x89PNG\x0D\x0A\x1A\x0A")))
+	require.Equal(t, "text/plain; charset=utf-8", detectContentType("", []byte("hello")))
+}
+
+func TestPrintEntries(t *testing.T) {
+	key := newSecretBoxKey()
+	now := time.Now()
+
+	box, err := sealMetadata(entryMetadata{Filename: "report.pdf", ContentType: "application/pdf", Size: 1536, Host: "laptop"}, key)
+	require.NoError(t, err)
+
+	archive, err := sealMetadata(entryMetadata{Filename: "photos.tar", ContentType: "application/x-tar", Size: -1, Archive: archiveTar, Files: 12}, key)
+	require.NoError(t, err)
+
+	entries := []entryInfo{
+		{ID: newULID(), ExpiresAt: now.Add(time.Hour), Metadata: box, Device: "laptop-device"},
+		{ID: newULID()},
+		{ID: newULID(), Metadata: []byte("garbage")},
+		{ID: newULID(), Metadata: archive},
+	}
+
+	var buf bytes.Buffer
+	printEntries(&buf, entries, []secretBoxKey{newSecretBoxKey(), key}, now)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 5)
+
+	require.Contains(t, lines[0], "NAME")
+	require.Contains(t, lines[1], "report.pdf")
+	require.Contains(t, lines[1], "application/pdf")
+	require.Contains(t, lines[1], "1.5KiB")
+	require.Contains(t, lines[1], "laptop")
+	require.Contains(t, lines[1], "laptop-device")
+	require.Contains(t, lines[1], "1h0m0s")
+	require.Contains(t, lines[2], "never")
+	require.Contains(t, lines[3], "<unreadable>")
+	require.Contains(t, lines[4], "photos.tar")
+	require.Contains(t, lines[4], " 12 ")
+}
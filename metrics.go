@@ -0,0 +1,249 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/vrischmann/hutil/v2"
+)
+
+// requestDurationBuckets are the upper bounds in seconds of the buckets of the request durations histogram.
+var requestDurationBuckets = []float64{.005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10}
+
+// metrics are the counters of the server exposed on /metrics in the Prometheus text format.
+//
+// The API handler reports the requests and the authentication failures, the store reports what it holds.
+// The methods are safe to call on a nil *metrics so that the tests don't have to create one.
+type metrics struct {
+	mu sync.Mutex
+
+	requests          map[requestLabels]*histogram
+	signatureFailures map[string]uint64
+	boxOpenFailures   uint64
+
+	storeEntries      int64
+	storeBytes        int64
+	storeEvictions    uint64
+	storeEvictedBytes uint64
+}
+
+// requestLabels identifies a series of the requests metrics.
+type requestLabels struct {
+	action string
+	code   int
+}
+
+// histogram counts observations in cumulative buckets, like a Prometheus histogram.
+type histogram struct {
+	buckets []uint64 // one per requestDurationBuckets
+	count   uint64
+	sum     float64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		requests:          make(map[requestLabels]*histogram),
+		signatureFailures: make(map[string]uint64),
+	}
+}
+
+// observeRequest records an API request for the action which ended with the status code.
+func (m *metrics) observeRequest(action string, code int, elapsed time.Duration) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	labels := requestLabels{action, code}
+
+	h, ok := m.requests[labels]
+	if !ok {
+		h = &histogram{buckets: make([]uint64, len(requestDurationBuckets))}
+		m.requests[labels] = h
+	}
+
+	seconds := elapsed.Seconds()
+	for i, bound := range requestDurationBuckets {
+		if seconds <= bound {
+			h.buckets[i]++
+		}
+	}
+	h.count++
+	h.sum += seconds
+}
+
+// signatureFailed records a request for the action whose signature or envelope was refused.
+func (m *metrics) signatureFailed(action string) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.signatureFailures[action]++
+	m.mu.Unlock()
+}
+
+// boxOpenFailed records a payload which couldn't be opened with any pre-shared key.
+func (m *metrics) boxOpenFailed() {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.boxOpenFailures++
+	m.mu.Unlock()
+}
+
+// entriesAdded records entries added to the store, or removed from it if n is negative.
+func (m *metrics) entriesAdded(n int, size int64) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.storeEntries += int64(n)
+	m.storeBytes += size
+	m.mu.Unlock()
+}
+
+// entriesEvicted records expired entries removed from the store.
+func (m *metrics) entriesEvicted(n int, size int64) {
+	if m == nil {
+		return
+	}
+
+	m.mu.Lock()
+	m.storeEntries -= int64(n)
+	m.storeBytes -= size
+	m.storeEvictions += uint64(n)
+	m.storeEvictedBytes += uint64(size)
+	m.mu.Unlock()
+}
+
+// write writes the metrics in the Prometheus text exposition format.
+func (m *metrics) write(w io.Writer) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	// NOTE(vincent): a bufio.Writer keeps the first error, it's returned by Flush.
+	cw := bufio.NewWriter(w)
+
+	// Requests
+
+	labels := make([]requestLabels, 0, len(m.requests))
+	for l := range m.requests {
+		labels = append(labels, l)
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].action != labels[j].action {
+			return labels[i].action < labels[j].action
+		}
+		return labels[i].code < labels[j].code
+	})
+
+	writeMetricHeader(cw, "apero_http_requests_total", "counter", "Number of API requests by action and status code.")
+	for _, l := range labels {
+		fmt.Fprintf(cw, "apero_http_requests_total{action=%q,code=\"%d\"} %d\n", l.action, l.code, m.requests[l].count)
+	}
+
+	writeMetricHeader(cw, "apero_http_request_duration_seconds", "histogram", "Duration of the API requests by action and status code.")
+	for _, l := range labels {
+		h := m.requests[l]
+		for i, bound := range requestDurationBuckets {
+			fmt.Fprintf(cw, "apero_http_request_duration_seconds_bucket{action=%q,code=\"%d\",le=%q} %d\n", l.action, l.code, formatFloat(bound), h.buckets[i])
+		}
+		fmt.Fprintf(cw, "apero_http_request_duration_seconds_bucket{action=%q,code=\"%d\",le=\"+Inf\"} %d\n", l.action, l.code, h.count)
+		fmt.Fprintf(cw, "apero_http_request_duration_seconds_sum{action=%q,code=\"%d\"} %s\n", l.action, l.code, formatFloat(h.sum))
+		fmt.Fprintf(cw, "apero_http_request_duration_seconds_count{action=%q,code=\"%d\"} %d\n", l.action, l.code, h.count)
+	}
+
+	// Authentication
+
+	actions := make([]string, 0, len(m.signatureFailures))
+	for action := range m.signatureFailures {
+		actions = append(actions, action)
+	}
+	sort.Strings(actions)
+
+	writeMetricHeader(cw, "apero_signature_failures_total", "counter", "Number of requests refused because of their signature or envelope, by action.")
+	for _, action := range actions {
+		fmt.Fprintf(cw, "apero_signature_failures_total{action=%q} %d\n", action, m.signatureFailures[action])
+	}
+
+	writeMetricHeader(cw, "apero_box_open_failures_total", "counter", "Number of payloads which couldn't be opened with the pre-shared keys.")
+	fmt.Fprintf(cw, "apero_box_open_failures_total %d\n", m.boxOpenFailures)
+
+	// Store
+
+	writeMetricHeader(cw, "apero_store_entries", "gauge", "Number of entries in the store, including the expired entries not yet removed.")
+	fmt.Fprintf(cw, "apero_store_entries %d\n", m.storeEntries)
+
+	writeMetricHeader(cw, "apero_store_bytes", "gauge", "Size in bytes of the entries in the store, including the expired entries not yet removed.")
+	fmt.Fprintf(cw, "apero_store_bytes %d\n", m.storeBytes)
+
+	writeMetricHeader(cw, "apero_store_evictions_total", "counter", "Number of expired entries removed from the store.")
+	fmt.Fprintf(cw, "apero_store_evictions_total %d\n", m.storeEvictions)
+
+	writeMetricHeader(cw, "apero_store_evicted_bytes_total", "counter", "Size in bytes of the expired entries removed from the store.")
+	fmt.Fprintf(cw, "apero_store_evicted_bytes_total %d\n", m.storeEvictedBytes)
+
+	return cw.Flush()
+}
+
+func writeMetricHeader(w io.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// handler returns the handler of /metrics.
+func (m *metrics) handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet && req.Method != http.MethodHead {
+			responseStatusCode(w, http.StatusMethodNotAllowed)
+			return
+		}
+
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := m.write(w); err != nil {
+			log.Printf("unable to write metrics. err: %v", err)
+		}
+	})
+}
+
+// requestAction returns the action of an API request for the metrics,
+// path is the path of the request after /api.
+//
+// The action comes from the URL so that unauthenticated requests are counted too;
+// unknown paths are grouped so that clients can't create as many series as they want.
+func requestAction(path string) string {
+	version, tail := hutil.ShiftPath(path)
+	if version != apiVersion1 && version != apiVersion2 {
+		return "unknown"
+	}
+
+	head, tail := hutil.ShiftPath(tail)
+	switch head {
+	case actionCopy, actionMove, actionPaste, actionList, actionRotateKey, actionSubscribe, actionPair:
+		return head
+	case "upload":
+		sub, _ := hutil.ShiftPath(tail)
+		switch sub {
+		case "start", "chunk", "finish":
+			return "upload-" + sub
+		}
+	}
+
+	return "unknown"
+}
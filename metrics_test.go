@@ -0,0 +1,194 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	client.retries = 0
+
+	id, err := client.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+	require.NoError(t, err)
+
+	_, err = client.doList(listRequest{})
+	require.NoError(t, err)
+
+	// A payload not sealed with the pre-shared key
+
+	resp, err := http.Post(httpServer.URL+"/api/v2/list", "application/octet-stream", strings.NewReader("foobar"))
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+	// A request signed by an unknown key
+
+	conf := client.conf
+	conf.SignPublicKey, conf.SignPrivateKey = mustKeyPair(t)
+	unknown := newClient(conf)
+	unknown.retries = 0
+
+	_, err = unknown.doList(listRequest{})
+	require.Error(t, err)
+
+	// Unknown paths are grouped
+
+	resp, err = http.Get(httpServer.URL + "/api/v2/foobar")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	var buf bytes.Buffer
+	require.NoError(t, api.metrics.write(&buf))
+
+	output := buf.String()
+	require.Contains(t, output, `apero_http_requests_total{action="copy",code="202"} 1`)
+	require.Contains(t, output, `apero_http_requests_total{action="list",code="200"} 1`)
+	require.Contains(t, output, `apero_http_requests_total{action="list",code="400"} 2`)
+	require.Contains(t, output, `apero_http_requests_total{action="unknown",code="404"} 1`)
+	require.Contains(t, output, `apero_http_request_duration_seconds_bucket{action="copy",code="202",le="+Inf"} 1`)
+	require.Contains(t, output, `apero_http_request_duration_seconds_count{action="list",code="400"} 2`)
+	require.Contains(t, output, "apero_signature_failures_total{action=\"list\"} 1\n")
+	require.Contains(t, output, "apero_box_open_failures_total 1\n")
+	require.Contains(t, output, "apero_store_entries 1\n")
+
+	// The store reports the entries removed
+
+	_, body, err := client.doMove(moveRequest{ID: id})
+	require.NoError(t, err)
+	body.Close()
+
+	buf.Reset()
+	require.NoError(t, api.metrics.write(&buf))
+	require.Contains(t, buf.String(), "apero_store_entries 0\n")
+	require.Contains(t, buf.String(), "apero_store_bytes 0\n")
+
+	// Every metric has a type
+
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := line[:strings.IndexAny(line, "{ ")]
+		name = strings.TrimSuffix(strings.TrimSuffix(strings.TrimSuffix(name, "_bucket"), "_sum"), "_count")
+		require.Contains(t, buf.String(), "# TYPE "+name+" ", line)
+	}
+}
+
+func TestMetricsHandler(t *testing.T) {
+	m := newMetrics()
+	m.observeRequest(actionMove, http.StatusOK, 30*time.Millisecond)
+	m.observeRequest(actionMove, http.StatusOK, 2*time.Second)
+
+	w := httptest.NewRecorder()
+	m.handler().ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.Equal(t, "text/plain; version=0.0.4; charset=utf-8", w.Header().Get("Content-Type"))
+
+	output := w.Body.String()
+	require.Contains(t, output, `apero_http_request_duration_seconds_bucket{action="move",code="200",le="0.025"} 0`)
+	require.Contains(t, output, `apero_http_request_duration_seconds_bucket{action="move",code="200",le="0.05"} 1`)
+	require.Contains(t, output, `apero_http_request_duration_seconds_bucket{action="move",code="200",le="2.5"} 2`)
+	require.Contains(t, output, `apero_http_request_duration_seconds_sum{action="move",code="200"} 2.03`)
+
+	w = httptest.NewRecorder()
+	m.handler().ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	require.Equal(t, http.StatusMethodNotAllowed, w.Code)
+}
+
+func TestMetricsNotOnServerHandler(t *testing.T) {
+	_, _, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	resp, err := http.Get(httpServer.URL + "/metrics")
+	require.NoError(t, err)
+	resp.Body.Close()
+
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
+
+func TestStoreMetrics(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	for _, conf := range []storageConfig{{}, {Type: "disk", Path: dir}} {
+		t.Run(conf.Type, func(t *testing.T) {
+			m := newMetrics()
+
+			st, err := newStore(conf, m)
+			require.NoError(t, err)
+
+			now := time.Now()
+
+			_, err = st.Add(strings.NewReader("foo"), addOptions{ExpiresAt: now.Add(-time.Minute)})
+			require.NoError(t, err)
+			id, err := st.Add(strings.NewReader("barbaz"), addOptions{})
+			require.NoError(t, err)
+			_, err = st.Add(strings.NewReader("quux"), addOptions{})
+			require.NoError(t, err)
+
+			require.Equal(t, int64(3), m.storeEntries)
+			require.Equal(t, int64(13), m.storeBytes)
+
+			n, err := st.RemoveExpired(now)
+			require.NoError(t, err)
+			require.Equal(t, 1, n)
+
+			require.Equal(t, int64(2), m.storeEntries)
+			require.Equal(t, int64(10), m.storeBytes)
+			require.Equal(t, uint64(1), m.storeEvictions)
+			require.Equal(t, uint64(3), m.storeEvictedBytes)
+
+			_, content, err := st.Remove(id)
+			require.NoError(t, err)
+			require.Equal(t, "barbaz", mustReadEntry(t, content))
+
+			require.Equal(t, int64(1), m.storeEntries)
+			require.Equal(t, int64(4), m.storeBytes)
+
+			require.NoError(t, st.Close())
+
+			// The entries already on disk are reported
+
+			if conf.Type == "disk" {
+				m := newMetrics()
+
+				st, err := newStore(conf, m)
+				require.NoError(t, err)
+				defer st.Close()
+
+				require.Equal(t, int64(1), m.storeEntries)
+				require.Equal(t, int64(4), m.storeBytes)
+			}
+		})
+	}
+}
+
+func TestRequestAction(t *testing.T) {
+	testCases := []struct {
+		path   string
+		action string
+	}{
+		{"/v2/copy", actionCopy},
+		{"/v1/move", actionMove},
+		{"/v2/upload/chunk", actionUploadChunk},
+		{"/v2/pair/123456", actionPair},
+		{"/v2/upload/foobar", "unknown"},
+		{"/v3/copy", "unknown"},
+		{"/v2/foobar", "unknown"},
+	}
+
+	for _, tc := range testCases {
+		require.Equal(t, tc.action, requestAction(tc.path), tc.path)
+	}
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	crypto_rand "crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"time"
+)
+
+var (
+	errInvalidClientCertKey = errors.New("the client certificate key isn't an ed25519 key")
+	errCertDeviceMismatch   = errors.New("the request isn't signed by the device of the client certificate")
+)
+
+// payloadBox seals and opens the payloads of the requests and responses.
+//
+// Payloads are sealed with the pre-shared key, except on a connection authenticated with a
+// client certificate: TLS already protects them so the box is clear.
+type payloadBox struct {
+	psKey secretBoxKey
+	clear bool
+	// device is the device of the client certificate of a clear box, the requests must be signed by it.
+	device string
+}
+
+func (b payloadBox) seal(data []byte) []byte {
+	if b.clear {
+		return data
+	}
+	return secretBoxSeal(data, b.psKey)
+}
+
+func (b payloadBox) open(box []byte) ([]byte, bool) {
+	if b.clear {
+		return box, true
+	}
+	return secretBoxOpen(box, b.psKey)
+}
+
+// certDevice returns the name of the device whose sign key is the key of the client certificate.
+//
+// Like with a request signature, the SignPublicKey of the configuration is the device without a name.
+func (s *apiHandler) certDevice(conf serverConfig, cert *x509.Certificate) (string, error) {
+	pub, ok := cert.PublicKey.(ed25519.PublicKey)
+	if !ok {
+		return "", errInvalidClientCertKey
+	}
+
+	if conf.SignPublicKey.IsValid() && bytes.Equal(conf.SignPublicKey, pub) {
+		return "", nil
+	}
+
+	devices, err := s.devices.Devices()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	for _, d := range devices {
+		if d.isRevoked() {
+			continue
+		}
+		for _, key := range d.keys(now) {
+			if bytes.Equal(key, pub) {
+				return d.Name, nil
+			}
+		}
+	}
+
+	return "", errUnknownDevice
+}
+
+// newServerTLSConfig returns the TLS config of the server.
+//
+// With client certificates enabled a client can present a certificate of the sign key of a registered device,
+// issued by the client CA if there's one. Clients without a certificate still use the pre-shared key.
+func newServerTLSConfig(conf tlsConfig, api *apiHandler) (*tls.Config, error) {
+	cert, err := tls.LoadX509KeyPair(conf.CertFile, conf.KeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("unable to load TLS certificate. err: %v", err)
+	}
+
+	tlsConf := &tls.Config{
+		Certificates: []tls.Certificate{cert},
+	}
+	if !conf.ClientCerts {
+		return tlsConf, nil
+	}
+
+	//
+
+	tlsConf.ClientAuth = tls.RequestClientCert
+	if conf.ClientCAFile != "" {
+		data, err := ioutil.ReadFile(conf.ClientCAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read client CA. err: %v", err)
+		}
+
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(data) {
+			return nil, fmt.Errorf("no certificate in %s", conf.ClientCAFile)
+		}
+
+		tlsConf.ClientAuth = tls.VerifyClientCertIfGiven
+		tlsConf.ClientCAs = pool
+	}
+
+	// NOTE(vincent): this is called after the chain is verified with the client CA, if any.
+	tlsConf.VerifyPeerCertificate = func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+		if len(rawCerts) == 0 {
+			return nil
+		}
+
+		cert, err := x509.ParseCertificate(rawCerts[0])
+		if err != nil {
+			return err
+		}
+
+		_, err = api.certDevice(api.config(), cert)
+		return err
+	}
+
+	return tlsConf, nil
+}
+
+// newDeviceCert returns the client certificate of the device, its key is the sign key.
+//
+// The certificate is read from ClientCertFile if set, otherwise a self-signed one is created.
+func newDeviceCert(conf clientConfig, now time.Time) (tls.Certificate, error) {
+	priv := ed25519.PrivateKey(conf.SignPrivateKey)
+
+	if conf.ClientCertFile != "" {
+		data, err := ioutil.ReadFile(conf.ClientCertFile)
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("unable to read client certificate. err: %v", err)
+		}
+
+		var cert tls.Certificate
+		for {
+			var block *pem.Block
+			block, data = pem.Decode(data)
+			if block == nil {
+				break
+			}
+			if block.Type == "CERTIFICATE" {
+				cert.Certificate = append(cert.Certificate, block.Bytes)
+			}
+		}
+		if len(cert.Certificate) == 0 {
+			return tls.Certificate{}, fmt.Errorf("no certificate in %s", conf.ClientCertFile)
+		}
+
+		leaf, err := x509.ParseCertificate(cert.Certificate[0])
+		if err != nil {
+			return tls.Certificate{}, fmt.Errorf("invalid client certificate. err: %v", err)
+		}
+		if pub, ok := leaf.PublicKey.(ed25519.PublicKey); !ok || !bytes.Equal(pub, conf.SignPublicKey) {
+			return tls.Certificate{}, errors.New("the client certificate isn't for the sign public key")
+		}
+
+		cert.PrivateKey = priv
+		cert.Leaf = leaf
+
+		return cert, nil
+	}
+
+	//
+
+	serial, err := crypto_rand.Int(crypto_rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to generate serial number. err: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: conf.DeviceName},
+		NotBefore:    now.Add(-time.Hour),
+		NotAfter:     now.Add(selfSignedCertValidity),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(crypto_rand.Reader, &template, &template, priv.Public(), priv)
+	if err != nil {
+		return tls.Certificate{}, fmt.Errorf("unable to create client certificate. err: %v", err)
+	}
+
+	return tls.Certificate{
+		Certificate: [][]byte{der},
+		PrivateKey:  priv,
+	}, nil
+}
+
+// newClientHTTPClient returns the HTTP client for the client config:
+// it connects to the unix socket of the endpoint, or pins the server certificate
+// and presents the device certificate if configured.
+func newClientHTTPClient(conf clientConfig) (*http.Client, error) {
+	if socket, ok := unixEndpointSocket(conf.Endpoint); ok {
+		return newUnixHTTPClient(socket), nil
+	}
+	if !conf.ClientCert {
+		return newHTTPClient(conf.CertFingerprint, nil)
+	}
+
+	cert, err := newDeviceCert(conf, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return newHTTPClient(conf.CertFingerprint, &cert)
+}
+
+// errorTransport is a http.RoundTripper which always fails, it's used when
+// the client can't be configured as intended.
+type errorTransport struct {
+	err error
+}
+
+func (t errorTransport) RoundTrip(*http.Request) (*http.Response, error) {
+	return nil, t.err
+}
@@ -0,0 +1,304 @@
+package main
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	crypto_rand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// testCA is an in-process CA issuing client certificates.
+type testCA struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+func newTestCA(t *testing.T) *testCA {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crypto_rand.Reader)
+	require.NoError(t, err)
+
+	template := x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "apero test CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	der, err := x509.CreateCertificate(crypto_rand.Reader, &template, &template, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCA{cert: cert, key: key}
+}
+
+func (ca *testCA) PEM() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: ca.cert.Raw})
+}
+
+func (ca *testCA) issue(t *testing.T, name string, pub publicKey) []byte {
+	template := x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(crypto_rand.Reader, &template, ca.cert, ed25519.PublicKey(pub), ca.key)
+	require.NoError(t, err)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+}
+
+func newMTLSTestServer(t *testing.T, api *apiHandler) *httptest.Server {
+	tlsConf, err := newServerTLSConfig(api.conf.TLS, api)
+	require.NoError(t, err)
+
+	httpServer := httptest.NewUnstartedServer(serverHandler(api, newUIHandler(api.conf)))
+	httpServer.TLS = tlsConf
+	httpServer.StartTLS()
+
+	return httpServer
+}
+
+func TestServerClientMTLS(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	api, pskClient, plainServer := newTestServerClient(t)
+	plainServer.Close()
+
+	certPEM, keyPEM, err := generateSelfSignedCert([]string{"localhost", "127.0.0.1"}, time.Now())
+	require.NoError(t, err)
+
+	api.conf.TLS = tlsConfig{
+		CertFile:    filepath.Join(dir, "cert.pem"),
+		KeyFile:     filepath.Join(dir, "key.pem"),
+		ClientCerts: true,
+	}
+	require.NoError(t, ioutil.WriteFile(api.conf.TLS.CertFile, certPEM, 0644))
+	require.NoError(t, ioutil.WriteFile(api.conf.TLS.KeyFile, keyPEM, 0600))
+
+	fingerprint, err := certFileFingerprint(api.conf.TLS.CertFile)
+	require.NoError(t, err)
+
+	api.devices = newDeviceRegistry(filepath.Join(dir, "devices.toml"))
+
+	phonePub, phonePriv := mustKeyPair(t)
+	require.NoError(t, api.devices.Add("phone", phonePub, time.Now()))
+
+	httpServer := newMTLSTestServer(t, api)
+	defer httpServer.Close()
+
+	// NOTE(vincent): no pre-shared key, the payloads are only protected by TLS.
+	conf := clientConfig{
+		Endpoint:        httpServer.URL,
+		EncryptKey:      newSecretBoxKey(),
+		SignPublicKey:   phonePub,
+		SignPrivateKey:  phonePriv,
+		DeviceName:      "phone",
+		CertFingerprint: fingerprint,
+		ClientCert:      true,
+	}
+	require.NoError(t, conf.Validate())
+
+	newTestClient := func(conf clientConfig) *client {
+		c := newClient(conf)
+		c.retries = 0
+		return c
+	}
+
+	t.Run("self-signed", func(t *testing.T) {
+		c := newTestClient(conf)
+
+		id, err := c.doCopy(copyStreamHeader{}, strings.NewReader("hello"))
+		require.NoError(t, err)
+
+		_, err = c.doList(listRequest{})
+		require.NoError(t, err)
+
+		info, body, err := c.doPaste(pasteRequest{ID: id})
+		require.NoError(t, err)
+		defer body.Close()
+
+		require.Equal(t, "phone", info.Device)
+		data, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		require.Equal(t, "hello", string(data))
+	})
+
+	t.Run("psk", func(t *testing.T) {
+		// Clients without a certificate still use the pre-shared key
+		pskConf := pskClient.conf
+		pskConf.Endpoint = httpServer.URL
+		pskConf.CertFingerprint = fingerprint
+
+		_, err := newTestClient(pskConf).doList(listRequest{})
+		require.NoError(t, err)
+
+		// and clear payloads are refused without a certificate
+		unsealed := newTestClient(pskConf)
+		unsealed.box = payloadBox{clear: true}
+
+		_, err = unsealed.doList(listRequest{})
+		require.Error(t, err)
+	})
+
+	t.Run("unknown-device", func(t *testing.T) {
+		otherConf := conf
+		otherConf.SignPublicKey, otherConf.SignPrivateKey = mustKeyPair(t)
+
+		_, err := newTestClient(otherConf).doList(listRequest{})
+		require.Error(t, err)
+	})
+
+	t.Run("ca", func(t *testing.T) {
+		ca := newTestCA(t)
+
+		caConf := api.conf
+		caConf.TLS.ClientCAFile = filepath.Join(dir, "ca.pem")
+		require.NoError(t, ioutil.WriteFile(caConf.TLS.ClientCAFile, ca.PEM(), 0644))
+
+		caAPI := newAPIHandler(caConf, api.st, api.uploads, nil)
+		caAPI.devices = api.devices
+
+		caServer := newMTLSTestServer(t, caAPI)
+		defer caServer.Close()
+
+		clientConf := conf
+		clientConf.Endpoint = caServer.URL
+
+		// The self-signed certificate isn't issued by the CA
+
+		_, err := newTestClient(clientConf).doList(listRequest{})
+		require.Error(t, err)
+
+		// The certificate issued by the CA is
+
+		clientConf.ClientCertFile = filepath.Join(dir, "phone.pem")
+		require.NoError(t, ioutil.WriteFile(clientConf.ClientCertFile, ca.issue(t, "phone", phonePub), 0644))
+		require.NoError(t, clientConf.Validate())
+
+		_, err = newTestClient(clientConf).doList(listRequest{})
+		require.NoError(t, err)
+
+		// but only for a registered key
+
+		otherConf := clientConf
+		otherConf.SignPublicKey, otherConf.SignPrivateKey = mustKeyPair(t)
+		otherConf.ClientCertFile = filepath.Join(dir, "other.pem")
+		require.NoError(t, ioutil.WriteFile(otherConf.ClientCertFile, ca.issue(t, "other", otherConf.SignPublicKey), 0644))
+
+		_, err = newTestClient(otherConf).doList(listRequest{})
+		require.Error(t, err)
+
+		// and the certificate must be for the sign key of the client
+
+		_, err = newDeviceCert(clientConfig{
+			SignPublicKey:  otherConf.SignPublicKey,
+			SignPrivateKey: otherConf.SignPrivateKey,
+			ClientCertFile: clientConf.ClientCertFile,
+		}, time.Now())
+		require.Error(t, err)
+	})
+
+	t.Run("other-device", func(t *testing.T) {
+		// The requests must be signed by the device of the certificate
+
+		laptopPub, laptopPriv := mustKeyPair(t)
+		require.NoError(t, api.devices.Add("laptop", laptopPub, time.Now()))
+
+		c := newTestClient(conf)
+		c.conf.SignPublicKey, c.conf.SignPrivateKey, c.conf.DeviceName = laptopPub, laptopPriv, "laptop"
+
+		_, err := c.doList(listRequest{})
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "400 Bad Request")
+
+		// Same for the v1 API, where the signer is found from the signature
+
+		content := []byte("hello")
+		req := copyRequest{Signature: sign(laptopPriv, content), Content: content}
+		_, err = c.doRequest(req, http.MethodPost, http.StatusAccepted, "/api/v1/copy")
+		require.Error(t, err)
+		require.Contains(t, err.Error(), "400 Bad Request")
+	})
+
+	t.Run("revoked", func(t *testing.T) {
+		c := newTestClient(conf)
+
+		_, err := c.doList(listRequest{})
+		require.NoError(t, err)
+
+		require.NoError(t, api.devices.Revoke("phone", time.Now()))
+
+		// The connection established before the device was revoked is reused
+		_, err = c.doList(listRequest{})
+		require.Error(t, err)
+
+		_, err = newTestClient(conf).doList(listRequest{})
+		require.Error(t, err)
+	})
+}
+
+func TestDeviceCert(t *testing.T) {
+	pub, priv := mustKeyPair(t)
+
+	cert, err := newDeviceCert(clientConfig{
+		SignPublicKey:  pub,
+		SignPrivateKey: priv,
+		DeviceName:     "phone",
+	}, time.Now())
+	require.NoError(t, err)
+
+	leaf, err := x509.ParseCertificate(cert.Certificate[0])
+	require.NoError(t, err)
+	require.Equal(t, ed25519.PublicKey(pub), leaf.PublicKey)
+	require.Equal(t, "phone", leaf.Subject.CommonName)
+
+	_, ok := cert.PrivateKey.(ed25519.PrivateKey)
+	require.True(t, ok)
+	require.NoError(t, leaf.CheckSignature(leaf.SignatureAlgorithm, leaf.RawTBSCertificate, leaf.Signature))
+}
+
+func TestMTLSConfigValidate(t *testing.T) {
+	require.NoError(t, tlsConfig{CertFile: "cert.pem", KeyFile: "key.pem", ClientCerts: true}.Validate())
+	require.NoError(t, tlsConfig{CertFile: "cert.pem", KeyFile: "key.pem", ClientCerts: true, ClientCAFile: "ca.pem"}.Validate())
+	require.Error(t, tlsConfig{ClientCerts: true}.Validate())
+	require.Error(t, tlsConfig{CertFile: "cert.pem", KeyFile: "key.pem", ClientCAFile: "ca.pem"}.Validate())
+
+	conf := clientConfig{
+		Endpoint:   "http://localhost:7568",
+		EncryptKey: newSecretBoxKey(),
+		ClientCert: true,
+	}
+	conf.SignPublicKey, conf.SignPrivateKey = mustKeyPair(t)
+
+	require.Error(t, conf.Validate(), "a client certificate requires https")
+
+	conf.Endpoint = "https://localhost:7568"
+	require.NoError(t, conf.Validate())
+
+	conf.ClientCert = false
+	conf.ClientCertFile = "phone.pem"
+	require.Error(t, conf.Validate())
+}
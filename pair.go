@@ -0,0 +1,746 @@
+package main
+
+import (
+	"bytes"
+	"crypto/hmac"
+	crypto_rand "crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"math/big"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/BurntSushi/toml"
+	"github.com/tyler-smith/go-bip39"
+	"github.com/vrischmann/hutil/v2"
+	"golang.org/x/crypto/hkdf"
+)
+
+// Pairing transfers the client config from a device to a new one through the staging server,
+// like magic-wormhole does.
+//
+// The device with the config opens a mailbox on the server and shows a short code made of the
+// mailbox number, the nameplate, and two words. The new device only needs the code: both devices
+// run a CPace exchange with it through the mailbox, confirm they derived the same key and the
+// config is sent encrypted with it.
+//
+// Opening a mailbox is authenticated like any other request but the new device has no keys yet
+// so the mailbox messages are not. The server never sees the code: it only relays messages,
+// each one can be written once and a mailbox expires quickly.
+//
+// Only the keys shared by all the devices are sent: the new device generates its own sign key
+// which must be added to the server registry.
+
+const (
+	pairingSideA = "a" // the device sending its config
+	pairingSideB = "b" // the new device
+
+	pairingPhasePAKE    = "pake"
+	pairingPhaseConfirm = "confirm"
+	pairingPhaseConfig  = "config"
+
+	// pairingMailboxTTL is how long a pairing mailbox can be used.
+	pairingMailboxTTL = 10 * time.Minute
+	// maxPairingMailboxes is the maximum number of pairing mailboxes open at the same time.
+	maxPairingMailboxes = 100
+	// maxPairingNameplate is the maximum mailbox number, it's kept small to be easy to type.
+	maxPairingNameplate = 999
+	// maxPairingMessageSize is the maximum size of a single mailbox message.
+	maxPairingMessageSize = 16 << 10
+	// pairingPollTimeout is how long the server waits for a message before telling the client to try again.
+	pairingPollTimeout = 30 * time.Second
+
+	// pairingCodeWords is the number of words of a pairing code.
+	pairingCodeWords = 2
+)
+
+var (
+	errPairingNotFound      = errors.New("pairing mailbox not found")
+	errPairingMessageExists = errors.New("pairing message already written")
+	errTooManyPairings      = errors.New("too many pairings in progress")
+	errPairingCodeMismatch  = errors.New("the devices don't have the same pairing code")
+)
+
+// pairingMailbox relays the messages of the two sides of a pairing.
+type pairingMailbox struct {
+	expiresAt time.Time
+	messages  map[string][]byte
+	written   map[string]chan struct{}
+}
+
+func (b *pairingMailbox) waitChan(key string) chan struct{} {
+	ch, ok := b.written[key]
+	if !ok {
+		ch = make(chan struct{})
+		b.written[key] = ch
+	}
+	return ch
+}
+
+// pairingMailboxes are the pairing mailboxes of the server, by nameplate.
+type pairingMailboxes struct {
+	mu    sync.Mutex
+	boxes map[string]*pairingMailbox
+}
+
+func newPairingMailboxes() *pairingMailboxes {
+	return &pairingMailboxes{
+		boxes: make(map[string]*pairingMailbox),
+	}
+}
+
+// expire removes the expired mailboxes. The lock must be held.
+func (m *pairingMailboxes) expire(now time.Time) {
+	for nameplate, box := range m.boxes {
+		if now.After(box.expiresAt) {
+			delete(m.boxes, nameplate)
+		}
+	}
+}
+
+// Create opens a new mailbox and returns its nameplate.
+func (m *pairingMailboxes) Create(now time.Time) (string, time.Time, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire(now)
+	if len(m.boxes) >= maxPairingMailboxes {
+		return "", time.Time{}, errTooManyPairings
+	}
+
+	var nameplate string
+	for {
+		n, err := crypto_rand.Int(crypto_rand.Reader, big.NewInt(maxPairingNameplate))
+		if err != nil {
+			return "", time.Time{}, err
+		}
+		nameplate = strconv.FormatInt(n.Int64()+1, 10)
+		if _, ok := m.boxes[nameplate]; !ok {
+			break
+		}
+	}
+
+	box := &pairingMailbox{
+		expiresAt: now.Add(pairingMailboxTTL),
+		messages:  make(map[string][]byte),
+		written:   make(map[string]chan struct{}),
+	}
+	m.boxes[nameplate] = box
+
+	return nameplate, box.expiresAt, nil
+}
+
+// Put writes the message of a side for a phase. A message can only be written once,
+// this means that only one device can join a pairing.
+func (m *pairingMailboxes) Put(nameplate, side, phase string, data []byte, now time.Time) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire(now)
+	box, ok := m.boxes[nameplate]
+	if !ok {
+		return errPairingNotFound
+	}
+
+	key := side + "/" + phase
+	if _, ok := box.messages[key]; ok {
+		return errPairingMessageExists
+	}
+	box.messages[key] = data
+	close(box.waitChan(key))
+
+	return nil
+}
+
+// Get returns the message of a side for a phase if it's written,
+// otherwise it returns a channel closed once it is.
+func (m *pairingMailboxes) Get(nameplate, side, phase string, now time.Time) ([]byte, <-chan struct{}, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.expire(now)
+	box, ok := m.boxes[nameplate]
+	if !ok {
+		return nil, nil, errPairingNotFound
+	}
+
+	key := side + "/" + phase
+	if data, ok := box.messages[key]; ok {
+		return data, nil, nil
+	}
+
+	return nil, box.waitChan(key), nil
+}
+
+// Close removes a mailbox.
+func (m *pairingMailboxes) Close(nameplate string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.boxes, nameplate)
+}
+
+func isValidPairingMessage(side, phase string) bool {
+	switch {
+	case side == pairingSideA:
+		return phase == pairingPhasePAKE || phase == pairingPhaseConfirm || phase == pairingPhaseConfig
+	case side == pairingSideB:
+		return phase == pairingPhasePAKE || phase == pairingPhaseConfirm
+	default:
+		return false
+	}
+}
+
+// handlePair handles the pairing requests:
+// POST /api/v2/pair opens a mailbox, PUT and GET /api/v2/pair/<nameplate>/<side>/<phase>
+// write and wait for a message.
+func (s *apiHandler) handlePair(w http.ResponseWriter, req *http.Request, version string, tail string) {
+	if version == apiVersion1 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	nameplate, tail := hutil.ShiftPath(tail)
+	if nameplate == "" {
+		s.handleCreatePairing(w, req)
+		return
+	}
+
+	side, tail := hutil.ShiftPath(tail)
+	phase, tail := hutil.ShiftPath(tail)
+	if tail != "/" || !isValidPairingMessage(side, phase) {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	switch req.Method {
+	case http.MethodPut:
+		s.handlePutPairingMessage(w, req, nameplate, side, phase)
+	case http.MethodGet:
+		s.handleGetPairingMessage(w, req, nameplate, side, phase)
+	default:
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *apiHandler) handleCreatePairing(w http.ResponseWriter, req *http.Request) {
+	conf := s.config()
+
+	if req.Method != http.MethodPost {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		responseStatusCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	data, box, ok := s.openBox(conf, req, data)
+	if !ok {
+		log.Printf("unable to open box")
+		responseStatusCode(w, http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	var payload pairRequest
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Printf("unable to unmarshal pair request payload. err: %v", err)
+		responseString(w, "invalid pair request", http.StatusBadRequest)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		log.Printf("pair request payload invalid. err: %v", err)
+		responseString(w, "invalid pair request", http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	device, err := s.verifyRequest(conf, box, apiVersion2, actionPair, payload.Envelope, nil, payload.Signature)
+	if err != nil {
+		log.Printf("unable to verify pair request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nameplate, expiresAt, err := s.pairing.Create(time.Now())
+	switch {
+	case err == errTooManyPairings:
+		responseString(w, err.Error(), http.StatusServiceUnavailable)
+		return
+	case err != nil:
+		log.Printf("unable to create pairing mailbox. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	log.Printf("pairing mailbox %s opened by device %q", nameplate, device)
+
+	responseSealedJSON(w, box, pairResponse{Nameplate: nameplate, ExpiresAt: expiresAt}, http.StatusOK)
+}
+
+func (s *apiHandler) handlePutPairingMessage(w http.ResponseWriter, req *http.Request, nameplate, side, phase string) {
+	data, err := readRequestBody(req, maxPairingMessageSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		responseStatusCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	err = s.pairing.Put(nameplate, side, phase, data, time.Now())
+	switch {
+	case err == errPairingNotFound:
+		responseStatusCode(w, http.StatusNotFound)
+	case err == errPairingMessageExists:
+		responseString(w, err.Error(), http.StatusConflict)
+	case err != nil:
+		log.Printf("unable to write pairing message. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+	default:
+		w.WriteHeader(http.StatusNoContent)
+	}
+}
+
+// handleGetPairingMessage waits for a message. If it's not written before pairingPollTimeout
+// the response has no content and the client must try again.
+func (s *apiHandler) handleGetPairingMessage(w http.ResponseWriter, req *http.Request, nameplate, side, phase string) {
+	timer := time.NewTimer(pairingPollTimeout)
+	defer timer.Stop()
+
+	for {
+		data, written, err := s.pairing.Get(nameplate, side, phase, time.Now())
+		switch {
+		case err == errPairingNotFound:
+			responseStatusCode(w, http.StatusNotFound)
+			return
+		case err != nil:
+			log.Printf("unable to read pairing message. err: %v", err)
+			responseString(w, "internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		if data != nil {
+			// NOTE(vincent): the config is the last message, the mailbox isn't needed anymore.
+			if side == pairingSideA && phase == pairingPhaseConfig {
+				s.pairing.Close(nameplate)
+			}
+
+			w.Header().Set("Content-Type", "application/octet-stream")
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+			return
+		}
+
+		select {
+		case <-written:
+		case <-timer.C:
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case <-req.Context().Done():
+			return
+		}
+	}
+}
+
+// doCreatePairing opens a pairing mailbox.
+func (c *client) doCreatePairing() (pairResponse, error) {
+	var req pairRequest
+	req.Envelope, req.Signature = signDeviceEnvelope(c.conf.SignPrivateKey, c.conf.DeviceName, apiVersion2, actionPair, nil)
+
+	body, err := c.doRequest(req, http.MethodPost, http.StatusOK, "/api/v2/pair")
+	if err != nil {
+		return pairResponse{}, err
+	}
+
+	var resp pairResponse
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return pairResponse{}, fmt.Errorf("unable to unmarshal response")
+	}
+
+	return resp, nil
+}
+
+// pairingRelay exchanges the messages of a pairing through a mailbox of the staging server.
+type pairingRelay struct {
+	httpClient *http.Client
+	endpoint   string
+	nameplate  string
+	deadline   time.Time
+}
+
+func (r *pairingRelay) makeURL(side, phase string) string {
+	return endpointURL(r.endpoint, "/api/v2/pair/"+r.nameplate+"/"+side+"/"+phase)
+}
+
+// put writes the message of side for phase.
+func (r *pairingRelay) put(side, phase string, data []byte) error {
+	req, err := http.NewRequest(http.MethodPut, r.makeURL(side, phase), bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	resp, err := r.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("unable to reach the staging server. err: %v", err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil
+	case http.StatusNotFound:
+		return errors.New("pairing not found, it may have expired")
+	case http.StatusConflict:
+		return errors.New("another device already used this pairing code")
+	default:
+		return fmt.Errorf("invalid status code %s. body=%q", resp.Status, maybeReadHTTPResponseBody(resp))
+	}
+}
+
+// get waits for the message of side for phase, until the deadline of the relay.
+func (r *pairingRelay) get(side, phase string) ([]byte, error) {
+	for time.Now().Before(r.deadline) {
+		resp, err := r.httpClient.Get(r.makeURL(side, phase))
+		if err != nil {
+			return nil, fmt.Errorf("unable to reach the staging server. err: %v", err)
+		}
+
+		switch resp.StatusCode {
+		case http.StatusOK:
+			data, err := ioutil.ReadAll(io.LimitReader(resp.Body, maxPairingMessageSize))
+			resp.Body.Close()
+			return data, err
+
+		case http.StatusNoContent:
+			resp.Body.Close()
+			continue
+
+		case http.StatusNotFound:
+			resp.Body.Close()
+			return nil, errors.New("pairing not found, it may have expired")
+
+		default:
+			return nil, fmt.Errorf("invalid status code %s. body=%q", resp.Status, maybeReadHTTPResponseBody(resp))
+		}
+	}
+
+	return nil, errors.New("pairing expired")
+}
+
+// newPairingCode creates a pairing code like 42-word-word.
+func newPairingCode(nameplate string) (string, error) {
+	words := bip39.GetWordList()
+
+	parts := []string{nameplate}
+	for i := 0; i < pairingCodeWords; i++ {
+		n, err := crypto_rand.Int(crypto_rand.Reader, big.NewInt(int64(len(words))))
+		if err != nil {
+			return "", fmt.Errorf("unable to read random data. err: %v", err)
+		}
+		parts = append(parts, words[n.Int64()])
+	}
+
+	return strings.Join(parts, "-"), nil
+}
+
+// parsePairingCode normalizes a pairing code as typed by the user and returns its nameplate.
+func parsePairingCode(s string) (code string, nameplate string, err error) {
+	parts := strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return r == '-' || unicode.IsSpace(r)
+	})
+	if len(parts) != 1+pairingCodeWords {
+		return "", "", fmt.Errorf("invalid pairing code %q", s)
+	}
+	if n, err := strconv.Atoi(parts[0]); err != nil || n < 1 || n > maxPairingNameplate {
+		return "", "", fmt.Errorf("invalid pairing code %q", s)
+	}
+
+	return strings.Join(parts, "-"), parts[0], nil
+}
+
+// pairingKeys derives the keys used after the CPace exchange from its shared key.
+type pairingKeys struct {
+	confirmA   []byte
+	confirmB   []byte
+	encryptKey secretBoxKey
+}
+
+func derivePairingKeys(key []byte) (pairingKeys, error) {
+	var keys pairingKeys
+
+	r := hkdf.New(sha256.New, key, nil, []byte("apero pairing"))
+
+	keys.confirmA = make([]byte, 32)
+	keys.confirmB = make([]byte, 32)
+	for _, p := range [][]byte{keys.confirmA, keys.confirmB, keys.encryptKey[:]} {
+		if _, err := io.ReadFull(r, p); err != nil {
+			return keys, fmt.Errorf("unable to derive pairing keys. err: %v", err)
+		}
+	}
+
+	return keys, nil
+}
+
+func pairingConfirmation(key []byte, side string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("apero pairing confirmation " + side))
+	return mac.Sum(nil)
+}
+
+// exchangePairingKeys runs the CPace exchange and the key confirmation as the side.
+func exchangePairingKeys(relay *pairingRelay, side, code string) (pairingKeys, error) {
+	peer := pairingSideB
+	pakeSide := byte(cpaceSideA)
+	if side == pairingSideB {
+		peer = pairingSideA
+		pakeSide = cpaceSideB
+	}
+
+	pake, msg, err := newCPace(pakeSide, []byte(code))
+	if err != nil {
+		return pairingKeys{}, err
+	}
+
+	// NOTE(vincent): the new device writes its message only after the other one is there,
+	// this makes sure the mailbox exists before the new device claims it.
+	if side == pairingSideA {
+		if err := relay.put(side, pairingPhasePAKE, msg); err != nil {
+			return pairingKeys{}, err
+		}
+	}
+	peerMsg, err := relay.get(peer, pairingPhasePAKE)
+	if err != nil {
+		return pairingKeys{}, err
+	}
+	if side == pairingSideB {
+		if err := relay.put(side, pairingPhasePAKE, msg); err != nil {
+			return pairingKeys{}, err
+		}
+	}
+
+	key, err := pake.Finish(peerMsg)
+	if err != nil {
+		return pairingKeys{}, err
+	}
+	keys, err := derivePairingKeys(key)
+	if err != nil {
+		return pairingKeys{}, err
+	}
+
+	// Key confirmation
+
+	ownKey, peerKey := keys.confirmA, keys.confirmB
+	if side == pairingSideB {
+		ownKey, peerKey = keys.confirmB, keys.confirmA
+	}
+
+	if err := relay.put(side, pairingPhaseConfirm, pairingConfirmation(ownKey, side)); err != nil {
+		return pairingKeys{}, err
+	}
+	confirmation, err := relay.get(peer, pairingPhaseConfirm)
+	if err != nil {
+		return pairingKeys{}, err
+	}
+	if !hmac.Equal(confirmation, pairingConfirmation(peerKey, peer)) {
+		return pairingKeys{}, errPairingCodeMismatch
+	}
+
+	return keys, nil
+}
+
+// pairingConfig is the part of the client config sent to a new device: how to reach the server
+// and the keys shared by all the devices. The sign keys and the name identify a device, they're never sent.
+type pairingConfig struct {
+	Endpoint           string
+	PSKey              secretBoxKey
+	EncryptKey         secretBoxKey
+	RetiredEncryptKeys []secretBoxKey
+	CertFingerprint    string
+	ClientCert         bool
+}
+
+func newPairingConfig(conf clientConfig) pairingConfig {
+	return pairingConfig{
+		Endpoint:           conf.Endpoint,
+		PSKey:              conf.PSKey,
+		EncryptKey:         conf.EncryptKey,
+		RetiredEncryptKeys: conf.RetiredEncryptKeys,
+		CertFingerprint:    conf.CertFingerprint,
+		ClientCert:         conf.ClientCert,
+	}
+}
+
+// newPairedDeviceConfig returns the client config of the new device named name, with a new sign key pair.
+func newPairedDeviceConfig(shared pairingConfig, name string) (clientConfig, error) {
+	if !isValidDeviceName(name) {
+		return clientConfig{}, errInvalidDeviceName
+	}
+
+	pub, priv, err := generateKeyPair()
+	if err != nil {
+		return clientConfig{}, fmt.Errorf("unable to generate sign key pair. err: %v", err)
+	}
+
+	return clientConfig{
+		Endpoint:           shared.Endpoint,
+		PSKey:              shared.PSKey,
+		EncryptKey:         shared.EncryptKey,
+		SignPublicKey:      pub,
+		SignPrivateKey:     priv,
+		DeviceName:         name,
+		RetiredEncryptKeys: shared.RetiredEncryptKeys,
+		CertFingerprint:    shared.CertFingerprint,
+		ClientCert:         shared.ClientCert,
+	}, nil
+}
+
+// sendPairingConfig sends the shared part of the client config to the device which joins the pairing with code.
+func sendPairingConfig(relay *pairingRelay, code string, conf clientConfig) error {
+	keys, err := exchangePairingKeys(relay, pairingSideA, code)
+	if err != nil {
+		return err
+	}
+
+	data, err := json.Marshal(newPairingConfig(conf))
+	if err != nil {
+		return fmt.Errorf("unable to marshal config. err: %v", err)
+	}
+
+	return relay.put(pairingSideA, pairingPhaseConfig, secretBoxSeal(data, keys.encryptKey))
+}
+
+// receivePairingConfig joins the pairing with code and receives the shared part of the client config.
+func receivePairingConfig(relay *pairingRelay, code string) (pairingConfig, error) {
+	keys, err := exchangePairingKeys(relay, pairingSideB, code)
+	if err != nil {
+		return pairingConfig{}, err
+	}
+
+	box, err := relay.get(pairingSideA, pairingPhaseConfig)
+	if err != nil {
+		return pairingConfig{}, err
+	}
+
+	data, ok := secretBoxOpen(box, keys.encryptKey)
+	if !ok {
+		return pairingConfig{}, errors.New("unable to decrypt the pairing config")
+	}
+
+	var conf pairingConfig
+	if err := json.Unmarshal(data, &conf); err != nil {
+		return pairingConfig{}, fmt.Errorf("invalid pairing config. err: %v", err)
+	}
+
+	return conf, nil
+}
+
+func runPair(args []string) error {
+	var conf clientConfig
+	if _, err := toml.DecodeFile(*globalConfig, &conf); err != nil {
+		return fmt.Errorf("invalid toml config. err=%v", err)
+	}
+	if err := conf.Validate(); err != nil {
+		return err
+	}
+
+	c := newClient(conf)
+
+	resp, err := c.doCreatePairing()
+	if err != nil {
+		return fmt.Errorf("unable to open a pairing. err: %v", err)
+	}
+
+	code, err := newPairingCode(resp.Nameplate)
+	if err != nil {
+		return err
+	}
+
+	joinFlags := "-endpoint " + conf.Endpoint
+	if conf.CertFingerprint != "" {
+		joinFlags += " -fingerprint " + conf.CertFingerprint
+	}
+
+	fmt.Printf("pairing code: %s\n", code)
+	fmt.Printf("on the new device run: apero join %s -device-name <name> %s\n", joinFlags, code)
+	fmt.Printf("expires at %s\n", resp.ExpiresAt.Local().Format("15:04:05"))
+
+	relay := &pairingRelay{
+		httpClient: c.httpClient,
+		endpoint:   conf.Endpoint,
+		nameplate:  resp.Nameplate,
+		deadline:   resp.ExpiresAt,
+	}
+	if err := sendPairingConfig(relay, code, conf); err != nil {
+		return err
+	}
+
+	fmt.Println("config sent")
+
+	return nil
+}
+
+func runJoin(args []string) error {
+	if *joinEndpoint == "" || *joinDeviceName == "" || len(args) != 1 {
+		return errors.New("usage: apero join -endpoint <url> -device-name <name> <code>")
+	}
+
+	code, nameplate, err := parsePairingCode(args[0])
+	if err != nil {
+		return err
+	}
+
+	httpClient, err := newHTTPClient(*joinFingerprint, nil)
+	if err != nil {
+		return err
+	}
+	if socket, ok := unixEndpointSocket(*joinEndpoint); ok {
+		httpClient = newUnixHTTPClient(socket)
+	}
+
+	relay := &pairingRelay{
+		httpClient: httpClient,
+		endpoint:   strings.TrimSuffix(*joinEndpoint, "/"),
+		nameplate:  nameplate,
+		deadline:   time.Now().Add(pairingMailboxTTL),
+	}
+
+	shared, err := receivePairingConfig(relay, code)
+	if err != nil {
+		return err
+	}
+
+	conf, err := newPairedDeviceConfig(shared, *joinDeviceName)
+	if err != nil {
+		return err
+	}
+	if err := saveEnrolledConfig(conf, *globalConfig, "", *joinForce, os.Stdout); err != nil {
+		return err
+	}
+
+	fmt.Printf("this device has its own sign key, add it to the server registry with:\n\n")
+	fmt.Printf("  apero devices add %s %s\n", conf.DeviceName, conf.SignPublicKey)
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPairingCode(t *testing.T) {
+	code, err := newPairingCode("42")
+	require.NoError(t, err)
+	require.True(t, strings.HasPrefix(code, "42-"))
+
+	got, nameplate, err := parsePairingCode(code)
+	require.NoError(t, err)
+	require.Equal(t, code, got)
+	require.Equal(t, "42", nameplate)
+
+	got, nameplate, err = parsePairingCode(" 7 Apple-RIVER\n")
+	require.NoError(t, err)
+	require.Equal(t, "7-apple-river", got)
+	require.Equal(t, "7", nameplate)
+
+	for _, input := range []string{"", "42", "42-apple", "apple-river-42", "0-apple-river", "1000-apple-river", "42-apple-river-sun"} {
+		_, _, err := parsePairingCode(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestPairingMailboxes(t *testing.T) {
+	now := time.Now()
+	m := newPairingMailboxes()
+
+	nameplate, expiresAt, err := m.Create(now)
+	require.NoError(t, err)
+	require.Equal(t, now.Add(pairingMailboxTTL), expiresAt)
+
+	data, written, err := m.Get(nameplate, pairingSideA, pairingPhasePAKE, now)
+	require.NoError(t, err)
+	require.Nil(t, data)
+
+	require.NoError(t, m.Put(nameplate, pairingSideA, pairingPhasePAKE, []byte("foo"), now))
+	<-written
+	require.Equal(t, errPairingMessageExists, m.Put(nameplate, pairingSideA, pairingPhasePAKE, []byte("bar"), now))
+
+	data, _, err = m.Get(nameplate, pairingSideA, pairingPhasePAKE, now)
+	require.NoError(t, err)
+	require.Equal(t, []byte("foo"), data)
+
+	require.Equal(t, errPairingNotFound, m.Put("foobar", pairingSideA, pairingPhasePAKE, nil, now))
+
+	// Expired
+	_, _, err = m.Get(nameplate, pairingSideA, pairingPhasePAKE, expiresAt.Add(time.Second))
+	require.Equal(t, errPairingNotFound, err)
+}
+
+func TestPairing(t *testing.T) {
+	_, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	newRelay := func(nameplate string) *pairingRelay {
+		return &pairingRelay{
+			httpClient: httpServer.Client(),
+			endpoint:   httpServer.URL,
+			nameplate:  nameplate,
+			deadline:   time.Now().Add(time.Minute),
+		}
+	}
+
+	pair := func(t *testing.T, codeB string) (pairingConfig, error, error) {
+		resp, err := client.doCreatePairing()
+		require.NoError(t, err)
+
+		code, err := newPairingCode(resp.Nameplate)
+		require.NoError(t, err)
+		if codeB == "" {
+			codeB = code
+		}
+
+		errCh := make(chan error, 1)
+		go func() {
+			errCh <- sendPairingConfig(newRelay(resp.Nameplate), code, client.conf)
+		}()
+
+		conf, err := receivePairingConfig(newRelay(resp.Nameplate), codeB)
+
+		return conf, err, <-errCh
+	}
+
+	t.Run("ok", func(t *testing.T) {
+		conf, errB, errA := pair(t, "")
+		require.NoError(t, errA)
+		require.NoError(t, errB)
+		require.Equal(t, newPairingConfig(client.conf), conf)
+	})
+
+	t.Run("wrong-code", func(t *testing.T) {
+		_, errB, errA := pair(t, "1-apple-river")
+		require.Equal(t, errPairingCodeMismatch, errA)
+		require.Equal(t, errPairingCodeMismatch, errB)
+	})
+
+	t.Run("unauthenticated", func(t *testing.T) {
+		resp, err := http.Post(httpServer.URL+"/api/v2/pair", "application/octet-stream", strings.NewReader("foobar"))
+		require.NoError(t, err)
+		resp.Body.Close()
+		require.Equal(t, http.StatusBadRequest, resp.StatusCode)
+
+		require.Error(t, newRelay("1000").put(pairingSideB, pairingPhasePAKE, []byte("foo")))
+	})
+
+	t.Run("single-device", func(t *testing.T) {
+		resp, err := client.doCreatePairing()
+		require.NoError(t, err)
+
+		relay := newRelay(resp.Nameplate)
+		require.NoError(t, relay.put(pairingSideB, pairingPhasePAKE, []byte("foo")))
+		require.Error(t, relay.put(pairingSideB, pairingPhasePAKE, []byte("bar")))
+	})
+}
+
+func TestNewPairedDeviceConfig(t *testing.T) {
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	api.conf.DevicesFile = filepath.Join(dir, "devices.toml")
+	api.devices = newDeviceRegistry(api.conf.DevicesFile)
+
+	client.conf.RetiredEncryptKeys = []secretBoxKey{newSecretBoxKey()}
+
+	_, err := newPairedDeviceConfig(newPairingConfig(client.conf), "")
+	require.Equal(t, errInvalidDeviceName, err)
+
+	conf, err := newPairedDeviceConfig(newPairingConfig(client.conf), "phone")
+	require.NoError(t, err)
+	require.NoError(t, checkEnrollConfig(conf))
+	require.Equal(t, "phone", conf.DeviceName)
+	require.Equal(t, client.conf.RetiredEncryptKeys, conf.RetiredEncryptKeys)
+	require.NotEqual(t, client.conf.SignPublicKey, conf.SignPublicKey)
+
+	// The new device is only accepted once it's in the registry
+
+	_, err = newClient(conf).doList(listRequest{})
+	require.Error(t, err)
+
+	require.NoError(t, api.devices.Add(conf.DeviceName, conf.SignPublicKey, time.Now()))
+
+	_, err = newClient(conf).doList(listRequest{})
+	require.NoError(t, err)
+}
@@ -0,0 +1,336 @@
+package main
+
+import (
+	"crypto/ed25519"
+	crypto_rand "crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"rischmann.fr/apero/internal/ui"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	// defaultProvisioningTTL is how long the provisioning URL can be used by default.
+	defaultProvisioningTTL = 5 * time.Minute
+
+	// provisioningCodeLength is the number of characters of a provisioning code, 5 bits each.
+	provisioningCodeLength = 12
+
+	// provisioningCodeAlphabet is the Crockford base32 alphabet, without the letters easily mistaken for digits.
+	provisioningCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+	provisioningSaltSize = 16
+
+	// compactConfigPrefix starts a client config encoded by encodeCompactConfig.
+	compactConfigPrefix = "apero://config?"
+)
+
+// provisioningPayload is what apero provision serves: the client config encrypted
+// with a key derived from the provisioning code.
+type provisioningPayload struct {
+	Salt []byte `json:"salt"`
+	Box  []byte `json:"box"`
+}
+
+// newProvisioningToken creates the random path of the provisioning URL.
+func newProvisioningToken() string {
+	var data [16]byte
+	if _, err := crypto_rand.Read(data[:]); err != nil {
+		log.Fatalf("unable to read random data. err: %v", err)
+	}
+	return hex.EncodeToString(data[:])
+}
+
+// newProvisioningCode creates a random provisioning code, formatted in groups of 4 characters to be easier to type.
+func newProvisioningCode() string {
+	var data [provisioningCodeLength]byte
+	if _, err := crypto_rand.Read(data[:]); err != nil {
+		log.Fatalf("unable to read random data. err: %v", err)
+	}
+
+	var buf strings.Builder
+	for i, b := range data {
+		if i > 0 && i%4 == 0 {
+			buf.WriteByte('-')
+		}
+		// NOTE(vincent): 256 is a multiple of 32 so there's no modulo bias.
+		buf.WriteByte(provisioningCodeAlphabet[int(b)%len(provisioningCodeAlphabet)])
+	}
+
+	return buf.String()
+}
+
+// parseProvisioningCode normalizes a provisioning code as typed by the user.
+//
+// The case, the dashes and the spaces don't matter, and like in Crockford base32
+// O is read as 0 and I and L as 1.
+func parseProvisioningCode(s string) (string, error) {
+	replacer := strings.NewReplacer("-", "", " ", "", "\t", "", "O", "0", "I", "1", "L", "1")
+	code := replacer.Replace(strings.ToUpper(s))
+
+	if len(code) != provisioningCodeLength {
+		return "", fmt.Errorf("invalid provisioning code %q: must be %d characters", s, provisioningCodeLength)
+	}
+	for _, r := range code {
+		if !strings.ContainsRune(provisioningCodeAlphabet, r) {
+			return "", fmt.Errorf("invalid provisioning code %q: invalid character %q", s, r)
+		}
+	}
+
+	return code, nil
+}
+
+// provisioningKey derives the key encrypting the provisioning payload from the code.
+//
+// The code is short so it goes through scrypt to make guessing it from a captured payload expensive.
+func provisioningKey(code string, salt []byte) (secretBoxKey, error) {
+	var key secretBoxKey
+
+	normalized, err := parseProvisioningCode(code)
+	if err != nil {
+		return key, err
+	}
+
+	data, err := scrypt.Key([]byte(normalized), salt, 1<<15, 8, 1, secretBoxKeySize)
+	if err != nil {
+		return key, fmt.Errorf("unable to derive provisioning key. err: %v", err)
+	}
+	copy(key[:], data)
+
+	return key, nil
+}
+
+// sealProvisioningConfig encrypts the client config with a key derived from code.
+func sealProvisioningConfig(conf clientConfig, code string) ([]byte, error) {
+	plaintext, err := json.Marshal(conf)
+	if err != nil {
+		return nil, fmt.Errorf("unable to marshal provisioning config. err: %v", err)
+	}
+
+	payload := provisioningPayload{
+		Salt: make([]byte, provisioningSaltSize),
+	}
+	if _, err := crypto_rand.Read(payload.Salt); err != nil {
+		return nil, fmt.Errorf("unable to read random data. err: %v", err)
+	}
+
+	key, err := provisioningKey(code, payload.Salt)
+	if err != nil {
+		return nil, err
+	}
+	payload.Box = secretBoxSeal(plaintext, key)
+
+	return json.Marshal(payload)
+}
+
+// openProvisioningConfig decrypts a payload created by sealProvisioningConfig.
+func openProvisioningConfig(data []byte, code string) (clientConfig, error) {
+	var conf clientConfig
+
+	var payload provisioningPayload
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return conf, fmt.Errorf("invalid provisioning payload. err: %v", err)
+	}
+	if len(payload.Salt) != provisioningSaltSize {
+		return conf, errors.New("invalid provisioning payload salt")
+	}
+
+	key, err := provisioningKey(code, payload.Salt)
+	if err != nil {
+		return conf, err
+	}
+
+	plaintext, ok := secretBoxOpen(payload.Box, key)
+	if !ok {
+		return conf, errors.New("unable to decrypt the provisioning config, check the provisioning code")
+	}
+
+	if err := json.Unmarshal(plaintext, &conf); err != nil {
+		return conf, fmt.Errorf("invalid provisioning config. err: %v", err)
+	}
+
+	return conf, nil
+}
+
+// encodeCompactConfig encodes the endpoint, the keys and the device name of the client config in a short URI,
+// small enough to fit in a QR code.
+//
+// The keys are the pre-shared key, the encryption key and the seed of the sign private key,
+// concatenated and base64 encoded; the sign public key is derived from the seed.
+// The retired encryption keys are encoded the same way, separately.
+//
+// The client certificate file is not part of it, only whether client certificates are used:
+// the file has to be copied to the new device.
+func encodeCompactConfig(conf clientConfig) string {
+	keys := make([]byte, 0, 3*secretBoxKeySize)
+	keys = append(keys, conf.PSKey[:]...)
+	keys = append(keys, conf.EncryptKey[:]...)
+	keys = append(keys, ed25519.PrivateKey(conf.SignPrivateKey).Seed()...)
+
+	values := url.Values{}
+	values.Set("e", conf.Endpoint)
+	values.Set("k", base64.RawURLEncoding.EncodeToString(keys))
+	if conf.DeviceName != "" {
+		values.Set("d", conf.DeviceName)
+	}
+	if conf.CertFingerprint != "" {
+		values.Set("f", conf.CertFingerprint)
+	}
+	if len(conf.RetiredEncryptKeys) > 0 {
+		retired := make([]byte, 0, len(conf.RetiredEncryptKeys)*secretBoxKeySize)
+		for _, key := range conf.RetiredEncryptKeys {
+			retired = append(retired, key[:]...)
+		}
+		values.Set("r", base64.RawURLEncoding.EncodeToString(retired))
+	}
+	if conf.ClientCert {
+		values.Set("c", "1")
+	}
+
+	return compactConfigPrefix + values.Encode()
+}
+
+// decodeCompactConfig decodes a client config encoded by encodeCompactConfig.
+func decodeCompactConfig(s string) (clientConfig, error) {
+	var conf clientConfig
+
+	if !strings.HasPrefix(s, compactConfigPrefix) {
+		return conf, errors.New("not a compact config")
+	}
+
+	values, err := url.ParseQuery(strings.TrimPrefix(s, compactConfigPrefix))
+	if err != nil {
+		return conf, fmt.Errorf("invalid compact config. err: %v", err)
+	}
+
+	keys, err := base64.RawURLEncoding.DecodeString(values.Get("k"))
+	if err != nil || len(keys) != 3*secretBoxKeySize {
+		return conf, errors.New("invalid compact config keys")
+	}
+
+	var retired []byte
+	if values.Get("r") != "" {
+		retired, err = base64.RawURLEncoding.DecodeString(values.Get("r"))
+		if err != nil || len(retired)%secretBoxKeySize != 0 {
+			return conf, errors.New("invalid compact config retired keys")
+		}
+	}
+
+	switch values.Get("c") {
+	case "":
+	case "1":
+		conf.ClientCert = true
+	default:
+		return conf, errors.New("invalid compact config client certificate")
+	}
+
+	conf.Endpoint = values.Get("e")
+	conf.DeviceName = values.Get("d")
+	conf.CertFingerprint = values.Get("f")
+	for ; len(retired) > 0; retired = retired[secretBoxKeySize:] {
+		var key secretBoxKey
+		copy(key[:], retired)
+		conf.RetiredEncryptKeys = append(conf.RetiredEncryptKeys, key)
+	}
+	copy(conf.PSKey[:], keys[:secretBoxKeySize])
+	copy(conf.EncryptKey[:], keys[secretBoxKeySize:2*secretBoxKeySize])
+
+	priv := ed25519.NewKeyFromSeed(keys[2*secretBoxKeySize:])
+	conf.SignPrivateKey = privateKey(priv)
+	conf.SignPublicKey = publicKey(priv.Public().(ed25519.PublicKey))
+
+	return conf, nil
+}
+
+// provisioningHandler serves the encrypted provisioning payload at a single path, only once
+// and only until it expires.
+//
+// Once served the payload is never served again, even if the client didn't get it entirely:
+// in this case the provisioning has to be started again.
+type provisioningHandler struct {
+	path      string
+	payload   []byte
+	expiresAt time.Time
+	now       func() time.Time
+
+	mu     sync.Mutex
+	served bool
+	done   chan struct{}
+}
+
+func newProvisioningHandler(path string, payload []byte, expiresAt time.Time) *provisioningHandler {
+	return &provisioningHandler{
+		path:      path,
+		payload:   payload,
+		expiresAt: expiresAt,
+		now:       time.Now,
+		done:      make(chan struct{}),
+	}
+}
+
+// Done is closed once the payload has been served.
+func (h *provisioningHandler) Done() <-chan struct{} {
+	return h.done
+}
+
+func (h *provisioningHandler) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	if req.URL.Path != h.path {
+		responseStatusCode(w, http.StatusNotFound)
+		return
+	}
+	if req.Method != http.MethodGet {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	h.mu.Lock()
+	switch {
+	case h.served:
+		h.mu.Unlock()
+		log.Printf("provisioning URL fetched again by %s", req.RemoteAddr)
+		responseStatusCode(w, http.StatusGone)
+		return
+	case h.now().After(h.expiresAt):
+		h.mu.Unlock()
+		responseStatusCode(w, http.StatusGone)
+		return
+	}
+	h.served = true
+	close(h.done)
+	h.mu.Unlock()
+
+	log.Printf("provisioning config fetched by %s", req.RemoteAddr)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusOK)
+	w.Write(h.payload)
+}
+
+// newProvisioningUIHandler returns the handler of the UI showing the keys, served by page at path
+// which must end with a /. Anything outside of path is not found.
+func newProvisioningUIHandler(path string, page http.HandlerFunc) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc(path+"provisioning.css", ui.ServeFile("/provisioning.css"))
+	mux.HandleFunc(path+"provisioning.js", ui.ServeFile("/provisioning.js"))
+	mux.HandleFunc(path, func(w http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != path {
+			responseStatusCode(w, http.StatusNotFound)
+			return
+		}
+		page(w, req)
+	})
+
+	return mux
+}
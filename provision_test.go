@@ -0,0 +1,179 @@
+package main
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestProvisioningCode(t *testing.T) {
+	code := newProvisioningCode()
+	require.Len(t, code, provisioningCodeLength+2)
+
+	normalized, err := parseProvisioningCode(code)
+	require.NoError(t, err)
+	require.Equal(t, strings.Replace(code, "-", "", -1), normalized)
+
+	got, err := parseProvisioningCode(" abcd efgh-jkoi ")
+	require.NoError(t, err)
+	require.Equal(t, "ABCDEFGHJK01", got)
+
+	for _, input := range []string{"", "ABCD-EFGH", "ABCD-EFGH-JKMN-P", "ABCD-EFGH-JKMU"} {
+		_, err := parseProvisioningCode(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestSealProvisioningConfig(t *testing.T) {
+	conf := newEnrollTestConfig(t)
+	code := newProvisioningCode()
+
+	payload, err := sealProvisioningConfig(conf, code)
+	require.NoError(t, err)
+	require.NotContains(t, string(payload), conf.PSKey.String())
+	require.NotContains(t, string(payload), conf.Endpoint)
+
+	got, err := openProvisioningConfig(payload, code)
+	require.NoError(t, err)
+	require.Equal(t, conf, got)
+
+	_, err = openProvisioningConfig(payload, newProvisioningCode())
+	require.Error(t, err)
+}
+
+func TestProvisioningHandler(t *testing.T) {
+	now := time.Now()
+	payload := []byte(`{"salt":"","box":""}`)
+
+	get := func(h http.Handler, method, path string) (int, []byte) {
+		req := httptest.NewRequest(method, path, nil)
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, req)
+
+		data, err := ioutil.ReadAll(w.Result().Body)
+		require.NoError(t, err)
+
+		return w.Code, data
+	}
+	done := func(h *provisioningHandler) bool {
+		select {
+		case <-h.Done():
+			return true
+		default:
+			return false
+		}
+	}
+
+	t.Run("single-use", func(t *testing.T) {
+		h := newProvisioningHandler("/abcd", payload, now.Add(time.Minute))
+
+		code, _ := get(h, http.MethodGet, "/foobar")
+		require.Equal(t, http.StatusNotFound, code)
+		code, _ = get(h, http.MethodPost, "/abcd")
+		require.Equal(t, http.StatusMethodNotAllowed, code)
+		require.False(t, done(h))
+
+		code, data := get(h, http.MethodGet, "/abcd")
+		require.Equal(t, http.StatusOK, code)
+		require.Equal(t, payload, data)
+		require.True(t, done(h))
+
+		code, _ = get(h, http.MethodGet, "/abcd")
+		require.Equal(t, http.StatusGone, code)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		h := newProvisioningHandler("/abcd", payload, now.Add(time.Minute))
+		h.now = func() time.Time { return now.Add(2 * time.Minute) }
+
+		code, _ := get(h, http.MethodGet, "/abcd")
+		require.Equal(t, http.StatusGone, code)
+		require.False(t, done(h))
+	})
+}
+
+func TestProvisioningUIHandler(t *testing.T) {
+	h := newProvisioningUIHandler("/abcd/", func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("keys"))
+	})
+
+	get := func(path string) (int, string) {
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodGet, path, nil))
+		return w.Code, w.Body.String()
+	}
+
+	code, body := get("/abcd/")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "keys", body)
+
+	for _, path := range []string{"/abcd/provisioning.css", "/abcd/provisioning.js"} {
+		code, _ := get(path)
+		require.Equal(t, http.StatusOK, code, path)
+	}
+
+	// The keys are only shown with the random path
+
+	for _, path := range []string{"/", "/abcd/foo", "/provisioning.js", "/abcde/"} {
+		code, body := get(path)
+		require.Equal(t, http.StatusNotFound, code, path)
+		require.NotContains(t, body, "keys", path)
+	}
+}
+
+func TestCompactConfig(t *testing.T) {
+	conf := newEnrollTestConfig(t)
+
+	s := encodeCompactConfig(conf)
+	require.True(t, strings.HasPrefix(s, compactConfigPrefix))
+
+	got, err := decodeCompactConfig(s)
+	require.NoError(t, err)
+	require.NoError(t, checkEnrollConfig(got))
+	require.Equal(t, conf, got)
+
+	// Small enough for a readable QR code
+	q, err := encodeQRCode([]byte(s), qrLevelM)
+	require.NoError(t, err)
+	require.True(t, q.version <= 10, "version %d", q.version)
+
+	t.Run("fingerprint", func(t *testing.T) {
+		conf := conf
+		conf.Endpoint = "https://192.168.1.10:7568"
+		conf.CertFingerprint = certFingerprint([]byte("foobar"))
+
+		got, err := decodeCompactConfig(encodeCompactConfig(conf))
+		require.NoError(t, err)
+		require.NoError(t, checkEnrollConfig(got))
+		require.Equal(t, conf, got)
+	})
+
+	t.Run("retired-keys", func(t *testing.T) {
+		conf := conf
+		conf.Endpoint = "https://192.168.1.10:7568"
+		conf.RetiredEncryptKeys = []secretBoxKey{newSecretBoxKey(), newSecretBoxKey()}
+		conf.ClientCert = true
+
+		got, err := decodeCompactConfig(encodeCompactConfig(conf))
+		require.NoError(t, err)
+		require.NoError(t, checkEnrollConfig(got))
+		require.Equal(t, conf, got)
+	})
+
+	for _, input := range []string{
+		"",
+		"http://192.168.1.10:5000/abcd",
+		s + "&r=abcd",
+		s + "&c=yes",
+		compactConfigPrefix + "e=http%3A%2F%2Fexample.com&k=abcd",
+		strings.Replace(s, "k=", "k=%", 1),
+	} {
+		_, err := decodeCompactConfig(input)
+		require.Error(t, err, input)
+	}
+}
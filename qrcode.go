@@ -0,0 +1,642 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// This file implements a QR code encoder, used to show the provisioning URL and config.
+//
+// It only supports the byte mode which is all we need for URLs and base64 data.
+// See ISO/IEC 18004 or https://www.nayuki.io/page/creating-a-qr-code-step-by-step
+// for a description of the steps.
+
+// qrECLevel is the error correction level of a QR code.
+type qrECLevel int
+
+const (
+	qrLevelL qrECLevel = iota // recovers 7% of the data
+	qrLevelM                  // recovers 15% of the data
+	qrLevelQ                  // recovers 25% of the data
+	qrLevelH                  // recovers 30% of the data
+)
+
+const (
+	qrMinVersion = 1
+	qrMaxVersion = 40
+
+	// qrQuietZone is the number of light modules around the code required by the spec.
+	qrQuietZone = 4
+)
+
+var errQRDataTooLong = errors.New("data too long for a QR code")
+
+var (
+	// qrECCodewordsPerBlock is the number of error correction codewords of each block, by level and version.
+	qrECCodewordsPerBlock = [4][41]int{
+		{-1, 7, 10, 15, 20, 26, 18, 20, 24, 30, 18, 20, 24, 26, 30, 22, 24, 28, 30, 28, 28, 28, 28, 30, 30, 26, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 10, 16, 26, 18, 24, 16, 18, 22, 22, 26, 30, 22, 22, 24, 24, 28, 28, 26, 26, 26, 26, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28, 28},
+		{-1, 13, 22, 18, 26, 18, 24, 18, 22, 20, 24, 28, 26, 24, 20, 30, 24, 28, 28, 26, 30, 28, 30, 30, 30, 30, 28, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+		{-1, 17, 28, 22, 16, 22, 28, 26, 26, 24, 28, 24, 28, 22, 24, 24, 30, 28, 28, 26, 28, 30, 24, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30, 30},
+	}
+	// qrECBlocks is the number of error correction blocks, by level and version.
+	qrECBlocks = [4][41]int{
+		{-1, 1, 1, 1, 1, 1, 2, 2, 2, 2, 4, 4, 4, 4, 4, 6, 6, 6, 6, 7, 8, 8, 9, 9, 10, 12, 12, 12, 13, 14, 15, 16, 17, 18, 19, 19, 20, 21, 22, 24, 25},
+		{-1, 1, 1, 1, 2, 2, 4, 4, 4, 5, 5, 5, 8, 9, 9, 10, 10, 11, 13, 14, 16, 17, 17, 18, 20, 21, 23, 25, 26, 28, 29, 31, 33, 35, 37, 38, 40, 43, 45, 47, 49},
+		{-1, 1, 1, 2, 2, 4, 4, 6, 6, 8, 8, 8, 10, 12, 16, 12, 17, 16, 18, 21, 20, 23, 23, 25, 27, 29, 34, 34, 35, 38, 40, 43, 45, 48, 51, 53, 56, 59, 62, 65, 68},
+		{-1, 1, 1, 2, 4, 4, 4, 5, 6, 8, 8, 11, 11, 16, 16, 18, 16, 19, 21, 25, 25, 25, 34, 30, 32, 35, 37, 40, 42, 45, 48, 51, 54, 57, 60, 63, 66, 70, 74, 77, 81},
+	}
+	// qrFormatLevelBits are the bits of each level in the format information.
+	qrFormatLevelBits = [4]int{1, 0, 3, 2}
+)
+
+// qrCode is an encoded QR code. Modules are indexed by row then column, true is dark.
+type qrCode struct {
+	version int
+	level   qrECLevel
+	mask    int
+	size    int
+	modules [][]bool
+
+	// isFunction marks the modules of the function patterns, they're not masked.
+	isFunction [][]bool
+}
+
+// encodeQRCode encodes data in the smallest QR code with the error correction level.
+func encodeQRCode(data []byte, level qrECLevel) (*qrCode, error) {
+	version := qrMinVersion
+	for ; version <= qrMaxVersion; version++ {
+		if qrDataBits(len(data), version) <= qrDataCodewords(version, level)*8 {
+			break
+		}
+	}
+	if version > qrMaxVersion {
+		return nil, errQRDataTooLong
+	}
+
+	codewords := qrEncodeSegment(data, version, level)
+	codewords = qrAddECAndInterleave(codewords, version, level)
+
+	//
+
+	q := newQRCode(version, level)
+	q.drawFunctionPatterns()
+	q.drawCodewords(codewords)
+
+	bestMask, bestPenalty := 0, -1
+	for mask := 0; mask < 8; mask++ {
+		q.applyMask(mask)
+		q.drawFormatBits(mask)
+		if penalty := q.penalty(); bestPenalty < 0 || penalty < bestPenalty {
+			bestMask, bestPenalty = mask, penalty
+		}
+		q.applyMask(mask) // XOR undoes it
+	}
+
+	q.mask = bestMask
+	q.applyMask(bestMask)
+	q.drawFormatBits(bestMask)
+
+	return q, nil
+}
+
+func newQRCode(version int, level qrECLevel) *qrCode {
+	size := version*4 + 17
+
+	q := &qrCode{
+		version:    version,
+		level:      level,
+		size:       size,
+		modules:    make([][]bool, size),
+		isFunction: make([][]bool, size),
+	}
+	for i := range q.modules {
+		q.modules[i] = make([]bool, size)
+		q.isFunction[i] = make([]bool, size)
+	}
+
+	return q
+}
+
+// qrDataBits returns the number of bits needed to encode n bytes in a single byte mode segment.
+func qrDataBits(n int, version int) int {
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	return 4 + countBits + n*8
+}
+
+// qrRawModules returns the number of modules available for the data and error correction codewords,
+// that is everything but the function patterns and the format and version information.
+func qrRawModules(version int) int {
+	n := (16*version+128)*version + 64
+	if version >= 2 {
+		numAlign := version/7 + 2
+		n -= (25*numAlign-10)*numAlign - 55
+		if version >= 7 {
+			n -= 36
+		}
+	}
+	return n
+}
+
+// qrDataCodewords returns the number of data codewords of a version and level.
+func qrDataCodewords(version int, level qrECLevel) int {
+	return qrRawModules(version)/8 - qrECCodewordsPerBlock[level][version]*qrECBlocks[level][version]
+}
+
+// qrEncodeSegment encodes data as a byte mode segment, padded to the data capacity.
+func qrEncodeSegment(data []byte, version int, level qrECLevel) []byte {
+	var bb qrBitBuffer
+
+	bb.append(0x4, 4)
+	if version >= 10 {
+		bb.append(len(data), 16)
+	} else {
+		bb.append(len(data), 8)
+	}
+	for _, b := range data {
+		bb.append(int(b), 8)
+	}
+
+	// Terminator, then pad to a byte and fill the capacity with alternating bytes
+
+	capacity := qrDataCodewords(version, level) * 8
+
+	terminator := capacity - len(bb)
+	if terminator > 4 {
+		terminator = 4
+	}
+	bb.append(0, terminator)
+	bb.append(0, (8-len(bb)%8)%8)
+	for pad := 0xEC; len(bb) < capacity; pad ^= 0xEC ^ 0x11 {
+		bb.append(pad, 8)
+	}
+
+	return bb.bytes()
+}
+
+// qrAddECAndInterleave splits the data in blocks, adds the error correction codewords to each
+// and interleaves the blocks.
+func qrAddECAndInterleave(data []byte, version int, level qrECLevel) []byte {
+	numBlocks := qrECBlocks[level][version]
+	ecLen := qrECCodewordsPerBlock[level][version]
+	rawCodewords := qrRawModules(version) / 8
+
+	// The last blocks have one more data codeword than the first, short, ones
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	divisor := reedSolomonDivisor(ecLen)
+
+	blocks := make([][]byte, numBlocks)
+	for i, k := 0, 0; i < numBlocks; i++ {
+		n := shortBlockLen - ecLen
+		if i >= numShortBlocks {
+			n++
+		}
+		dat := data[k : k+n]
+		k += n
+
+		block := make([]byte, 0, shortBlockLen+1)
+		block = append(block, dat...)
+		if i < numShortBlocks {
+			// NOTE(vincent): placeholder to make all blocks the same length, skipped when interleaving.
+			block = append(block, 0)
+		}
+		block = append(block, reedSolomonRemainder(dat, divisor)...)
+
+		blocks[i] = block
+	}
+
+	result := make([]byte, 0, rawCodewords)
+	for i := range blocks[0] {
+		for j, block := range blocks {
+			if i != shortBlockLen-ecLen || j >= numShortBlocks {
+				result = append(result, block[i])
+			}
+		}
+	}
+
+	return result
+}
+
+// qrBitBuffer is a sequence of bits, most significant first.
+type qrBitBuffer []bool
+
+func (bb *qrBitBuffer) append(v int, n int) {
+	for i := n - 1; i >= 0; i-- {
+		*bb = append(*bb, (v>>uint(i))&1 != 0)
+	}
+}
+
+func (bb qrBitBuffer) bytes() []byte {
+	result := make([]byte, (len(bb)+7)/8)
+	for i, bit := range bb {
+		if bit {
+			result[i/8] |= 1 << uint(7-i%8)
+		}
+	}
+	return result
+}
+
+//
+// Reed-Solomon in GF(2^8) modulo x^8 + x^4 + x^3 + x^2 + 1
+//
+
+func gfMultiply(x, y byte) byte {
+	var z int
+	for i := 7; i >= 0; i-- {
+		z = (z << 1) ^ ((z >> 7) * 0x11D)
+		z ^= int((y>>uint(i))&1) * int(x)
+	}
+	return byte(z)
+}
+
+// reedSolomonDivisor returns the generator polynomial of the degree, (x - α^0)(x - α^1)...(x - α^(degree-1)),
+// without its leading term and with the coefficients from the highest to the lowest power.
+func reedSolomonDivisor(degree int) []byte {
+	result := make([]byte, degree)
+	result[degree-1] = 1
+
+	root := byte(1)
+	for i := 0; i < degree; i++ {
+		for j := range result {
+			result[j] = gfMultiply(result[j], root)
+			if j+1 < len(result) {
+				result[j] ^= result[j+1]
+			}
+		}
+		root = gfMultiply(root, 0x02)
+	}
+
+	return result
+}
+
+// reedSolomonRemainder returns the error correction codewords of data.
+func reedSolomonRemainder(data []byte, divisor []byte) []byte {
+	result := make([]byte, len(divisor))
+	for _, b := range data {
+		factor := b ^ result[0]
+		copy(result, result[1:])
+		result[len(result)-1] = 0
+		for i, coef := range divisor {
+			result[i] ^= gfMultiply(coef, factor)
+		}
+	}
+	return result
+}
+
+//
+// Drawing
+//
+
+func (q *qrCode) setFunction(x, y int, dark bool) {
+	q.modules[y][x] = dark
+	q.isFunction[y][x] = true
+}
+
+// drawFunctionPatterns draws the finder, timing and alignment patterns
+// and reserves the format information modules.
+func (q *qrCode) drawFunctionPatterns() {
+	for i := 0; i < q.size; i++ {
+		q.setFunction(6, i, i%2 == 0)
+		q.setFunction(i, 6, i%2 == 0)
+	}
+
+	q.drawFinderPattern(3, 3)
+	q.drawFinderPattern(q.size-4, 3)
+	q.drawFinderPattern(3, q.size-4)
+
+	positions := q.alignmentPatternPositions()
+	last := len(positions) - 1
+	for i, x := range positions {
+		for j, y := range positions {
+			// Skip the three corners with a finder pattern
+			if (i == 0 && j == 0) || (i == 0 && j == last) || (i == last && j == 0) {
+				continue
+			}
+			q.drawAlignmentPattern(x, y)
+		}
+	}
+
+	q.drawFormatBits(0)
+	q.drawVersion()
+}
+
+// drawFinderPattern draws a finder pattern and its separator centered on x, y.
+func (q *qrCode) drawFinderPattern(x, y int) {
+	for dy := -4; dy <= 4; dy++ {
+		for dx := -4; dx <= 4; dx++ {
+			xx, yy := x+dx, y+dy
+			if xx < 0 || xx >= q.size || yy < 0 || yy >= q.size {
+				continue
+			}
+			dist := maxInt(absInt(dx), absInt(dy))
+			q.setFunction(xx, yy, dist != 2 && dist != 4)
+		}
+	}
+}
+
+func (q *qrCode) drawAlignmentPattern(x, y int) {
+	for dy := -2; dy <= 2; dy++ {
+		for dx := -2; dx <= 2; dx++ {
+			q.setFunction(x+dx, y+dy, maxInt(absInt(dx), absInt(dy)) != 1)
+		}
+	}
+}
+
+// alignmentPatternPositions returns the coordinates of the centers of the alignment patterns on each axis.
+func (q *qrCode) alignmentPatternPositions() []int {
+	if q.version == 1 {
+		return nil
+	}
+
+	numAlign := q.version/7 + 2
+	step := (q.version*4 + numAlign*2 + 1) / (numAlign*2 - 2) * 2
+	if q.version == 32 {
+		step = 26
+	}
+
+	result := make([]int, numAlign)
+	result[0] = 6
+	for i, pos := numAlign-1, q.size-7; i >= 1; i, pos = i-1, pos-step {
+		result[i] = pos
+	}
+
+	return result
+}
+
+// qrFormatBits returns the 15 bits of the format information: the level and the mask
+// followed by their BCH error correction bits, masked.
+func qrFormatBits(level qrECLevel, mask int) int {
+	data := qrFormatLevelBits[level]<<3 | mask
+
+	rem := data
+	for i := 0; i < 10; i++ {
+		rem = (rem << 1) ^ ((rem >> 9) * 0x537)
+	}
+
+	return (data<<10 | rem) ^ 0x5412
+}
+
+func (q *qrCode) drawFormatBits(mask int) {
+	bits := qrFormatBits(q.level, mask)
+	bit := func(i int) bool { return (bits>>uint(i))&1 != 0 }
+
+	// Around the top left finder pattern
+	for i := 0; i <= 5; i++ {
+		q.setFunction(8, i, bit(i))
+	}
+	q.setFunction(8, 7, bit(6))
+	q.setFunction(8, 8, bit(7))
+	q.setFunction(7, 8, bit(8))
+	for i := 9; i < 15; i++ {
+		q.setFunction(14-i, 8, bit(i))
+	}
+
+	// Split between the other two finder patterns
+	for i := 0; i < 8; i++ {
+		q.setFunction(q.size-1-i, 8, bit(i))
+	}
+	for i := 8; i < 15; i++ {
+		q.setFunction(8, q.size-15+i, bit(i))
+	}
+	q.setFunction(8, q.size-8, true)
+}
+
+// qrVersionBits returns the 18 bits of the version information: the version followed by its BCH error correction bits.
+func qrVersionBits(version int) int {
+	rem := version
+	for i := 0; i < 12; i++ {
+		rem = (rem << 1) ^ ((rem >> 11) * 0x1F25)
+	}
+	return version<<12 | rem
+}
+
+func (q *qrCode) drawVersion() {
+	if q.version < 7 {
+		return
+	}
+
+	bits := qrVersionBits(q.version)
+	for i := 0; i < 18; i++ {
+		dark := (bits>>uint(i))&1 != 0
+		a, b := q.size-11+i%3, i/3
+		q.setFunction(a, b, dark)
+		q.setFunction(b, a, dark)
+	}
+}
+
+// drawCodewords places the codewords in the modules which are not part of a function pattern,
+// in a zigzag of two columns wide from the bottom right corner.
+func (q *qrCode) drawCodewords(data []byte) {
+	i := 0
+	for right := q.size - 1; right >= 1; right -= 2 {
+		// The vertical timing pattern is skipped
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < q.size; vert++ {
+			for j := 0; j < 2; j++ {
+				x := right - j
+				y := vert
+				if (right+1)&2 == 0 {
+					y = q.size - 1 - vert
+				}
+
+				if !q.isFunction[y][x] && i < len(data)*8 {
+					q.modules[y][x] = (data[i/8]>>uint(7-i%8))&1 != 0
+					i++
+				}
+				// NOTE(vincent): the remainder bits, if any, stay light.
+			}
+		}
+	}
+}
+
+// qrMaskBit reports if the mask flips the module at x, y.
+func qrMaskBit(mask, x, y int) bool {
+	switch mask {
+	case 0:
+		return (x+y)%2 == 0
+	case 1:
+		return y%2 == 0
+	case 2:
+		return x%3 == 0
+	case 3:
+		return (x+y)%3 == 0
+	case 4:
+		return (x/3+y/2)%2 == 0
+	case 5:
+		return x*y%2+x*y%3 == 0
+	case 6:
+		return (x*y%2+x*y%3)%2 == 0
+	default:
+		return ((x+y)%2+x*y%3)%2 == 0
+	}
+}
+
+// applyMask flips the data modules selected by the mask. Applying it twice undoes it.
+func (q *qrCode) applyMask(mask int) {
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if !q.isFunction[y][x] && qrMaskBit(mask, x, y) {
+				q.modules[y][x] = !q.modules[y][x]
+			}
+		}
+	}
+}
+
+// penalty scores the patterns which make a code harder to read, the mask with the lowest score is used.
+func (q *qrCode) penalty() int {
+	var result int
+
+	at := func(x, y int, transpose bool) bool {
+		if transpose {
+			return q.modules[x][y]
+		}
+		return q.modules[y][x]
+	}
+
+	finderLike := [2][11]bool{
+		{true, false, true, true, true, false, true, false, false, false, false},
+		{false, false, false, false, true, false, true, true, true, false, true},
+	}
+
+	for _, transpose := range []bool{false, true} {
+		for y := 0; y < q.size; y++ {
+			// Runs of 5 or more modules of the same color
+			run := 1
+			for x := 1; x < q.size; x++ {
+				if at(x, y, transpose) == at(x-1, y, transpose) {
+					run++
+					continue
+				}
+				if run >= 5 {
+					result += 3 + run - 5
+				}
+				run = 1
+			}
+			if run >= 5 {
+				result += 3 + run - 5
+			}
+
+			// Patterns looking like a finder pattern
+			for x := 0; x+11 <= q.size; x++ {
+				for _, pattern := range finderLike {
+					match := true
+					for k, dark := range pattern {
+						if at(x+k, y, transpose) != dark {
+							match = false
+							break
+						}
+					}
+					if match {
+						result += 40
+					}
+				}
+			}
+		}
+	}
+
+	// 2x2 blocks of the same color and balance of dark and light modules
+
+	var dark int
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				dark++
+			}
+			if x+1 < q.size && y+1 < q.size {
+				c := q.modules[y][x]
+				if c == q.modules[y][x+1] && c == q.modules[y+1][x] && c == q.modules[y+1][x+1] {
+					result += 3
+				}
+			}
+		}
+	}
+
+	total := q.size * q.size
+	percent := dark * 100 / total
+	result += absInt(percent-50) / 5 * 10
+
+	return result
+}
+
+//
+// Rendering
+//
+
+// dark reports if the module at x, y is dark, the quiet zone around the code is light.
+func (q *qrCode) dark(x, y int) bool {
+	if x < 0 || y < 0 || x >= q.size || y >= q.size {
+		return false
+	}
+	return q.modules[y][x]
+}
+
+// SVG renders the code as an SVG image, scaled to fit its container.
+func (q *qrCode) SVG() string {
+	dim := q.size + qrQuietZone*2
+
+	var path strings.Builder
+	for y := 0; y < q.size; y++ {
+		for x := 0; x < q.size; x++ {
+			if q.modules[y][x] {
+				fmt.Fprintf(&path, "M%d,%dh1v1h-1z", x+qrQuietZone, y+qrQuietZone)
+			}
+		}
+	}
+
+	return fmt.Sprintf(`<svg xmlns="http://www.w3.org/2000/svg" viewBox="0 0 %d %d" shape-rendering="crispEdges">`+
+		`<rect width="100%%" height="100%%" fill="#fff"/><path d="%s" fill="#000"/></svg>`,
+		dim, dim, path.String())
+}
+
+// WriteTerminal renders the code with Unicode half blocks, two rows of modules per line.
+//
+// The colors are set explicitly with ANSI escape codes so that the code is dark on light
+// whatever the colors of the terminal are.
+func (q *qrCode) WriteTerminal(w io.Writer) error {
+	const (
+		reset = "\x1b[0m"
+		upper = "▀"
+	)
+
+	var buf strings.Builder
+	for y := -qrQuietZone; y < q.size+qrQuietZone; y += 2 {
+		for x := -qrQuietZone; x < q.size+qrQuietZone; x++ {
+			// The foreground draws the upper module, the background the lower one
+			fg, bg := "97", "107"
+			if q.dark(x, y) {
+				fg = "30"
+			}
+			if q.dark(x, y+1) {
+				bg = "40"
+			}
+			fmt.Fprintf(&buf, "\x1b[%s;%sm%s", fg, bg, upper)
+		}
+		buf.WriteString(reset + "\n")
+	}
+
+	_, err := io.WriteString(w, buf.String())
+	return err
+}
+
+func absInt(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
+
+func maxInt(a, b int) int {
+	if a > b {
+		return a
+	}
+	return b
+}
@@ -0,0 +1,258 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+// decodeQRCode decodes the modules of a QR code encoded by encodeQRCode.
+//
+// It checks the function patterns, the format information and the error correction codewords
+// but doesn't correct errors: it's only meant to verify the encoder output.
+func decodeQRCode(modules [][]bool) ([]byte, error) {
+	size := len(modules)
+	if size < 21 || (size-17)%4 != 0 {
+		return nil, fmt.Errorf("invalid size %d", size)
+	}
+	version := (size - 17) / 4
+
+	// Format information, next to the top left finder pattern
+
+	var format int
+	bit := func(x, y int, i uint) {
+		if modules[y][x] {
+			format |= 1 << i
+		}
+	}
+	for i := 0; i <= 5; i++ {
+		bit(8, i, uint(i))
+	}
+	bit(8, 7, 6)
+	bit(8, 8, 7)
+	bit(7, 8, 8)
+	for i := 9; i < 15; i++ {
+		bit(14-i, 8, uint(i))
+	}
+
+	level, mask := qrECLevel(-1), (format^0x5412)>>10&0x7
+	for l, bits := range qrFormatLevelBits {
+		if bits == (format^0x5412)>>13 {
+			level = qrECLevel(l)
+		}
+	}
+	if level < 0 || qrFormatBits(level, mask) != format {
+		return nil, fmt.Errorf("invalid format information %015b", format)
+	}
+
+	// Function patterns
+
+	q := newQRCode(version, level)
+	q.drawFunctionPatterns()
+	q.drawFormatBits(mask)
+	for y := 0; y < size; y++ {
+		for x := 0; x < size; x++ {
+			if q.isFunction[y][x] && q.modules[y][x] != modules[y][x] {
+				return nil, fmt.Errorf("invalid function pattern module at %d,%d", x, y)
+			}
+		}
+	}
+
+	// Codewords, read in the same zigzag as they're drawn
+
+	var bb qrBitBuffer
+	for right := size - 1; right >= 1; right -= 2 {
+		if right == 6 {
+			right = 5
+		}
+		for vert := 0; vert < size; vert++ {
+			for j := 0; j < 2; j++ {
+				x, y := right-j, vert
+				if (right+1)&2 == 0 {
+					y = size - 1 - vert
+				}
+				if !q.isFunction[y][x] {
+					bb = append(bb, modules[y][x] != qrMaskBit(mask, x, y))
+				}
+			}
+		}
+	}
+	codewords := bb[:len(bb)/8*8].bytes()
+
+	// Deinterleave and check the error correction codewords: the syndromes of a valid block are zero
+
+	numBlocks := qrECBlocks[level][version]
+	ecLen := qrECCodewordsPerBlock[level][version]
+	rawCodewords := qrRawModules(version) / 8
+	numShortBlocks := numBlocks - rawCodewords%numBlocks
+	shortBlockLen := rawCodewords / numBlocks
+
+	if len(codewords) != rawCodewords {
+		return nil, fmt.Errorf("got %d codewords, expected %d", len(codewords), rawCodewords)
+	}
+
+	blocks := make([][]byte, numBlocks)
+	for i := range blocks {
+		blocks[i] = make([]byte, shortBlockLen+1)
+	}
+	k := 0
+	for i := 0; i <= shortBlockLen; i++ {
+		for j := range blocks {
+			if i != shortBlockLen-ecLen || j >= numShortBlocks {
+				blocks[j][i] = codewords[k]
+				k++
+			}
+		}
+	}
+
+	var data []byte
+	for j, block := range blocks {
+		dataLen := shortBlockLen - ecLen + 1
+		if j < numShortBlocks {
+			block = append(block[:dataLen-1:dataLen-1], block[dataLen:]...)
+			dataLen--
+		}
+
+		root := byte(1)
+		for i := 0; i < ecLen; i++ {
+			var syndrome byte
+			for _, b := range block {
+				syndrome = gfMultiply(syndrome, root) ^ b
+			}
+			if syndrome != 0 {
+				return nil, fmt.Errorf("invalid error correction codewords in block %d", j)
+			}
+			root = gfMultiply(root, 0x02)
+		}
+
+		data = append(data, block[:dataLen]...)
+	}
+
+	// Byte mode segment
+
+	bits := make(qrBitBuffer, 0, len(data)*8)
+	for _, b := range data {
+		bits.append(int(b), 8)
+	}
+	read := func(n int) int {
+		var v int
+		for i := 0; i < n; i++ {
+			v <<= 1
+			if bits[i] {
+				v |= 1
+			}
+		}
+		bits = bits[n:]
+		return v
+	}
+
+	if mode := read(4); mode != 0x4 {
+		return nil, fmt.Errorf("invalid mode %x", mode)
+	}
+	countBits := 8
+	if version >= 10 {
+		countBits = 16
+	}
+	n := read(countBits)
+	if n*8 > len(bits) {
+		return nil, fmt.Errorf("invalid length %d", n)
+	}
+
+	result := make([]byte, n)
+	for i := range result {
+		result[i] = byte(read(8))
+	}
+
+	return result, nil
+}
+
+func TestQRCode(t *testing.T) {
+	levels := []qrECLevel{qrLevelL, qrLevelM, qrLevelQ, qrLevelH}
+
+	for _, n := range []int{0, 1, 16, 17, 100, 250, 500, 1000, 1273} {
+		for _, level := range levels {
+			data := bytes.Repeat([]byte("apero://config?e=https%3A%2F%2Fexample.com"), n/40+1)[:n]
+
+			t.Run(fmt.Sprintf("%d-%d", n, level), func(t *testing.T) {
+				q, err := encodeQRCode(data, level)
+				require.NoError(t, err)
+				require.Equal(t, q.version*4+17, q.size)
+
+				// Smallest version
+				require.True(t, qrDataBits(n, q.version) <= qrDataCodewords(q.version, level)*8)
+				if q.version > 1 {
+					require.True(t, qrDataBits(n, q.version-1) > qrDataCodewords(q.version-1, level)*8)
+				}
+
+				got, err := decodeQRCode(q.modules)
+				require.NoError(t, err)
+				require.Equal(t, data, got)
+			})
+		}
+	}
+
+	t.Run("corrupted", func(t *testing.T) {
+		q, err := encodeQRCode([]byte("hello"), qrLevelM)
+		require.NoError(t, err)
+
+		q.modules[q.size-1][q.size-1] = !q.modules[q.size-1][q.size-1]
+
+		_, err = decodeQRCode(q.modules)
+		require.Error(t, err)
+	})
+
+	t.Run("too-long", func(t *testing.T) {
+		_, err := encodeQRCode(make([]byte, 3000), qrLevelL)
+		require.Equal(t, errQRDataTooLong, err)
+	})
+}
+
+func TestQRCodeTables(t *testing.T) {
+	// Data capacities from the spec
+	testCases := []struct {
+		version   int
+		level     qrECLevel
+		codewords int
+	}{
+		{1, qrLevelL, 19},
+		{1, qrLevelM, 16},
+		{1, qrLevelQ, 13},
+		{1, qrLevelH, 9},
+		{10, qrLevelM, 216},
+		{40, qrLevelL, 2956},
+		{40, qrLevelM, 2334},
+		{40, qrLevelQ, 1666},
+		{40, qrLevelH, 1276},
+	}
+	for _, tc := range testCases {
+		require.Equal(t, tc.codewords, qrDataCodewords(tc.version, tc.level), "version %d level %d", tc.version, tc.level)
+	}
+
+	require.Equal(t, 0x77C4, qrFormatBits(qrLevelL, 0))
+	require.Equal(t, 0x5412, qrFormatBits(qrLevelM, 0))
+	require.Equal(t, 0x07C94, qrVersionBits(7))
+	require.Equal(t, 0x28C69, qrVersionBits(40))
+}
+
+func TestQRCodeRendering(t *testing.T) {
+	q, err := encodeQRCode([]byte("hello"), qrLevelM)
+	require.NoError(t, err)
+
+	dim := q.size + 2*qrQuietZone
+
+	svg := q.SVG()
+	require.True(t, strings.HasPrefix(svg, "<svg "))
+	require.Contains(t, svg, fmt.Sprintf(`viewBox="0 0 %d %d"`, dim, dim))
+
+	var buf bytes.Buffer
+	require.NoError(t, q.WriteTerminal(&buf))
+
+	lines := strings.Split(strings.TrimSuffix(buf.String(), "\n"), "\n")
+	require.Len(t, lines, (dim+1)/2)
+	for _, line := range lines {
+		require.Equal(t, dim, strings.Count(line, "▀"))
+	}
+}
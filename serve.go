@@ -0,0 +1,139 @@
+package main
+
+import (
+	"context"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"syscall"
+	"time"
+)
+
+// defaultShutdownTimeout is how long the server waits for the requests in progress when it stops.
+const defaultShutdownTimeout = 30 * time.Second
+
+// serveUntilSignal serves on ln until SIGINT or SIGTERM is received on signals and SIGHUP calls reload.
+//
+// On SIGINT or SIGTERM the server stops accepting connections and waits up to shutdownTimeout
+// for the requests in progress, the connections still open after that are closed.
+// The event streams and the long polls are ended right away since they'd always reach the deadline.
+//
+// The server uses TLS if srv.TLSConfig is set.
+func serveUntilSignal(srv *http.Server, ln net.Listener, signals <-chan os.Signal, shutdownTimeout time.Duration, reload func() error) error {
+	// NOTE(vincent): the context of every request derives from this one,
+	// the long-lived requests end when it's canceled.
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	srv.BaseContext = func(net.Listener) context.Context { return ctx }
+	srv.RegisterOnShutdown(cancel)
+
+	errCh := make(chan error, 1)
+	go func() {
+		if srv.TLSConfig != nil {
+			errCh <- srv.ServeTLS(ln, "", "")
+		} else {
+			errCh <- srv.Serve(ln)
+		}
+	}()
+
+	for {
+		select {
+		case err := <-errCh:
+			return err
+
+		case sig := <-signals:
+			if sig == syscall.SIGHUP {
+				if err := reload(); err != nil {
+					log.Printf("unable to reload the config, keeping the current one. err: %v", err)
+				} else {
+					log.Printf("config reloaded")
+				}
+				continue
+			}
+
+			log.Printf("received %s, shutting down", sig)
+
+			shutdownCtx, cancelShutdown := context.WithTimeout(context.Background(), shutdownTimeout)
+			err := srv.Shutdown(shutdownCtx)
+			cancelShutdown()
+
+			if err != nil {
+				log.Printf("requests still in progress after %s, closing their connections", shutdownTimeout)
+				srv.Close()
+			}
+
+			// NOTE(vincent): Serve returns http.ErrServerClosed as soon as Shutdown is called.
+			<-errCh
+
+			return nil
+		}
+	}
+}
+
+// logRequests logs every request handled by h once it's done.
+//
+// Unlike the logging middleware of hutil the response writer is still a http.Flusher,
+// which event streams need.
+func logRequests(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w, rec := newStatusRecorder(w)
+		defer func(start time.Time) {
+			log.Printf("[%3d] %s %d %s", rec.status(), req.URL.Path, rec.size, time.Since(start))
+		}(time.Now())
+
+		h.ServeHTTP(w, req)
+	})
+}
+
+// statusRecorder remembers the status code and the size of a response.
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+	size int
+}
+
+// newStatusRecorder wraps w, the recorder is a http.Flusher if w is one since event streams need it.
+func newStatusRecorder(w http.ResponseWriter) (http.ResponseWriter, *statusRecorder) {
+	rec := &statusRecorder{ResponseWriter: w}
+	if _, ok := w.(http.Flusher); ok {
+		return flushingStatusRecorder{rec}, rec
+	}
+	return rec, rec
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(p []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	n, err := r.ResponseWriter.Write(p)
+	r.size += n
+	return n, err
+}
+
+// status returns the status code of the response, 200 if nothing was written.
+func (r *statusRecorder) status() int {
+	if r.code == 0 {
+		return http.StatusOK
+	}
+	return r.code
+}
+
+type flushingStatusRecorder struct {
+	*statusRecorder
+}
+
+func (r flushingStatusRecorder) Flush() {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	r.ResponseWriter.(http.Flusher).Flush()
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestServeUntilSignal(t *testing.T) {
+	var (
+		slowStarted = make(chan struct{})
+		slowRelease = make(chan struct{})
+		pollStarted = make(chan struct{})
+	)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/slow", func(w http.ResponseWriter, req *http.Request) {
+		close(slowStarted)
+		<-slowRelease
+		w.Write([]byte("done"))
+	})
+	mux.HandleFunc("/poll", func(w http.ResponseWriter, req *http.Request) {
+		close(pollStarted)
+		<-req.Context().Done()
+		w.WriteHeader(http.StatusNoContent)
+	})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	url := "http://" + ln.Addr().String()
+
+	signals := make(chan os.Signal)
+	reloads := make(chan struct{}, 1)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serveUntilSignal(&http.Server{Handler: mux}, ln, signals, 10*time.Second, func() error {
+			reloads <- struct{}{}
+			return nil
+		})
+	}()
+
+	// SIGHUP reloads the config without stopping
+
+	signals <- syscall.SIGHUP
+	<-reloads
+
+	// Requests in progress are drained, long polls are ended
+
+	type result struct {
+		body string
+		err  error
+	}
+	get := func(path string) <-chan result {
+		ch := make(chan result, 1)
+		go func() {
+			resp, err := http.Get(url + path)
+			if err != nil {
+				ch <- result{err: err}
+				return
+			}
+			defer resp.Body.Close()
+
+			data, err := ioutil.ReadAll(resp.Body)
+			ch <- result{string(data), err}
+		}()
+		return ch
+	}
+
+	slow := get("/slow")
+	<-slowStarted
+	poll := get("/poll")
+	<-pollStarted
+
+	signals <- syscall.SIGTERM
+
+	res := <-poll
+	require.NoError(t, res.err)
+
+	select {
+	case err := <-errCh:
+		t.Fatalf("the server stopped with a request in progress, err: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(slowRelease)
+
+	res = <-slow
+	require.NoError(t, res.err)
+	require.Equal(t, "done", res.body)
+
+	require.NoError(t, <-errCh)
+
+	// No more connections
+
+	_, err = http.Get(url + "/slow")
+	require.Error(t, err)
+}
+
+func TestServeUntilSignalTimeout(t *testing.T) {
+	blocked := make(chan struct{})
+	defer close(blocked)
+
+	started := make(chan struct{})
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	srv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		close(started)
+		<-blocked
+	})}
+
+	signals := make(chan os.Signal)
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- serveUntilSignal(srv, ln, signals, 50*time.Millisecond, func() error { return nil })
+	}()
+
+	respErr := make(chan error, 1)
+	go func() {
+		resp, err := http.Get("http://" + ln.Addr().String())
+		if err == nil {
+			resp.Body.Close()
+		}
+		respErr <- err
+	}()
+	<-started
+
+	signals <- syscall.SIGINT
+
+	require.NoError(t, <-errCh)
+	require.Error(t, <-respErr)
+}
+
+func TestLogRequests(t *testing.T) {
+	h := logRequests(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// Event streams need to flush
+
+		flusher, ok := w.(http.Flusher)
+		require.True(t, ok)
+
+		w.Write([]byte("foo"))
+		flusher.Flush()
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/api/v2/subscribe", nil))
+
+	require.True(t, rec.Flushed)
+	require.Equal(t, "foo", rec.Body.String())
+}
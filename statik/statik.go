@@ -8,6 +8,6 @@ import (
 )
 
 func init() {
-	data := "PK\x03\x04\x14\x00\x08\x00\x08\x00\x0b\xaaSO\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0b\x00	\x00layout.htmlUT\x05\x00\x01&}\xab]TP\xcdN\xc30\x0c\xbe\xe7)\x8c\xcft\x85\x1b\x87\xa4\xd24\xe0\n\x87M\x82c\x96xK\xb44)\x8d\xb7i\x8a\xfa>\xf0\x1c{1\xb4\x16\xd08Y\xf6\xf7c\xfb+\x05,m|$\xc0\xa0Oi\xcf\x08\xc3 \x84\xbcy|Y,\xdf_\x9f\xc0q\x1b\x1a!/\x05\x82\x8e[\x85\x14\xb1\x11B:\xd2\xb6\x11\x00\x00\xb2%\xd6`\x9c\xee3\xb1\xc2\xd5\xf2\xb9z\xc0k(\xea\x96\x14\x1e<\x1d\xbb\xd43\x82I\x91)\xb2\xc2\xa3\xb7\xec\x94\xa5\x837T\x8d\xcd-\xf8\xe8\xd9\xebPe\xa3\x03\xa9\xfb\xd9\xdd?+\xc7\xdcU\xf4\xb1\xf7\x07\x85o\xd5j^-R\xdbi\xf6\xeb@W\xbe\x9e\x14\xd9-\xfd*\xd9s\xa0f\xde\x9d\xbf\xfa\x04\xc4`\xb4\xd1n\x7f\xfed\xca\xb2\x9e@11\x83\x8f;\xe8)(\xcc|\n\x94\x1d\x11#\xb8\x9e6\n\xebq439c#d=\xfd/\xe4:\xd9\xd3\xb4\xa7\x14X\x87dv\x80?\x87 \xcc`\x18J\x01\x8a\xf6\x12\xab\xac'\xf2E=\xc6*\xfe\xb0\xef\x00\x00\x00\xff\xffPK\x07\x08\xf2\xa1\x0bj\x0b\x01\x00\x00\x88\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00tvpO\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1e\x00	\x00partials/provisioning-key.htmlUT\x05\x00\x01\xfc\x0c\xd0]\xa4\x90\xc1j\x850\x10E\xf7\xef+.\xc1\xc2{P\x9f\xfb\xa2\xfe\xcbh\xc6\x1a\xaaSITZ\x86\xfc{I\xa8mi\xddu\x17\xee!\xe72W\x15\x96\x07'\x0c3\xf2\xdb\x0b\xbf\x1b\xc4x\xa9\xad\xdb\xd1O\x14B\xf3\x19\x97\x96V2\xedE\x15\x9e\xe4\x99Q\xb8G\x14\x1d\x9e\x1a\\\xfd&\x1cp\xbf\xa5\x9f\xaa%\xdc\x80\xab\x0b4-#\xa1\xe8n(\x7f\x1931\xad*\x16\xefd\x1d`\x1ez\x93d1\xd6a!I\x84\xac}\x95\xd4\x92\xc2*\xa7ue\xdd\xde\xe6\x06\x9e\x02\xff\xd1\xca6w\xec\xff\xe7\x15{\x1c\x91\x9e\xb9\xe1\xa0\xdf\xf0k\xb1y>\x1b,\xa7?\xf6\xba\xe3D\xf3\x11\x00\x00\xff\xffPK\x07\x08\xea^\x06\x85\xb1\x00\x00\x00x\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xe7\xa5yO\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00	\x00provisioning.cssUT\x05\x00\x01\xd2=\xdc]\xacU\xdbn\xe3 \x10}\xf7W\x8cZUj$\x139Nzs\xa5\xfd\x91\xaa\x0f\xd8Ll6\x18\x10\xe0$\xdeU\xff}\x05\xbe\xc4N\xd2joO	\x03s\xe6p\xe60\xae\\-\xe2(W\xac\x85\x9f\x11\x80\xa6\x8cqYf\x90\xbcF\x0055%\x97\xb3\x05\x11\xb8u\x19\xd0\xc6\xa9I\xd0\xf0\xb2\x9aD\x0f\x9c\xb9*\x83\x97\xe4\xee5\xfa\x88N\xe8[%\x1d\xd9\xd2\x9a\x8b6\x03K\xa5%\x16\x0d\xdf\xfa\x14\xc1%\x92\n;\x9c\xd5\xf2\xf1u8n\xf9\x0f\xf4\x91'\xac}\xacPB\x99\x0cn7\x9bM\xc0\xaeVqT\xa5qT\xadC\x853\x984\x9cYVH\x19\x9ap\x80q\xab\x05m3(\x0dg\x1e\xd0\xff\x12\x87\xb5\x16\xd4!)\x94hji30\xa8\x91\xba\xfb4\x867\xc1\xe5\xee\x1d\xd2$\xd1\xc7\x85\xcf\xc8i\xb1+\x8dj$#\x03\x9b\xf5z=\xab\xf4\x0dh\xa860yH\xf4\xd1\xa7:<:B\x05/e\x06\x05J\x87fr\xa7C\xc5\x1d~U\xa0\xcfgX(C\x1dW2\x03\xa9$\x9eW^\xd2\xc2\xf1=\x06\x02'\xa8,hC\x0d)\x0de\x1c\xa5\xbb_='\x0c\xcb8\x80\xc3\xcb\xe6.\x06e\xa8,\x11\x1e\xef\x16\x1dfc\x84\xbd\xa6\xda\x85l\xd4 \xb5Y\x04\x00p\xa3\x8d\xdas\xcb\x95\xe4\xb2\x84\xe9\x824F\xdctgP2\xad\xb8t0\xfc	{\x01\xb8wT\xae\x9cSu\x06i\xd7\xf6>\xea\x94\x1eC\xdf\x1b\xeb\xf8\xb6%\x85\x92\x0e\xa5\xcb\xc0jZ \xc1=J\xd1\x06\xfe\xb7~\x8br\x89\x86Tx\xdca{\xf52_\xde\xc5\xee\xb0\x05\x94\x85i\xb5\x17|\x87m\x7f\x03\xdd\xe4\x82\x17~W\x1b\xbe\xa7\x0e\xfdV\xdf\xcc\xa6\x96\xa4\xa4:\x83U\xc7\xd4\xa8\xc3d='V\xd7\x7f\xcdk\xd4\xf2\x0bv\xc3\xfa\x0f9N\xe4\xe5\x0ek{r\xab\xb7E\xe0|\x9dr\xf0\xf6\x90c\x1d5\xee\x14=5j\x88\x8fsb\x7f\x18L|\xfc/\xd0\x13\xac*\x8d\xa1g\\\xa5\x01\xf9\xda#\xbc\xfe\xea\x10q\xf2>sA\x8b\xdd5\x8f\x86\xf1\xd8O\xce\xce\xa2\xc9r\xf5\xd0\xe9:\x84\xc7\xb3\xe3\xce\xd4\xd2\xc9DX\xc2\xa8\xeb\xa6G\xae\x0c\xc3\xa10\xa4\xfa\x08V\x89N\x8c\xd9$\xad\x95T\xc1\xfc\x17C\xf3\xb9o\xe6A\x19F\x0e\xc6\xf7W*SSq>\x8c\xfan\x87\xc3\xe37`\x15\xa6\xd6I\xcb\x8eY&]E\x8a\x8a\x0bv\xef\xdf\xda\xe2l\xce\xcc\xc5\xfb\x98\xe7\xfe\xce,\xb9\x18\xc1O1\xbc1^r\xf7\x0e\xab\xadYt\xcd\xfaGi\xd2\xfe%\xce\xd81\xbe\xff\xd4 \xa3Y\xd7\xfd(\x1fUZ_\x13\xc9j*\xe7\xb7\xcd\x85\xf2\xee\xf9\x0c}\xc2-Y\xae\x9fg\x1f\xbb\xd2\xd0\xf6\xb2\xc4R6u\xde\x7f\xd5F\x876A\xf3_\x01\x00\x00\xff\xffPK\x07\x08\xba\xfa\xc2\xc6\xbc\x02\x00\x00\xd5\x07\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xd0\xa5yO\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x11\x00	\x00provisioning.htmlUT\x05\x00\x01\xa9=\xdc]\xbc\x95\xdfn\xd30\x14\xc6\xef\xfb\x14\x07s\x03\x12i`\xdb\x05\x02;\xd24\x86&1D\xb5\xad\x12\\z\xf1Y}\x98c\x07\xdb\xcb\x1aE} \x9e\x83\x17Cn\xd2\xb5\xec\x8f\xe84iWu\xf5\xf9\xfb~\xc7\xee\xd7\xa4\xeb@\xe1\x05Y\x04\xe6\x9d\x8b\x0c\x16\x8b\x11\x7f\xf1\xe9\xdb\xc1\xd9\x8f\xc9!\xe8X\x99b\xc4\xd3\x07\x18ig\x82\xa1e\xc5\x08\x80k\x94*-\x00x\x85QB\xa9\xa5\x0f\x18\x05\x9b\x9e}\xce\xde3\xc87E++\x14\xac!\xbc\xae\x9d\x8f\x0cJg#\xda(\xd85\xa9\xa8\x85\xc2\x86J\xcc\x96_\xde\x00Y\x8a$M\x16JiP\xbc\x1b\xbf\xbd\x15\xa6c\xac3\xfcuE\x8d`\xdf\xb3\xe9~v\xe0\xaaZF:7\xb8\x91L(P\xcdp\xed\x8d\x14\x0d\x16\xfb\xf5\x9f\xdf\xdeA\xed]C\x81\x9c%;\xe3y/\x8d\xfa}\x86\xec%x4\x82\x85\xd8\x1a\x0c\x1a12\xd0\x1e/\x04\xdb\xb4\x8d\xcb\x10\x86t\x9e\xafn\x83\x9f;\xd5\x0e@E\x0d\x94F\x86 X\x92\xd1\xb3^\x00\xe0r\xc8{YY\xac\x9c\xa5\x92\xadv\xca2R\x83\xac\xf8:\x08<\x97w]\x1a\xe7\xac8\xc2\xf9\x8d\xc8sE\xcd\xea\x00\x1b\xdc+o\xc2\x9a\xaaw\x8b\xc9\xc6\x01`zr\xccs\xbd\xbb\xd6\xf7\x8a\xae\x83\xf1\xe6\x9e\xe9\xc91,\x16<\xd7{Cz\x1fshU\xed\xc8\xc6\xfb\xec+\xed\xc6w\xef\x80\xa4\x04K\xbf\x95$\x8b>\xd38\xbf\xc460X^\xb9`\x8aBmd\xfb\x01\xac\xb3\xf8q}\x82\x7f\xae4Yn\xa4\xc4\xdf)&\x1e\xb3\xa0\xa5G\x05\x97\xd8\xf2\\\xef\xac\xf5\xae\x83\x88UmdDX\xb9\xe1\xd5\xf8\x08\xe7\xe3\xc9\xe9\x17l_\xa7\xe6\x0fQ\xfd\xb4[B\x0fm\xe9\xdb:\x92\xb3\x8f\x82\x0e\xb6\xa7\x90Oif\xa1\xbe:7T>\n\x9d|\x93\xa5\xed\xe9tOM\"<\x1a\xdf\xfb\x1e\xe4\xff\xa70U\xb5\xec\xcb\xd6\xb3n]\x8cep\x1at\xf5\x07|\xaer\xdc\x05?kA\xee\xe2\x9f\xbd$\x0f\x8c\xb0]Q\xd2\xa2\x7f\xf6\xa6e(=\xd5\x11\x82/o=\xb3\x7f\x06V\xf0\xbc\x97\x8b\x11\xcf\xfb\xf7[\xd7\x01Z\x95\xe2\xff\x06\x00\x00\xff\xffPK\x07\x08\xa1[\xe9\x85#\x02\x00\x00\x0e\x07\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00XypO\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0f\x00	\x00provisioning.jsUT\x05\x00\x01x\x11\xd0]\x94\x8f\xcdJ\xfc0\x14G\xf7}\x8aK\xfe\x8bi\xa1\xff>\x80C\x05\x85\x01\x05\xbbr).b\xfa\xd3\x86\xc9\x87&\xb7\xa5E|wIf\x06\x1c?p\xdc\x84@\xce\xc9=\xd7\x80Iv\x96Z\xea\xbd\x1a-\x1c7/#\xc2r\x0b\x03\xc5>\x94B\xde\x0d\x01\x8f\xed\xea\x9fu\xb0\xdei\xb5\xba\x17U\x91\xbd+\xcc\xa7\x88\x03\xe6\xecdI\x1d\x0f{\x02o\x0c\xd2\xf5r\xb9\xeeK\xa1\xbcc\xa9\x1d\xc2\x7fk\xb7X\xe2~\x94\xfa4\xeagm\xc0\xbc\xf7\n\xd9\xd9F\xf6\xfdf\x82\xe3\x1b\x1d\x19\x0e\xa1\x14\xcah\xb5\x155\x9505a\xaa\xa8=\xa7\xd7\x82\x88\xf2>M\x80\xf5\x13.\x98\x83~\x18\x19\x89\x971\x8aj\xbdC:\xdbD\xf0\x97\xe7\x9a\x84T\xac'\x1c\xc0|\xa4\xea&\xf2b@-\x89^\xc7g#\x973r\xdeA\xec0\x95\xfe;\x00b]\xbc\xa5\xecd\xfd\xa9\xbb\xb3\xbfe\xe7\x90\xd3\xbb?V}\x9b}\xb4X\xee~\x0f\x00\x00\xff\xffPK\x07\x08\x9fI\xaea\xfa\x00\x00\x00I\x02\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x0b\xaaSO\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00	\x00	\x00style.cssUT\x05\x00\x01&}\xab]\\\x8eM\n\x830\x10F\xf7s\x8a\x0f\xba\xd5bl(%\x9e&\xad\xd1\x0c\xc4D\x92\x14\xb4\xc5\xbb\x97\x08\xa5?\xcb\xf9x\xf3x6O\xae\xa2k\xe8W<	\x98u\xdf\xb3\x1f\x15\x9a\x8e\x80I\xc7\x91\xfd~l\xf4\x81\x86\xe0s=\xe8\x89\xdd\xaa\x90\xb4Ou2\x91\x87\xef\x0f\xd9\xcc\x0b\xf4=\x872:\xf6\xa6\xb6\x86G\x9b\x15\xc4\xf1\xdc\xbd\x1d\x89\x1fFA\\\xe6\xa5L\xb7\xe0BT8H)\xbb\x9f\x14\x88\xa6\x10\x1b\x91\x15\x15\xd9\xb6\"{\xdaC\xfe\xc4ma^\x01\x00\x00\xff\xffPK\x07\x08}\xc3\xc6\xce\x8f\x00\x00\x00\xd1\x00\x00\x00PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x0b\xaaSO\xf2\xa1\x0bj\x0b\x01\x00\x00\x88\x01\x00\x00\x0b\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x81\x00\x00\x00\x00layout.htmlUT\x05\x00\x01&}\xab]PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00tvpO\xea^\x06\x85\xb1\x00\x00\x00x\x01\x00\x00\x1e\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81M\x01\x00\x00partials/provisioning-key.htmlUT\x05\x00\x01\xfc\x0c\xd0]PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xe7\xa5yO\xba\xfa\xc2\xc6\xbc\x02\x00\x00\xd5\x07\x00\x00\x10\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81S\x02\x00\x00provisioning.cssUT\x05\x00\x01\xd2=\xdc]PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xd0\xa5yO\xa1[\xe9\x85#\x02\x00\x00\x0e\x07\x00\x00\x11\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81V\x05\x00\x00provisioning.htmlUT\x05\x00\x01\xa9=\xdc]PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00XypO\x9fI\xaea\xfa\x00\x00\x00I\x02\x00\x00\x0f\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xc1\x07\x00\x00provisioning.jsUT\x05\x00\x01x\x11\xd0]PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x0b\xaaSO}\xc3\xc6\xce\x8f\x00\x00\x00\xd1\x00\x00\x00	\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\x80\x81\x01	\x00\x00style.cssUT\x05\x00\x01&}\xab]PK\x05\x06\x00\x00\x00\x00\x06\x00\x06\x00\xac\x01\x00\x00\xd0	\x00\x00\x00\x00"
+	data := "PK\x03\x04\x14\x00\x08\x00\x08\x00/;Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x06\x00	\x00app.jsUT\x05\x00\x01\xea\"\xd3j\xa4z\xeb\x92\x1b\xb7\x95\xf0\x7f>\xc5Q\xd7|IwD5G\x8a\xac\x8a9\xa1U\x1ek\x9cOk\xd9Ri\xa4d\xab\xb4\xda\x08\xec>$\xe1i\x02m\x00$E\x8fY\x95\xa7\xc9\x83\xe5I\xb6\x0en\x8dnR#e\xf7\xcfL\x1388\xc0\xb9_\x80\xc9\x04\xde\xac\x10X\x8bJ\xc2\x0e\xe7P5\x1c\x85)G\x93\xc9h2\x81\xe7\x06\x0ckn4\x18	f\x85\xb0}\x04\xdf\xbez\x0e\xf8\x91U\xa6\xd9C\xc3o\xd0\x8eWr\xbdf\xa2\x86\x86\x0b\xf4(\xa6\x80[T{P\xf8\xcb\x06\xb5\x01\xaeA#k\xb0&\xbc;nVva\xab\xf0\x81^1\x855\xdc\xe0\x1e\x08\x87\xe6K\x81u\n\xc2\xb7\xcc \xcd\x8f=N\x14F\xed	#\x8aJ\xed[\xe3\xc1	3\xad\xf0\xa3\\\nZ\x04s\\H\x85\xd0 \xdbr\xb1\xb4\x10s%w\x1aUi\x89\xbf\xc1\xbd\x06A\x98-\x0cB#+\xd6\\\x1b\xa9\xd8\x12\xcb\xd1\x88\xaf[\xa9\x0c\xfc\x01\x98\x86\n\x16J\xae!+'vcY\xfe\xac\xb3\x8b\xd1\xa8\x92B\x1b\x8b\xc8/\xfb\x01\xf70\x83\xcc\xf2\xb5\xa4\xf1\xec\xc2\x03Yj\xbfc\xd5\n\x7fbk\x8c@\x8e\x0b\x11\x8a\xb5\xfc\xaf\xa84\x910\x83l\xfb(NTR\x18\x14\xe6\xcd\xbe\xc5\x97\x95Asm\x14\xb25\x01\xb1\xb6mx\xc5\x88\xec\x89\xa4\xa9\x07\xda\xce\xd1\xf9\x1at\xa7\x83\x19\x88M\xd3\\\xd8\x01\xe2\"G\x1a{\xf7\xde\x8flQ\x98\x0e\xc8oy\x063\xc8y]\xc0\xec\x1b\xa8e\xb5Y\x93\x82,\xd1\\5H\x9f\x97\xfb\xe75M_\x8cF\x8b\x8d\xa8h\x7f\xd0t0f6:_\xeb\xe5\x18\xb8\xbeRJ\xaa\x02nG@\x14h\x03\xd8\xc0\x0c\xce\xf2L[\xb0\xac\xb8\x18\x01`S\x1a\xfch\xbes$\xc2\x0c\xd6z	\xbf\xfd\x06Y\xe6g\xab\x86i\xfd\x82kS\x1a\xb9\\6\x98gHx\xb31\xdc\xbb\x17\xf6\xb8\x18\x1d\x92\x83,\x18orT\xc9\xd6\xb2\xc1\xd2\xae\xb2\xc3\x84\xb8;,*U\xaeQk\xb6D\xda\xf7\xda(.\x964Z\x8c\xc1\xa8\x0d:\xe4\x93	\xfc\x80{=\x1a1\xbd\x17\x15t{\xa1\xa9V\x7f\x93\xaan\xb86y\xb7\xa3\x01\x85\xba\x85\x19\xb0\x1d\xe3\xc6\x81\xe5\xd9d\xe7!\xcb\x9f\xb5\x14\x8e\x01|\x01\xf9=\x02.\xe5M\x01f\xa5\xe4\x0e\x04\xee\xc0r/\xff\xb0\x11l\xde \xd9\xa2\xc5a5\x99\xb0\x00\xa1\x99\xc2\xd9\xad]\xea8z\xf8`1*4\x1b%\xec\x01\xec>y$\xa1eJ[5\xb5\x1f\x1a\x18)\x08p\x01R \xc8\x85E\xbe\x90j\xcd\x8c\x06\xbd\x92;\x01\xf3\xbd\xf7\x13\xad\x92[N\xba9%^0\xb8|\xfe\xea\x8f_\xc3Z\xe0Z\n^\x81T\xc0`\x85\x1fA[\xfe\x8d\xa1\x95Z\xf3y\xb3\x07\xdd6\xdc\x10\x1e\xdd\xb2\nu9\xe4`8S.\xd8\x1a\xc7\xb0e\xcd\x06S>Vr\xdd\xb2\x8a4\xc3N\x95\n\xdb\x86U\x98O\xfeK\xdf\x9f,\xc7\x90ul\x9c\xfc\xf7\xbb\xf3\x07_\xb3\x07\x8bo\x1f|\xff\xfe\xf6\xc9\xe3\xc3\xd9\xa44\xa8M\xeeq\x14\x0eo\xe4PU\xd6X\xc9\x1a\xff?~\x8c \xc4\xc0\xc3h\x04@\xdef\x00\x1d\xa8}#\x7f\xc0}n\x8f3N\x05\xdc\xe9\x81C\x03\x153\xd5\n:]\x84c\xf9\x9e\xdd\x12\xdd\x07rl\x02\xb9Y!1r\xcb\x1a^w\xcc\x15R\x11oI\xda\x89\xb6zi\x1fz\xba\xdfHV\x93\xa2\xf6U\x91\xed`\xd6\xf7pK4\xcf\x0d\xae\xf3\xbe\xfb\x8a\x9c\xbc\xa7\xd8\xae\x08\x84;\xef\x11\xadX\x1bI\xae{\x06\xffq\xfd\xf2\xa7\xd2\x8a/'\xf0D\xf5hk\x80V\xff\x80\xfbi\xe4\xf2%\xd3\xf8\xe4q\xee\x96\x97v\xb2\x18[@\xef\xbb\xef\x80\xee \xfc\x12\n\x18\xd7\x88\xf5\xa7\xd0\x87y\x0f^\xe3\x96W8\xf5g/\xddO2\xf7,#\x80C\xdf\x81h\xb6%+\xd1\xf9\x8d\xe3\xe2d\x02\xdf\xea\x9b4\x88\x80\x90\x86l\x92\xf0\x18;A\x8c\x84\xdd\n\x05p\xf3{\x0d\x8d\xdc\x81\x14v?\xb6D\xcfU\xc1\xb6|\xc9\x8cT\xa5\x1f\x87\xdf\xfd\x0e\x8e\x06\xcb\x96\x82\x806\xc5\xa7\xa7\xc8\xa2G\xd0\x97\xa8\xf6\x12\xb5\x1c\xed\x8b\x95H\x04'.g\x9e|\xb1\xcf\x9d\x8c\x12)\xa1H\xa4t\xd3\x13\xd0PD\x03\xd0#\xe9\xf4\xe53\x80\x1e\x88\xa6\x13\xce\x8d\x97\x8b\x1b?\x14#\x00\xe7\xb9\x06\x1e\xc3\xc5f+ rV\xa9\xa6\xdf\xc0\xac\xaf|\xce:\xa3\x8f\xc9\x8e\xf3\x8fll]^\xd9\xea\x1b\xdc\x97\xd6\xa8\xfd\xc9R\x9aO\xe1\xe9\xa7\x1c\x01\x8f\x1f\x1d\"\xeb\x18r\n\x15\xcd\xa6IO@F\xe3~x\x880p\xcd\xc2\xb9\x1fn\xbe4\x8a\xaf\xf3\xc2\xeb\xf5\x08Ru&\x1b\xb5\x0b\x14j\x0cj\xe4\xcd\xfc&D\x89\xd7.{\xd3\xa9[A\xb14\xabW\n\x17\xfc#\xd6\xf9\xdc\xf1\xdc/\xac\xcaJ\x8a\x8a\x99\xbc*7\\\x98'\x8f/\xaf\xf2y\xe9\x96\x14c\x98\xc7\xf0C\xd4x\xe4P)d\x86B\x90\x00\x14[ldkC\x8f\xb5%\xe6\xe4\x1c\x12C\x0d\xdc$\xa9!\xdb\x93\x93\x1bS\x18\xb2\xb9(a}f\xe9\xbf\n\x88\xb8\xb0x\xfe\"cj\xdb\xd9vw\x86\xdc\xed3\x0e(SE\x8ag\n\xfa\xe4`\xa7\xfe?1\x17`K\x86*\xc54\xc9\xdc\xdc\x84\xe1k\xd4\x86\xad\xdb)\xfc\xc8\xcc\xaa\\4R\xaa\xfc\x193X\n\xb9\xcb\x0b\x98\xc0\xc3\xf3\xf3s\xafgB\nR\xff\xaaTL\xd4r}\xb97\xa8\xf3\x87O\x82\x04\x9d\xef \x8b\xf6b.\xe2\xe9\xfc\x00\xcc \x99N\\u\xc8kf\x9d\x8c\xec\xf9\xaarc\x16\x7f\xba\xb2v\x99\xbb\\4\xe4\xec\x99?\xd4@\xe2\xbd\x15q{\xcf\x80\xe2\xdfY\xe3\x18\x18\x96$*\x13!\"\xf7N\x1f%\xc2Y\xbey\x98\x84~xz\xe7\xe1\x13\xc8\xa2\x80\xa9\x8d\xc5o\xb90\x7f\xfaV)\xb6\xcf\x83P\x06(\x82\x8a8\x9fD\x99i\xef\x140\x1b\xfa\xb9\xc1)/F\x9d\xbd\xdcz\xef\xe2\x10L#\xaa.\xb21\xb3Qx\xe4:\xab\x12\xebG_}\xf5\xf0\xebk\xbe\x14\x8e\x8e\xe0U\xc6A\xd4Eq*\x9e!k\xc8\xfb\xe7r\xfe\xf3\xc0r5V\n\xcd\xa5\xfcx\x8d\xac\xe9Ky\x10/hm1v\x8cv\xb1\xa1\xbf\x89lQ\xd0\x92\xbcf\x86\xa5\xa6\xd46\x8c\x0b\xca\xf1a\x96n\xf8\xb2Eaa\x078\x9d\xba'\x8bf\xae~9\xce\x8d\xb3.7\xa6\xcd\xad\xc9S\xd2+\x85\xc6,MG\x92D\xc5Q\xf8\xcc\xa6}\xdd&\xc5\xc98SK\xef\xac\xf25\x9a\x95\xac\xc9Q\x98\xd5\x18\xe6\xb2\xde\x7f&\xd3w\x80\xb7\xe0\x16N\xfd\x7f\xb7tj\xff\xc2\xe1D\xf6\xef4\xe3D\x8eH\xf8\x0e\xb6\xb0	Ur?\xf1\xa7\xd40w\x81\x85NcK\xaa\xbc(|(\x88\x99bT\xc1\x81\xd2'+\x19Y\xc1\xe5f\xb1@\x95\x17\x03\x11/\x14[cp\xfe\x91v\x98\x0d\xd1=\x86\xfb\x10\x03\x00\xedL\xf3\xcf\x98a\x7f\xe5\xb8\xcb\x15\xearn7((i!\xdb\xfb\xe3\xa3\xfc|\xdc_BP\x14\xa2\xe6cx\x9c\xcaR\xa1\x0e\xe1d\xd3\x92I\xfe\xe8}\x1c\xd7ix\x08\x1d\x85\x10R\xaa\xd5F\xdc\xd8\x88\xb2\xe0\x82\xebUpw\x9a\xca\x1e&<\xae\xb2\xa3\xb5\x87<\xe7\xf5\x18\xc4\x18j\xbeDm\x06&\x14\x83_m\xb5\xea\xed\x8b\xe7\xcf\xa84\x1e\xa7\xbeM\x14qq8\xfd\x95\xab\xc4\x133%%\xfe\x11\x0d#\xa3\xc8\xa9P\xf7ZF\xa9\xe3=\xfb\xbb\\\xfb\xe9\x98\x9b\xdf\x1e\x12w\x7f\x97\xa5\x0d\xd2\xe4\x01:o\x83>wI+\x81S\x86\x98\xec\xfd\x7f\xb51\xaab='z%\x0b\x11\x19\xab\xe7\xce\x10\xb3W/\xaf\xdfdc\xc8&\xac\xe5\x93\xed\xa3	\xad\xcf\xc6\x9d\x87K\xc3{\xe6'\x07\xday^\x14t\x96A\x9d\xdew_\xa9\xbb&\x85m\xcb\xd08\xf9\xed7x\xf7\xbe(\xd7\xac\xcd\x83\x8cf\xdf@~\x0beia\xf6cX\xd7\xd3\x93\xb2<x{\x9aL\x9c\x97 \xba\xa9\x14\xd7\x94\x07I\x05k\xb9\x0d	\x91Q\xae/\xe68M	\x90\x86\x1aC\xdf\xcb\xf7\x83\x8eJ\xe9\x0ekLmx/\xabQ\xf8\x0b%4t\xd6Sy\xd0P\x85\x8b1\xf0z\n\xbc\xa6\x80\x12p8WF\xb2qN\x81\xfcsFG\xcf\xe0)d\xcf\xae^\\\xbd\xb9\xca`\nNT\x17\xa3\xcf\x894\xb8\xc6\x0fA\xa4g\xb7\x0e\xf3\xe1C\"W\x85\xbf\x10\xf7\xe2)\x04\xcc\xfa^\x85\xb0{\xb72\xb6\xdaS\xce\xf7\x06_.\x16\x1aMQ.;?cE\xdf\x93v\xa97s\xeb\xf6\xf2\xc7c \xdf%\x8a\x9e\x02\x84\xa0\xe5\xdan\xcf\x9c\x18\x86+\xed\xb2Sft\x1cW\x9ck\xf9\x9e7\x98/x\xd3kv\xac\xeb\x98p\xd2\x14\xb5\x06\xa6\xf6\xab\xa4O\x9f29\xe9\xff\xdd\xec\xdb0I\x9f\xa4\x99\xa7\x1b\x85!\xaf\xf85\x80\xd3\xa7\x1b\\Im\xa6\xbd\xe4\x89jb_\xe4\x86\xd28\x1e\xce;\x1f\x98\xfd[\x89\xc3\xba.N\xfa\x97\x887u[\x03[\xf5\xcd\x15\xde\xe0Q`\n\xab=\xcd\xc9\xa1\x9c\x9c\xae\xdc^\x9d\x03\xfa\xc4\x19&\x13\xb86L\xb9*\xde\x89\xa6;\x9a\xa6\x99\xd7\xa7\xad&s\xc0\x0f,L6\x86\xc4\x99\x06N\x1d\xa5pa\xa2H-\xca\"H]\xd0\xd0D\x8e\xbc\x9e\xdby\x12v\xee\xbc\x9f?n\xe1\x15\x98hCQ[\xd2l\x04\xd44J}`.j\xfc\x0838\xf7\x15!\xe44*\x17\x0b;f?\xfe\x1cX\xeb\xc3\xb2\x1b\xbd?s\xe7--\xbe\xbf[Ur\xd8\xee\xdf\x0f	\x8c\x17\x0c\x01\xc0,b\x89V&\x17\x8b\xb1\xc3u\x84\xca\x1d;p\x7f\x85\xacF\x15-\x02>%\x01\xbbS6\xee'\x04\xb9\xc3M\x91\xdb\x1e\x8f\xdc\x9b^\xb1G_=\xc9-|\x11J\x10\xb0N.B\xfb\x86\x87]3\xf5K\xed\xd8\xc1\x9f\xedX:oO	'\x1c*V^.{\x8a\xb2r\xc4\x91i\xb8\x89p(\xbfK\xd7\xa0\xfe\xf0\xd6RI\xd7\x18g\xb7\xd1\x17XC%\xb5\xcel\xf2\x97\x94\x9991\x18\xee;\x81\x87\x94\n\xfe@\x15'\x15\x9e}\xa1\x16\x87\xff\x17\x92C\xd7\xe8\xfa\xde\xa5G\xdctF\xe02&o\x05\xa3;\xe4\xe0\x00?/\x88\x13\x01y<:-\x08K\x84N\xe4`\xed\xe6K\x0d$\x1e(r=\xd2\xd2\xc5\xe2\x17|\x8b\xb0ikfz\xd9\x98\xde\xccu\xa5\xf8\x1cs\xb60\xe8/\x13(\x15s\x17&\x85\xbf8)\xabFj\xf4\x9d\x94\xc9\x04~z\xf9\xe6*\xdfrQ\xa10\xc5\x14XHD}\xbe	\x15\x13\xbf70G\xb0}\xec=\xd6\xa0\xdd5\xdb\x15\xa1\xbd\x96\x1bU\xa1\x03r\xf2PXI!\xb0\xb2-tn46\x0b\xb27m\x90\xd5\xc0,+\xa9y\xcf\xb5\xef\xa8\xf8\xf2\x80\xc1Ba\x97\xe6\x96\x9fK\x01\xb2Hn6\xc8\x03\x1c\xf5\xc5\x18\xec\xc7\xd4\xfdsb\xe8\xae\x8ep\x97\x9e?\x8f\x8e*b}\xea\xe9\x9fep\x9f\x1ag\xb2\xc6\xb7\xaf\x9f\x7f'\xd7\xad\x14(L\xde\xef\x12\xbe}\xfd\xa23\x14\x1b\xfa\xbdC\xf3<gum\xb7\xa3k!\x14\xa8\xf2\xcc\xa6L\xd9\x18r\x9b\x8e)\xb4\xd4\xe7Ei;\xf09\x15N\x84!\"\x90\xc2^\x07\xd1%\x97]\xe0K\xf2\x81D\xbb\xb1x=FC\x1a\xcd\x1b\xbeF\xb91y\xba\xba\xeb\xd3P[\xf7^P\x93\xc8\x82\xbca.\xd7\xdd?\x7f\xe6o\x08\x00\x0ec\xf8\x8a:A\x17\xc7U{\x0f<-9|*\xeaM\x1b\xbe\x81sx\x1a\x06\xdf\x0d&\x1f\xc0\xc3\xf7%\xafa\n\xd9yFw'\xc8L\xfe\xe8IT\xfe\xd7(j\xa4\x1b\x9bD\xf3\xa9A\xc8\xcc5\xff\x15s\x91&'\x1b\xc1\xedE\xe1\xbb\xec\x92l\xed\x07n\xff\xfd\xe8\xfe\xfd\x85_f\xef/Bt	\x91e\xb7\xe2\x0dB.\xe0\x9b\x19<<\x7f\xf4\x988\xc3\xe1\xcf\x0eU \xe0\x01<\x0c\x91C\xc0\xc4\x01\x12&\x00~\xff>}\x1c:\xda?\x9c\xddr\x9br\x12\xcd\x82\xda7\xa5\x91\xdf\xdb\xa6\xe4\xc3\xe2\x00g\xb7\x16\xf3;\xfe\xfe\xf0\xa1_\xbb\xd6r'\xc8\x8d\xe6\xde\xff\x8d\xc1fT\xc1\x1d\xd2\xddj\x8fVEw\x95o_\xbf(\x9dY\xbd\x9c\xff\x8c\x95!\xbd$]\xbfl\xe4<\x7f\xe7\x97\xbe\x1f\xc3-P\x066M\x91}:\x1dsu@4HJ\xa7\xe2\x0d\xab\xdb\xcc_\xb2\xe6\x19\xcb\xacb\xb0r\xa5\x90\"\xf3FY\x15de\xa0\x86\xf4\x92\xad\x91\x06#\x0e\xea.\x94\xacmQ\xd4\xdf\xadxS\xe7\xcc#\xa9\x1a^\xdd\xe4\xfe\x87B\xca\xd9\xbd\xdf:\xd2h\"\\\xe1V\xde$\x84oTS\x8c\xe1\xc9\xb9S\xd7\x94\xb7\xa4s\xfb\xcb\x8d1R\xe4\x0d\x9bc3\x0emR\xf0\xa5O\xc7\xd8\xb9\x05\xbb\x83f\x07\xe0\x08w\xdf.\xbd\x9dA\x98JgzW\xc7v\xefd\xf6\xd8MX\x16dcp\x19yj\xbd\xddu_/\xf6~O%\x95\x0b\xbd\x96\x94\x92\xd7\x87\x7f\xfd\xe3\x9f\x1f\xbc\xf9\x06\xaa\xbc\xe0\xfb\xf7\xbd\xfdJ,,\x8f+\x8f5\xd2\x17\xe5u\x19r\x7fR\xa2\xb0\xce\xf3\xb2\\\xd7\xa5\xdf\xcdV\x00\xc5\xc5\xd1\xa1\xfd\x8dh\xf0HGuZ\xe1\x0f\x19\xbd\xa4\x83>u]	\xdde\xba\x07\"sL\xfb1N&}[S\xd6\xa9\x9c*\xeb\x0d)\xa7{\x04\xe0\x1d\x95;\xab\x1d\x1f<\x04\xa0\x17\x00!9\xf5\xef\x07\x88\x01\xd4\xb0	NN7\xbc\xc2\xbc UEE>\xbb\x9f\x81\x1au\x87\x9e\x19\x95\x85\\\xcb\xcb\x10\x9b\xc6\xba7/\x9f\xbb\xa5\xe1\x81\x12_\x19\xe1)'\x86{\xb3\x19lD\x8d\x0bN\x0f[\x9e\xfa\x85av\xea\x7f\xd3\x8f\x98\x86V\xe5\xa6\xe15\x19\xa2\xc7\xc5\xeb\xa24\xf2\x05\xbdLA\xff.!\x02;\x08\xdf\xf1\x8ej\xb2\xaeK*\xe9\xba\x9bM\x00\xeb\x94{l$6\x13\x17-\xc1\x81cA\x93M}\x17\xcf\xeaN\xb5L=\x90\x17\xfd\x8a\x93\xaa\xe7\x81L\xd0\xfaC\xcap\xa7\x98\xfa\x8b\xf6\xf3\xb0\xee5H|D\xe3\x06\xb3>H\xbaq\xea\x99\xb2W\xd4i\xa1\x90\xd5\xfa\x0f;\x1b\"\xf1g\x97\xffH=\x8e\xb1\xefu\x0c\x16\x0f\xe8\xf5\xb8\x82~\x99#\x97l\x94O\xba\x8f\xbb\x03\xd1*\xad`\xbc\xa6G\xc7\xd2\xeb\x97\x11\x8a\x81\xad\xdd\xddo\xd09\xe9\xb2\x97y\xe7\xf1\x12\xdd\xa0y\xd2\x8d\x04\xeeK\xaa\x91\xbeOtG\x1d\xb69:\x07r\xd2Y\x9d\xf0I\xa7<R\xcf\x1f\x1d|\x16s\xbdb.\x87\x89]a\x1a\xc1\xda\xff\xd0\xfe\xbd\x9a\x8d\xc6\xe0\xaf~\xe3\x85\"\x17\xda\xb0\x86\x1a\xeb\xacm\xa9I\xd0\xda\\\x9b\x96hT\xf6~m'\xd5\x0d*\xd8\x08\xc3\x1b\x9a\x10\xe5\xe9\x9e\x8e\xdb\xd5gkT+\xdc\xdbqQ\xcb]Y\xd1\xdb/\x1d\xba\xb6I\x1f\xcbND\xe9\xda_\xba\xa4F@\xde\x7f3\xd6kX\xdaL\xba\xd3	\xbb\xca>8\xf3\xe1<\x91'\xa5\xfbr\x11\xca\x80h\xed'..\x1c\x9651\xdc\xa6\xdc\x17\x89\xa5\xce\x1b9\x8f\xdb\xd1\xaa\x92FB\x8a\xec\x90Q\x1e\x0237\xebj[Mm\xb7<\xfb\xcf\x07\xa4\x034\x1d\x1dn\"\xfe\xae\xb8\xb5\x0c'\xa2c\x83\xf3_\xff\xf8g\xb4\xff\xa1JQ\x1aFx\xf3wt\x94\xf7.\x9d\x83\xa7P\xe3QmA[\xd3M_\x96u\x99\x1a-ry\xc5!:\x80\x84\x0d56h0\xf2\xc1\xba-\x92g\xe0c\x92{\x07\x96&4\xbd9\xa2\x04vLC%[\x8eu\xd9'\xa9\x17\x84\xa3>\xa3\xd9\xb4I\x82Eo\xb1\xec{\x87\x96\xee\xed\x85\xbf\x81\xa0\x97t\x04\x98\x15\xe5\x8a\xd75RV\xe5\x01\x08\xd9Y\x9e\xad\x19\x17\xe9\xec\xbd\xfe\xf4B\xaa%\x9a\xd3\x00\xc7n\xc4vH\xbcn\xc7\x03\xf9\x17r'\xa9\xf9t\xe5\x13\xe1\xfbf\x13.\xc4\xe8]\x0b\xd1]jd\xaaZ\xd9X\x9ae\x05\xac8\xbd\x9b\xd9\x87'`\xc4n\xcc\xa9.\xa3W`\xd4\x1e#\xd6\x1eF\xa3\x841\xc7)\xa0\xde\xcc\xd7\xdct9 nc\x16\x88\xdb\xb2\xa5TB\x98g\xb8`\x9b\xc6>\x90H\x1d\xa5\x7fN\xe9\xce\x9e<C\xc1mi\x18\xf1\xb2\xb8\x18\xeaB_\xda\x9e\x87_\xea\xdc\xc8^R9}:\xa1\xedR\xd9\xde\xa3 \x97\xe5\x7f\xea\xa5W\xefuh\x1ak\xe8m\xe8\xdd\xcd\x8e\xa3\xc2\xf8T(J\xf4d\xc1\x1aM\x8abIr\x0e\xa3V\xb2\xfd\x95z\x176\x1d\x0c\xbf\x88_\xe1\xfb\x04\xbd\xb5bK\xb9Ez\xfe\xf9\x05\x92\x8b\x88\xbaG\xa4\xac\xae\xf3\x8c\xe2\xf3\xd6nu\xf8\xfcv\xf6}pd\xf1	\x94\xbe\x94\x8aX?\x87R\xb6\xff\xeb\xd3\x0f\xb7\"\x1ewN\xd1*\"u\xe8\xdf(&\xf4\x02\x95-$\xb4'\x93\x14\x89\xa2\xfaI\xab\xa8VL,qp\xae\x14\xb3m\xd5\x95\xf4\x10\xba\xd3v\x8f\xde7V\xbc	\xb8GH>\x7f\xa7}G\xe483\x1fJ\xfff#iF/t\xe2\xeb6\xe7R\xe2\xcf\xb2\x07Z*\\\xd2)U\x9eM\xf4\x8e\x9e^\xf7\x9a:6\xdf\xf1\xb7\x9fL\xb1u\xbc\x8d~\xfd\xe2\xda:\x8fWvt\xe8R\xe8X^\xfb\xbb\x17\x93\x17\xa3\xd0\xc0\xf1N\xce\x19\xeb`;\xc0F#\xdc\x9eRn\xfft\xc1\xeeX\xae\x98\xce3\x1b\x05\xb2\xa2H\xa3\xc3s\xeb7\xbaG\x83FRd\xd8\x9f\x88\x7f\xa5\xf7g	V\x1bQ=VW\xd2m\x04\xdb2\xde\xd0+\xe1\xcc\x9f\xfb\xceHT\xc9MS\xc7\x1ed\x85|K\xcfG\xe2\x03\n\xd6\xb6`_\xb4\xd0]\xb9\xdd\x08\xd8\x92qQfc0j\x83\xc5\xc5\xe80\xfa\x9f\x01\x00PK\x07\x08J\x86\xfb\xf4\xd9\x10\x00\x00B0\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xac:Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00	\x00	\x00crypto.jsUT\x05\x00\x01\xf5!\xd3j\xac|\x7f\x97\xe3\xb6\x91\xe0\xff\xfd)*\xfd\xd6^j\x9a\xad\x06@\x12$\xd3\xa3\xb9\xe7\xb1\xbdI\xd6N\xe2\xf38\xd9\xec\xf6\xb6o\xf1\x8b-z$RCR=\xea\xc4s\x9f\xfd^\x81\x00EIT\xcf8g\xbf\xe7i\n*\xd4\xef*T\x01\xa0nn\xe0\xcb\xe6i\xd3\xd5\x0f\x8d\xd8,\x9f@\xd5\xeb\x8d\xe8J\xb92\xf0\xbe\xec\x96\xd0-\x0d\x88\x8dijP\xab\xd2T]\x08e\x05\x9b\x95(+\xf8w\xf1(\xde\xa8\xa6\xdct\xf3\x8b\x9b\x9b\x8b\x9b\x1b\xf8\x0f#{dP\xb6PW\xab'\x10\x8f\xa2\\	\xc4VV\xd0\x1a\xb5m\x0c\xa8\xba\xea\xcc\xaekAT\x1atm\xda\xea_;(\xd7\x9b\x95Y\x9b\xaaC\xa8\xc6t\xb2\xde\x85\x1777\xd0\xd6`\x1eM\xf3\xd4-\xcb\xea\x01\xd1\xea\xba2\xb04\x8d\xf9-\xfc\xed\x8dX\xb5\x82\x91\xeb\xef\xea\xd5\x13\x8dH\x02\xc1\x9f\xc4\x97\xab=\x86Y\x08_k\x96$4\x87\xb6|\xa8D\xb7mLk\xd1\xbe\xf9\xfd\x17\xd7,\xe1\x96\x05|N(\x9b\xc3\x0fKdN\x1b(-K\xaa\xae\xdaNT\x1dt\xe5\xda\xdcB\xd9\xf5\"!;\xb0\x14\x95^\x99\xd6\xaa\xe7\xadyj\xa1.\x10-~\xdc\xb6\xa6\x81f[U\x96\xe1^a\xdd\xd2\x94\x0d\xd4\xef+\x90M\xfd\xbe5\xcd\xfc\x02\xc1\xbf\xaeT\xad\x11liV\x1b\xd3\xb4\x17\x17f\xb7\xa9\x9b\x0e\x8am\xa5\xba\xb2\xae`\xdb\x15\x99\x852A;\x83\x7f\\\x004\xa6\xdb6\x15T\xe6=\xfc`v]\xffe\x13\xcc\xe6\xc6\x83\xdd^|\x98F\xf4\x95\xb1\x10r\x12Q\xff%\"\xd2\x1el\x12\x91\xaa+%\xba`>\x9f\x8b\xa6\x11O\x8e\xab\x95\xe9\xa0\x82\x05\x90\xdb\x0b\x80\xa2n \xb0\xda\x03\x01u\x01\x1e\xb0\x82\xab\x05\x88\xf9\xcaT\x0f\xdd\xf2\xf6\xe2\x02\x10Y\xdbAcZXXF\xfeRV]\xf6\x05\x82\x07\xd5\x0cQ!\xde\xba(>\x86\x19Y@\xd5\xb4\xf3\xd6t\x81\x08q\x8e\x9d\x0f\xf8tH\x16\xe0\xc3^\x8d\x8di'\xa5\xec\x95\xf9Z\xb4\x86\xc7^a\xc8\x0b2zy9\x08\x89C\xa5e\x0eJx	\xd2\x13\x81\x12i\x92]F\x08\xf1\xdc\xb58\xf4\xa6k\xca\xeaa^4\xf5\xfa\xcb\xa5h\xbe\xac\xb5\x99\x8b\xcdf\xf5\x14T\xdb\xd5*\x049o\xb7\xd2J\x15\x94!b\xf1HfG\x8c\xcb\xae\x16\xe7l\xad\xcd\x88y\xa7\x9d^\xd3\xb2D#\x89\xae\x96v\xee\xb3\x06\x90e\xe5\xc4\x99\x9d\x95w\x00\xb9\x85\xf2\xeaj\x86\xea\xbc+\xefa\x81\x84\xe6\xca	\xf8E\x17\x94\xb3\xdb=\xeb\x9f\xa2\xf3\xbf|\xff\xed\x91\x9f\x1eYd\xde\x98\xcdJ(\x13\xdc\xfc\xf7\xd5\xcdC\x08\x97\xd7\x97\xe3\xb1\x1b;\xf6\x7f.\xa7\x15\xd4\xe3\xfa\xbd\xd9\x1d\xd1\xb0\x9eg\x8d\x13\xc8\x10\x82\xc7\x19,^\xc1\xe3\xbc\xab{\xb3\x05\x94\xcf\xe6\x1b\xa1\xdft\xa2\xe9\x02\x16\xc2%\xb9\x9c\xcd\xe6?\xd5e\x15\\\x9e!\xd5\xdb\x02I9C\x94\x05\x04\xad\xd3\x1a|\x06\x0c~\xb3X\x00\x81\x9f\x7f\x86\xdf\xdc\xfcxG\xaesq]|q\xfdo\xf7/\xfe\xe5f\xde\x99\xb6\x0b\xda\x99\xf7\xa0n\xd9\xd4\xefm\x9c|\xdd4u\x13\\\x96\xd5\xa3X\x95\x1a\x96f\x07\xade\xf1r\xf0\x93g,;\x90\xbf\x01v\xd6\xb6\x8di\xcf\xd8v#\x9a\xd6\xfc\xa1\xea\x82\x16\x9d\xb5\xed\x9a\xa0\x84\x17\xc0B`\xb3\x10(\xff\x14S7\xa2\xd2\xf5\xfa\xf5Sg\xda\xa0:0\x81\xb2\xeb\xc7\xfc\xc1t\xdf[\x98\xbf\x8a\xd5\x16\x81\x8e\x93\xc3\xb4\xb6\xb7e\xd5\xf1\xf8\xf5\xd7\x1e\xe93J\xc8,\x9f8\xf8\x95\xe8\xc4_K\xf3>@\x91\xe5\xb6(L3\xc3<\xf2\xba|@\x92<\x0eH\x08\xaf\xcb\x07\x14\xb9\x9aM\x89ws\x03\x7f\xf9\xf6\x0f_\xb5 \x1a\x03-.b\xa2u\x06iAn;\xbb\xfa\x18\x8d\xa3\xfdJ@9H\x14~~q\xd1\xb3\xa8\x9aZ\xbd-\xeaFc~!\x94Eq\xc2\xd3,\xff\xe2\xf5\x97_}\xfdo\xbf\xfb\xfd\xbf\x7f\xf3\xc7?}\xf7\xbf\xbf\x7f\xf3\xc3_\xff\xe3o\xff\xf9_\x97\xb7\xe7\xfc\x0c\xb9\x98t4t2\xc6g\xe7]\x08g\xa2\xf3\xb8<\xf7\x88\x8eP\x0d\xbe\xe1\x98\xc4d\xde\xce\xbb\xfa/\x9b\x8di\xbe\x14\xad	\x06\xdf\xec!0\x17\x0e\xa2\xcc\xcbJ\x9b\xdd\x9f\x8b@\xb9T\x8c\xae\x8f\xaeE>\x81\x0f\x84G&\x82Gx\xf9\x12\x92j\x06?{\x1b\x94\xce\xc7\x9f\xb7/\xe5\xa7\xaeM\x13\xcc[\xaf\xfa\xfcu}\xedy\x1f<\xfbO\xdb\xb54M\xf0\x08\x9f\x03\xd9\x15E5\xf0\xf1\xea\xd5\x02\xb2\xca\x87\xd6G\x9c\xbb\xcf.(\x88O/g5\xbaC\x8d\xca\xd9H\xd2l,\xe9no\x90\xe7\x17\x1e\xc6]\x02\xf6K\xcd\xc8\x0cw#\xa9\"Z\xcd\xee\xe1\n\xda\xb1`\xc9\xb1`\xd31\xbb]\x95\xfa\x87rm\x8eV\x14X\x1c\xfa\x9e_\xb5\xd7\xed\xc1\xa2}\xb4Rz~-\xd4\xba\xc5\xfc\x91p\xb8\x02yW\xde\x8f\x02\xcc\x85\xa7	\xd6n\xa9\xbb\xb9\x01W\xf4]\\\x0c9\xb6\xa9\xbbU\xb0\x0b\xc1\x05\xbd3O\xb0C\xcf\xb1\xea\x0cv\xf0\xea\xd5+\x08\"\x06\xd7\xe0s\xc70\xbd\xed\x11~_o+\xdd\x06\xbb\x1e\xc9\x14\xd3\x8c\xb8\x85\x9dyE\xef\xee\xe2{\xf8q\xd1s\x10\xec\xee\x08*wwG\xd9=\x92%!\xa4\xce\x85vw\xd9\x01`\xdc\x03\x12\x0f\x97\x0fp\x94\x1d\x00f=`\xec\x01i4@\x92\x03@\x9cwe\xe9x\xc8l\x80\xcc\x0f \x13\xc7\xe4)\x8f4:\x00\xcc{\xc0d\x82\xc9\x038\x9c\x86\xa4\xf3	&\x93CHG:\x9a`\x92\x1e\xaa\x92:]\xf2S6\x0f5D\x9d.\xe9\x842\xf9\x01\xa4\xd3\x10\x9dR&=\xd4&\xefA\xd9\x04\x9f\x87:\xa2^\x9b\x13\xeaL\x0f \x9d\x92\xe8\x94:\x0f\xf5\x99\xf6\x90\xd1\x84\xcd\xe9\x91>\x9dB\xd3\x01\x14\xf9\x9c4\x129BzV\x9dg=3\x9aT\xe6\x04\x93dJ\xee)U\x1e\x9a\xc7i2>\xe50\x9d\xb2\xcd\x84\x1e\xe3)5\xf2	\x0e\x0f\xb5\x18O)q\xd22\x94\x1cy\xfa\xb9\xf8\xa6\xf4\xbcS\xe6S\x01N\xe9\x04\x9b\x94L\xc5\xe3T\x84S6\xed\x95\xf1G\x83\x9c\xb2g\xfc\xf2($\xbd\x0b\xb3\x89lt\xec\x99>*\x8fC\xfd\xc3T\xee}\xd3\x89\xce\x04o\xcd\x13v\xcb\x9bm7^a\xde\xc2\xe2\xb0L{k\x9e\\\x99\x16b\xef=\xc7J\xea\xcfE\xd1\x9a.\x84\x88\x8d\xda\x9a\xeax\xa6\xc5=\xccu\x9fF\xb3m\xcd0L\x1f\x17\x15\x11;\xa8*Z\xf4\xf1\x05\x90\x1d\xa7)I3\x9e\xf4\x83	\xb6>d\x17E\x8c\xf0\x98\x9b~\x90:\xd04\xe7\x8c\xe9\x88\xb9Q\x07\xcb%#<I\xe3s\x0be|\xb8\xb0\xdfQ\xb8\x02[\x85\xbf\xc5\x1a\x19\xeb\xd3\x88\xd9\xfa;\x0e\xa1k\xb6\xc6\xd9\xae\xbd\xa3\x93\x90\x14\x17\xd9)p\xee\xa1\xabg\xf0\x9e\xd4\x08\xc3\x1a\xbat\x8b\xe8\x19\x1b\xee`q\xd6\xd4\xc8\xc1\xf1\x12\xfc\xb1\xcd\x81\x033?\x1e;\xc8\xa8\x8eG\xa8;\x12B\x12\x02E\x1fLB\xe0!\xa4!d!\xe4\xf7\xf3\xa2n\xbe\x16j\x19\x04?\x85P\xban\xaf=\x16\x7fw\xf7\xd3\xbd\xd3\xd6\x99\xe2\x7f7\xc8\xd6\x18\xb1v*j\xa1\xea\x8b|\xac\xf1pc\xc8oW\xa1\xd3bw`\xc4z\xbe\xd7\xe0!\x8e^\x8fU])3\xd45\xce+\xb7\x12\xe7/\x8eTnA\xf7\x9b\x07(+\x9f\x8d\xf5hmr\xb6L\xb6\xdf\xa2\xe8\xc1\x11\"\xcaC`\xf1l\xa4\xee\x16\x0dxl\xcf\x9e\xab\xbdI\x9f7\xdf\x1fE\xb7\x9c+S\xae\x82\nn\x80\xc73x\x81\xff~\xbaI\xf7^5\x19\xa0\xe3Xr\xbbG!\xa8z[u\xa6\xc1\x0f\xb7v\xf4%T\xb7~{\x88\xc7\x03\xc0(\xdaPRL\xd0\x0b\xff\x9d\x0b.;\x9ec\x14ZI\x8aU]7\x81\x03\x81\x1b ;J\xdc\x7f\xc3jluk'\xfa\x90;qy\x80\xe9\xb2\x93\xfaby\xec\x9b\x96o\x1f\xc9\xc1\x0e\xfb\x97+\xc7Xy?\xb3\xe5.\x19\x85\xf8\x87\x8b\x03\xc7=p\x94j(\xad\xfd>\xaa\xefJ7\xee\xf3w\xd8\x88\xd1\n\xebi\x1a\x91j\x06\xd7\xb6i\xe8\xad\xb0\x16\xed[\x1a\x91S\x10Z\xdd\x8e2\xfd\xca\xb8\xa6\xe6|O\xe4\xcbm\xbf\x8d\x868\x8e\x1a\xb63\x8d\x12\xb6\x0d\xe3\xd8|\xbc=Xe\xa4\xa5\xfc\xed\xd7\xc1\xe3Q0M&\x97\xea\xec\x9eH\xe5\x0c\xf1\xab\xb4\x8d\x83f\xbc\x96\x83u\xfb`W\xb4q\xe6D\x7f\x1dT\x87\xab\xdd\xd8r\x18\xe2\xb6W%\x85\xfdO\x9d\xf9[T\xfb\xa0i\xcf\"\xc4P\x8f\x98K\x1ah\x8b\xe5\xb4y\xdc\xca\xb4n\x1f\x9c\x9d\\WD\xb9\xd7\x8ck\x0dW\xb5\xc2\xc5\x1b\x01\x8fw5]w\x0e\xb0\x84\xab\x91\x80v\xca\x0c\xae\xbc+96\xed\xb0w\x8a\x17\x90\xcd\x86\xc9/\x16\xe0\x82\x12\x99\x0d\x96\xf0\xb9\xf7G\xc4\x92T\xf0\x02\x07_\xbd\xea\x9d\xf2\x97A\xe2j\xb7\x84\xcf\x16\xfb0\xb8\x1dE\xd1\xe0SK\x0c<\xb7\xf15v:\xf3n+V\xfd\x16\x97\x08\xc1y=n\x81\xf8\x1dp\xbb)\xe3}}\xe6\xdd\xa3\x10\xab\xd6\xf8&Z?\xd7C\x0f;\xe9\xbdSj\xf8y\x01\x02=\xf2\xc7\xe36Z\xc3\x027\x19\x87\x0e\xda\x9f\x8f\x0c\xdbH.\xb3\xdb\xf1\xd7\xf5\xee\xcf\x8f\xa6Y\x1a\x81\xd4)\xbf\xb5s\x86\xef\xde\x18\xb1\xfa\x13\xae\x11\xd0\x1a\xb1\xeaO@\xd6\xa6m\xc5\xc3\xe8\xc4\xc8.\"\xf6p\xc5\xaf\x85\xb8\x00v\xe2\x01\x8az\xb5\xaa\xdf\x1b\x0d\xf2\xc9\x8e\xa9r\xb34\x0d\x9e\x07\xcd=3C`\x9c\xd2\xecC\xc4-\x8bG\x91\xd2\xaf\xa8\xb0xn%\xdd\xfb,\\\xa1\xaf\x8f\x16*u\xba6\xee\xa1\xcff\x84=\x88\xb3\x82B\x03\xe0\x96\xc6\x03>\xfc\xe8\xb8\xba\x8b\x18f\xeb\xfb\xb1\x03\xb9\x83\x14\xef\\\x81\n\x1d\xf0A\x88c@\x86\xa0\x86\x14=\xe8\xe4\xcf\x1bS\xf5v\xa87\xa6jA\x80\xacw\xa0\x1a#\xba^\xbb\xa7\xda\x0b\xa1\xec\x06\x83\xe0y\x03\xbac\xd9\xfdk\x0bn#\xf9\x19\x13\x0c\xe4\x02<\x99;1\x01\xfa\xb5\xacw\xce#\xe1\xe5\xa9/\x0d\x0e\x8e\x84\x8f\xf4\x8e3\x07\xa1Og\xde\xfe\"\x1b\xabS\x0b#w\xbf\x19\xc5\xe3'\xe9\xfc\x80)\x12NH4l~\x1eH\x86\xc9~`x}\xeaU\xeac>\xa5\x0e=j\x8d\x8e\xb4\x005\xedOC>Z\x9fx\x08\xda\xfd\\\x90\n\xb7\x05\xef\"u\xd3\x98\x8d\xa9\xb4\xd1\xd0\xd5\x16\xd8\x1ayU\xbe5{\xb1-\xba\xb2\x82\xdf\xd5\x1f\x89\xd4\xc9u\xac\xa7\xb38\xd8\xf9g\xf1\xec\xf6$\x1e\x9c\x15\x0f\xc8N\xc7\xfe\xf4\x11\xc00\x11=\x16}\xf29/e\xc9\x91[\x0e\xdc\x1c\xa0\x19\x1c\x7f\xef\xa5,\x9ep\x11;\x88\xacy[\xf4\x99\xd6\x19\x0d]\xc8X\xed\xfe\xae\xde\x9fw\x0b\xcb5\xfa\x01~S\xd4\xcdZt\xc3\xb1@\xcfE\x9f\xc8\xfe(\x1eJLR\xa3#\xe1K{2\xff\xe6\x07\x8a;\xf7\x13\xa9\xbc\x9f\xf8\xe5r[\xbd}S\xfe\x1d\x8bv\x1ec\xedD\xc9\xb82\x1b\x13\xe9%\xdd4\xa6(wCE\x1cBQVb5e\xce\xa3\xbe\xccY\xd4\xf5\x10\xa6s\x98\xf6%\x05\n\xe0\x16u\x87\xdc\xba\x00\x06\xa8\xa3\xa1p\x19\xebkM\x1eU\x87\xd8\x86\xe3\x1d\xe5O\x9a\xf6\x06\xb34\x9f\xf1\x88^\xbe\xaf+{\xc0\x14\xd8\x1b\x0c\xb8\xea\x9c,#=\xc7G\x9e\xea\xca\x15\x07!\x9a\x0e\xab\xa8\xbb\x13\xeb\x84n\xf6\xfd^^\xd7\x14\xb8e|\xea8\xfb\xf6\xd6\xa7\x11\x97\x10\xd1\\\xb0\x80\x81\xc5\xbd\xd3\xd5Ea\x8f\xb6\xb1\xe2\x982\xb0+pz<V\x9f\x988\xf0\xdb\xe3\xc4|4\xd1u)V\xb2\xf9f\xdb.\x83\xd3\xf5>\xb0\x88|d~\x82\xbb\x0cQ\x8a\xa8\xddwWW\x07\xa7\xf2c\xde\x1c\x0f#W\x90\x8d\x11o\x87\x84z\xb8l\xce\xe7s\xcb\xedsI\xa0\xe7\xf1+\xd3\x9b\\\x8bN\x9cX{\xed\x82\n\xbf\xdc\xeb\x99\x84\xa7\x91\xe7\xb8\x9c\xddN\xac'\x16\xcb\xc4\x9ca\x81\xb0w=\xba\xa64\xedx\x85\xaeW\xda\xdd\xaa\xe9O\x0e\x05\xb4e\xf5\xb0\xb2\xd9wd\xc8\xc1\x11\xb0\xe9\x1e\xa7\xa5\x91H\xfb\x03\xb6\x11\xf4ba\x97\xa4\x89\xd36W+\x95-\xa8\xbai\xb6\x9b\xce\xe8K\x87\xc3\xa9y@s\xb4\xa0\x0d\xe1q\xa8\xb1\xcc\xee\x12x\xdd\xf4@Nc\xb6\xcc\xa5\xfcY.\xbaf\x8bV\xed\xb9\x18\x8c\x83k\x97\xd1.sM:<\\\x9d\x16\xad\x13\x81z6\x1e\x87\x92\x0e\xfdq\x01,\xbe\x85aK`O\xdd[\xd1W\xc9be\xf4\x89\x06\x0ebs\x98\xb9\xb7K?\xcd\xeb\x04mC\x9e5\xcc\xb1J\x8e\xc3\xfa\x10\xdfK\xf7\x19\xd9\x1d'\x01k\xc6c\xbf\xe9\xa3\xb6\x9f\x10~z\xfa\x9f\xf4\xb4\x7f\xc2\xcb\x8eS\x8d\xe5\xf14M\x0c\x9a\xfb\xc5\xd9`\x7f\xbfkXG\x97\x82%\xfc\x9b\x89\xfd\xa2\xbb\x0b\x00\xb2\x8bY&X\x91g!\x90]J\xa34\x8es\x8a\xcf2Q\xa4\x90\xaa\xc0g\x93\xcbDK\x91\xe0s\x94'\\\xb1D\xe2s\x92\x17\x94\xd2\xc2\xc2\xe7,*2&b|\x16\x92\xaa\xc4\xe8$\xb4\x14tFR!z\n\x94eQ\"\x89\x9d\xc1\xe2\x88f\x894\xf8\x9c$D\xa5ZE\xf8\x9c2i\x12\x9dZL\x19\xd1F\xd2\xc2\xc2\xe4R+\xc2E\x8a\xcf\x8a\xe6\xb2\xa0i\xdcS0q.y\xae,VSH\x13\xa7\x19\xc7gR(\x9ake\x9fYL\x94\xa0J\xd9gmr\xa6\xb8\x95-\x16i\x9c\xc5B\xe0s\xa2$\x11\xb9\xb60)/\xf2,\xd3\xa2\xa7\x90g\x91Ih\xc2\xf0\x1b\x91ETq\xae\xf1Y\x12\x12\xb1TY\xd9d\x91\xe4i\xa1z\xfe\xb8!D\x16V\x1e\x9d\x884\xa7\xb1\x1d'\\	\x1e%\x96S\x1a\xb3\x9c\xe5<\xed)\xb0T\xa6DdV\xc7\xccP\xc9hd\xb1\xc6\x9a)\xae\x0b\xcbS\x12E\x19\xd1\xd4b\xe5	\x11i\x94X-\xa5\x9c\x0b\"\xa4\xb5IF\x15S9\xeb5\xc6R\xc6T\xe6\xec \x98,L&,m\x91Q\xc1ylg(\x16\xcbL\xa6\xc4>\xa7\\%TX\n\x9a\xe6\xccd4\xb7\xcf<\xcf	g\x96Z\x11\x13\x13%=\xa7\x94p!HJz\n4\x17\xb1\xa2\xb8\x99Bv\xd4D)W\xc4\xca\xc0\xd28K\xd3\xd8\xca\x10\xc5\x92H%\x9d/QE\x94\xb4\xd4b\xa33!\xe2\xde\x0e2WJ\xc4\xd6><c\x86\x17E\xd4SH\xe3\xac\xc8\x98\xb1\xd2\xa5\x99Hx\xd4[1\x8bU\x96f\xd4\xf2\x97)\x95\x12\xd6S\xce\x894EQX\xac\"N\x08W\xc6\xca,M\x91\x8b\xa8p\xb6Ji\x9a\x15,\xbc\xb8\x9f\x8d\x8b\xc3\xa6\xee\x9a\xc9\xb3u\xdca\xf4\x87\xeb/_\x9e;[_\xeb\xef\x84\xee\x1b\x02\xbb\x89\x83\xf9)\x84>	\xe2\xf3~/\xb0r;E.A^\x01\x9e_\xec\x011\xf0\xed\xfd\xc1`\xc0\x03\xd7\x10T\xf0\xd9\x1e\xf1l6\xfet{\xf1)\xdb|\xfe\xce\xe0\xba}p\xcdH{\xb7o\xeaq\x8b\x17\xe3\xef\xd3\xaf\nUp\x8dg\x0b\xae\xc6\xddc\xc2-\xee\xac\xfa\x94kp}\xae\xc2\xa9\xe3B\x05\x17\x1bX\x8c\xf5\x89[\xd6\xd9s\xc7 8\xc539R\xc6\xf2L\x16\xc4\xe8\xe0\x82\xe4\x86s\xeb\x11R\xf2T\x98\xde\xc3#\xc5M\x11\xa5}\xe4'qQ$\x91\xf5\xa6\x84\x12\x93\xb0\xd4z_.I\xc2\xb3\xcc\xfa7-\xb2H\xe7\xc2zY\"\x0dQ\x9a\xe6\xe8\xbb\xf7#~\xdfO\xf0a\x0f\x01&v\xef\xfd\x86\xbd\x15\xc9w\xc7\xc3\xd6\xbd_\x9f\x9f\xdd<\x7f\xdf\xb7\xd1\x8f\xf3\x87\xa9=\xf4\xd9\xed)\x06\xbb\xef\x8ewU\x0e\xcf\xe0\xbcS\xb5\xb8\xe5m\xc3\xe3\xfd]\x89[\xd5\xc9=\x1e\xb3\xc2\x8f'\x834\xc3\xd1\xfd\x88\x8d\x1d\x7f\x8fc@G\x0f\xd1\xb1\xfb\x10\xe81:;\x98\x8f\xb0\xb1\x1e\x19%\x036'\xa7#f\xef1\xb4\x04\xae\xdc\x80\xbdZ\xd0\xd2\x1e\xd8\x1e\n\xf4}\xca\x1dnQ\x86\xa0B\xd0!\x98\x10\x8a\x10\x1eBX\xda\x00XN\xe8\x86<\xaf\x9aA\x16\x13\x02\x1fd0!P:\xfe\xc4\x92#%(t\xce\xc0\xc0\xe7P \\\xf0\x7f\xf1\xf1\xe1\x08\xa8C\xec\xc1\x127xZL\x12\xca>\xd9\xa8\xf9\x06\x85\xb7\xb2\xf6\xa7\xce\xb7g,&\xf0\xb2\xa2gD\xd8s\xec\xd1'\xc6\x8e(\xae\xc5OHR\xc0\xe7\xb8\x8f\xfbc\xff\xa4pJ \xed\xd3!x\xc7\x10\xdaj}-~r\x8c8\x88%J\xf8\xe0\xe1\x1f`\x01\x85\xff\x80m\xa1+\xde\x00\xb0\xe4\x0d4\\AG\x0f$\xc1\xc2S\xf9\x0f\xd8\xb9H\xff\x01ob	\xff\x01sE\xd0\xa1v:6\x9a\xef,\xbe\xc4\xe3\xeb\xab\x01|\x89WE\xae\x06TK{\xb7c \xb3\xbc\xc3c\xff\x05h\xb7{n\xafK\x0c\x9c.\xf1\xd0\xfbj\x90by\x87\x1e7H\xb8\xb4\x970\x16\xb0t\xf7\xae\x9f?x98\xd5m>v\xac\xbb\xdc\x9f\xde\xee\xfc\xe9m3u|;\x9b\xca\xba\xaeFL(\x0b\xfbk\xfd\xdb\xd6h\xc0\xf4\xbb\x16\xab\x95\xdf\xd1nmN\xd9\xbf;\xb0\xdf\x98Y\x8a\x842,(\xb1\x80\xbc\xf4\xf5\xa3NY&\x0cc\x97!\\\xfa:\x92E\xa6\xe0\x89\xd28\xe6\xebI\xa3b\x1dIV\xe0\x98\xaf+3\x9a\xe5ZJ\x85c\xbe\xbe,\xa28\x93I\x94]b\xf2\xbc\xf4\x95\xa6\xe4$\xd1\x84\xe6\x08\xe9+NQ\xd0<.r\x89c\xbe\xf2\xd4\x82\xeb\x8c\xd2\x0c\xc7|\xfd)\"\x12\x11\x16[\x0e}\x1d\x1a')\xe1\x854=\x15_\x91\xc6\xc6\xc4\x92e\x96\x1f_\x99\xea\xa4(dl\xecl_\xa1\x16,\x95Y\xce\xad,\xbeR\x8d$\xe59\x97\x14\xc7|\xc5\xca\x12\x95R\x16%=\x15_\xbb\xaa\x82\xe7\x8c\xe71B\xfa\n67\x05\x8d\x85\xb6T|%\x1beq\xc1\x12\x13\xe1\x98\xafh3\x99)\x9d\xc8\x04\xc7|e\x9b\xa6B\xe5\x8a;*\xbe\xc6Mr&	K-\xa4\xafu\xb9\x11\xdc\xc4\x99\xc5\xe8k^\xa9cZHm\xb9\xf1\xb5o\x16Q\x9aD=\x15_\x01\x1b\xc3\xb9.\x84\xec\xa9\xf8Z\x98i\x19G\x8c\x12\x9c\xedk\xe2<+\xb0\x8a\xb5\xda\xf1\xb5\xb14\xa6 \xc6X*\xbeF\x8e\xb4\xc8\xb2BY\x99}\xad\x9cGD\xa0\xdez*\xbej6\x84D\x19\xeb\xf5\xed\xabg\"\x88\xe1&\xb5\x94}\x0d\x1ds\xcdXQX\xfb\xf9Z:Q\x8c\xab\x9cq\x1c\xf35u\"T\xcc\x84\xd1\xce\xc7\\u\x9d\xeb<\x91\x91\xb6T|\x95\x9dIQ\xf0H\x1b\x1c\xf3\xd5v\xa4\xd2T2a}\xccW\xddqj\xb40\xc6R\xf1\xd57\x8d3\x16%\x91\xd7\x98\xab\xc2cUP\x12q\xab	_\x8dK\x15\xb3\x88\x10\xeb;\xbe*\xd7\xa4\xc8\xf24\xef\xc7\\uNx\x12K\x13Y\x99}\x95\xae\xb9)\x12F]\xbc\xf8z=Ix\"\xf2\xde.\xbenO\xd2\x942\xc2\x04\xce\xf6\xf5{\xc4\xa4\xd4TZY|\x15/3\xcd4Q\xfd\x98\xab\xe6\x13\x1aS!\x93\xc8\xf9\x98\xab\xebu\x91\x19#s\x1b\x95\xbe\xbe74\x97q\xd6k\xc7\xd7\xf9*Qy\"\xb8\xf5;_\xef\x9b(\xa6\x99P6z}\xdd\x9f\xa6<2Q\xea\xa8\xf8\x0e@s\xc9d&\xecl\xdf\x07$\xda\x14\x92\xf5\x96\xf6\xfd@\x1c\xd1\x94\x15\xdc\xca\xec\xfb\x02A\x0b\"dj}\xcc\xf7\x07T\xf08\xca\x8d\xea\xa9\xf8N\x81E<\xa2\x86Y\xbe}\xc7\xa0M\xc6\xa46V>\xdf9H\xa6x\x9aS\x1b\x1b\xbe\x830Q\xca\x92\x88YY\x94`id\x941\x82qNsGE\xd3\x8c\xcbL\xa5\x0c\x1b\x1fFR\x844B\x8bTk\xae\xb4!FR\xebcE\x92\xea\xb8H\x0bc\xb8\xd14\xb5\xdc\x10^\x10\x9ebX\xd0\x94\x17\xd2\xda\x8f\x08\x1e\xa5Z%\x82a\xbc	\xdeS\xa14*\xf2\x8c\xc4\xc8+\xd1\xc2b\xa42\xa5DF	\x8d\xa8\x8aSjyd\x99\x96iZ$,\"q\xaa3\xeb\x8b\x11SB\xc8T\xc6D\xa5,\xce\xad\xb6#\x95\x1bi\x88\xa0\x89\xca\xa5\x91N\x968\xa2\x9a\xa7*\xce\x15%D\xc7\xd6\x06\xb1R\x89\x8e\xa5Q221\x936\x0e\xb0+fy\xae\n\xc5\x93\xd4\xf4~\x97\x14J\xf2B\xc8Hh^\x08\xb4\x01\\r\x15\xc74\xcfT,\xe24\xc9hz\x19^\xdc\xcf\xd7b\x13\xe0\xb5\xeb\xc5+\xdfX\\\x92\xdd%V=\xb8\xb6\xb9%	O\xb3y\xbc\xbf\x86\xc1\xe3\x89[\x18X\xe2\xf0x\xa2\xa1\xb3\x1d\xddAC\xc7c\xece\xaa\xd9l\xe6\x0e\xd5y<\xbd\xd7j\x97\xc2\x8f\xf7+\x94en\xd3\xde\xc3\xfc\x92\x8e\x05\x97Y\x80K\xdf\xa0\x14\x91T*'\xd6%|\xa3\x92\xc5J\x88\x14S\x0c\x1a\xaboX\n\x93\xc7E\xd6;\xa3o\\\x92\x82\xea\x88\x17\xd4\x1a\x10S\x9ekb\x846<c\xda-Z}3\xc3dd\xb8\xa26\x0d\xfa\xa6\xa6\x901\x95\x9a\xbb`\xed\x9b\x1b\x1a\xa5\x86\xd14\xb78?f\xb0\xe3\x0e\xa8\xaf~2wY\xe8\xf8\xe6\xd2s\xbd\x0fe\xd9/n~\xf6\xbd\xea\xfe\x0e\x91\xbf*>\xd9\xffdd\xba\xc8\xf7\xd54\x8f\x0f\x9a\x9d\xca\x97\xd2\x87\xe3Y5j[\xf0\xf6\xe3\xabW\x90\xfam?\xaf\x91\xa1q\x18\xe6\xf6\x1d\xcf)N\x1c\xe7t\x8c\xd3\xb6B\xc0\xab\xe3N(x\xbe\x15\x1a{\xf7\xaf\xd3\x14\x9d\xd3\xd7H6\x13\x02\x8d\xc7B\xe1@v4\x10\xd3\xeaS\xda#\x17\xf7\x9f\xde'a\xe1:\xee\x93\x0e\xe5\x9f2/\xb6K\x07\xdc\x89\x10\xa2\x03\xfeq \xaf~\xdd\xb6i\xcf\xd7\xffO\xeft,\xdd?\xd5@\x1d\"q]\xd4\xd9\x9ey\xa21	\x96\xa8\xf1\x85\xfb{\x05\xbb\xbd\xdb\xf9W;\x9f\xbf\x94\xc6\xe3_\xa77\xdaG\xbf\x8d\xfbg\xda#\xf7\xe6\xb4_^\x8c\x1e\xdd\x02d	\xbe\x91u\x8d\x91y;|\xff\xed\xf8{V\xe1\x05,\x96\xa61\x8bh\x9a\xa6i\x94b\xfd\x97\xe0\x9e)\xcd\xa34\xcdI\x96E<\xce\xe2<:\xb8'\xb8\xae5z\x13^\x9c0\xfa\xbb\xf1\x16X\x83\xf6\x81\xcf\xf0\xa6\xc3pL\xd5\xd8\xcb\x81\x15\xfc/h\xe0\xb7\xd0\xc0\x95\xbb\x071\xdc!Z\xd7\xfa\xbb\xfa=\xbe\xcei\x8e\x90\xe2\xa6#\xa2\xc4k\x8a\xbd\xf1\x91\xb4\x0camU\xf8~Y\xae\x0c\xeeE\xbc\x02\xe2\xee\x0c\xf6\xc7\x11\x18~\x98xpn\xd0\xc0\x0b\xdc\x12pL\xf5h\x02y4h\xecE@z\xfc\x9aUc\x97S\xaf\xbd\xaf\x1c\x03\xd7\x94Q\xce\xed\xcd5\xc7\xbb\x1d\xe0UhMp\x0d\xcc\xbe\xc0\xe4\xa7\xe1\x0b\xb1ny\xa4	\xa5\x8c1\x1a\xc5y\x12%1!i\xca\x12BiBc\x92'Y\x96D\x08\x11'1\xa1\x8c\xe7\x11\x89i\x96\xa4\x8cp\x12sJ#\x96Ey\x9cgq\x9ar\xc6\x08\xabp!\x8byDy\x16%<\x8fs\xc6\xe34\xa3<\x8f- !Q\x9c&\x94G4\xa6\x11I\xf3<\xca8g	g,\xe14I\xb3\x88D\x11'\x11\xe5	Kh\x96$9'\x16#\xb5\xff\xa2\x9c\xbf.\xb7\xf0\xc2F\xe6\xaf\xcb\xf0,\xbc\xc0\x93C{1\xb6\xf4\x87\xb4e\x05f\xd7\xf5\x97eT]7\xba\xac\x04^\xf2\xbe\xfb[\x08\xff\x19\xc2\x7f\x85\xf0\xc3\xfd\xe8Z\xb7\xd1_h\x1dlBx7ve\xe1\x8c\x1dlp\xeb\xe5\x1a6\xf6M\xb0\x17\x10\xbc\xeb?\xbf\xc3\xcf\xa3J\xc1{g\x0f\x7fu\x04\x7fu\x02\xaf\x1c<C7\xda\xe0\x06\xce\x0bx\xd7\xff1\xfa\xab\x11b}\x08\xc8z@6\xde\xa7E\xf7\x92p\xddo\x16\xf5C\xb8S\xa5\xe1\xba\xcf\xa3\xfd\x10fc\xdc\xae\x1a\x0d\xe1N\x97\x84+\x10\xa3x\xbdCb\x06^@1\x0b\xadD\x0f\xf0\x02\x96\xee\xb9\x80\x17\xf0\xe0\x9e\x11f9\xbb?\xbaR\xa9\xdf(\xb1\x12\xcd\x1f\xb7\xab.hC\xd8\xec\x8f\x0f\xdea\x10\x90*D\x0f\xb3\xff\x93\xea~\x14\xc6\xedI\x18\xb7.\x8cqbo\xa4w\x88\x10\xe7\x00l\x86\xc1\xcd~\xb0\x9d\x0e\xe3w\xc7<\xba\xdfkp\xcc\xf5\xea\xf9;\x16T.\x9eQ\xcd\xa3`\xde+l\xe7\x8c\x81\xd6\x85\x17\xf0\xf7r\xf4\xdd\xd3\xf0\x1d\x1d\xbe\x1b\xbe\xecW\x8c\xe1F\xea\xd3\xf0\x1e\x0c^Q\x8e\xe8=^\xa9qo\x81\xee\x9c\xd8/_B:\x91\xf7\xc7b\xec6\xa2\xd2o\x8c\xd1Ak\x8c\x1eK\x83\x96ue\xbf\xfdj\xcf&:\xf6r\xde\xaeJe\x022\xb0!P\xa0\xcf\xf1|=CPay\xfa|\x01\x94\xa5\xfb\xcf?\xe3-\xfc\x11K\xff\x00\xf1\xdb\xfd\xcd`1\xf3\x97k~\x0b\xcb\xfdM\x8d\x88\xcd\xe0\x83?\xf45\xfd\x8a\xf5\xddV\xaeJ\xf5\x8dyr\x16\xea/\x9fn\xec(\x1ea\xfb\xf71\"\xe6\xde\xcf@\x19B\xf4\x14\xb5\xc4+\x11\xcb\xfa=l\x9a\xf2Qt\xee\xb7=\xec[\xdd]\xdd\x98\x89\x0b\x92\xc74G\xbarK\xd4\xe0\x10\x07\xde{\xaa\xdf\xb9\x08]>?s\xb3\xcd\x91zS>TvF\x08G=\xd7?@x%\xc1\x07\xeb\xc1\xc74\xf6\x86\xdal1\xa7L\xf3v\xc0\xc80\xa3q\x1e8\x98\xc4y\x80\xbf\xc7\xea.\x0d O\xb3\x19b\xf8vD\xee\xfb\xb3\xc4\x9a11O\xeb\xed\xf3\xb4\xbe\x0f\x91\xffs\xb4\xde\xb8\xc9\x0d\\\xc1[x\x81J\xe9\x99\xd9\xdbd\x8fg\x08\x9a7\xfe\xf2{_\xfd\xbc\xfe\xc3wQ\x0e\xeb\xca\xac\xeb\xaaT\xf6\x1d\x1e\xfb;/\xa2\x85MS\xeb\xad\xea\xaf\xf0\xd8\x0bx8\xf2X\xb6e]\xcdO\xad\xe6Q\xfcP\x7fc\x9e\x02\xff)\x84\xf7u\xa3We{\xf0\xaa\x1b\x8e\xe1\xcd2\x0f5\xef\x9ar\x1d\xcc\xe6]\xfdm\xfd\xde\xbfq?o7\xab\xb2\x0bn\xfe\xbb\xbd\xba\x19n\xdc\xd8\x99\xfe\xec\xf43\x88\xf6\xbf*q\xf0\xcdK\xa0l\xe2\xd6\x89\xff\x1d	O\xd7\xdd\xd2\xc0\xbc*\xcb\xee\xe8\xd5s\xd7\xbb\xd6\x8dF\xa5\xe0_\xf7B\xb8\x97\x02S\x9d\x97n\xf8\x0d\x00\x1c\xf8\xf8\xcf\x00\xfc\xcf\xb6z[\xe1\xcf\xe4 8\xfc\xcb?\xf0\xcf\x07\\r=g\xff\xe3pX\xb6\xae\x16P\xee\x7f\x9c\x83\x8d~\x9b\x83R\xdc&\xf6?\x861\xb8\x95Z\x1a\xf5\xb6\xdd\xae_\xe3l\xfc\x91\x92n\xd0\xd9\x0dD\xd1\xde\x85L\xd55\xf5\xe6i\x02\xee\xfa\x00\xc9\xed\xc5\xf1\x94\xd3\x1f\xb2\x19\xe3\xbaq\x9d\xf6T\xdb\xe8\xe0\x1c!\xd7B\xba\xc1\xa3\x1f\xde@\xe9\xc7\xbf\xbd\x91\x85\x90\xcd\xf0Hk\xc4\x8fg\x13\x16\xe3\x9f5q\x87\xcb\x0e\xed,\x04|\x1dg\xf1\n\xe4\xb4\x1e\xb3\xd3\xdf8\xe9\xfd\xcdc\xf7\\\xe0\xfbUn\x08\xf52\xb3\xfe7f\xd3Q\xb4_zw\xf9\xa8\x1f\x0e8G\x96\xf4Y\xb5\xea\x9az\xf3t{\xf1\xe1\xe2\xff\x0d\x00PK\x07\x08\x8cr\xe4\xe0\x8b\x19\x00\x00\xc3J\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00!;Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0c\x00	\x00icon-192.pngUT\x05\x00\x01\xce\"\xd3j\xbc\x90oP\x93u\x00\xc7\x7f{\x1e\xfc\xb98\x1fX\x88\xe0\x1eS\x9f2\x0e\xf0O^mJ\x18\xc9O\x02\xe6\xc3\xd96\xd72\xe8\xcf\x02\xf4\xa2\x89.\x82\x07\xec@d\x18\xea&t>\xd8\xe4\xcf\x9d\xe2\xe3\xa4\xb0\x95\x8a\xf6\x07\xff\x90\xeb\xbai\xc2t\xc3'Inn\x92Pm\xee\xf4\x12\xc7\x85\xdbX\xebzW]o\xeb\xc5\xf7s\xdf7\x9f7\x1f\xa3R.#b\xc9X\x00\x00A\xaf\xcdU\x01\x00\xbe\xf9sB\x0c\x00\xe0\xb9\x10V\x02\x80\xdf\xa5s\xd7\xa8\xdf;x\xd7s\xf5]r\x8dhF\xf1\xf9\xe6\x96\x8aMq\xb6\xd6\xa2\xcc;\xfac\xfb\x0b\x12Kg\x16\x86\xbc\xe5O\xd5]\xcf\xaa\x0f\xec>\xdb\xbbg|\xe2\xa1\xbdc\xd8\x18\xc0\x11\x10\xc4\x88\x10\x10\xc4\x88\xfe\xa7\xa7\x9e9t{\xbax\xf5/\xd1\xd1\xbe\xc0\xf8\xae`\xc0\x19\x1fn\x08\x96DG?|\xb3\xba\xc23xOR4\x9c\x8b\xf6UJ^\xe7i\x9eB\xce*I\x91\xaa\x01\xd5\x9d\x13\xa7}\"\xe1n\xf0\xe2o\x8f\xa5rY\x8cK\xe2\x87i\xa9\x87.\xb5\x84\xc0\xa0\xa2'\xe5(W\xd6\xf0\xfc\x9c\xe8\x8f_\x11\xeaH\xf6\xfa\xaf\xf7\xee\xecJ\xf6k{\x9bD\x91O\xcd\xbf\x9ebE\xa1\xdf\x8fl{Un\xa8s\x9e	/\xb3\x8c\xb4\xfb\xda\x98\x82\x86\xe3q\xb5\xaa;\xe9}W\xf6\xcb:\x14]\x8a\x91v\xe7H{U\xd2<\xac~\x0b\xf7}\xdbc\xe6\xedj\x93$yAN\x7f\xb6m\xc0\x1e?\xe1\x99}Xt:\x83\xa5W\x97x\x8f\xdf\xcbZ\x7f\xd0,l\xdd\xfa\xc1:\xea0>p\x13}lk\xf2\xd4\x84u\xb3\xedS\x85R\x16\xfa\x87\x91\xdf[\x1a\xe9&d\xb8\x96\xcb'\xbaw\x9c\xb8\xaep\x18\xa4,\xf4\xf3\xc8\xef\xfd.D\xcf5A\x1e\xbdA\n)\xfd\x0f\x82L\xab.\xcbz\xfa\xe2G\x8dRe3\xc4\x84\\5r\x9c|\xd1\xfb\x8aeH\xcaB\xcb(\x02\x82/\xe2.\x07\xbf\xac\xd8\xf1\xb3Q\x8ek\xb91\xe2\xef1\xb7\n~\x8a\xb3n\xdf\xa3H\xe0 \x8f\x0e\xcc\x13Rz\x80\xfd\x13\x9cXo\xb1\xea\xae6\xaf\x90\xb2\xd0\xe2\xfe\xab-\x88\x19\xaaA\xba\x07\xf6\x8c\xe0\xackZ\x8aP\xf77\x02\xcc\xa7A\xf2S\xbb^ZJ\xbfM\xae\xc5\x87\xdf\xe1V\x06\x1c\xb7\xe6\xbb7\x08m\x08\xbc\xecN\x88L%\xe5E\x13\xd2\x0bb\x16b\xb7&6.\xb9\x7f\xbf\xe6\n\xa1_\x80oj\x9e\xef*\xad\x1f\xe3\x11\x99fn\x04\x98o)\xf5\xda\x16oR\xf9\x1c\x13\xe4Q:\xd1\xdd\xf4\x9bI\xd9g\x90\xb20v3\x952\xb9\xed\x91\x15\xb3d\xb8\x96\xfb\x0c\xaar\xac\x07\x94\xceB)\x0b\xf7\x96S)\x93\x9f7\xb9\xa0\xea\x89\xe7\xc8\xce\xb4\x0b\xb2k\xc9l\x99\xb1\xc7\xdd?\xa5\xeb\x12i\x1e7=t\xd8\xca\xa6\x13\xc6\x82\xa2\xd4x\x8d\xb9\x7f\xf1`\xe7\xa2vt\x92`\\\xbdvPY\xdbU\xb9<:\xaeq\x88\x03\x1bo\xae\xcc6<\x1bz\xdfp\x9b\xd10I\x9a\x92%\xf0\xd0*\xb51\xec8\x13\xb8\x94~dta-O\xa5\x84z\xcc\xce\xb9%\x03\xfb|-N?\x88\xd83\x86\xce-_\xf4\x82B\x8e/S\xe7=\xcd<#\xa8~+1?U\xac\xcc\xc8oe\x1e\x08\xaa\x8b\x13\xd7\x95\xc5r\xb2'/\x9e\xef\x14\xfa ]\xe5n\x81\xcc\xd9\xeeF\x1d\x08a\x93\xbb-\x03\x1b\x80k\xc6\xe5Lf\xf1f\xf2_\xaa\xff\xa7 1_\x15\x88_eo\xeb\xb8q\xf4Q\x00\x00\xa0\xf3\xe4\xb9'r\x8aw\xfe1\x00PK\x07\x08S\xd8\x8e`n\x03\x00\x00%\x04\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00!;Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0c\x00	\x00icon-512.pngUT\x05\x00\x01\xce\"\xd3j\xecV{PSg\xde~NN\x12\x92\x10H@.QD\x82\x0d\x8a\x08\x1a\x8b\xd5\"\xb4\x1c\x10\x14D\x05\xbc\x14\x055\x11\xbc\x80PQ\x16\x14\x15\xc8	\xe2\x1d\x04\xc1\x0b\"\xd5\x88\xa8\x11E\xb1ZoT\x88w\xaaR\x01\x15P[\x89\x97\nx\xa9RC\xc1@\xc87\xee\xd2]\xbf~\xdf\xce\xec\x1f;\x9d\x9d\x9d>\xf3;s\xde\xdf\x9c\xe7}\x7f\xcf\xef\xcc{\x9e\xf7l\x08\x99<\xde\x8c\xd7\x8f\x07\xc0,0\xc0o\n\xc0\xc0\xfb\x8b\xf3\xfe\xb6\xaa\xdf\xd8R\xc0&4\xd0\xcfgZr\xe1\xcb\xbb\xa1\\P\x1c\xd6\x85'e\xb5/\x9a&	'>z\xd90*\x7f\xc2/\x92\xc0\x0e\xbd'\xfe\xc4\xbf\x1f$u\"\xcc\x84\x04\xc0\xb9\xdc]\xba*c\xe9>\xd1\x07\x0f\xff\xc4\xbf\x17bs\x0d!Z\x95\xb4$]\xceg\xce\xb5\xba\xb09r\xbeM\xe4\xfc\xfby&\xdf\x05f8T\x0f\xba\xd7^\x1d\xe3\xd29\xe0\x1f\xf4\xffVh\xa5hN\xbb\xe9\xf22-Ug\xf7\xf5\x8d\xd2\xf6\xd8\xedi\x07\xeb\x0b\xeac\xcf\xd7W\x9e\xde\xcd\x9b\xe6|\x8c\x01\xe4\xb0\xe9\xd4\x8d\xb7\xc8\x01w\xde\x00h\xb4\xa5]\xaf4\x13\x82\xdc\x95\x00^\xb2\xa8\xac\x96K`\x0f\x91\x02\xa8'C<\xf5R\xfa\xc2\xa9\xf7\xdb\xf6\x06:o\xfd\x9cG3\xbb\x02\x00\xdcdS\x1d\xb2\x1f\x07\x13\xdc\xedA\x00&2\xa8\x0e\xef\xa4=L\xc9\x0c'\x02\x10\x9a\xd3:\x87`o\xb6T\x9aG\x00b\x1e~4\x1e\xed\x92\x88/\x8c\xe4\x02t\x1b\x02\x8cj]\x14\xc8\xbd|\x00\x1f\x119=\xebuQ }\xdegQ\xc8\xe9i\xd1E\x81\xbc\xd4\x17@\x1a\x83\xeaX\xb8l\x0fs\xbf$\xf9\xeb\x85&j\xb3K\x94\xd0\x7f\xee\xcf\x8a\x96)\xbc\xfd5\x076D\x05\xcd\x89q\x18\x93\xde\xb3\xa7\xb6\x91G\x11G\"Pd|\xd8\x12F.\xd2\xa6\x98\xf9\xe9\x83\x14\xad\x95\x89\xfa\xf2\xcd\x8b\x1a\xe6\x123\xf0`\x18fN\xf5\x88\x9dHL\xd5\xd6X\x85\x80\xa7\xbd\xcb\x10\x1a\xb6\xed\x93\x99\xa9\x95\xa3\x17Q\x843\xdd\xcc\x12\xeb\xc36\xa4}\xa2b\x9b\xdf\xa5\x8a+u;z\xba\xf6Wn\x8a\xe8x\xec\nS\xd5eF\x8ex\x95d\xfd\x109\xcf\xab\x80\xc1\xa3\x88\xeeP\x14)\xb2[\xc2\xc8EZc\xbf\xf5\xba\x1d=u\x8a.\xdd\xf2\xd2\xf29\x87Yrb\xd6)\xcc\xf3[\x97)eMT\xbe\x1e\"l\x1dn\xd8\xaf\xd8\xa3[^\x9b:\xec>\xa3\x0cs\xc2a\xb9w\xe1\xc0|\xc6\x01\xaa\xb8R\xb7\xa3\xc7\xecv\xd3\xdd\xe5\x15+Y*|nJ1Gf\x03#M4\x83U\x07\xf6\xd0\xf9M?\xa4\xecY]\xa00\xd7\xeb\xfb[\xb5\x0e7\xecW\xbc\xd5\xad\xf3\x8b\x90\xf8&\x95L=\xbf\xfa\xb9\x06W\xe3\xa5\xbe	\xb9y)\xf1\xdf=\"\xb2\xc7'w\x9a\xd4\xfc\xe4\x80\xd3\xe5g_\xed\xa0e\x8c`\x05\xd7O\x1f\xa4h\x1dnP\x1c\xf0\x96\xeb\xd1\xe7\xe3\xbc\x9d7WU\xf1\xed\x1a\xfb_\x194\xf2\xc0\x88\xac\xb0\x06\x91\x97v\x85`\xb70_\xc3\x9c\x13&\xe7\xf8\xb4\xf0}\xf7\x0eK:T^t\x8eb\xd4\xad\xc8\xf1\x0d\xaf\xcb]\xe6\x16\xf3\x88y\xf0\xf0\x12\xde\xec\xd6	\x0d\xd3\x13Y\xd6\xea\xa3k\\\xfc\x9c\\B_l\xe2\xb9EgQC\xad\xf6;\xfb\x0f\x1d\xca\x93\xd2Y\xa4\xebS\xc9`\x8f\xd8\xeb\\\xf7\xfc\xac\xab\xae'\x8b\x9d\xdc\x0e\xc7pG\x95U+\xdd2$\x12\xc7\x17\x1c\xaeGU\xb5\xdf\xb0i\xc5\x03\xad\x87\xaa9^\xdaj\x93\xe1\x12\x89#\xf7\xf56a\xda\x9br\xd3\xca\x9a\xec4]y\x90\xb70\xfd\xcd\x1c\xceX\xaf\xc7\x16\xf1\x15\xc9!\x19\x91,\xab\xb1\x1e\xd7C\x17\x1fH\xa0\x99\xf3\xec\xc5\xd6\x9a\\\x96\xf6\xd3\xcd\x7f\xe9\xb9\x1c{#\xf6Fl\xec\xf7\xb1\xa6\xdb\xcb\x8e\xac\x9d\x1f=\xce\xbd \xab\x9e_\xa8=\xc2]\xc8\x19'M\xcf\xea\xc3/\x12\x96fF\xab\xfd\xdd\x16e\x85\x98\xaa\xa9RA	c\xc6\x01\xf4\xbf\xb6\xf4\xe8gm%\xbf\x94\xfcRr\xee\xcd\xf4\xf6\x0b\x9b\xed\x9f\x8e\x12\xae\xa1\x84\xdb\xce\x18\xf7\x9e\xa8\x1a\x94\xfbS\xb4\xe5\x03Mw\xda\x925\xe3\xee\xae9\xb3\xf1E\xd6\xe3\xf0\xef\xbf\xf3\xf08\xb8\xfc\xab\xbc\x1a\xde\xab\xf3\xbe\x11\xe6\xdf9z\x147\x08\x02\x13\xfd;6\xd7\xb2\xe8W\x90^\xcd\x17Lj\x0d\x19\xb3\xd5\xbc}\xc2:K9Q\x11O\xdfh\xfbt\xc0\xb5\xb7\x95O,\xa2FT\xac\xca$\xe4\xc3\x919\xe2\xd9\xbe\xa9+\xf3\x1e\x16\x8f6|3\x9a{B\xba\xc7\xe2Y\x82\xd4\xfc\xea\x85Y\xde\xa2-6\xady\x03\xcd`G\xc83G\x98M=\x97{\xb4x\xe8\xec\xd6	\xe9n\x8d\xcd,;\xd4\x134!\x05[\x03\x11UDD\x07\x9aF\xe4&o\xea8\xd46\xac\xf9\x91W\xe1\xb5\x13o\x85	\x8e\xde\x9eOQ\xc6A}\xf8\x83\xb3\xfb\xfb\xc6\x8c+\xa8\x8dr\xb7L\n\x0d^?\x06q\x04H9\xf84!\xa5\xfd\xc8\x9a\x0d\xbf&\x0eT\xdav\x16jH\x0d\x0fR\xb05\x10\x81\x0c\x18\x89/\xa3\x03\xed,W\x1d\x0f\xde\x94\xeaA.\xd4\xf23d\x9d\xee\x97\x04\xb8\x84\xb2\xfa\xfb\x87\xdc\xcc\x02\xc8	\x8b)	\xcd\x94\x04\x01\x13\x194!\x05[\x03\x11\xa5&\x00\x00\x005\x10U\xaf\x8ft\xeb\x19\xef\x18\xef\x18\x84F\x04\x11H9\xf84!\xe9G\xd7\xbb7\xae\x0c\x00WL4Y\x02\xee\x90\x83O\x13R\xb0s\xa2\xa1\xff~Z\x93#!\xa1\x99\xdd[\x01\x11@\xca\xc1\xa7	)\xedG\xd6$\x8e+0\xe7\x8a	\x896\x94\x01\x95\x1d\xf84!\x05[\x03_3\x00\x00\x00K\x82J\xe8rj\xb0\xad\xb2\xad\xb2m\xb9g\xcbA8\xa1\x81\x08\xa4\x1c|q-N\xeez\xb6l,#\x00\xdc\xce\xd9\x0c\xa8\x04\xe0\xd3\x84\x14l\x0d|\xcd\x00\x00\x00,	*\xce\xf3J\xbdm\x95m\x95m\xcbu[\x0e\n\xa0\x81\x08\xa4\x1c|\xf1\x1d\x00\x00\x00\xb1\x05\xb6\x9e\x9a\x14\x93\xaaLQ\xa6(\x8f*\x94\xd0\xf2P\xd1\xf8M\x94\x0b'R\xb46\xbd{\xa6\x03}x\xd4\xf1)3VG><}\x04V4#\x04<\x8a\xe8o\x0d\x00\x00T\x16\x18\xd15h\xc1l\xe5,\xe5,e\xc5\x1c%\x84&\x14\xe1\x0c\x96\nVT\x0c\x01\x00\x00\x00\x00\x00\xd4\x84\xc6\xfe\xed\x05\xfb\xdb|9[J\x16V\x03U\x0c\x15\xachF\x08x\xaa\x93\xbfw\xf4;\xd0\xa6\xef\xac\x90\x17\x107\xb1\x80\xde\xd5\x17\xf0\xf9{\xf5\xad6\xbf\x91~\x8fe\xa4\xfc\xdb\x8d\x9b\xc3\x96\xc9=\xcb\xec;\xd3L\xd8\x14\xef\xafR\xde\xcf\xdb\xcf\xfa\x80\xf7!\xe8\x1f\x91_\x9apb\x9b}\xa3y\x9c\xc9\xee\xfbJBH\xfc\xf3\x16\xfe)NA;\xdfM\xf7\xf5\xb3>w\x06\xab\x83k\xee\x05\x87\xe0&\xd9[\x99\xa7:\xf9\x01\xf1\x0f\xc1\x1b\x81&{\xb6\xa7\x9a\x10\xaeMwv\xe6%\x16\xed\xec\xc3\xbe\xde4\xa8\xecv\x1fv\xd5\x8d\xc4 0\xa9\xff\xac\x90YhV\xd8\xdf:\xf8\xe4S\xf7k\xf1\xdb:K\x99\xf2\xcf\x7f\xd3\x17\xe2\x02B\xf8/Gg\x18\xbcf\x06y\xcd|\xcb\xbec\x17\x12O\x994\xd1<\xca\xe5_Y*\x9aC\xdfs\x19\x191\x93v\xd3\xd8h\xb7\xb0f \x9d\x10\x83\xf1>\xe8)\x04\x87\xc6\xff\x1f\xd4Xd\x86\x9dm\xbb\xce\x88#N\xa1r\x16\xcd\x12\xdb\xfdm\x12\x18\xce\xd6\xffg\xc6V\x96\xc6l\x81\x8e}\x1brH\xe1*\x00C(\xf8\xad\xb1\xad\xbc\x0f^\xc6\xdf\xe4^gh\x07\xb8\xa7f\xc7@\n\x11l\xd8 \x1a-\xb0\xb0\xe9\xec\xbd\xe3\xfb\n\xd7^\x14\xae\xbf\xc8\xed\x92\x9d\x14LJ\x91\x86GT\xb3~\xf6v\x88\x9b[\xb5\x93$\xe5\xe0\xd3\xc4\x91\x8f\x7f\xdb\x06U,\xec\xfc2g\xa6\xd1\xc1\xe8`\xdcs\xba\xc7\x1b5L\xb0{\x1dd/\xbb\x97\x84\xb3P\x9d\xfcl\xc2\x0b\xe3\x13\xe3\x13\xe3\xdb\xc7F\x13\xe8\xd1\xcb\xf9\xd0%lq\xf9\xd4\xa48\x83\xa2[\xd1\xadH3\x18A\x0d\xed\xe5\xd0DF?z\xd9\xed\x1f\x16\xdfQ\xb4\xcb\x0c\x8a)FBe\xf7^\xc6\xef\xbc,\x81\xa0\x12v95\x18\xaf\x19\xaf\x19\x9b\x1f\x18\x05\xe0\xa3\x97#\xa2\xd4D\xf4\x98\x8f&\x8d7\x84\x19\xef\x1a\x87\xb7;`\xc1\x87\x02t_\x1d\xaa\xd8\xd7s\xd8\xf8\xdc\xb8\xd0`B\x8f\x80\xb4\xb7\x05\xce'(]X\xd6\xae6>W$\x1b\x14w\x14\xc8g\xfe\xdd\\C\xc8\xde\xaa\xf4,\xe44\xf9\xbck7\xb6\x19\xb9F#4\xd2\xdeu\xff*\x9a=\xf2!\x9d!\xeb\xcc\xbb$\xfb^\xd3\xf7S\xf2\xb8m\xd7\xcdFR\xe3\x85\xcc\x11\x0b\xe6M\xb0\xab[{r\xefx;\xcbde\xb0\xa9\x17\x8e\xe0\x83\xb5k6\xfc\x9a80\xd4\xb6\xbdPMjl>\xd4S\x1d=\xdd\xdd2\xc11\x98\xb3\xc2C\xd0\xfc\xc8+m\x8c\xa0\xcd\xe6\xf5\x89'f(\x87<s\xc4\x97S\xc3s\xb7\x17;}\x16\x1f\x98\xee\xd6x\x90\xfd\xbf\x0e<5\xd12\xc1\xb3<7`\x93n\xc5\x96a&O\xe4\xfdo\xbe0\xf9\xe8\xe6\xc5\x94)\xf9\x84|\x08\x8e|\xf3\xebs\xb7\xf3\x81\xf1u\xbb\x1e\xbe\x9a\x14\xe9zu\xe1\xe53\xfd\xb3\x9f,\xf5Rx\x0e l\xb4\xe5&\"|Aj\xc2c\x8f=\xa8\xdb\x9b\xe8l\xff\xdc\xdb~B\xa2\x7fGE\xedy\x02\x9f7\x9e\xd8\xf7\xc3\xb4H\x11\xef\xd2\xb9\x87\x96\x9e\xe5/\xe2:\xa69J\x8a\xb7O\x11U\xcd{\xd9\xb9)Y\xfb\xa3Q\xe15\xd8\xd1r\xcb/\x9b\x98\xe15_d\x8cj\xf1\x1b<&\xab\x8e{tI\xe9\x9a\xc3\xac\x90(\xe2\xd3\xcdO\xf8\xde\x8f\x0e_\x0f\xdbv\xac\xe4\xb8l:\xc5Zd\x1d\xb2:\xfcNQ\xfd\x80E>VE\xef,\xcc\xd2-2\xe5]\\\xf6\xe4%\x0b\xd6\xbaG\x8fs/xU\xda\xd7u\xee\xce>A\xa6\xaa\x87\x8c%\xfd\xaf\xf5M\xb8\xd8\xb0\xfd\x98\x9b,\xe2/\x15\xa7\xc0\xad\x8a\xa3\xd8?9\x8f?\xbb\xf3\xba\x8f\xc5\xc6\x15!\xfc\xc7\x16\xd9\xc9o\xaf\x90\x8e\x1cwJ\xa4\xdb\xe7`\xd8\\'H{\xe3iS)v\xe4\x06&\x1f\xc9\xd0\x90\x0fC\xb5,\xebC\x01W\xf8.\xbe\xe6	o\xb7\x98\xfaZ\xe6\xe6\xac\xc8`\xda5.\xb8hs\xb2\xd8\xc9\xedD\x0c\xd7=\xbf\xfa\x82kU\xe0\xe4\x84g[\xfb\xc8\xf1\xc0\xaeL\xe5\xec?\xa4`fh\xb4O\xac\xf7\xc9t\xb5\x9f\xd3\xa2\xb4P\x1fV\xc9g*\xde\xb9\xbamG\xa7'\xae\xe9Sx\xf5\xb1U\xb1\x93[Ll\xa0\xa9D\x9c\xc5\x18\xa2\x0e\x9a\xfd\xfc\x8bi\xb6\xbe\xc2\x95\x0e{\xc5R%wV\x98\xfc\x0d\xddl\x9f\x9c~u\xe4\x81\xa5IYs\xc9]\x0de\xdc\x8f\xf3v\xde\x8c\xad\xe2\x8b4q\xacgM\xad\xf3\xea\xdc\x06\xa5\xbeK\xed6~\xdbck(\x92\xe9Ng\xa5\x99\xa9BI\xd5\xf2\xe0\xf3\x8f\x88\xec\xca6Nn^\xe1\xd3\xd8 s\xbe\xfc\x07\xf2#\xf5\xe4\xa4\x92\xe5!\xf6\x969g\xfc/\xdaQ\xc7F#\x98\xb1\x9b\x99\xe6\xc5]\xdf\xb3\xbb\xf6\x18\xa1\xfb\x12O\xe3\x87\x19\xfd\xd3\x00\x0d#\xfa<\xfc\xa8f\x8eT\x7fj\xdfr\xd6S^K\n\xeb\xe9\x18usRN\x8b\xad\xa1\x1f\xcd^\xb2\x18\xbe{#\x9cE\x89y>\xb1\xe8\xa7Y\xcbV\xf1\\e,\xbf\xca\x96\xcaq\x95l\x15\x91\x12\x8b\xc8\xc8W\xf9\xd2z\xa7k%D4V\xdb\xd1.e3\xac\x85\x86\"\xe3(\xbd\x1bL\xb5\x17\x18e\xec\x92cD\xedp\xfdk\xff\xca\xa1\xe20\xaaRFp\xb7|\xe2{Y\xbb\xd1|2Y\x03\xcbO0]5\xcbZh(2\xce\xd5E\xc0Fs\x8a%7{*#\x8bd:\xef\xbe\x06\x1b\x9a\xf5\xe6:\xa3\x8c\x9dp\x94\xa8\x1d\xae\x1f\xed_9\x1a\xf1\x08>O\xac\xdf\xd5\xbdt\xea\xe2\xfe\xb2\x97\x0d7\xcc.\x86\xf0\xfd}6\xf4lZp\x8b\x1d\xd0]m\xd7 \xff\xaa\xf6\x1b\xb8\xc2T\xde\xc6\x90\xb2\xd6N$Zw\xf4\xc4\xb6\x9a\x00\x0c\x88}61\xcf\x1d\xebZ~N\x000IZ\xd9\x8f],\xd3\x8f\xad\x13\x00\x97IZ\xe9\xc5.\x96\xe9\xc9\xdb\x02`\x1eA+g\xb0\x8be\xfaK\xb7\x05\xc0S\x06\x95\xbe\xcb\xb2\xa5\xe2\xe7\xc1I\x0e\x80\x9a\x04i\xfcXl\xa8\xdb\xf3U\x1a\xa0\xb2\x82e\xca6\xaa#\xa9\xf2@\x17@\xd9A\xd2\x99Cu$U\x9e\xe9\x02(o\xac\x0bRu\x9dk\xba\x9b\n\xc8\x85h\x96\x8b\xf5EM\x03R\x01\xf90\xdc\xbaD\xe9\xfc\x15\xd3t\x04h{\xb8\xb6Sm\xd6\x8a\xb86\x02t)2\xdb\xa96kEP\x1b\x01z\x1dj\x8a\xd2\xebc\x0du\xf5$p\xcbD\xe3\xd1\xd1u\xfe\xdb\xec\x8e\xb3=\x9f\x03\xe8\xf5\x9a\xff\x1ah\x94\xf8\xf6\xde\xb1\xd5\xef\xff{\x99\x91=\xf5\x9f]<37\xf3\xf7\x87\xd9\x9f\xe9\x1f\x96>f\x9c\xf9G\xf2\xe7\xe0\x8f\x1e\x88\x87\xd1\xa6\xf2~\xf33?\x96\xf35\x10\xee\xb0\xb9\x13Ux\xbf\xfe\xfd\x87\x12\xe8?\xd9\xef\xa8\xaf<\xfd\x7f\x06\x00PK\x07\x08\xeb0#\xed\x91\x0c\x00\x00\x8a\x16\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xa8:Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\n\x00	\x00index.htmlUT\x05\x00\x01\xec!\xd3j\x9cTKn\xdb0\x10\xdd\xeb\x14\x03\xaeZ\xa0\x89\x90\xaei\x02E\xd3\x02\xdd\x14\x01\xd2\x0b\xd0\xe2(b-qXr\xe4D1|\xa0\x9e\xa3\x17+H\xd1\xb6\x9c4E\x10n\xa4\x997\x9f7$\x1fw;0\xd8Z\x87 \x1ar\x8c\x8e\x05\xec\xf7\x954v\x0bM\xafc\\\x89\x0e\xb5\xc1 T\x05\x00 \xbb+\xf5\xc9\xff\xf9\x1dH\xd6\xddU\xf1\xadGfr`\xcdJ\xb4\x14\xee\x90\x05\xf0\xe4q%f@@g\x8dA\xa7\xbef\x1068EY\xcf\x98\xaadm\xecVU\x95\xf4\xb9@d\xcdc\x14\x87\xe6\xc5T\xb2\xf6)\xa6\xa50\xcca\xc8\xa3?E\xcdVi3\x93\xf2\xf37\xad\x1b\x1d\x19\x81;\xcc\xad!vt\xef`=\x81l\xc8\xa0\xd2\x1e\x03\x81\x0f\xb4\xb5\xd1\x92\x93u\xf6~\x00\xb4\xdca\x00\x1dap8\x90\xb3M\x04\xcav\x87\x0f\x97\xc7\xe2?:\x9c@\x07\x04r\xfd\x04\x91)\xa0\x01\xeb\x80;\x1ba\x1d\xe8>b\x98\xa3\xe7\x19R\x96\xec\xf5\x1a{h)\x94I.|\xdc\xe0$\xd4M\xc0\x8b\xd8\xe9Tb\x83\x93\xacs\\\x19\x88\xf1\x81u@}\x9a\xbfd\x81\xd3\x03\xaeD1R\xc7\x95\xf8( \xe0\xaf\xd1\x064J\xd6\x87\xd4\x17\xdb\xa3k\xc2\xe49US_\xe6\x7fK\xeeU\x1c\x16\xa9\x85\xc8\xd2\xf3&6\xd1\xde9\x1f\xecV3fF\xb7\xf6\xceAq\xbc\x8a\xd3\x93\x02\x85\xd7S\xef\x9b\xb8\x19\xdc\xda\x06\x85\xba\xce\xdf\\\x19\xdeQ\xde.\xdd\xbf??1\xeb\xfc\xc8\x0bZ%\xb7\xd09X\xb3T\xd2\x14\x02\xf4\xc8\xd4\xd0\xe0{d\\	j[\xa1\xaa3\x8d\xcd\xc1q\\\x0f\x96\x85\xfa6x\n\xcf\x05\xd5R\x18\x92Z\x92\x88S\xf7A\xdb\x93\x08\x977 \x81&\x90\x7f$\x87G1\x1d\x1d'\x01-&im\x8f\xf1\xa0\xefd\x08\x18\xc6\x9e\xad\xef\xf1\xacCZ\xd7\x81<\xa4\x98$\x99\xa4\x90\x00Mo\x9b\x0d0A\xd3\x11E,h\xb2\xc9O\x07\x99\x94=,\xd7^\xaf{<pC\xc7\xc1b\\R\xe3\xf4>\x9d\xec\xb4$\x87sGZ\x92;\xf5]\x0f(k\xee\xfe\x8d\xde\xda\xc7\xff\xa0\x9f\xc9[4/\xe3\xf3\x85x\x19\x7f\x8e\xc8z\xc9S\xd6O&\x91\xbc&3\xe5M?\x8e-\xeb\xec,\x87X\xe7\xbdY<\xa1\xb1	\xd6s9\x9c\x81\xcc\xd8\xa3\x80\x18\x9a\x95\xa8\xb5\xf7\x97?s\x859HU\xbb\x1d\xa03\xb0\xdfW\x7f\x07\x00PK\x07\x08\x18\xe6\xd9(]\x02\x00\x00\x05\x06\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x000;Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0b\x00	\x00layout.htmlUT\x05\x00\x01\xec\"\xd3jl\x91\xc1n\x131\x10\x86\xef~\x8aa\xb8\xe2\x0d\x85\x0bH\xeb\x95\xaa\x02W8\xb4\x12\x1c\x1d\xef\x9f\xd8\xaa\xd7^\xd6\x93D\x91\xb5\xef\x03\xcf\xd1\x17C\xc9\xd2\x12\xa0\xbeX3\xff\xff\x8d=\xfak\xa5\x1e\x9b\x90@\x1c\xed1\xef\x84i\x9e\x95j_|\xf8|s\xfb\xed\xcbG\xf22\xc4N\xb5\xa7\x8b\xa2M[\xc3H\xdc)\xd5z\xd8\xbeSDD\xed\x00\xb1\xe4\xbc\x9d\n\xc4\xf0\xdd\xed'\xfd\x8e/\xa5d\x07\x18\xde\x07\x1c\xc6<	\x93\xcbI\x90\xc4\xf0!\xf4\xe2M\x8f}p\xd0\xe7\xe2\x15\x85\x14$\xd8\xa8\x8b\xb3\x11\xe6\xaay\xfd\xd7(/2j|\xdf\x85\xbd\xe1\xaf\xfa\xeeZ\xdf\xe4a\xb4\x12\xd6\x11\x17s\x03\x0c\xfa-\x9e\xf9\x84x\x0c\xd0.\xc7<]\xf8_\xbe=\x9fG\xbf\x04\x89\xe8\xae\xc7\x87\x9fS&\x089\xeb\xac\xdf=\xfc\x10\x94v\xb5\x88jY/\x86tO\x13\xa2\xe1\xc1\xa6\xb0A\x11&?acx\xf5\xd8h\x0eX?\x89\xdd\xbf\x98\x1d\xc7\x08-y\xe7\xbc\x0e.\xa7'\xfcT\xe8\xab\xf7o\x9a1m\xff\xc7\x8a\x1c#\x8a\x07\xfe\xbcwn5\xae\x14\xeeT\xbbZ\xd2Q\xed:\xf7\xc7\x85\xae\x95\xd61\xbb{\xe2\xdfk354\xcf\xb5\x12R\x7f\n\xbd]-\xe6\x13}\x0e]\xd5JH=\xcd\xf3\xaf\x01\x00PK\x07\x08\x9b\xabf\xd9J\x01\x00\x00&\x02\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00#;Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x14\x00	\x00manifest.webmanifestUT\x05\x00\x01\xd2\"\xd3j\xacRM\xae\xd30\x10\xde\xe7\x14#\xb3{j_\x94\xa2.\xda\x1d\xe2\x00 \xc1\xee\xa9\xaa\xa6\xf6\xb4\xb5^b[\xe3	4\xa0\x1e\x88sp14\xf9k\x90\x80\x15\xb3\xb1\xf3\xfd\xf8sf\xfc\xbd\x000\x01\x1b2{0\xef\xd2\xcf\x1f\x1c\xcdJ\xb1|\x8d,\xc7?1\x8e\xb2e\x9f\xc4\xc7\xa0\xa6\xf71u\x80\xc1A\xc2,\x04'\x92\xafD\x01\xba\xd828\xfa\xe2-\xe5\x15Ppk\x89k\n\x0e(X\xee\x92\x90{\x1e\x83\x04Y\x8e-\xd7zX9b6&Z|;\x9fS\x8d\x9d\"Y08\xacc\xa0AzB\xfbz\xe1\xd8\x06w\xb4\xb1\x8e\xac\x9a7\xe7\xbe\x06\x81\\\xa9\xa1\x05\xf7\xb6\xaf\x81\xf36\x86l\xf6\xf0R\x00\x00h/\xb4Lf\xab\xd2R\xe9u\xb5\xdb<\xa7p\xe9\x0dZ&\xfbo\xa4&S\xed6\xb7j\xb7y0\xd2\x0d\xb7\xf6\x0d^\xa8\xfc\xcd\x94ZN1\xf7,\x86\x0e\x1a\xcc\xafx\xaa\xc9\xf4\x91\xf7\xd5?\xf2\xb7\xd5\xdf\xf2\xb7\xd5\xe6\xb6\xad\xfeG~\x01p\x18\xa7\x8eLGA\xbe\x90\x98\xfd\xd8\x11\x83v\x1av\xd9\x0b\xc6D\xd3\x90\\\xa3\xd33?~\xf8\xf4yB)\xd8\xe9\"M[\x8bO\xc8R\x9e#7k\x87\x82\x93*!c\x93\xe7\x0c\x1d\x94\x97\xbaw\x0d\x9b\xc7_\xd1M\xef2\xac3:>\x18]f\xec\xeckz\x8cs\xd9R\x80\xc53\x1fd\x93K\xcb\xa0\xb5\x944\xe5\xc5<\x95O\xe60s\xf7qw\x98\x1bu/\xee\xc5\xaf\x01\x00PK\x07\x08\x1a!9\x86w\x01\x00\x006\x03\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00A\xbeyO\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x1e\x00	\x00partials/provisioning-key.htmlUT\x05\x00\x01\xaah\xdc]\xa4\x90QK\xc40\x10\x84\xdf\xefW\x0c\xa1\xc2\x1d\x98\xbbw\xe9\xf5\xbfl\x9b\xad\x0d6kI\xda\xa2,\xf9\xef\x92b\x15\xb5o\xbe-3\xec7\xbb\xa3\n\xc7\xbd\x17\x86\x19\xf8\xed\x85\xdf\x0dr>\xd5\xce\xaf\xe8FJ\xe9\xfe)[G3\x99\xe6\xa4\x8aH\xf2\xcc\xa8\xfc#\xaa\x16Ow\x9c\xe3\"\x9cp\xbd\x94MU\x0b\xdf\xe3\xec\x13\x8d\xd3@\xa8\xda\x0b\xec/\xe2\xe6\x98F\x15S\xf42\xf70\x0f\x9d)\xb0\x9c\xeb4\x91\x14\x87\x9c{\x95\x92R\xc4\xdb\xa6\xd67\xe7\xd7r\x81\x05\x8f\x89\xff`e	-\xc7\xffq\xc5\xedO\x94qK\xd8S\xbf\xcd\xaf\xc6B8*,\x84\x9f}]q\x80\xf9\x18\x00PK\x07\x08\xea^\x06\x85\xb0\x00\x00\x00x\x01\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc8AQ]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x10\x00	\x00provisioning.cssUT\x05\x00\x01X.\xd3j\xccV\xcdn\xdb8\x10\xbe\xeb)\x06-\x02$\x80)\xc8v\x9a&4\xd0\x17)z\xa0\xc5\xb1\xc45Er\x87\x94m\xad\x91w_P?\x96\xe48\xd9\xeen\x0f=\xd8\x12\x87\xe4\xcc\xc7\x8f\xdf\xcc\xa8\x0c\x95^$[+\x1b8'\x00NH\xa9L\xc1!\xdb$\x00\x95\xa0B\x99\xd9\x80i\xdc\x05\x0e\xa2\x0ev\\\xc1H\x15\xe5\xc4zT2\x94\x1c^\xb2\xbbM\xf2\x9a\x8c\xdew\xd6\x04\xb6\x13\x95\xd2\x0d\x07/\x8cg\x1eI\xed\xa2#\xad\x0c\xb2\x12;?\xcb\xf4)\xda\xda\xe5^\xfd\x85\x1c\x96\xe9W\xac\xa2-\xb7\xda\x12\x87\xcf\x8f\x8f\x8f\xad\xefr\xb9H\xca\xd5\")\xd7p~\xebf\xd5\xaeIK\x14\x12\xa9] \x95wZ4\x1c\nR2:\x8cO\x16\xb0rZ\x04d\xb9\xd5ue<\x07B\x87\"\xdc\xaf\x17\xf0]+\xb3\xff\x01\xab,s\xa7\x87\xb8c+\xf2}A\xb66\x92\x0dh\xd6\xeb\xf5,\xd27\x10m\xb4\x01\xc9\x97\xcc\x9d\xe2\xd6\x80\xa7\xc0\x84V\x85\xe1\x90\xa3	H\x933\x1dK\x15\xf0\xa3\x00\xfd~\x89\xb9%\x11\x945\x1c\x8c5x\x1d9\x15yP\x07\x84\xf3\x0c+o)\x16\xc4\n\x12R\xa1	\xf7\xcb\xe7Lb\xb1h\x9d\xc3\xcb\xe3\xdd\x02,	S <\xdd=t>k\xd2\xfe\x16koh\x13\x84\xc2\xf3\x04\x00\xe0\x93#{P^Y\xa3L\x01\xd3\x01\xabI\x7f\xea\xd6\xe4V\"\xc4?v\x10\xba\xc6\xde\x8a'\xa7\xa8\x81\xee1\x9f1\xd2Ye\x02\x0c/\x13_;e\n$Gqz\xf2\xde\xefo\xc1v:f[\x1b\x82\xad8\xac:)\xf5\xd6`\xdd\xc5\xf4G\xed\x83\xda5,\xb7&\xa0	\x1c\xbc\x1392<\xa0\xd1\xcd\x84\x93o\x90F\xf4oE]Yc\xdb=\xf1\x1e5\x86\x80\xc4\xe2\xb8\xcb\xa9t\xd9\x859Z\x92lK(\xf6\x1c\xda\x07\x13Z\xb7\xee?\xc7\xc8B\x19$V\xe2i\x8f\xcdM\xfe?\xa4\xdf\xef\xb1\x01495.jd\x8fMO\xaf\xab\xb7Z\xe5q\xd6\x91:\x88\x80q\xaa\xd7_]\x19V\x08\xc7\xa1GH\xf68\x19\xcf\x81U\xd5\x7f\xc65\\\xf4G\xe8\x06\xb4\xff\x12\xe3\xe4\xf6T\xc0\xca\x8f	6G\xff'\xc5\x9b\xfb)Z/\xc5`\xb9\xa3\xf8\xfbY\xae\xd2.\xc6\xed\x10m\xf6\x0f\x10}\x10\x14F\xeb(\xbb\xce>\xf1U\xae\xe0\xfcN\x01\xb9]1\x10\xb1\xc7\x1b\x0b\xc8V\x8b|\x7f+\x17\xb2\xcdX\xf5\xbbT\xc8\xd2\xe5\x17\xac\xa6\xe6!o\xc6\x99i\xead\xb33\xfbC\x01\xe7\xb1\x05,\xb3\xd8\x03b\xd4\x13\xeb\xbb\xc2S\xd6\x17\xc3!\xd9<\xea\xdd\xec\xb6\xd2Va\xff\x9f\xbe\x0b\x8a\x97\xecp\x1c\xaa\xe4\xe9\x97\xb8\x9e\xf8*W\x0b\xe8\x11\xff\xf6\x97\xd4\xc2dR\x84\xae=m-I\x1c\xd4\x01+w\x02ou\xd7\x16\xdf\xafj\xb3\xae\xfc\x1c\x95\x12\x89\x8e\x15\xedH1+\x8c\xa5J\xe8\xebn\xd7_\xf6&\x19!sX\xb6J\x18\xb9\xec\x90q\x13J\x96\x97J\xcb\xfbXx\x1f\xae\x1a\x19\xeb\xd9\xea\xc8{\x9d\xef\xbd\xa1\x99\x7f\xec\xf1\xab\xaf\x0b\xf8.U\xa1\xc2\x8f\x98\xe5\x0f\x9b\xe4\x17P\xd3\xf6\x98\xeb\x93IuxW \x17\xb1\xae\xfb\xf4\xb8|\x8a\xado\x91\xe4\x9d0\xf3\xd3n\xb5\xcd\xf7\xd7\xb4\x8f\xde'\xd8\xb2t\xfd<\xfb\x9a*H4oC\xa4\xa6\xae\xb6\xfdg\xd3E\xa15n\x92\xd7\xe4\xef\x01\x00PK\x07\x08Et\xb5\x81F\x03\x00\x006\n\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc8AQ]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x11\x00	\x00provisioning.htmlUT\x05\x00\x01X.\xd3j\xbcV\xcdn\xe36\x10\xbe\xeb)\xa6\xec\xa5\x05*\xa9Mr(ZJ@\xe0&\x08\xd0\x14u\x9c\x18h\x8f\x8c8\xb6f#\x91\n\xc9(6\x04?\xd0>\xc7\xbe\xd8\x82\x96\xfc\x93H\xc1\xda\x1b \xbe\x98\xf67\xdf\xf7\x0d\xc9\x99\x91\x9a\x06$\xceH!0\xa3\xb5c\xb0Z\x05\xfc\x87\xbf\xfe\x1d\xdd\xfd?\xbe\x80\xdc\x95E\x1ap\xff\x05\x85P\xf3\x84\xa1bi\x00\xc0s\x14\xd2/\x00x\x89N@\x96\x0bc\xd1%lzw\x19\xfe\xce \xde\x07\x95(1a5\xe1s\xa5\x8dc\x90i\xe5P\xb9\x84=\x93ty\"\xb1\xa6\x0c\xc3\xf5\x8f_\x80\x149\x12Eh3Q`\xf2[\xf4\xeb+\xb1\xdc\xb9*\xc4\xc7'\xaa\x13\xf6_8=\x0fG\xba\xac\x84\xa3\xfb\x02\xf7\x94	\x13\x94s\xdcq\x1d\xb9\x02\xd3\xf3\xea\xcbg\xa3\xa12\xba&KZ\x91\x9a\xf3\xb8\x85\x826\xe1\x82\xd4\x03\x18,\x12f\xdd\xb2@\x9b#:\x06\xb9\xc1Y\xc2\xf6iQfm\xa7\xce\xe3\xcdi\xf0{-\x97\xdd\xce%\xd5\x90\x15\xc2\xda\x84y\x18\xcd\xfa\xe0\xfc\x87\x8bN\xef\xc7Ra\xa9\x15el\x13)2G5\xb2\xf4\x9f\x0e\xe0\xb1\xe8\xb3r\\\xb0\xf4\n\x17\x83\xe0\xa3\xc9\xb4D\x96\xdeL\xc0/\xb61<\x96T\xa7A/\xb7'S\xd8]f\xf9i:\xde\xdb$L'\xd7<\xceOw\xf8Y\xda4\x10\xed\xc7L'\xd7\xb0Z\xf18?\xeb\xd4\x07d\xdaL^\xe8lv\xec!\xb6\x16\x1di\x89CJ\x17\x8b\x8a\x0cZ\x10n(\x95\x0e=w\x83T%+Mj\x98\xd8a[\x9e\x07\x01\x9a\x06h\x06\xd1\x08\x8d\xbb$5GS\x996h\xeb{\x9az\x90f\x94	\x870\xdb\x05}{\x87=\xd1W\xce\xa8\xe4\xc6\xa9w_$\xbd\x94r\x82\x14\x9a0\xc7\xc5\x03.-\x83u\x95&L\x92\xad\n\xb1\xfc\x03\x94V\xf8\xe7\xeeB_T\xa1\xa7l!\x7fM'\xe9\xd8`hsaP\xc2\x03.y\x9c\x9f\xec\xf0\xa6\x01\x87eU\xf8mn\xd8\xf0St\x85\x8bh|\xfb7.\x7f\xde;\x956\xdb\x03M/Tf\x96\x95#\xad\x8e2\xedh\xefq\xbe\xa5\xb9\x82\xea\xe9\xbe\xa0\xec(k\xcf\x1b\xafi\xefw7T\xfb\x13=\xda\xbe\xe5\xbd\xe9\xdf-\xdf*\x98v.\x1cU0\x9bQ\xb2\xcd\x90\xe7'\xe9H\xab\x19\xcd{\x85\x12\xddL|\xffF-|@a\x0c\x8a\x0f\xcc\x9ea\x9b\xfe\x00\xea\xa2\x0e?\x90\xb2\\7\xd0Pn]\xad\x7fg\xa7\xac\x85\xfd\xcdm\x86\xf8GuK\xdf\xf8C;\xa6o\xff\xe1]\xf3F\n\x87u\x8e\xff\xaf}~\xfb\xa5\xcd\x0cU\x0e\xac\xc9^=\xf7?Y\x96\xf2\xb8\x85\xd3\x80\xc7\xfe\xe5(\x0d\x9a\x06PIX\xad\x82\xaf\x03\x00PK\x07\x08\x02z\xf9\xe9\xa9\x02\x00\x00R	\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\x92AQ]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x0f\x00	\x00provisioning.jsUT\x05\x00\x01\xf4-\xd3j\x94\x90\xb1j\xc30\x10\x86w?\xc5\xa1\x0e\xb1@\xf5\x03\xb4\xb8\x90\x86\x0c\x85n\x1d\x8d\x07E\xfeS\x8b\xc8R#]LL\xc9\xbb\x17'\xad\x13\xda\xc5\xbdE\x02}\xfa>8\x07&\xd6\x9bD%U\x19\x11Q\xd5\x04s\xe8\xe0\xb9\xd8\x1f\x10\x8778\x18\x0e1\x17\xbaj#\xb6\xe5\xe2\xae\xf3\xe8\x82\xb7fQ\x0b\xa9h\xc2\xdf\xc1k\x87\xf1\xe7\xf3\xf0\xd2\xe4\xc2\x04\xcf\xdaz\xc4\xfb\xae\xdbaHB\xd6jf\xa1\xc5q\xb6\xbc\xc5\xf1\x9f\xf6}4\xa1\xc1\xec\xc0\x05\xbf\x04\xea,\x1b\x97UlC\\k\xd3\xe6y\xa5\x15\x99ZR\xf9D\x9f\xe7\xbc.t\xd3\xac{x~\xb5\x89\xe1\x11sa\x9c5;\xa1(\x87S\x84\xfe\x86\x1e\xe7\x970p\x8b\xb8Tt>W\xb7\xea\x9f9\xbf,\x8b\x88.\xf4X2G\xbb90\xc6\x8cNI\xc8\xc7\xc9<\xc1\xab\"\xf1\xe0@%\x89\xc6\xa6\x0f\xa7\x87\x07\xf2\xc1C\\\xd9\x93\xcc\xa6\xbb.\x12\xf8\x8fX\x91\xd0\x86m\x8f\xdb\x84\xb9\x9a\xbfe'\x99\x9dd\xf65\x00PK\x07\x08\x82\xc9\xb6\xf8\x08\x01\x00\x00W\x02\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00\xc6:Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00	\x00	\x00style.cssUT\x05\x00\x01$\"\xd3jtS]\x8e\xa30\x0c~\xe7\x14\x96\xaa}k*\xe8V\xa3\x99\xf44\x86\x98\x92\x9d\x10G\x89\xd9\xd2\x19\xcd\xddW\x04\xba\x85\xd9\xeeS\x8b\xe3|\xfe~\x9cNz\xb7/j67\xf8,\x00\x02\x1ac\xfdECy.\x00z\x8c\x17\xeb\xf3\xc7W\xf1hj\xd9\x8bj\xb1\xb7\xee\xa6!\xa1O*Q\xb4\xed\xfa\xc6\xa9\x0c#\xe0 <\x17Gu\xb5F:\x0do/e\x18\xa7\x9a\xb3\x9eTG\xf6\xd2\x89\x86\xea\xf02\xd52n\xb2\x1f\xa4\xa1z\x9d\xdb\x1av\x1c5\xecN\xa7\xd3yC\x0f\xaa\x0c\xf4U\x14]\xb5/\xba\xe3\xbe\xe8~\xc2\xe7\xbf\xc0\xc7\xdcS\x0f\"\xec\x1f\xe4\xe7!\xe5\xe1\x95\xfa|~\xe8\x08\x0d\xc5\xdc`l\n\x0eo\x1aZG\x99\x03:{\xf1\xca\n\xf5ICC^(N\xe5_C\x12\xdb\xdeT\xc3^\xc8\x8b\x86\x14\xb0!U\x93\\\x89\xfc\x0c\x9b\x04eH\x19\xb6\xb7~\xad\xf7>x\xee8P\x8c<\x8f\xbf+\xae\xcbr\xe9 \x19\x028\xac\xc9m\xf9\xd5\x8e\x9b\xf7\x87\xe9J8\xe8\x87-\xcb=\xa1Q0\x12\xee\xef\x05\xeb\xc3 \x19h\x89\xa4*\xcb\x1f\x13J\xcd\xe3\xe4KN\xbf\xe6h(\xaa\x9a\xc7\xf3\xf7\xbc{\xf6\x9c\x95\xae\xa7\xac\xfc}N\xc6D\x0e\x1f\xec\xe9?\n\xfe\xae]\xde\x9bj\xd9\x91\x99\x85\x86c\x18\xc1`\xea\xc8\xc0\x0e\x11\x1fG*\xa2\xb1C\xd2\xb0l\xcb$V\xe5\xb8\xd6A5CL\x93\xa5\x81\xed\x9c\xdd\x9a\xd0\x01\x1b\xb1\xbfg^\x8b\xea%\x01\x8e\xe8/4\xc1\xd6\xd8\xbc_\"\x0f\xde\xdc\xcfvm\xdb\xbeR3[@^\xa2\xa5\xf4\xcc\xd3\xb5\x19\xc7\x8d\xaa	\xc9aH\xa4\xe1\xfe\xef\xdb\x13(\x0fo\xd4o'H\xb7_}\x98\xed\x8b=\x85\xf1\x99\x0f\x8eZY9V\xb3\x08\xf7\x1a\xaa0Bbg\x0d\xec\x8c1S\xc3\x95\xa3Qu$|\xd7\x90\x7f\x14:\xb7U(&\xdb\xc5~\x11\xdbY!\x95\x97A\x83\xe7k\xc4\xf0\x1d\xc8s\xec\xd1\x9d\x8b\xaf\xe2\xcf\x00PK\x07\x08\xf0$\xb5\x94\xf3\x01\x00\x00i\x04\x00\x00PK\x03\x04\x14\x00\x08\x00\x08\x00);Q]\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x00\x05\x00	\x00sw.jsUT\x05\x00\x01\xde\"\xd3j\x84Va\x8f\x1b\xb7\x11\xfd\xae_1%\x82d\x17\xd1q\xcf\x0d\n\xb4\xa7*\x85\xe18\x85\x81 6\xec3\\ 5\x10\xde\xee\xac\x96>\x8a\xdc\x92\xb3RTG\xff\xbd\x98!W'\x9d\xe3Z_\xb4\x1c\x92o\x863\x8fo\xd84p; \x98\x11c\x80\x84qg[\x84}\x88\xf7\x18\xf5\xa2i\x16M\x03/\x08\xb6\xe6\x1e\x13\xd0\x80\xb0\xc7;h\x9dEO`}\"\xe3\x9c\xb9s\x08\xc6w0%s\xe7\x10B\xdf;\xebq)\xb6\x88-\xda]\xd9\xdb\x06O\xbc1\x0d&b\xc7\xd0{K\x83\xc0\x16,\xec\xc0\x8c#\xd0\x10\xc3\xb4\xc93\xef\xf0\x0e\xde\xf0\x06\xb85q\x83\x04O_\xbd\x98#\x13{w\xc2\xb5	\x82w\x07\xb8\xc7\x91\xa3\x03\x03.\xb4\xc6Ak\xda\x01o\x04m4\x1b\x04\xf4m<\x8c\x94$\xc2it\xc1t	,A\xf0-\x82\xa5o\x12\x87\x16F\xf4K\xd9\x93\xc3\x85\xd1\x19\xeb	\x7f#\xf0\xb8\xc3\x08\x11\x196\x9f\x8c\x13\xc7	[\xb4\xc1'\xe23<\xe3\xc9\x9f\xcd\x16a\x0dJ\xb2{e\xc6\xf1j\xf7D\xad\xca\"\x81\xfd\x83ebW\xab3\xac\x1f\xad\xc3\x04k\xf8e\x01\xa0\x1a\xb5\x94?3\x8e\xfaC*\x039Ox\x18':8\xd4m\x9a\xc7\xfb\x10;g\x13\xe9\x0f)\xf8b\xdb\x1ao{L\xa4\xf7x7\x7f\x97)\xdb\x06\x7f\xf5\xe4o\x7f\xd6\xa3\xdf\x9c\x9b\xfe\xf2d6\xbd_-\x16	]\xafM\xd7=\xdf\xa1\xa7\x9fl\"\xf4\x18+UJ\xa9\x96P\xe1\xae\x86\xf5\xf7\xf0q\x01\x80;\xbd7\x96\xdez\xb2\xae\x92\x82$\xcd)\xae\xcesUk\x1a\xd0Wy^\xb6\xca\x17;y\xea\\5\xa7\xa2\xae\xeb\xd5\x02@\xfc\xa7{;\xbe3\x96\xac\xdfT\xf5jq\xac?\x1f\x98i\xc9\xee\x0c\xe1\xff\x8bl\x01\x00\xd9i\x92O\x00}\x8f\x87T\xd5\xf3(\x07\xe8\xcd\x16\x13#\x143\xc0\xab\x18\xb66\xa16\xae\x80\xe4\x9f,\xd4\xbdu\x841o\xe3]b\x86?\xad\xd7\x97L\xf9\xfa\xeb\x87\x89Kv\xd4zk\xc6\xb3\xfd%\x81\x1d:$\xcc\xe69B\x80zq\xf9_b\x96\x8d\x92\x9a|\x7f\x93n\x9d\xb1\xdbJv~!u=R;<\xca[f\xf1\x14\x1d\xac\xc1\xe3\x1e\xde\xbe\xfe\xa9\xc2\x9d\x8e\xf8\x9f\x89Y5E'U\xb2=TSt:D\xbb\xb1^\x0e-A\xf0\xd5$\x1b|\x99\xa8!\"M\xd1\xaf\x16e\xcf\x19\xd4\x16i\x08\x1d\xac\xd7kP\xaf^\xbe\xb9U\x9c)\xc6\x1c\x0d\x0d|\xf8<\xd5H\xceT-|\x13\xc6ELc\xf0\xdd;KCU\x94H$\xe3\x0c<S	N\xde\x01\x8e\x1cA\xd3\xc0\xcf/o\x9fW;\xeb[\xf4Tg\xf5x\xfa\xea\x05\x8bL\xbe\xffR\x83n	<8@96O'\xbb\xf1,d\xbe\x83\xd6\xf8,Iw\x08S\xc2N$F\x7f\xf6\x84\x9c\x1c\xf5\xcf\xe7\xb7\n~\xff\xfd\xe2\x80:\x91\x89\x94\xe4 |\xf5m\xa3\xea\x8b\x8c=:\xacGb\x0d\xff\xd1\xc6D\x8f\x0e+en\x1a8_\x92\xf5+K\x19\xebo\x1f\xc3V\x06e\x11\xec\x07\xf40\x86\x94,+|\n@\x83!\x98\xc6\xce\x10&`q\x1em{\x9f5}\x1a!\xda\xcd@`\xf6\xe6\xb0\x14\x8d=\xe1I\xce2Zi\x13za\xd2\xc1\xb7\xd0O\xbee:\\\xc4U\x95\xac\xe6\x92f\xc2e\x885\xa3[\x82\xcf*\x89\xf0\x88\xe2\xa1\x90!\xef\xe5\x14\x9d\xb6\n\xa7O\x1e\x98\xaa\xb9,\xbcH\x87\xfb\xbah\xcf8\x9d\xc2X\n\x80n]\xf0X]\x12Gf\x18\xe3\x08\xad\xa1v\x80\nc\x9c\x99x\x16xw\x19\xb9\xde\x9a\xb3 \x96\xf0\x11\xec\xc6\x87\x88o\xd0\xc4v\xb8\x99Y\xa5\xb7\xa1+$\xf7fg7,bp,\x010\x95\x04\xac\x9b\x19Q\\\xe5i\xee\xa5{\xc0\x18yx\\\x1c\xa5\xf8\xe7\x97\x01\x12\x858\xf71\xbe\x1d\x1d\xf4\xdcp\x96\x10\xe2\xb9QZ\x9f\xed\xd9\x14Qj\xeeCY\xc9\x90\\\xe7\x88\x9d\x8d\xd8R\x02\n\x0f\xedv?\xd8v8\xf5Y\x1ap\xfbI\xd1/.\xe7\x1f\x14\xbd\x0fq{\xca\xdc\x9c\x146\xfe`\xc8\xb0\xee\x7f\x99\x1d\x8f\x04U\xf8\x91\x0bc\xb9(?\x18B\xed\xc3\xfe\x1c\xac/}\x97\x1d\xe9\x0d\x12\xf7 %FU\x9f4\x9d\xc7\xa2\x89\xfc\x91\xdf1\xbe\xc5\xd0\x03\xb7*\x16*\xb6\xebd\xff\x8b\xf0=\\g\xbf}\x88P9$\xb0\xb0\x86\xeb\x15X\xf8\xbb,K\xda\xa1\xdf\xd0\xb0\x02\xfb\xed\xb7\x97\xec\xe1iX\xcb_\xfa\xc5\xbe\xe7b\xc2\x05\x93\x98\xa7E\xf8\x7f\xcdJ\xd85_}\xb4\xdd\xf1\xea\xab\x8f\xf6\xf8\xeb\xb2\xcc\xb1R\xbf\x16YL(\xb1/\x8b\x1b\xfe\x0dh:\x8c\xe9\xe6\xcc\x04\xa0\x9e\xe5g\xdb\xd5\xedaDu#!h:\x8c\xc8\"\xa5\xcc8:\x9b\x95\xbc	-!]%\x8ah\xb6jv\xc8?\xf5\xaf+\xce\x06+\x99\xba\xe1\xe7W\xe8\xf0\xed\xeb\x17\xcf\xc2v\x0c\x1e=I \x9a\xa7\xeb\x87m\xc7\xf9\xf3\xc8\x0dJZT\x11gf\xfcy\xba\xe4f\\\xcf\xf9j\x1ax:\x8e)s\x19\x9c\xf5\xf7\x89\x1f\x83h\x99\xb8\xd0[t\xdd\x12R\xd8\"\xd9-\xca\xd4]\xa0A\xcb3\x98,9\x9c\xdf\x913\x9a\x08\xb7h\x16#\xe07	|\xa0\xc1\xfa\x0d\xa0K\xb8\x94\x17#Li2\xce\x1d\x80N(\xa1\x07sz;\x9a\x8d\xc8>\x00W}g\xdc\x94\x1ft\x8a/\x95Z\x82\x9a\xa2S\xef\x1f5\xf9j\xe6]1q\xbaU\xad)J\xd3>\x110\xbf\xb1v\xb9y\xa83Q\xc8n\x1e\xe5\xe8\xc1\xf7\x03\xbc\x92\x80\xd5#\x0f\x85c\xf9*p\x9c\xb0.\xbb\x1f\\/\xc1f\xef\x82\xaa\xad\xef\xf0\xb7\x97\xbd\x84\xb4^\x83\xad\xf5\x87`}\xa5\xfe\xed9\xaeS`\x02V\xc2-E\xfb,\x97?a\xf3\xf5\x89\xca\x8f\xc8\xcc\xa8\xac\xa0\x0f$\xfe\x84\xba\x92\xeeF\x1e\xf2+h\x07\x13\x13\xd2z\xa2\xfe\xea\xaf\n\x8e3\xcf\n\xd3\x98k\x85oEU\xe7k\xa3g\x99\xabT\xf3\x0f)\xb0Z\xc2w\xd7\xdf\xd5\xab\xc5q\xf1\xbf\x01\x00PK\x07\x08h\x9a\xda\x9c\xd5\x05\x00\x00P\x0d\x00\x00PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00/;Q]J\x86\xfb\xf4\xd9\x10\x00\x00B0\x00\x00\x06\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x00\x00\x00\x00app.jsUT\x05\x00\x01\xea\"\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xac:Q]\x8cr\xe4\xe0\x8b\x19\x00\x00\xc3J\x00\x00	\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x16\x11\x00\x00crypto.jsUT\x05\x00\x01\xf5!\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00!;Q]S\xd8\x8e`n\x03\x00\x00%\x04\x00\x00\x0c\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\xe1*\x00\x00icon-192.pngUT\x05\x00\x01\xce\"\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00!;Q]\xeb0#\xed\x91\x0c\x00\x00\x8a\x16\x00\x00\x0c\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x92.\x00\x00icon-512.pngUT\x05\x00\x01\xce\"\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xa8:Q]\x18\xe6\xd9(]\x02\x00\x00\x05\x06\x00\x00\n\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81f;\x00\x00index.htmlUT\x05\x00\x01\xec!\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x000;Q]\x9b\xabf\xd9J\x01\x00\x00&\x02\x00\x00\x0b\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81\x04>\x00\x00layout.htmlUT\x05\x00\x01\xec\"\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00#;Q]\x1a!9\x86w\x01\x00\x006\x03\x00\x00\x14\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81\x90?\x00\x00manifest.webmanifestUT\x05\x00\x01\xd2\"\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00A\xbeyO\xea^\x06\x85\xb0\x00\x00\x00x\x01\x00\x00\x1e\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81RA\x00\x00partials/provisioning-key.htmlUT\x05\x00\x01\xaah\xdc]PK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc8AQ]Et\xb5\x81F\x03\x00\x006\n\x00\x00\x10\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81WB\x00\x00provisioning.cssUT\x05\x00\x01X.\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc8AQ]\x02z\xf9\xe9\xa9\x02\x00\x00R	\x00\x00\x11\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81\xe4E\x00\x00provisioning.htmlUT\x05\x00\x01X.\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\x92AQ]\x82\xc9\xb6\xf8\x08\x01\x00\x00W\x02\x00\x00\x0f\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81\xd5H\x00\x00provisioning.jsUT\x05\x00\x01\xf4-\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00\xc6:Q]\xf0$\xb5\x94\xf3\x01\x00\x00i\x04\x00\x00	\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xb4\x81#J\x00\x00style.cssUT\x05\x00\x01$\"\xd3jPK\x01\x02\x14\x03\x14\x00\x08\x00\x08\x00);Q]h\x9a\xda\x9c\xd5\x05\x00\x00P\x0d\x00\x00\x05\x00	\x00\x00\x00\x00\x00\x00\x00\x00\x00\xa4\x81VL\x00\x00sw.jsUT\x05\x00\x01\xde\"\xd3jPK\x05\x06\x00\x00\x00\x00\x0d\x00\x0d\x00w\x03\x00\x00gR\x00\x00\x00\x00"
 	fs.Register(data)
 }
@@ -1,12 +1,17 @@
 package main
 
 import (
+	"bytes"
 	crypto_rand "crypto/rand"
 	"errors"
 	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
 	"sort"
 	"strings"
 	"sync"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 )
@@ -28,33 +33,196 @@ func newULID() ulid.ULID {
 	return id
 }
 
+// store holds the entries staged on the server.
+//
+// Every entry has an expiration time after which it is treated as if it didn't exist;
+// a zero expiration time means the entry never expires.
+// Expired entries are only physically removed by RemoveExpired.
+//
+// Add reads the content until io.EOF and must check the quota and store the entry atomically,
+// so that concurrent additions can't exceed the quota. If reading the content fails nothing
+// is stored and the error is returned unchanged.
+//
+// The copy and remove methods return the content of the entry as a reader
+// which the caller must close. If there's no entry they return errEntryNotFound.
 type store interface {
-	Add(data []byte) (ulid.ULID, error)
-	CopyFirst() ([]byte, error)
-	Copy(id ulid.ULID) ([]byte, error)
-	RemoveFirst() ([]byte, error)
-	Remove(id ulid.ULID) ([]byte, error)
-	ListAll() ([]ulid.ULID, error)
+	Add(content io.Reader, opts addOptions) (ulid.ULID, error)
+	CopyFirst() (entryInfo, io.ReadCloser, error)
+	Copy(id ulid.ULID) (entryInfo, io.ReadCloser, error)
+	RemoveFirst() (entryInfo, io.ReadCloser, error)
+	Remove(id ulid.ULID) (entryInfo, io.ReadCloser, error)
+	ListAll() ([]entryInfo, error)
+	RemoveExpired(now time.Time) (int, error)
+	Usage() (storeUsage, error)
+	// Close flushes the store to its storage, the store must not be used afterwards.
+	Close() error
 }
 
-var errEntryNotFound = errors.New("entry not found")
+var (
+	errEntryNotFound = errors.New("entry not found")
+	errQuotaExceeded = errors.New("quota exceeded")
+	errStoreClosed   = errors.New("store closed")
+)
+
+// entryInfo describes an entry without its content.
+//
+// Metadata is sealed by the clients with the encryption key (see entryMetadata),
+// the server stores it as is.
+// Device is the name of the device which created the entry, empty if it's unknown.
+type entryInfo struct {
+	ID        ulid.ULID `json:"id"`
+	ExpiresAt time.Time `json:"expires_at"`
+	Size      int64     `json:"size"`
+	Metadata  []byte    `json:"metadata,omitempty"`
+	Device    string    `json:"device,omitempty"`
+}
+
+// addOptions are the parameters of a new entry.
+type addOptions struct {
+	// ExpiresAt is the expiration time of the entry. Zero means it never expires.
+	ExpiresAt time.Time
+	// Metadata is the sealed metadata of the entry, if any.
+	Metadata []byte
+	// Device is the name of the device which created the entry, if known.
+	Device string
+	// Quota is checked before adding the entry.
+	Quota storeQuota
+	// Committed, if set, is called once the entry is stored, with the lock of the store held
+	// so that it's called in the order of the IDs.
+	Committed func(info entryInfo)
+}
+
+// storeQuota limits what a store can hold. A zero value means no limit.
+type storeQuota struct {
+	MaxEntries int
+	MaxSize    int64
+}
+
+// storeUsage is what a store currently holds, including the expired entries
+// not yet removed.
+type storeUsage struct {
+	Entries int
+	Size    int64
+}
+
+// allows reports if an entry of the given size can be added with this usage.
+func (q storeQuota) allows(usage storeUsage, size int64) bool {
+	if q.MaxEntries > 0 && usage.Entries+1 > q.MaxEntries {
+		return false
+	}
+	if q.MaxSize > 0 && usage.Size+size > q.MaxSize {
+		return false
+	}
+	return true
+}
+
+// limit returns a reader of content which fails with errQuotaExceeded as soon as
+// the content read doesn't fit in the quota with this usage.
+//
+// This is only a first check so that a client over quota can't make the store read a whole entry,
+// the quota must still be checked when adding the entry since the usage may have changed.
+func (q storeQuota) limit(content io.Reader, usage storeUsage) io.Reader {
+	if q.MaxSize <= 0 {
+		return content
+	}
+
+	remaining := q.MaxSize - usage.Size
+	if remaining < 0 {
+		remaining = 0
+	}
+
+	return &quotaReader{r: io.LimitReader(content, remaining+1), remaining: remaining}
+}
+
+type quotaReader struct {
+	r         io.Reader
+	remaining int64
+}
+
+func (r *quotaReader) Read(p []byte) (int, error) {
+	n, err := r.r.Read(p)
+	r.remaining -= int64(n)
+	if r.remaining < 0 {
+		return n, errQuotaExceeded
+	}
+	return n, err
+}
+
+func (e entryInfo) isExpired(now time.Time) bool {
+	return !e.ExpiresAt.IsZero() && !now.Before(e.ExpiresAt)
+}
+
+// newStore creates the store described by the configuration, it reports what it holds to m.
+func newStore(conf storageConfig, m *metrics) (store, error) {
+	switch conf.Type {
+	case "disk":
+		s, err := newDiskStore(conf.Path)
+		if err != nil {
+			return nil, err
+		}
+		s.metrics = m
+
+		usage := s.usage()
+		m.entriesAdded(usage.Entries, usage.Size)
+
+		return s, nil
+	default:
+		s := newMemStore()
+		s.metrics = m
+		return s, nil
+	}
+}
+
+// runReaper removes the expired entries of the store every interval.
+// It returns when stop is closed.
+func runReaper(st store, interval time.Duration, stop <-chan struct{}) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		var now time.Time
+		select {
+		case now = <-ticker.C:
+		case <-stop:
+			return
+		}
+
+		n, err := st.RemoveExpired(now)
+		if err != nil {
+			log.Printf("unable to remove expired entries. err: %v", err)
+			continue
+		}
+		if n > 0 {
+			log.Printf("removed %d expired entries", n)
+		}
+	}
+}
 
 type memStoreEntry struct {
-	id      ulid.ULID
+	entryInfo
 	content []byte
 }
 
+// memStoreEntryReader is a seekable reader of an entry.
+type memStoreEntryReader struct {
+	*bytes.Reader
+}
+
+func (r memStoreEntryReader) Close() error { return nil }
+
 func (e memStoreEntry) String() string {
-	return fmt.Sprintf("{id: %s, content: %s}", e.id.String(), string(e.content))
+	return fmt.Sprintf("{id: %s, expires at: %s, content: %s}", e.ID.String(), e.ExpiresAt, string(e.content))
 }
 
 func (e *memStoreEntry) IsValid() bool {
-	return len(e.content) > 0 && !isEmptyULID(e.id)
+	return len(e.content) > 0 && !isEmptyULID(e.ID)
 }
 
 type memStore struct {
 	mu      sync.Mutex
 	entries []memStoreEntry
+
+	metrics *metrics
 }
 
 func newMemStore() *memStore {
@@ -75,115 +243,198 @@ func (s *memStore) Dump() string {
 	return builder.String()
 }
 
-func (s *memStore) Add(data []byte) (ulid.ULID, error) {
-	entry := memStoreEntry{
-		id:      newULID(),
-		content: data,
-	}
-
+func (s *memStore) Add(content io.Reader, opts addOptions) (ulid.ULID, error) {
 	s.mu.Lock()
-	s.entries = append(s.entries, entry)
+	usage := s.usage()
 	s.mu.Unlock()
 
-	return entry.id, nil
-}
+	if !opts.Quota.allows(usage, 0) {
+		return ulid.ULID{}, errQuotaExceeded
+	}
+
+	data, err := ioutil.ReadAll(opts.Quota.limit(content, usage))
+	if err != nil {
+		return ulid.ULID{}, err
+	}
 
-func (s *memStore) CopyFirst() ([]byte, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if len(s.entries) < 1 {
-		return nil, nil
+	entry := memStoreEntry{
+		entryInfo: entryInfo{
+			ID:        newULID(),
+			ExpiresAt: opts.ExpiresAt,
+			Size:      int64(len(data)),
+			Metadata:  opts.Metadata,
+			Device:    opts.Device,
+		},
+		content: data,
 	}
 
-	entry := s.entries[0]
+	if !opts.Quota.allows(s.usage(), entry.Size) {
+		return ulid.ULID{}, errQuotaExceeded
+	}
 
-	tmp := make([]byte, len(entry.content))
-	copy(tmp, entry.content)
+	s.entries = append(s.entries, entry)
+	s.metrics.entriesAdded(1, entry.Size)
 
-	return tmp, nil
+	if opts.Committed != nil {
+		opts.Committed(entry.entryInfo)
+	}
+
+	return entry.ID, nil
+}
+
+// usage computes the usage of the store.
+// The caller must hold the lock.
+func (s *memStore) usage() storeUsage {
+	var usage storeUsage
+	for _, entry := range s.entries {
+		usage.Entries++
+		usage.Size += entry.Size
+	}
+	return usage
 }
 
-func (s *memStore) Copy(id ulid.ULID) ([]byte, error) {
+func (s *memStore) Usage() (storeUsage, error) {
 	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	var entry memStoreEntry
-	for _, v := range s.entries {
-		if v.id == id {
-			entry = v
-			break
+	return s.usage(), nil
+}
+
+// first returns the position of the oldest entry not expired or -1.
+// The caller must hold the lock.
+func (s *memStore) first() int {
+	now := time.Now()
+	for i, entry := range s.entries {
+		if !entry.isExpired(now) {
+			return i
 		}
 	}
+	return -1
+}
 
-	s.mu.Unlock()
-
-	//
-
-	if !entry.IsValid() {
-		return nil, errEntryNotFound
+// position returns the position of the entry if it exists and is not expired, or -1.
+// The caller must hold the lock.
+func (s *memStore) position(id ulid.ULID) int {
+	now := time.Now()
+	for i, entry := range s.entries {
+		if entry.ID == id && entry.IsValid() && !entry.isExpired(now) {
+			return i
+		}
 	}
+	return -1
+}
+
+// NOTE(vincent): the content of an entry is never modified once added
+// so it's safe to return a reader of it without copying it.
 
-	tmp := make([]byte, len(entry.content))
-	copy(tmp, entry.content)
+func (s *memStore) CopyFirst() (entryInfo, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	return tmp, nil
+	return s.copy(s.first())
 }
 
-func (s *memStore) RemoveFirst() ([]byte, error) {
+func (s *memStore) Copy(id ulid.ULID) (entryInfo, io.ReadCloser, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if len(s.entries) < 1 {
-		return nil, nil
+	return s.copy(s.position(id))
+}
+
+// copy returns the entry at position pos.
+// The caller must hold the lock.
+func (s *memStore) copy(pos int) (entryInfo, io.ReadCloser, error) {
+	if pos < 0 {
+		return entryInfo{}, nil, errEntryNotFound
 	}
 
-	entry := s.entries[0]
-	s.entries = s.entries[1:]
+	entry := s.entries[pos]
 
-	return entry.content, nil
+	return entry.entryInfo, memStoreEntryReader{bytes.NewReader(entry.content)}, nil
 }
 
-func (s *memStore) Remove(id ulid.ULID) ([]byte, error) {
+func (s *memStore) RemoveFirst() (entryInfo, io.ReadCloser, error) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	var (
-		pos   int
-		entry memStoreEntry
-	)
-	for i, v := range s.entries {
-		if v.id == id {
-			pos = i
-			entry = v
-			break
-		}
-	}
+	return s.remove(s.first())
+}
 
-	//
+func (s *memStore) Remove(id ulid.ULID) (entryInfo, io.ReadCloser, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-	if !entry.IsValid() {
-		return nil, errEntryNotFound
+	return s.remove(s.position(id))
+}
+
+// remove removes the entry at position pos and returns it.
+// The caller must hold the lock.
+func (s *memStore) remove(pos int) (entryInfo, io.ReadCloser, error) {
+	info, content, err := s.copy(pos)
+	if err != nil {
+		return info, nil, err
 	}
 
 	s.entries = append(s.entries[:pos], s.entries[pos+1:]...)
+	s.metrics.entriesAdded(-1, -info.Size)
 
-	return entry.content, nil
+	return info, content, nil
 }
 
-func (s *memStore) ListAll() ([]ulid.ULID, error) {
-	ids := make([]ulid.ULID, 0, 32)
+func (s *memStore) ListAll() ([]entryInfo, error) {
+	entries := make([]entryInfo, 0, 32)
+
+	now := time.Now()
 
 	s.mu.Lock()
 	for _, entry := range s.entries {
-		ids = append(ids, entry.id)
+		if entry.isExpired(now) {
+			continue
+		}
+		entries = append(entries, entry.entryInfo)
 	}
 	s.mu.Unlock()
 
-	sort.Slice(ids, func(i, j int) bool {
-		return ids[i].Compare(ids[j]) < 0
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ID.Compare(entries[j].ID) < 0
 	})
 
-	return ids, nil
+	return entries, nil
+}
+
+func (s *memStore) RemoveExpired(now time.Time) (int, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var size int64
+
+	entries := s.entries[:0]
+	for _, entry := range s.entries {
+		if entry.isExpired(now) {
+			size += entry.Size
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	n := len(s.entries) - len(entries)
+	s.metrics.entriesEvicted(n, size)
+
+	// Don't keep references to the content of the removed entries
+	for i := len(entries); i < len(s.entries); i++ {
+		s.entries[i] = memStoreEntry{}
+	}
+	s.entries = entries
+
+	return n, nil
+}
+
+// Close is a no-op, the content of a memory store is lost when the server stops.
+func (s *memStore) Close() error {
+	return nil
 }
 
 var _ store = (*memStore)(nil)
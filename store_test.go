@@ -1,29 +1,43 @@
 package main
 
 import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 	"github.com/stretchr/testify/require"
 )
 
 func TestMemStore(t *testing.T) {
+	testStore(t, func(t *testing.T) store {
+		return newMemStore()
+	})
+}
+
+// testStore runs the behavioral tests every store implementation must pass.
+// newStore must return a new empty store each time it is called.
+func testStore(t *testing.T, newStore func(t *testing.T) store) {
 	t.Run("add-multiple-copy", func(t *testing.T) {
-		s := newMemStore()
+		s := newStore(t)
 
 		// Add 3 entries and expect all 3 to still be in the list
 		// after calling CopyFirst
 
-		s.Add([]byte("foo"))
-		s.Add([]byte("bar"))
-		s.Add([]byte("baz"))
+		s.Add(strings.NewReader("foo"), addOptions{})
+		s.Add(strings.NewReader("bar"), addOptions{})
+		s.Add(strings.NewReader("baz"), addOptions{})
 
-		data, err := s.CopyFirst()
+		info, data, err := s.CopyFirst()
 		require.NoError(t, err)
-		require.Equal(t, "foo", string(data))
-		data, err = s.CopyFirst()
+		require.Equal(t, "foo", mustReadEntry(t, data))
+		require.Equal(t, int64(3), info.Size)
+		_, data, err = s.CopyFirst()
 		require.NoError(t, err)
-		require.Equal(t, "foo", string(data))
+		require.Equal(t, "foo", mustReadEntry(t, data))
 
 		ids, err := s.ListAll()
 		require.NoError(t, err)
@@ -31,23 +45,24 @@ func TestMemStore(t *testing.T) {
 	})
 
 	t.Run("add-remove", func(t *testing.T) {
-		s := newMemStore()
+		s := newStore(t)
 
 		// Add 3 entries and expect all of them to be removed correctly
 
-		id, err := s.Add([]byte("foobar"))
+		id, err := s.Add(strings.NewReader("foobar"), addOptions{})
 		require.NoError(t, err)
-		id2, err := s.Add([]byte("foobar2"))
+		id2, err := s.Add(strings.NewReader("foobar2"), addOptions{})
 		require.NoError(t, err)
-		id3, err := s.Add([]byte("foobar3"))
+		id3, err := s.Add(strings.NewReader("foobar3"), addOptions{})
 		require.NoError(t, err)
 
 		do := func(i ulid.ULID, exp string) {
-			tmp, err := s.Remove(i)
+			info, tmp, err := s.Remove(i)
 			require.NoError(t, err)
-			require.Equal(t, exp, string(tmp))
+			require.Equal(t, i, info.ID)
+			require.Equal(t, exp, mustReadEntry(t, tmp))
 
-			tmp, err = s.Remove(i)
+			_, tmp, err = s.Remove(i)
 			require.EqualError(t, err, errEntryNotFound.Error())
 			require.Nil(t, tmp)
 		}
@@ -58,54 +73,216 @@ func TestMemStore(t *testing.T) {
 	})
 
 	t.Run("add-multiple-pop", func(t *testing.T) {
-		s := newMemStore()
+		s := newStore(t)
 
 		// Add 3 entries and expect all of them to be in the list
 		// and to be removed in FIFO order
 
-		s.Add([]byte("foo"))
-		s.Add([]byte("bar"))
-		s.Add([]byte("baz"))
+		s.Add(strings.NewReader("foo"), addOptions{})
+		s.Add(strings.NewReader("bar"), addOptions{})
+		s.Add(strings.NewReader("baz"), addOptions{})
 
 		entries, err := s.ListAll()
 		require.NoError(t, err)
 		require.Len(t, entries, 3)
 
-		tmp, err := s.RemoveFirst()
+		_, tmp, err := s.RemoveFirst()
 		require.NoError(t, err)
-		require.Equal(t, "foo", string(tmp))
+		require.Equal(t, "foo", mustReadEntry(t, tmp))
 
-		tmp, err = s.RemoveFirst()
+		_, tmp, err = s.RemoveFirst()
 		require.NoError(t, err)
-		require.Equal(t, "bar", string(tmp))
+		require.Equal(t, "bar", mustReadEntry(t, tmp))
 
-		tmp, err = s.RemoveFirst()
+		_, tmp, err = s.RemoveFirst()
 		require.NoError(t, err)
-		require.Equal(t, "baz", string(tmp))
+		require.Equal(t, "baz", mustReadEntry(t, tmp))
 
-		tmp, err = s.RemoveFirst()
-		require.NoError(t, err)
+		_, tmp, err = s.RemoveFirst()
+		require.Equal(t, errEntryNotFound, err)
 		require.Nil(t, tmp)
 	})
 
 	t.Run("add-copy", func(t *testing.T) {
-		s := newMemStore()
+		s := newStore(t)
 
 		// Add 1 entry, copy it and expect it to stay in the store
 
-		id, err := s.Add([]byte("nope"))
+		id, err := s.Add(strings.NewReader("nope"), addOptions{})
 		require.NoError(t, err)
 
-		tmp, err := s.Copy(id)
+		_, tmp, err := s.Copy(id)
 		require.NoError(t, err)
-		require.Equal(t, []byte("nope"), tmp)
-		tmp, err = s.Copy(id)
+		require.Equal(t, "nope", mustReadEntry(t, tmp))
+		_, tmp, err = s.Copy(id)
 		require.NoError(t, err)
-		require.Equal(t, []byte("nope"), tmp)
+		require.Equal(t, "nope", mustReadEntry(t, tmp))
 
 		var empty ulid.ULID
-		tmp, err = s.Copy(empty)
+		_, tmp, err = s.Copy(empty)
 		require.EqualError(t, err, errEntryNotFound.Error())
 		require.Nil(t, tmp)
 	})
+
+	t.Run("add-large", func(t *testing.T) {
+		s := newStore(t)
+
+		// Entries larger than any internal buffer must survive unchanged
+
+		content := bytes.Repeat([]byte("abcdefgh"), 100000)
+
+		id, err := s.Add(bytes.NewReader(content), addOptions{})
+		require.NoError(t, err)
+
+		info, tmp, err := s.Remove(id)
+		require.NoError(t, err)
+		require.Equal(t, int64(len(content)), info.Size)
+		require.Equal(t, string(content), mustReadEntry(t, tmp))
+	})
+
+	t.Run("add-failed-read", func(t *testing.T) {
+		s := newStore(t)
+
+		// Nothing must be stored if the content can't be read entirely
+
+		content := io.MultiReader(strings.NewReader("foo"), errorReader{io.ErrUnexpectedEOF})
+
+		_, err := s.Add(content, addOptions{})
+		require.Equal(t, io.ErrUnexpectedEOF, err)
+
+		usage, err := s.Usage()
+		require.NoError(t, err)
+		require.Equal(t, storeUsage{}, usage)
+	})
+
+	t.Run("expiry", func(t *testing.T) {
+		s := newStore(t)
+
+		// Add 1 expired entry and 1 live entry, expect the expired one
+		// to be invisible until it's removed
+
+		now := time.Now()
+
+		expiredID, err := s.Add(strings.NewReader("old"), addOptions{ExpiresAt: now.Add(-time.Minute)})
+		require.NoError(t, err)
+		id, err := s.Add(strings.NewReader("new"), addOptions{ExpiresAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+
+		_, tmp, err := s.Copy(expiredID)
+		require.EqualError(t, err, errEntryNotFound.Error())
+		require.Nil(t, tmp)
+		_, tmp, err = s.Remove(expiredID)
+		require.EqualError(t, err, errEntryNotFound.Error())
+		require.Nil(t, tmp)
+
+		_, tmp, err = s.CopyFirst()
+		require.NoError(t, err)
+		require.Equal(t, "new", mustReadEntry(t, tmp))
+
+		entries, err := s.ListAll()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, id, entries[0].ID)
+		require.WithinDuration(t, now.Add(time.Hour), entries[0].ExpiresAt, time.Second)
+
+		n, err := s.RemoveExpired(now)
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		n, err = s.RemoveExpired(now)
+		require.NoError(t, err)
+		require.Equal(t, 0, n)
+
+		_, tmp, err = s.RemoveFirst()
+		require.NoError(t, err)
+		require.Equal(t, "new", mustReadEntry(t, tmp))
+
+		// Everything expires eventually
+
+		_, err = s.Add(strings.NewReader("foo"), addOptions{ExpiresAt: now.Add(time.Hour)})
+		require.NoError(t, err)
+
+		n, err = s.RemoveExpired(now.Add(2 * time.Hour))
+		require.NoError(t, err)
+		require.Equal(t, 1, n)
+
+		_, tmp, err = s.RemoveFirst()
+		require.Equal(t, errEntryNotFound, err)
+		require.Nil(t, tmp)
+	})
+
+	t.Run("quota", func(t *testing.T) {
+		s := newStore(t)
+
+		quota := storeQuota{MaxEntries: 2, MaxSize: 10}
+
+		// The entry count is limited
+
+		_, err := s.Add(strings.NewReader("foo"), addOptions{Quota: quota})
+		require.NoError(t, err)
+		_, err = s.Add(strings.NewReader("bar"), addOptions{Quota: quota})
+		require.NoError(t, err)
+		_, err = s.Add(strings.NewReader("baz"), addOptions{Quota: quota})
+		require.Equal(t, errQuotaExceeded, err)
+
+		usage, err := s.Usage()
+		require.NoError(t, err)
+		require.Equal(t, storeUsage{Entries: 2, Size: 6}, usage)
+
+		// The total size is limited
+
+		_, tmp, err := s.RemoveFirst()
+		require.NoError(t, err)
+		require.Equal(t, "foo", mustReadEntry(t, tmp))
+
+		_, err = s.Add(strings.NewReader("foobarbaz"), addOptions{Quota: quota})
+		require.Equal(t, errQuotaExceeded, err)
+		_, err = s.Add(strings.NewReader("foobar1"), addOptions{Quota: quota})
+		require.NoError(t, err)
+
+		usage, err = s.Usage()
+		require.NoError(t, err)
+		require.Equal(t, storeUsage{Entries: 2, Size: 10}, usage)
+
+		// The content isn't read further than the quota allows
+
+		_, tmp, err = s.RemoveFirst()
+		require.NoError(t, err)
+		require.Equal(t, "bar", mustReadEntry(t, tmp))
+
+		content := bytes.NewReader(make([]byte, 1<<20))
+
+		_, err = s.Add(content, addOptions{Quota: quota})
+		require.Equal(t, errQuotaExceeded, err)
+		require.True(t, content.Len() > 1<<19, "%d bytes read", 1<<20-content.Len())
+
+		// Nor at all if the store is full
+
+		_, err = s.Add(strings.NewReader("foo"), addOptions{Quota: quota})
+		require.NoError(t, err)
+
+		content = bytes.NewReader([]byte("foo"))
+
+		_, err = s.Add(content, addOptions{Quota: quota})
+		require.Equal(t, errQuotaExceeded, err)
+		require.Equal(t, 3, content.Len())
+	})
+}
+
+// mustReadEntry reads and closes the content of an entry.
+func mustReadEntry(t *testing.T, r io.ReadCloser) string {
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+	require.NoError(t, err)
+
+	return string(data)
+}
+
+type errorReader struct {
+	err error
+}
+
+func (r errorReader) Read(p []byte) (int, error) {
+	return 0, r.err
 }
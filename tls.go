@@ -0,0 +1,192 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crypto_rand "crypto/rand"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// selfSignedCertValidity is the validity of the certificates generated by genconfig.
+const selfSignedCertValidity = 10 * 365 * 24 * time.Hour
+
+var errCertFingerprintMismatch = errors.New("the server certificate doesn't match the pinned fingerprint")
+
+// tlsConfig configures the TLS listener of the server.
+//
+// CertFile and KeyFile are PEM encoded, if both are empty the server doesn't use TLS.
+// The certificate can be self-signed: clients pin its fingerprint instead of verifying it with a CA.
+//
+// With ClientCerts devices can authenticate with a client certificate of their sign key instead of the pre-shared key.
+// If ClientCAFile is set the client certificates must be issued by this CA, otherwise self-signed certificates are accepted:
+// either way the key of the certificate must be the key of a registered device.
+type tlsConfig struct {
+	CertFile string `toml:",omitempty"`
+	KeyFile  string `toml:",omitempty"`
+
+	ClientCerts  bool   `toml:",omitempty"`
+	ClientCAFile string `toml:",omitempty"`
+}
+
+func (c tlsConfig) enabled() bool {
+	return c.CertFile != "" || c.KeyFile != ""
+}
+
+func (c tlsConfig) Validate() error {
+	if c.enabled() && (c.CertFile == "" || c.KeyFile == "") {
+		return fmt.Errorf("both the TLS cert file and key file are required")
+	}
+	if c.ClientCerts && !c.enabled() {
+		return fmt.Errorf("client certificates require TLS")
+	}
+	if c.ClientCAFile != "" && !c.ClientCerts {
+		return fmt.Errorf("the client CA file requires client certificates")
+	}
+	return nil
+}
+
+// generateSelfSignedCert creates a self-signed certificate valid for the hosts, which can be
+// names or IP addresses, and returns it with its private key, PEM encoded.
+func generateSelfSignedCert(hosts []string, now time.Time) ([]byte, []byte, error) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), crypto_rand.Reader)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to generate key. err: %v", err)
+	}
+
+	serial, err := crypto_rand.Int(crypto_rand.Reader, new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to generate serial number. err: %v", err)
+	}
+
+	template := x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: "apero"},
+		NotBefore:             now.Add(-time.Hour),
+		NotAfter:              now.Add(selfSignedCertValidity),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+	for _, host := range hosts {
+		if ip := net.ParseIP(host); ip != nil {
+			template.IPAddresses = append(template.IPAddresses, ip)
+		} else {
+			template.DNSNames = append(template.DNSNames, host)
+		}
+	}
+
+	der, err := x509.CreateCertificate(crypto_rand.Reader, &template, &template, &key.PublicKey, key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to create certificate. err: %v", err)
+	}
+
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("unable to marshal key. err: %v", err)
+	}
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+
+	return certPEM, keyPEM, nil
+}
+
+// certFingerprint returns the SHA256 fingerprint of a DER encoded certificate, hex encoded.
+func certFingerprint(der []byte) string {
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:])
+}
+
+// certFileFingerprint returns the fingerprint of the first certificate of a PEM file.
+func certFileFingerprint(path string) (string, error) {
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			return "", fmt.Errorf("no certificate in %s", path)
+		}
+		if block.Type == "CERTIFICATE" {
+			return certFingerprint(block.Bytes), nil
+		}
+	}
+}
+
+// parseCertFingerprint parses a fingerprint as written by certFingerprint.
+// Colons, as shown by openssl, and the case are ignored.
+func parseCertFingerprint(s string) ([]byte, error) {
+	fingerprint, err := hex.DecodeString(strings.ToLower(strings.Replace(s, ":", "", -1)))
+	if err != nil || len(fingerprint) != sha256.Size {
+		return nil, fmt.Errorf("invalid certificate fingerprint %q", s)
+	}
+	return fingerprint, nil
+}
+
+// pinnedTLSConfig returns a TLS config which only accepts a server certificate with the fingerprint.
+//
+// The certificate chain and the host name are not verified: the pinned certificate is
+// what's trusted, which is what we want with a self-signed certificate.
+func pinnedTLSConfig(fingerprint []byte) *tls.Config {
+	return &tls.Config{
+		InsecureSkipVerify: true,
+		VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+			if len(rawCerts) == 0 {
+				return errCertFingerprintMismatch
+			}
+
+			sum := sha256.Sum256(rawCerts[0])
+			if !bytes.Equal(sum[:], fingerprint) {
+				return errCertFingerprintMismatch
+			}
+			return nil
+		},
+	}
+}
+
+// newHTTPClient returns the HTTP client used to talk to the staging server.
+// If fingerprint isn't empty the server certificate must match it, otherwise it's verified with the system CAs.
+// If cert isn't nil it's presented to the server as client certificate.
+func newHTTPClient(fingerprint string, cert *tls.Certificate) (*http.Client, error) {
+	if fingerprint == "" && cert == nil {
+		return &http.Client{}, nil
+	}
+
+	tlsConf := &tls.Config{}
+	if fingerprint != "" {
+		pin, err := parseCertFingerprint(fingerprint)
+		if err != nil {
+			return nil, err
+		}
+		tlsConf = pinnedTLSConfig(pin)
+	}
+	if cert != nil {
+		tlsConf.Certificates = []tls.Certificate{*cert}
+	}
+
+	return &http.Client{
+		Transport: &http.Transport{
+			Proxy:               http.ProxyFromEnvironment,
+			TLSClientConfig:     tlsConf,
+			TLSHandshakeTimeout: 10 * time.Second,
+		},
+	}, nil
+}
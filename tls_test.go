@@ -0,0 +1,163 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/pem"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTLSTestServer(t *testing.T, handler http.Handler) (*httptest.Server, string) {
+	certPEM, keyPEM, err := generateSelfSignedCert([]string{"localhost", "127.0.0.1"}, time.Now())
+	require.NoError(t, err)
+
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	httpServer := httptest.NewUnstartedServer(handler)
+	httpServer.TLS = &tls.Config{Certificates: []tls.Certificate{cert}}
+	httpServer.StartTLS()
+
+	return httpServer, certFingerprint(cert.Certificate[0])
+}
+
+func TestGenerateSelfSignedCert(t *testing.T) {
+	now := time.Now()
+
+	certPEM, keyPEM, err := generateSelfSignedCert([]string{"localhost", "127.0.0.1", "apero.lan"}, now)
+	require.NoError(t, err)
+
+	_, err = tls.X509KeyPair(certPEM, keyPEM)
+	require.NoError(t, err)
+
+	block, _ := pem.Decode(certPEM)
+	require.NotNil(t, block)
+	cert, err := x509.ParseCertificate(block.Bytes)
+	require.NoError(t, err)
+
+	require.Equal(t, []string{"localhost", "apero.lan"}, cert.DNSNames)
+	require.Len(t, cert.IPAddresses, 1)
+	require.True(t, cert.NotAfter.After(now.Add(365*24*time.Hour)))
+
+	// Fingerprint of the file
+
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "cert.pem")
+	require.NoError(t, ioutil.WriteFile(path, certPEM, 0644))
+
+	fingerprint, err := certFileFingerprint(path)
+	require.NoError(t, err)
+	require.Equal(t, certFingerprint(block.Bytes), fingerprint)
+
+	_, err = certFileFingerprint(filepath.Join(dir, "foobar.pem"))
+	require.Error(t, err)
+}
+
+func TestParseCertFingerprint(t *testing.T) {
+	fingerprint := certFingerprint([]byte("foobar"))
+
+	got, err := parseCertFingerprint(fingerprint)
+	require.NoError(t, err)
+	require.Len(t, got, 32)
+
+	// As shown by openssl x509 -fingerprint -sha256
+	var openssl []string
+	for i := 0; i < len(fingerprint); i += 2 {
+		openssl = append(openssl, strings.ToUpper(fingerprint[i:i+2]))
+	}
+	got2, err := parseCertFingerprint(strings.Join(openssl, ":"))
+	require.NoError(t, err)
+	require.Equal(t, got, got2)
+
+	for _, input := range []string{"", "foobar", fingerprint[:62]} {
+		_, err := parseCertFingerprint(input)
+		require.Error(t, err, input)
+	}
+}
+
+func TestPinnedHTTPClient(t *testing.T) {
+	httpServer, fingerprint := newTLSTestServer(t, http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		w.Write([]byte("hello"))
+	}))
+	defer httpServer.Close()
+
+	get := func(fingerprint string) error {
+		httpClient, err := newHTTPClient(fingerprint, nil)
+		require.NoError(t, err)
+
+		resp, err := httpClient.Get(httpServer.URL)
+		if err != nil {
+			return err
+		}
+		resp.Body.Close()
+		return nil
+	}
+
+	require.NoError(t, get(fingerprint))
+	require.Error(t, get(certFingerprint([]byte("foobar"))))
+	// Not signed by a CA
+	require.Error(t, get(""))
+
+	_, err := newHTTPClient("foobar", nil)
+	require.Error(t, err)
+}
+
+func TestServerClientTLS(t *testing.T) {
+	api, client, plainServer := newTestServerClient(t)
+	plainServer.Close()
+
+	httpServer, fingerprint := newTLSTestServer(t, serverHandler(api, newUIHandler(api.conf)))
+	defer httpServer.Close()
+
+	conf := client.conf
+	conf.Endpoint = httpServer.URL
+	conf.CertFingerprint = fingerprint
+	require.NoError(t, conf.Validate())
+
+	_, err := newClient(conf).doList(listRequest{})
+	require.NoError(t, err)
+
+	// The client fails closed with another certificate
+
+	conf.CertFingerprint = certFingerprint([]byte("foobar"))
+	c := newClient(conf)
+	c.retries = 0
+
+	_, err = c.doList(listRequest{})
+	require.Error(t, err)
+	require.Contains(t, err.Error(), errCertFingerprintMismatch.Error())
+}
+
+func TestTLSConfigValidate(t *testing.T) {
+	require.NoError(t, tlsConfig{}.Validate())
+	require.NoError(t, tlsConfig{CertFile: "cert.pem", KeyFile: "key.pem"}.Validate())
+	require.Error(t, tlsConfig{CertFile: "cert.pem"}.Validate())
+	require.Error(t, tlsConfig{KeyFile: "key.pem"}.Validate())
+
+	conf := clientConfig{
+		Endpoint:        "http://localhost:7568",
+		PSKey:           newSecretBoxKey(),
+		EncryptKey:      newSecretBoxKey(),
+		CertFingerprint: certFingerprint([]byte("foobar")),
+	}
+	conf.SignPublicKey, conf.SignPrivateKey = mustKeyPair(t)
+
+	require.Error(t, conf.Validate(), "a fingerprint requires https")
+
+	conf.Endpoint = "https://localhost:7568"
+	require.NoError(t, conf.Validate())
+
+	conf.CertFingerprint = "foobar"
+	require.Error(t, conf.Validate())
+}
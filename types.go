@@ -3,20 +3,27 @@ package main
 import (
 	"crypto/ed25519"
 	"fmt"
+	"time"
 
 	"github.com/oklog/ulid/v2"
 )
 
-// copyRequest is a request to copy content to the server.
+// copyRequest is a request to copy content to the server with the v1 API.
 //
 // A copy request must be signed by the client public key.
 // This means that before attempting a copy a client must register
 // its device.
 //
+// The signature only covers the content.
+//
 // The content must not be empty but there's no other constraint otherwise.
+//
+// TTL is the requested lifetime of the entry in seconds. If zero the server
+// uses its default TTL; the server also caps it to its maximum TTL.
 type copyRequest struct {
 	Signature []byte `json:"signature"`
 	Content   []byte `json:"content"`
+	TTL       int64  `json:"ttl,omitempty"`
 }
 
 // Validate validates the request parameters.
@@ -27,10 +34,68 @@ func (r copyRequest) Validate() error {
 	if len(r.Content) == 0 {
 		return fmt.Errorf("Content is empty")
 	}
+	if r.TTL < 0 {
+		return fmt.Errorf("TTL is negative")
+	}
+	return nil
+}
+
+// copyStreamHeader is the header frame of a copy request with the v2 API.
+//
+// The content follows the header in content frames and the signature is
+// in the trailer frame, this way neither the client nor the server needs
+// to hold the whole content in memory.
+//
+// The signature covers the envelope and the SHA-256 digest of the content.
+//
+// TTL has the same meaning as in copyRequest.
+// Metadata is the sealed metadata of the entry (see entryMetadata), it's optional.
+type copyStreamHeader struct {
+	Envelope *envelope `json:"envelope"`
+	TTL      int64     `json:"ttl,omitempty"`
+	Metadata []byte    `json:"metadata,omitempty"`
+}
+
+// Validate validates the header parameters.
+func (h copyStreamHeader) Validate() error {
+	if h.Envelope == nil {
+		return fmt.Errorf("Envelope is missing")
+	}
+	if h.TTL < 0 {
+		return fmt.Errorf("TTL is negative")
+	}
+	if len(h.Metadata) > maxEntryMetadataSize {
+		return fmt.Errorf("Metadata is too large")
+	}
+	return nil
+}
+
+// copyStreamTrailer is the trailer frame of a copy request with the v2 API.
+type copyStreamTrailer struct {
+	Signature []byte `json:"signature"`
+}
+
+// Validate validates the trailer parameters.
+func (t copyStreamTrailer) Validate() error {
+	if len(t.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Signature size is invalid")
+	}
 	return nil
 }
 
+// entryHeader is the header frame of a move or paste response with the v2 API.
+// The content of the entry follows it, starting at Offset.
+type entryHeader struct {
+	Entry  entryInfo `json:"entry"`
+	Offset int64     `json:"offset,omitempty"`
+}
+
+// moveRequest is a request to move an entry out of the server.
+//
+// With the v2 API the signature covers the envelope and the ID,
+// with the v1 API it only covers the ID and the envelope is ignored.
 type moveRequest struct {
+	Envelope  *envelope `json:"envelope,omitempty"`
 	Signature []byte    `json:"signature"`
 	ID        ulid.ULID `json:"id"`
 }
@@ -43,9 +108,19 @@ func (r moveRequest) Validate() error {
 	return nil
 }
 
+// pasteRequest is a request to copy an entry from the server.
+//
+// With the v2 API the signature covers the envelope and the ID,
+// with the v1 API it only covers the ID and the envelope is ignored.
+//
+// Offset is where the content starts in the response, this lets a client resume
+// an interrupted download. It's only supported with the v2 API and is covered
+// by the signature when it's not zero.
 type pasteRequest struct {
+	Envelope  *envelope `json:"envelope,omitempty"`
 	Signature []byte    `json:"signature"`
 	ID        ulid.ULID `json:"id"`
+	Offset    int64     `json:"offset,omitempty"`
 }
 
 // Validate validates the request parameters.
@@ -53,11 +128,27 @@ func (r pasteRequest) Validate() error {
 	if len(r.Signature) != ed25519.SignatureSize {
 		return fmt.Errorf("Signature size is invalid")
 	}
+	if r.Offset < 0 {
+		return fmt.Errorf("Offset is negative")
+	}
 	return nil
 }
 
+// signedData returns the data covered by the signature.
+func (r pasteRequest) signedData() []byte {
+	if r.Offset == 0 {
+		return r.ID[:]
+	}
+	return uploadMessage(r.ID, r.Offset, nil)
+}
+
+// listRequest is a request to list the entries of the server.
+//
+// With the v2 API the signature covers the envelope,
+// with the v1 API it covers the single byte "L" and the envelope is ignored.
 type listRequest struct {
-	Signature []byte `json:"signature"`
+	Envelope  *envelope `json:"envelope,omitempty"`
+	Signature []byte    `json:"signature"`
 }
 
 // Validate validates the request parameters.
@@ -68,6 +159,168 @@ func (r listRequest) Validate() error {
 	return nil
 }
 
+// uploadStartRequest is a request to start an upload session with the v2 API.
+//
+// The signature covers the envelope and the metadata.
+// TTL and Metadata have the same meaning as in copyStreamHeader.
+type uploadStartRequest struct {
+	Envelope  *envelope `json:"envelope"`
+	Signature []byte    `json:"signature"`
+	TTL       int64     `json:"ttl,omitempty"`
+	Metadata  []byte    `json:"metadata,omitempty"`
+}
+
+// Validate validates the request parameters.
+func (r uploadStartRequest) Validate() error {
+	if len(r.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Signature size is invalid")
+	}
+	if r.TTL < 0 {
+		return fmt.Errorf("TTL is negative")
+	}
+	if len(r.Metadata) > maxEntryMetadataSize {
+		return fmt.Errorf("Metadata is too large")
+	}
+	return nil
+}
+
+type uploadStartResponse struct {
+	ID        ulid.ULID `json:"id"`
+	ChunkSize int64     `json:"chunk_size"`
+}
+
+// uploadChunkHeader is the header frame of a request to upload a chunk of an upload session.
+// The chunk follows in a single frame.
+//
+// The signature covers the envelope, the session ID, the index and the SHA-256 digest of the chunk.
+type uploadChunkHeader struct {
+	Envelope  *envelope `json:"envelope"`
+	Signature []byte    `json:"signature"`
+	ID        ulid.ULID `json:"id"`
+	Index     int64     `json:"index"`
+}
+
+// Validate validates the header parameters.
+func (h uploadChunkHeader) Validate() error {
+	if len(h.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Signature size is invalid")
+	}
+	if isEmptyULID(h.ID) {
+		return fmt.Errorf("ID is empty")
+	}
+	if h.Index < 0 {
+		return fmt.Errorf("Index is negative")
+	}
+	return nil
+}
+
+// uploadFinishRequest is a request to finish an upload session and create the entry.
+//
+// Chunks is the number of chunks the client sent.
+// The signature covers the envelope, the session ID and the number of chunks.
+type uploadFinishRequest struct {
+	Envelope  *envelope `json:"envelope"`
+	Signature []byte    `json:"signature"`
+	ID        ulid.ULID `json:"id"`
+	Chunks    int64     `json:"chunks"`
+}
+
+// Validate validates the request parameters.
+func (r uploadFinishRequest) Validate() error {
+	if len(r.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Signature size is invalid")
+	}
+	if isEmptyULID(r.ID) {
+		return fmt.Errorf("ID is empty")
+	}
+	if r.Chunks < 0 {
+		return fmt.Errorf("Chunks is negative")
+	}
+	return nil
+}
+
 type listResponse struct {
+	Entries []entryInfo `json:"entries"`
+}
+
+// listResponseV1 is the list response of the v1 API, it only has the IDs of the entries.
+type listResponseV1 struct {
 	Entries []ulid.ULID `json:"entries"`
 }
+
+// subscribeRequest is a request to be notified of new entries with the v2 API.
+//
+// The signature covers the envelope and After.
+// After is the ID of the last entry the client knows of, entries created since
+// are sent right away; if it's empty only entries created from now on are sent.
+// Timeout is how long in seconds a long-poll request waits for a new entry.
+type subscribeRequest struct {
+	Envelope  *envelope `json:"envelope"`
+	Signature []byte    `json:"signature"`
+	After     ulid.ULID `json:"after"`
+	Timeout   int64     `json:"timeout,omitempty"`
+}
+
+// Validate validates the request parameters.
+func (r subscribeRequest) Validate() error {
+	if len(r.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Signature size is invalid")
+	}
+	if r.Timeout < 0 {
+		return fmt.Errorf("Timeout is negative")
+	}
+	return nil
+}
+
+type subscribeResponse struct {
+	Entries []entryInfo `json:"entries"`
+}
+
+// rotateKeyRequest is a request to replace the signing key of a device with the v2 API.
+//
+// The signature is made with the current key of the device, the proof with the new key;
+// both cover the envelope and the new public key.
+type rotateKeyRequest struct {
+	Envelope  *envelope `json:"envelope"`
+	Signature []byte    `json:"signature"`
+	PublicKey publicKey `json:"public_key"`
+	Proof     []byte    `json:"proof"`
+}
+
+// Validate validates the request parameters.
+func (r rotateKeyRequest) Validate() error {
+	if len(r.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Signature size is invalid")
+	}
+	if !r.PublicKey.IsValid() {
+		return fmt.Errorf("PublicKey is invalid")
+	}
+	if len(r.Proof) != ed25519.SignatureSize {
+		return fmt.Errorf("Proof size is invalid")
+	}
+	return nil
+}
+
+type rotateKeyResponse struct {
+	// PreviousKeyExpiresAt is when the server stops accepting the previous key.
+	PreviousKeyExpiresAt time.Time `json:"previous_key_expires_at"`
+}
+
+// pairRequest is a request to open a pairing mailbox with the v2 API.
+type pairRequest struct {
+	Envelope  *envelope `json:"envelope"`
+	Signature []byte    `json:"signature"`
+}
+
+// Validate validates the request parameters.
+func (r pairRequest) Validate() error {
+	if len(r.Signature) != ed25519.SignatureSize {
+		return fmt.Errorf("Signature size is invalid")
+	}
+	return nil
+}
+
+type pairResponse struct {
+	Nameplate string    `json:"nameplate"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
@@ -1,11 +1,21 @@
 package main
 
 import (
+	"encoding/json"
+	"log"
 	"net/http"
 
+	"rischmann.fr/apero/internal/ui"
+
+	"github.com/tyler-smith/go-bip39"
 	"github.com/vrischmann/hutil/v2"
 )
 
+// uiContentSecurityPolicy only allows the scripts, styles and images served by apero and
+// requests to the server itself: the keys are in the local storage of the browser,
+// an injected script must not be able to run or send them anywhere.
+const uiContentSecurityPolicy = "default-src 'self'; img-src 'self' blob:; object-src 'none'; base-uri 'none'; form-action 'self'; frame-ancestors 'none'"
+
 type uiHandler struct {
 	conf serverConfig
 }
@@ -17,13 +27,62 @@ func newUIHandler(conf serverConfig) *uiHandler {
 }
 
 func (s *uiHandler) handle(w http.ResponseWriter, req *http.Request, tail string) {
-	head, tail := hutil.ShiftPath(tail)
+	w.Header().Set("Content-Security-Policy", uiContentSecurityPolicy)
+
+	head, _ := hutil.ShiftPath(tail)
 	switch head {
 	case "":
 		s.handleIndex(w, req)
+	case "style.css", "app.js", "crypto.js", "icon-192.png", "icon-512.png":
+		ui.ServeFile("/"+head)(w, req)
+	case "sw.js":
+		// NOTE(vincent): the browser checks for a new service worker on each visit,
+		// it must not get a stale one from its HTTP cache.
+		w.Header().Set("Cache-Control", "no-cache")
+		ui.ServeFile("/sw.js")(w, req)
+	case "manifest.webmanifest":
+		w.Header().Set("Content-Type", "application/manifest+json")
+		ui.ServeFile("/manifest.webmanifest")(w, req)
+	case "wordlist.json":
+		s.handleWordList(w, req)
+	case "share":
+		s.handleShare(w, req)
+	default:
+		responseStatusCode(w, http.StatusNotFound)
 	}
 }
 
+// handleIndex serves the web client.
+//
+// The page only contains the client code: the entries are fetched, decrypted and encrypted
+// by the browser using the v2 API, the server never sees the keys.
 func (s *uiHandler) handleIndex(w http.ResponseWriter, req *http.Request) {
+	if req.Method != http.MethodGet && req.Method != http.MethodHead {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	tmpl := ui.ParseTemplate(nil, "/layout.html", "/index.html")
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := tmpl.ExecuteTemplate(w, "layout", nil); err != nil {
+		log.Printf("unable to execute template. err: %v", err)
+	}
+}
+
+// handleShare is only reached when the service worker didn't handle a share, for example
+// when it has been removed by the browser. The shared content is plaintext so it's discarded
+// without being read and the page tells the user to try again.
+func (s *uiHandler) handleShare(w http.ResponseWriter, req *http.Request) {
+	http.Redirect(w, req, "/?share=unavailable", http.StatusSeeOther)
+}
+
+// handleWordList serves the BIP39 word list used to decode the mnemonics shown by apero provision.
+func (s *uiHandler) handleWordList(w http.ResponseWriter, req *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "max-age=86400")
 
+	if err := json.NewEncoder(w).Encode(bip39.GetWordList()); err != nil {
+		log.Printf("unable to write word list. err: %v", err)
+	}
 }
@@ -0,0 +1,476 @@
+// The apero web client.
+//
+// It talks to the v2 API exactly like the command line client: every request is sealed
+// with the pre-shared key and signed with the private key, every entry is encrypted with
+// the encryption key before leaving the browser. The keys never leave localStorage.
+
+import * as c from "./crypto.js";
+
+const keysStorageKey = "apero.keys";
+const shareCacheName = "apero-share";
+const apiVersion = "v2";
+const contentTypeOctetStream = "application/octet-stream";
+
+let keys = null;
+let entries = [];
+let events = null;
+
+const $ = (id) => document.getElementById(id);
+
+function setStatus(msg, isError) {
+  const el = $("status");
+  el.textContent = msg || "";
+  el.classList.toggle("error", !!isError);
+}
+
+function fail(err) {
+  console.error(err);
+  setStatus(err.message || String(err), true);
+}
+
+// Keys
+
+async function fetchWordlist() {
+  const resp = await fetch("/wordlist.json");
+  if (!resp.ok) throw new Error(`unable to fetch the word list: ${resp.status}`);
+  return resp.json();
+}
+
+// parseKey parses a key in one of the formats shown by apero provision:
+// a BIP39 mnemonic or a hex string, possibly split by spaces.
+async function parseKey(name, value) {
+  const compact = value.replace(/\s+/g, "");
+  if (/^[0-9a-fA-F]{64}$/.test(compact)) {
+    return c.decodeHex(compact);
+  }
+
+  try {
+    return c.mnemonicToKey(value, await fetchWordlist());
+  } catch (err) {
+    throw new Error(`${name} is neither a valid mnemonic nor hex: ${err.message}`);
+  }
+}
+
+function loadKeys() {
+  const raw = localStorage.getItem(keysStorageKey);
+  if (!raw) return null;
+
+  const stored = JSON.parse(raw);
+  return {
+    psKey: c.decodeBase64(stored.psKey),
+    encryptKey: c.decodeBase64(stored.encryptKey),
+    signSeed: c.decodeBase64(stored.signSeed),
+    device: stored.device || "",
+  };
+}
+
+function saveKeys(k) {
+  // Ask the browser not to evict the keys when it's low on storage
+  if (navigator.storage && navigator.storage.persist) navigator.storage.persist();
+
+  localStorage.setItem(
+    keysStorageKey,
+    JSON.stringify({
+      psKey: c.encodeBase64(k.psKey),
+      encryptKey: c.encodeBase64(k.encryptKey),
+      signSeed: c.encodeBase64(k.signSeed),
+      device: k.device,
+    })
+  );
+}
+
+async function importKeys(form) {
+  const k = {
+    psKey: await parseKey("the pre-shared key", form.pskey.value),
+    encryptKey: await parseKey("the encryption key", form.encryptkey.value),
+    signSeed: await parseKey("the sign private key", form.signprivatekey.value),
+    device: form.device.value.trim(),
+  };
+
+  saveKeys(k);
+  form.reset();
+
+  return k;
+}
+
+// Requests
+
+function lengthPrefixed(b) {
+  return c.concat(c.uint64BE(b.length), b);
+}
+
+// signRequest creates an envelope for the action and signs it with the payload,
+// like signDeviceEnvelope in the Go client.
+function signRequest(action, payload) {
+  const envelope = {
+    action: action,
+    version: apiVersion,
+    timestamp: Math.floor(Date.now() / 1000),
+    nonce: c.randomBytes(16),
+  };
+  if (keys.device) envelope.device = keys.device;
+
+  const message = c.concat(
+    c.utf8Encode("apero request"),
+    lengthPrefixed(c.utf8Encode(envelope.version)),
+    lengthPrefixed(c.utf8Encode(envelope.action)),
+    c.uint64BE(envelope.timestamp),
+    lengthPrefixed(envelope.nonce),
+    keys.device ? lengthPrefixed(c.utf8Encode(keys.device)) : new Uint8Array(0),
+    lengthPrefixed(payload)
+  );
+
+  envelope.nonce = c.encodeBase64(envelope.nonce);
+
+  return {
+    envelope: envelope,
+    signature: c.encodeBase64(c.ed25519Sign(keys.signSeed, message)),
+  };
+}
+
+function sealJSON(obj) {
+  return c.secretBoxSeal(c.utf8Encode(JSON.stringify(obj)), keys.psKey);
+}
+
+function openJSON(data) {
+  const plaintext = c.secretBoxOpen(data, keys.psKey);
+  if (plaintext === null) throw new Error("unable to open the response");
+  return JSON.parse(c.utf8Decode(plaintext));
+}
+
+async function doRequest(method, path, body) {
+  const resp = await fetch(path, { method: method, body: body });
+  if (!resp.ok) {
+    throw new Error(`${path} failed with ${resp.status}: ${(await resp.text()).trim()}`);
+  }
+  return new Uint8Array(await resp.arrayBuffer());
+}
+
+function frame(b) {
+  const res = new Uint8Array(4 + b.length);
+  new DataView(res.buffer).setUint32(0, b.length);
+  res.set(b, 4);
+  return res;
+}
+
+// uploadMessage is the payload signed for the chunk and finish requests of an upload.
+function uploadMessage(id, n, digest) {
+  return c.concat(c.decodeULID(id), c.uint64BE(n), digest);
+}
+
+// Entries
+
+function openMetadata(entry) {
+  if (!entry.metadata) return {};
+
+  const plaintext = c.secretBoxOpen(c.decodeBase64(entry.metadata), keys.encryptKey);
+  if (plaintext === null) return {};
+  return JSON.parse(c.utf8Decode(plaintext));
+}
+
+async function listEntries() {
+  const data = await doRequest("POST", "/api/v2/list", sealJSON(signRequest("list", new Uint8Array(0))));
+  const resp = openJSON(data);
+
+  return (resp.entries || []).map((entry) => ({ ...entry, md: openMetadata(entry) }));
+}
+
+// fetchEntry pastes or moves an entry and returns its decrypted content.
+async function fetchEntry(action, id) {
+  const req = { ...signRequest(action, c.decodeULID(id)), id: id };
+  const method = action === "move" ? "DELETE" : "POST";
+
+  const data = await doRequest(method, `/api/v2/${action}`, sealJSON(req));
+
+  const n = new DataView(data.buffer, data.byteOffset).getUint32(0);
+  openJSON(data.subarray(4, 4 + n));
+
+  return c.secretStreamDecrypt(data.subarray(4 + n), keys.encryptKey);
+}
+
+async function uploadFile(file) {
+  const md = {
+    filename: file.name,
+    content_type: file.type || contentTypeOctetStream,
+    size: file.size,
+    host: keys.device || "browser",
+  };
+  const metadata = c.secretBoxSeal(c.utf8Encode(JSON.stringify(md)), keys.encryptKey);
+
+  const plaintext = new Uint8Array(await file.arrayBuffer());
+  const content = c.secretStreamEncrypt(plaintext, keys.encryptKey);
+
+  // Start the upload
+
+  const startReq = { ...signRequest("upload-start", metadata), metadata: c.encodeBase64(metadata) };
+  const start = openJSON(await doRequest("POST", "/api/v2/upload/start", sealJSON(startReq)));
+
+  // Send the chunks
+
+  let index = 0;
+  for (let off = 0; off < content.length; off += start.chunk_size, index++) {
+    const chunk = content.subarray(off, off + start.chunk_size);
+
+    const header = {
+      ...signRequest("upload-chunk", uploadMessage(start.id, index, c.sha256(chunk))),
+      id: start.id,
+      index: index,
+    };
+
+    await doRequest("PUT", "/api/v2/upload/chunk", c.concat(frame(sealJSON(header)), frame(chunk)));
+
+    setStatus(`Uploading ${file.name || "text"}: ${Math.floor(((off + chunk.length) * 100) / content.length)}%`);
+  }
+
+  // Finish it
+
+  const finishReq = {
+    ...signRequest("upload-finish", uploadMessage(start.id, index, new Uint8Array(0))),
+    id: start.id,
+    chunks: index,
+  };
+  await doRequest("POST", "/api/v2/upload/finish", sealJSON(finishReq));
+}
+
+// Live updates
+
+function subscribe(after) {
+  if (events) events.abort();
+
+  // NOTE(vincent): EventSource can only make GET requests and the sealed request
+  // must not be in the URL, the event stream is read from a POST request instead.
+  // A signed request can't be replayed so a new one is made to reconnect.
+  const req = { ...signRequest("subscribe", c.decodeULID(after)), after: after };
+  const controller = new AbortController();
+  events = controller;
+
+  readEvents(controller, sealJSON(req)).catch(() => {
+    if (controller.signal.aborted) return;
+
+    events = null;
+    setTimeout(() => {
+      if (keys && !events) subscribe(lastEntryID());
+    }, 5000);
+  });
+}
+
+async function readEvents(controller, body) {
+  const resp = await fetch("/api/v2/subscribe", {
+    method: "POST",
+    headers: { Accept: "text/event-stream" },
+    body: body,
+    signal: controller.signal,
+  });
+  if (!resp.ok) throw new Error(`subscribe failed with ${resp.status}`);
+
+  const reader = resp.body.getReader();
+  const decoder = new TextDecoder();
+  let buf = "";
+
+  for (;;) {
+    const { done, value } = await reader.read();
+    if (done) throw new Error("event stream closed");
+
+    buf += decoder.decode(value, { stream: true });
+
+    let end;
+    while ((end = buf.indexOf("\n\n")) >= 0) {
+      const lines = buf.slice(0, end).split("\n");
+      buf = buf.slice(end + 2);
+
+      if (lines.includes("event: entry")) refresh().catch(fail);
+    }
+  }
+}
+
+function lastEntryID() {
+  return entries.length > 0 ? entries[entries.length - 1].id : "0".repeat(26);
+}
+
+// Rendering
+
+function formatSize(n) {
+  const units = ["B", "KiB", "MiB", "GiB"];
+  let i = 0;
+  while (n >= 1024 && i < units.length - 1) {
+    n /= 1024;
+    i++;
+  }
+  return `${i === 0 ? n : n.toFixed(1)} ${units[i]}`;
+}
+
+function download(content, name, contentType) {
+  const url = URL.createObjectURL(new Blob([content], { type: contentType || contentTypeOctetStream }));
+
+  const a = document.createElement("a");
+  a.href = url;
+  a.download = name;
+  document.body.appendChild(a);
+  a.click();
+  a.remove();
+
+  setTimeout(() => URL.revokeObjectURL(url), 60000);
+}
+
+function entryButton(label, action, entry) {
+  const button = document.createElement("button");
+  button.type = "button";
+  button.textContent = label;
+  button.addEventListener("click", async () => {
+    try {
+      setStatus(`Fetching ${entry.id}…`);
+      const content = await fetchEntry(action, entry.id);
+      download(content, entry.md.filename || entry.id, entry.md.content_type);
+      setStatus("");
+      if (action === "move") await refresh();
+    } catch (err) {
+      fail(err);
+    }
+  });
+  return button;
+}
+
+function renderEntries() {
+  const tbody = $("entries");
+  tbody.textContent = "";
+
+  for (const entry of entries.slice().reverse()) {
+    const tr = document.createElement("tr");
+
+    const cells = [
+      entry.md.filename || entry.id,
+      formatSize(entry.md.size !== undefined ? entry.md.size : entry.size),
+      c.ulidTime(entry.id).toLocaleString(),
+      entry.device || entry.md.host || "",
+    ];
+    for (const text of cells) {
+      const td = document.createElement("td");
+      td.textContent = text;
+      tr.appendChild(td);
+    }
+
+    const actions = document.createElement("td");
+    actions.className = "actions";
+    actions.appendChild(entryButton("Paste", "paste", entry));
+    actions.appendChild(entryButton("Move", "move", entry));
+    tr.appendChild(actions);
+
+    tbody.appendChild(tr);
+  }
+}
+
+async function refresh() {
+  entries = await listEntries();
+  renderEntries();
+}
+
+async function uploadFiles(files) {
+  try {
+    for (const file of files) {
+      setStatus(`Uploading ${file.name}…`);
+      await uploadFile(file);
+    }
+    setStatus("");
+    await refresh();
+  } catch (err) {
+    fail(err);
+  }
+}
+
+// Sharing
+
+// uploadShared uploads the content shared with the installed app, kept by the service worker until then.
+async function uploadShared() {
+  if (!window.caches) return;
+
+  const cache = await caches.open(shareCacheName);
+  const requests = await cache.keys();
+
+  for (const req of requests) {
+    const resp = await cache.match(req);
+    const blob = await resp.blob();
+    const name = resp.headers.get("X-Filename");
+
+    setStatus("Uploading the shared content…");
+    await uploadFile(new File([blob], name ? decodeURIComponent(name) : "", { type: blob.type }));
+    await cache.delete(req);
+  }
+
+  if (requests.length > 0) {
+    setStatus("The shared content was copied.");
+    await refresh();
+  }
+}
+
+// Setup
+
+function showKeys(present) {
+  $("setup").hidden = present;
+  $("main").hidden = !present;
+  $("forget").hidden = !present;
+}
+
+async function start() {
+  showKeys(true);
+  await refresh();
+  subscribe(lastEntryID());
+
+  await uploadShared();
+  if (location.search !== "") history.replaceState(null, "", "/");
+}
+
+$("setup").addEventListener("submit", async (ev) => {
+  ev.preventDefault();
+  try {
+    keys = await importKeys(ev.target);
+    setStatus("");
+    await start();
+  } catch (err) {
+    fail(err);
+  }
+});
+
+$("forget").addEventListener("click", () => {
+  localStorage.removeItem(keysStorageKey);
+  keys = null;
+  entries = [];
+  if (events) events.abort();
+  events = null;
+
+  renderEntries();
+  showKeys(false);
+});
+
+const dropzone = $("dropzone");
+dropzone.addEventListener("dragover", (ev) => {
+  ev.preventDefault();
+  dropzone.classList.add("active");
+});
+dropzone.addEventListener("dragleave", () => dropzone.classList.remove("active"));
+dropzone.addEventListener("drop", (ev) => {
+  ev.preventDefault();
+  dropzone.classList.remove("active");
+  uploadFiles(ev.dataTransfer.files);
+});
+$("files").addEventListener("change", (ev) => {
+  uploadFiles(Array.from(ev.target.files));
+  ev.target.value = "";
+});
+
+if ("serviceWorker" in navigator) {
+  navigator.serviceWorker.register("/sw.js").catch(fail);
+}
+
+const params = new URLSearchParams(location.search);
+
+keys = loadKeys();
+if (keys) {
+  start().catch(fail);
+} else {
+  showKeys(false);
+  if (params.has("share")) setStatus("Import the keys to copy the shared content.");
+}
+
+if (params.get("share") === "unavailable") {
+  setStatus("The shared content couldn't be received, open the app once and share again.", true);
+}
@@ -0,0 +1,630 @@
+// Cryptography compatible with the apero client, in plain JavaScript.
+//
+// WebCrypto is only available in secure contexts and doesn't implement secretbox,
+// so everything is done here: XSalsa20-Poly1305 (NaCl secretbox), Ed25519 signatures,
+// SHA-256 and SHA-512. The code isn't constant time; it only ever handles the keys of
+// the user running it, in their own browser.
+
+// Encoding helpers
+
+export function utf8Encode(s) {
+  return new TextEncoder().encode(s);
+}
+
+export function utf8Decode(b) {
+  return new TextDecoder().decode(b);
+}
+
+export function concat(...arrays) {
+  let n = 0;
+  for (const a of arrays) n += a.length;
+
+  const res = new Uint8Array(n);
+  let off = 0;
+  for (const a of arrays) {
+    res.set(a, off);
+    off += a.length;
+  }
+  return res;
+}
+
+export function encodeBase64(b) {
+  let s = "";
+  for (let i = 0; i < b.length; i += 0x8000) {
+    s += String.fromCharCode.apply(null, b.subarray(i, i + 0x8000));
+  }
+  return btoa(s);
+}
+
+export function decodeBase64(s) {
+  const bin = atob(s);
+  const res = new Uint8Array(bin.length);
+  for (let i = 0; i < bin.length; i++) res[i] = bin.charCodeAt(i);
+  return res;
+}
+
+export function encodeBase64URL(b) {
+  return encodeBase64(b).replace(/\+/g, "-").replace(/\//g, "_");
+}
+
+export function encodeHex(b) {
+  return Array.from(b, (v) => v.toString(16).padStart(2, "0")).join("");
+}
+
+export function decodeHex(s) {
+  if (s.length % 2 !== 0 || !/^[0-9a-fA-F]*$/.test(s)) {
+    throw new Error("invalid hex string");
+  }
+  const res = new Uint8Array(s.length / 2);
+  for (let i = 0; i < res.length; i++) res[i] = parseInt(s.substr(i * 2, 2), 16);
+  return res;
+}
+
+export function randomBytes(n) {
+  return crypto.getRandomValues(new Uint8Array(n));
+}
+
+export function uint64BE(n) {
+  const res = new Uint8Array(8);
+  new DataView(res.buffer).setBigUint64(0, BigInt(n));
+  return res;
+}
+
+// ULIDs are sent as strings but signed as their 16 bytes.
+
+const crockford = "0123456789ABCDEFGHJKMNPQRSTVWXYZ";
+
+export function decodeULID(s) {
+  if (s.length !== 26) throw new Error("invalid ULID");
+
+  let v = 0n;
+  for (const c of s.toUpperCase()) {
+    const i = crockford.indexOf(c);
+    if (i < 0) throw new Error("invalid ULID");
+    v = (v << 5n) | BigInt(i);
+  }
+
+  const res = new Uint8Array(16);
+  for (let i = 15; i >= 0; i--) {
+    res[i] = Number(v & 0xffn);
+    v >>= 8n;
+  }
+  return res;
+}
+
+export function encodeULID(b) {
+  let v = 0n;
+  for (const x of b) v = (v << 8n) | BigInt(x);
+
+  let s = "";
+  for (let i = 0; i < 26; i++) {
+    s = crockford[Number(v & 31n)] + s;
+    v >>= 5n;
+  }
+  return s;
+}
+
+export function ulidTime(s) {
+  const b = decodeULID(s);
+  let ms = 0;
+  for (let i = 0; i < 6; i++) ms = ms * 256 + b[i];
+  return new Date(ms);
+}
+
+// Salsa20
+
+function rotl(x, n) {
+  return (x << n) | (x >>> (32 - n));
+}
+
+function salsa20Rounds(x) {
+  for (let i = 0; i < 20; i += 2) {
+    x[4] ^= rotl((x[0] + x[12]) | 0, 7);
+    x[8] ^= rotl((x[4] + x[0]) | 0, 9);
+    x[12] ^= rotl((x[8] + x[4]) | 0, 13);
+    x[0] ^= rotl((x[12] + x[8]) | 0, 18);
+    x[9] ^= rotl((x[5] + x[1]) | 0, 7);
+    x[13] ^= rotl((x[9] + x[5]) | 0, 9);
+    x[1] ^= rotl((x[13] + x[9]) | 0, 13);
+    x[5] ^= rotl((x[1] + x[13]) | 0, 18);
+    x[14] ^= rotl((x[10] + x[6]) | 0, 7);
+    x[2] ^= rotl((x[14] + x[10]) | 0, 9);
+    x[6] ^= rotl((x[2] + x[14]) | 0, 13);
+    x[10] ^= rotl((x[6] + x[2]) | 0, 18);
+    x[3] ^= rotl((x[15] + x[11]) | 0, 7);
+    x[7] ^= rotl((x[3] + x[15]) | 0, 9);
+    x[11] ^= rotl((x[7] + x[3]) | 0, 13);
+    x[15] ^= rotl((x[11] + x[7]) | 0, 18);
+
+    x[1] ^= rotl((x[0] + x[3]) | 0, 7);
+    x[2] ^= rotl((x[1] + x[0]) | 0, 9);
+    x[3] ^= rotl((x[2] + x[1]) | 0, 13);
+    x[0] ^= rotl((x[3] + x[2]) | 0, 18);
+    x[6] ^= rotl((x[5] + x[4]) | 0, 7);
+    x[7] ^= rotl((x[6] + x[5]) | 0, 9);
+    x[4] ^= rotl((x[7] + x[6]) | 0, 13);
+    x[5] ^= rotl((x[4] + x[7]) | 0, 18);
+    x[11] ^= rotl((x[10] + x[9]) | 0, 7);
+    x[8] ^= rotl((x[11] + x[10]) | 0, 9);
+    x[9] ^= rotl((x[8] + x[11]) | 0, 13);
+    x[10] ^= rotl((x[9] + x[8]) | 0, 18);
+    x[12] ^= rotl((x[15] + x[14]) | 0, 7);
+    x[13] ^= rotl((x[12] + x[15]) | 0, 9);
+    x[14] ^= rotl((x[13] + x[12]) | 0, 13);
+    x[15] ^= rotl((x[14] + x[13]) | 0, 18);
+  }
+}
+
+function salsa20State(key, input) {
+  const k = new DataView(key.buffer, key.byteOffset, 32);
+  const n = new DataView(input.buffer, input.byteOffset, 16);
+
+  const s = new Uint32Array(16);
+  s[0] = 0x61707865;
+  s[5] = 0x3320646e;
+  s[10] = 0x79622d32;
+  s[15] = 0x6b206574;
+  for (let i = 0; i < 4; i++) {
+    s[1 + i] = k.getUint32(i * 4, true);
+    s[11 + i] = k.getUint32(16 + i * 4, true);
+    s[6 + i] = n.getUint32(i * 4, true);
+  }
+  return s;
+}
+
+function hsalsa20(key, input) {
+  const x = salsa20State(key, input);
+  salsa20Rounds(x);
+
+  const res = new Uint8Array(32);
+  const v = new DataView(res.buffer);
+  [0, 5, 10, 15, 6, 7, 8, 9].forEach((j, i) => v.setUint32(i * 4, x[j], true));
+  return res;
+}
+
+// xsalsa20Stream returns n bytes of the XSalsa20 key stream.
+function xsalsa20Stream(key, nonce, n) {
+  const subkey = hsalsa20(key, nonce.subarray(0, 16));
+
+  const input = new Uint8Array(16);
+  input.set(nonce.subarray(16, 24));
+  const state = salsa20State(subkey, input);
+
+  const res = new Uint8Array(Math.ceil(n / 64) * 64);
+  const v = new DataView(res.buffer);
+  const x = new Uint32Array(16);
+
+  for (let off = 0, counter = 0; off < n; off += 64, counter++) {
+    state[8] = counter;
+    state[9] = Math.floor(counter / 0x100000000);
+
+    x.set(state);
+    salsa20Rounds(x);
+    for (let i = 0; i < 16; i++) v.setUint32(off + i * 4, (x[i] + state[i]) >>> 0, true);
+  }
+
+  return res.subarray(0, n);
+}
+
+// Poly1305
+
+const poly1305P = (1n << 130n) - 5n;
+const mask130 = (1n << 130n) - 1n;
+
+function leBigInt(b) {
+  let v = 0n;
+  for (let i = b.length - 1; i >= 0; i--) v = (v << 8n) | BigInt(b[i]);
+  return v;
+}
+
+function bigIntLE(v, n) {
+  const res = new Uint8Array(n);
+  for (let i = 0; i < n; i++) {
+    res[i] = Number(v & 0xffn);
+    v >>= 8n;
+  }
+  return res;
+}
+
+function poly1305(msg, key) {
+  const r = leBigInt(key.subarray(0, 16)) & 0x0ffffffc0ffffffc0ffffffc0fffffffn;
+  const s = leBigInt(key.subarray(16, 32));
+
+  let h = 0n;
+  for (let i = 0; i < msg.length; i += 16) {
+    const block = msg.subarray(i, i + 16);
+    h += leBigInt(block) + (1n << BigInt(block.length * 8));
+    h *= r;
+    h = (h & mask130) + 5n * (h >> 130n);
+    h = (h & mask130) + 5n * (h >> 130n);
+  }
+  h %= poly1305P;
+
+  return bigIntLE(h + s, 16);
+}
+
+function equalBytes(a, b) {
+  if (a.length !== b.length) return false;
+  let d = 0;
+  for (let i = 0; i < a.length; i++) d |= a[i] ^ b[i];
+  return d === 0;
+}
+
+// Secretbox
+
+export const secretBoxOverhead = 16;
+
+// secretBoxSealNonce seals the message with the nonce and returns the tag followed by the ciphertext.
+export function secretBoxSealNonce(msg, nonce, key) {
+  const stream = xsalsa20Stream(key, nonce, msg.length + 32);
+
+  const c = new Uint8Array(msg.length);
+  for (let i = 0; i < msg.length; i++) c[i] = msg[i] ^ stream[32 + i];
+
+  return concat(poly1305(c, stream.subarray(0, 32)), c);
+}
+
+// secretBoxOpenNonce opens a box created by secretBoxSealNonce, it returns null if it's invalid.
+export function secretBoxOpenNonce(box, nonce, key) {
+  if (box.length < secretBoxOverhead) return null;
+
+  const c = box.subarray(secretBoxOverhead);
+  const stream = xsalsa20Stream(key, nonce, c.length + 32);
+
+  if (!equalBytes(poly1305(c, stream.subarray(0, 32)), box.subarray(0, secretBoxOverhead))) {
+    return null;
+  }
+
+  const m = new Uint8Array(c.length);
+  for (let i = 0; i < c.length; i++) m[i] = c[i] ^ stream[32 + i];
+  return m;
+}
+
+// secretBoxSeal seals the message with a random nonce prepended to the box, like secretBoxSeal in Go.
+export function secretBoxSeal(msg, key) {
+  const nonce = randomBytes(24);
+  return concat(nonce, secretBoxSealNonce(msg, nonce, key));
+}
+
+export function secretBoxOpen(box, key) {
+  if (box.length < 25) return null;
+  return secretBoxOpenNonce(box.subarray(24), box.subarray(0, 24), key);
+}
+
+// Secret stream, see the Go implementation for the format.
+
+const secretStreamMagic = utf8Encode("aperoST1");
+export const secretStreamChunkSize = 64 << 10;
+
+function secretStreamNonce(prefix, counter, final) {
+  const nonce = new Uint8Array(24);
+  nonce.set(prefix);
+
+  let c = BigInt(counter);
+  if (final) c |= 1n << 63n;
+  nonce.set(uint64BE(c), 16);
+
+  return nonce;
+}
+
+export function secretStreamEncrypt(plaintext, key) {
+  const prefix = randomBytes(16);
+  const parts = [secretStreamMagic, prefix];
+
+  let counter = 0;
+  let off = 0;
+  for (;;) {
+    const chunk = plaintext.subarray(off, off + secretStreamChunkSize);
+    const final = chunk.length < secretStreamChunkSize;
+
+    parts.push(secretBoxSealNonce(chunk, secretStreamNonce(prefix, counter, final), key));
+    counter++;
+    off += chunk.length;
+
+    if (final) break;
+  }
+
+  return concat(...parts);
+}
+
+export function secretStreamDecrypt(data, key) {
+  const magic = data.subarray(0, secretStreamMagic.length);
+  if (!equalBytes(magic, secretStreamMagic)) {
+    // Entries created by old clients are a single box
+    const plaintext = secretBoxOpen(data, key);
+    if (plaintext === null) throw new Error("stream is corrupted");
+    return plaintext;
+  }
+
+  const prefix = data.subarray(8, 24);
+  if (prefix.length !== 16) throw new Error("stream is truncated");
+
+  const sealedSize = secretStreamChunkSize + secretBoxOverhead;
+  const parts = [];
+
+  let counter = 0;
+  for (let off = 24; ; off += sealedSize) {
+    const sealed = data.subarray(off, off + sealedSize);
+    if (sealed.length === 0) throw new Error("stream is truncated");
+
+    const final = sealed.length < sealedSize;
+    const plain = secretBoxOpenNonce(sealed, secretStreamNonce(prefix, counter, final), key);
+    if (plain === null) throw new Error("stream is corrupted");
+
+    parts.push(plain);
+    counter++;
+
+    if (final) break;
+  }
+
+  return concat(...parts);
+}
+
+// SHA-256
+
+const sha256K = new Uint32Array([
+  0x428a2f98, 0x71374491, 0xb5c0fbcf, 0xe9b5dba5, 0x3956c25b, 0x59f111f1, 0x923f82a4, 0xab1c5ed5,
+  0xd807aa98, 0x12835b01, 0x243185be, 0x550c7dc3, 0x72be5d74, 0x80deb1fe, 0x9bdc06a7, 0xc19bf174,
+  0xe49b69c1, 0xefbe4786, 0x0fc19dc6, 0x240ca1cc, 0x2de92c6f, 0x4a7484aa, 0x5cb0a9dc, 0x76f988da,
+  0x983e5152, 0xa831c66d, 0xb00327c8, 0xbf597fc7, 0xc6e00bf3, 0xd5a79147, 0x06ca6351, 0x14292967,
+  0x27b70a85, 0x2e1b2138, 0x4d2c6dfc, 0x53380d13, 0x650a7354, 0x766a0abb, 0x81c2c92e, 0x92722c85,
+  0xa2bfe8a1, 0xa81a664b, 0xc24b8b70, 0xc76c51a3, 0xd192e819, 0xd6990624, 0xf40e3585, 0x106aa070,
+  0x19a4c116, 0x1e376c08, 0x2748774c, 0x34b0bcb5, 0x391c0cb3, 0x4ed8aa4a, 0x5b9cca4f, 0x682e6ff3,
+  0x748f82ee, 0x78a5636f, 0x84c87814, 0x8cc70208, 0x90befffa, 0xa4506ceb, 0xbef9a3f7, 0xc67178f2,
+]);
+
+function rotr(x, n) {
+  return (x >>> n) | (x << (32 - n));
+}
+
+function mdPad(msg, blockSize, lengthSize) {
+  let n = msg.length + 1 + lengthSize;
+  n += (blockSize - (n % blockSize)) % blockSize;
+
+  const res = new Uint8Array(n);
+  res.set(msg);
+  res[msg.length] = 0x80;
+  new DataView(res.buffer).setBigUint64(n - 8, BigInt(msg.length) * 8n);
+  return res;
+}
+
+export function sha256(msg) {
+  const data = mdPad(msg, 64, 8);
+  const v = new DataView(data.buffer);
+
+  const h = new Uint32Array([
+    0x6a09e667, 0xbb67ae85, 0x3c6ef372, 0xa54ff53a, 0x510e527f, 0x9b05688c, 0x1f83d9ab, 0x5be0cd19,
+  ]);
+  const w = new Uint32Array(64);
+
+  for (let off = 0; off < data.length; off += 64) {
+    for (let i = 0; i < 16; i++) w[i] = v.getUint32(off + i * 4);
+    for (let i = 16; i < 64; i++) {
+      const s0 = rotr(w[i - 15], 7) ^ rotr(w[i - 15], 18) ^ (w[i - 15] >>> 3);
+      const s1 = rotr(w[i - 2], 17) ^ rotr(w[i - 2], 19) ^ (w[i - 2] >>> 10);
+      w[i] = w[i - 16] + s0 + w[i - 7] + s1;
+    }
+
+    let [a, b, c, d, e, f, g, hh] = h;
+    for (let i = 0; i < 64; i++) {
+      const s1 = rotr(e, 6) ^ rotr(e, 11) ^ rotr(e, 25);
+      const ch = (e & f) ^ (~e & g);
+      const t1 = (hh + s1 + ch + sha256K[i] + w[i]) | 0;
+      const s0 = rotr(a, 2) ^ rotr(a, 13) ^ rotr(a, 22);
+      const maj = (a & b) ^ (a & c) ^ (b & c);
+      const t2 = (s0 + maj) | 0;
+
+      hh = g;
+      g = f;
+      f = e;
+      e = (d + t1) | 0;
+      d = c;
+      c = b;
+      b = a;
+      a = (t1 + t2) | 0;
+    }
+
+    h[0] += a;
+    h[1] += b;
+    h[2] += c;
+    h[3] += d;
+    h[4] += e;
+    h[5] += f;
+    h[6] += g;
+    h[7] += hh;
+  }
+
+  const res = new Uint8Array(32);
+  const rv = new DataView(res.buffer);
+  h.forEach((x, i) => rv.setUint32(i * 4, x));
+  return res;
+}
+
+// SHA-512, only used on small messages for signatures.
+
+const sha512K = [
+  "428a2f98d728ae22", "7137449123ef65cd", "b5c0fbcfec4d3b2f", "e9b5dba58189dbbc", "3956c25bf348b538",
+  "59f111f1b605d019", "923f82a4af194f9b", "ab1c5ed5da6d8118", "d807aa98a3030242", "12835b0145706fbe",
+  "243185be4ee4b28c", "550c7dc3d5ffb4e2", "72be5d74f27b896f", "80deb1fe3b1696b1", "9bdc06a725c71235",
+  "c19bf174cf692694", "e49b69c19ef14ad2", "efbe4786384f25e3", "0fc19dc68b8cd5b5", "240ca1cc77ac9c65",
+  "2de92c6f592b0275", "4a7484aa6ea6e483", "5cb0a9dcbd41fbd4", "76f988da831153b5", "983e5152ee66dfab",
+  "a831c66d2db43210", "b00327c898fb213f", "bf597fc7beef0ee4", "c6e00bf33da88fc2", "d5a79147930aa725",
+  "06ca6351e003826f", "142929670a0e6e70", "27b70a8546d22ffc", "2e1b21385c26c926", "4d2c6dfc5ac42aed",
+  "53380d139d95b3df", "650a73548baf63de", "766a0abb3c77b2a8", "81c2c92e47edaee6", "92722c851482353b",
+  "a2bfe8a14cf10364", "a81a664bbc423001", "c24b8b70d0f89791", "c76c51a30654be30", "d192e819d6ef5218",
+  "d69906245565a910", "f40e35855771202a", "106aa07032bbd1b8", "19a4c116b8d2d0c8", "1e376c085141ab53",
+  "2748774cdf8eeb99", "34b0bcb5e19b48a8", "391c0cb3c5c95a63", "4ed8aa4ae3418acb", "5b9cca4f7763e373",
+  "682e6ff3d6b2b8a3", "748f82ee5defb2fc", "78a5636f43172f60", "84c87814a1f0ab72", "8cc702081a6439ec",
+  "90befffa23631e28", "a4506cebde82bde9", "bef9a3f7b2c67915", "c67178f2e372532b", "ca273eceea26619c",
+  "d186b8c721c0c207", "eada7dd6cde0eb1e", "f57d4f7fee6ed178", "06f067aa72176fba", "0a637dc5a2c898a6",
+  "113f9804bef90dae", "1b710b35131c471b", "28db77f523047d84", "32caab7b40c72493", "3c9ebe0a15c9bebc",
+  "431d67c49c100d4c", "4cc5d4becb3e42b6", "597f299cfc657e2a", "5fcb6fab3ad6faec", "6c44198c4a475817",
+].map((s) => BigInt("0x" + s));
+
+const mask64 = (1n << 64n) - 1n;
+
+function rotr64(x, n) {
+  return ((x >> n) | (x << (64n - n))) & mask64;
+}
+
+export function sha512(msg) {
+  const data = mdPad(msg, 128, 16);
+  const v = new DataView(data.buffer);
+
+  const h = [
+    "6a09e667f3bcc908", "bb67ae8584caa73b", "3c6ef372fe94f82b", "a54ff53a5f1d36f1",
+    "510e527fade682d1", "9b05688c2b3e6c1f", "1f83d9abfb41bd6b", "5be0cd19137e2179",
+  ].map((s) => BigInt("0x" + s));
+  const w = new Array(80);
+
+  for (let off = 0; off < data.length; off += 128) {
+    for (let i = 0; i < 16; i++) w[i] = v.getBigUint64(off + i * 8);
+    for (let i = 16; i < 80; i++) {
+      const s0 = rotr64(w[i - 15], 1n) ^ rotr64(w[i - 15], 8n) ^ (w[i - 15] >> 7n);
+      const s1 = rotr64(w[i - 2], 19n) ^ rotr64(w[i - 2], 61n) ^ (w[i - 2] >> 6n);
+      w[i] = (w[i - 16] + s0 + w[i - 7] + s1) & mask64;
+    }
+
+    let [a, b, c, d, e, f, g, hh] = h;
+    for (let i = 0; i < 80; i++) {
+      const s1 = rotr64(e, 14n) ^ rotr64(e, 18n) ^ rotr64(e, 41n);
+      const ch = (e & f) ^ (~e & mask64 & g);
+      const t1 = (hh + s1 + ch + sha512K[i] + w[i]) & mask64;
+      const s0 = rotr64(a, 28n) ^ rotr64(a, 34n) ^ rotr64(a, 39n);
+      const maj = (a & b) ^ (a & c) ^ (b & c);
+      const t2 = (s0 + maj) & mask64;
+
+      hh = g;
+      g = f;
+      f = e;
+      e = (d + t1) & mask64;
+      d = c;
+      c = b;
+      b = a;
+      a = (t1 + t2) & mask64;
+    }
+
+    [a, b, c, d, e, f, g, hh].forEach((x, i) => (h[i] = (h[i] + x) & mask64));
+  }
+
+  const res = new Uint8Array(64);
+  const rv = new DataView(res.buffer);
+  h.forEach((x, i) => rv.setBigUint64(i * 8, x));
+  return res;
+}
+
+// Ed25519
+
+const edP = (1n << 255n) - 19n;
+const edL = (1n << 252n) + 27742317777372353535851937790883648493n;
+
+function mod(a, m = edP) {
+  const r = a % m;
+  return r >= 0n ? r : r + m;
+}
+
+function modPow(b, e, m = edP) {
+  let r = 1n;
+  b = mod(b, m);
+  while (e > 0n) {
+    if (e & 1n) r = (r * b) % m;
+    b = (b * b) % m;
+    e >>= 1n;
+  }
+  return r;
+}
+
+const edD = mod(-121665n * modPow(121666n, edP - 2n));
+const edBase = [
+  15112221349535400772501151409588531511454012693041857206046113283949847762202n,
+  46316835694926478169428394003475163141307993866256225615783033603165251855960n,
+  1n,
+  mod(15112221349535400772501151409588531511454012693041857206046113283949847762202n *
+    46316835694926478169428394003475163141307993866256225615783033603165251855960n),
+];
+
+// Points are in extended coordinates [X, Y, Z, T].
+function edAdd(p, q) {
+  const a = mod((p[1] - p[0]) * (q[1] - q[0]));
+  const b = mod((p[1] + p[0]) * (q[1] + q[0]));
+  const c = mod(2n * p[3] * q[3] * edD);
+  const d = mod(2n * p[2] * q[2]);
+  const e = b - a;
+  const f = d - c;
+  const g = d + c;
+  const h = b + a;
+  return [mod(e * f), mod(g * h), mod(f * g), mod(e * h)];
+}
+
+function edScalarMult(s, p) {
+  let q = [0n, 1n, 1n, 0n];
+  while (s > 0n) {
+    if (s & 1n) q = edAdd(q, p);
+    p = edAdd(p, p);
+    s >>= 1n;
+  }
+  return q;
+}
+
+function edEncode(p) {
+  const zi = modPow(p[2], edP - 2n);
+  const x = mod(p[0] * zi);
+  const y = mod(p[1] * zi);
+
+  const res = bigIntLE(y, 32);
+  res[31] |= Number(x & 1n) << 7;
+  return res;
+}
+
+function edExpandSeed(seed) {
+  const h = sha512(seed);
+  const a = h.slice(0, 32);
+  a[0] &= 248;
+  a[31] &= 127;
+  a[31] |= 64;
+  return { a: leBigInt(a), prefix: h.subarray(32) };
+}
+
+// ed25519PublicKey returns the public key of the 32 bytes seed, which is how private keys are stored.
+export function ed25519PublicKey(seed) {
+  return edEncode(edScalarMult(edExpandSeed(seed).a, edBase));
+}
+
+export function ed25519Sign(seed, msg) {
+  const { a, prefix } = edExpandSeed(seed);
+  const pub = edEncode(edScalarMult(a, edBase));
+
+  const r = mod(leBigInt(sha512(concat(prefix, msg))), edL);
+  const R = edEncode(edScalarMult(r, edBase));
+  const k = mod(leBigInt(sha512(concat(R, pub, msg))), edL);
+  const S = mod(r + k * a, edL);
+
+  return concat(R, bigIntLE(S, 32));
+}
+
+// BIP39 mnemonics of keys as produced by apero provision.
+
+export function mnemonicToKey(mnemonic, wordlist) {
+  const words = mnemonic.trim().toLowerCase().split(/\s+/);
+  if (words.length % 3 !== 0 || words.length < 12) throw new Error("invalid mnemonic");
+
+  let bits = "";
+  for (const word of words) {
+    const i = wordlist.indexOf(word);
+    if (i < 0) throw new Error(`unknown word ${word} in mnemonic`);
+    bits += i.toString(2).padStart(11, "0");
+  }
+
+  const checksumBits = bits.length / 33;
+  const entropyBits = bits.length - checksumBits;
+
+  const entropy = new Uint8Array(entropyBits / 8);
+  for (let i = 0; i < entropy.length; i++) entropy[i] = parseInt(bits.substr(i * 8, 8), 2);
+
+  const checksum = Array.from(sha256(entropy), (b) => b.toString(2).padStart(8, "0")).join("");
+  if (checksum.substr(0, checksumBits) !== bits.substr(entropyBits)) {
+    throw new Error("invalid mnemonic checksum");
+  }
+
+  return entropy;
+}
@@ -0,0 +1,96 @@
+// Cross-implementation tests of crypto.js, run by TestUICrypto:
+//
+//   node crypto_test.mjs vectors.json
+//
+// The vectors are generated by the Go implementation; the values computed by crypto.js
+// are printed as JSON so that the Go implementation checks them too.
+
+import { readFileSync } from "fs";
+import * as c from "./crypto.js";
+
+const vectors = JSON.parse(readFileSync(process.argv[2], "utf8"));
+
+function check(ok, msg) {
+  if (!ok) {
+    console.error(msg);
+    process.exit(1);
+  }
+}
+
+function equal(a, b) {
+  return a !== null && c.encodeHex(a) === c.encodeHex(b);
+}
+
+function tamper(b) {
+  const res = b.slice();
+  res[res.length - 1] ^= 1;
+  return res;
+}
+
+const b = (s) => c.decodeBase64(s);
+const out = { secretBoxes: [], streams: [], signatures: [] };
+
+// Secretbox
+
+vectors.secretBoxes.forEach((v, i) => {
+  const key = b(v.key);
+  const msg = b(v.msg);
+  const box = b(v.box);
+
+  check(equal(c.secretBoxOpen(box, key), msg), `secretbox ${i}: unable to open the Go box`);
+  check(equal(c.secretBoxSealNonce(msg, box.subarray(0, 24), key), box.subarray(24)), `secretbox ${i}: box differs from Go`);
+  check(c.secretBoxOpen(tamper(box), key) === null, `secretbox ${i}: tampered box opened`);
+
+  const ownKey = c.randomBytes(32);
+  out.secretBoxes.push({ key: c.encodeBase64(ownKey), msg: v.msg, box: c.encodeBase64(c.secretBoxSeal(msg, ownKey)) });
+});
+
+// Secret stream
+
+vectors.streams.forEach((v, i) => {
+  const key = b(v.key);
+  const plaintext = b(v.plaintext);
+  const stream = b(v.stream);
+
+  check(equal(c.secretStreamDecrypt(stream, key), plaintext), `stream ${i}: unable to decrypt the Go stream`);
+
+  let truncated = false;
+  try {
+    c.secretStreamDecrypt(stream.subarray(0, stream.length - 1), key);
+  } catch (err) {
+    truncated = true;
+  }
+  check(truncated, `stream ${i}: truncated stream decrypted`);
+
+  const ownKey = c.randomBytes(32);
+  out.streams.push({ key: c.encodeBase64(ownKey), plaintext: v.plaintext, stream: c.encodeBase64(c.secretStreamEncrypt(plaintext, ownKey)) });
+});
+
+// Ed25519
+
+vectors.signatures.forEach((v, i) => {
+  const seed = b(v.seed);
+  const msg = b(v.msg);
+
+  check(equal(c.ed25519PublicKey(seed), b(v.publicKey)), `signature ${i}: public key differs from Go`);
+  check(equal(c.ed25519Sign(seed, msg), b(v.signature)), `signature ${i}: signature differs from Go`);
+
+  const ownSeed = c.randomBytes(32);
+  out.signatures.push({
+    seed: c.encodeBase64(ownSeed),
+    msg: v.msg,
+    publicKey: c.encodeBase64(c.ed25519PublicKey(ownSeed)),
+    signature: c.encodeBase64(c.ed25519Sign(ownSeed, msg)),
+  });
+});
+
+// Hashes
+
+vectors.hashes.forEach((v, i) => {
+  const msg = b(v.msg);
+
+  check(equal(c.sha256(msg), b(v.sha256)), `hash ${i}: sha256 differs from Go`);
+  check(equal(c.sha512(msg), b(v.sha512)), `hash ${i}: sha512 differs from Go`);
+});
+
+console.log(JSON.stringify(out));
@@ -1,21 +1,17 @@
-let aMm = document.querySelector("a[href='#mnemonic']")
-let aHex = document.querySelector("a[href='#hex']")
+let tabs = [
+    [document.querySelector("a[href='#mnemonic']"), document.getElementById("container-mmkeys")],
+    [document.querySelector("a[href='#hex']"), document.getElementById("container-hexkeys")],
+    [document.querySelector("a[href='#qrcode']"), document.getElementById("container-qrcodes")],
+]
 
-let cMm = document.getElementById("container-mmkeys")
-let cHex = document.getElementById("container-hexkeys")
+tabs.forEach(([a, c]) => {
+    a.addEventListener("click", (el, ev) => {
+        tabs.forEach(([otherA, otherC]) => {
+            otherA.removeAttribute("class");
+            otherC.style = "display: none";
+        })
 
-aMm.addEventListener("click", (el, ev) => {
-    aHex.removeAttribute("class");
-    aMm.setAttribute("class", "active");
-    
-    cHex.style = "display: none";
-    cMm.style = "";
+        a.setAttribute("class", "active");
+        c.style = "";
+    })
 })
-
-aHex.addEventListener("click", (el, ev) => {
-    aMm.removeAttribute("class");
-    aHex.setAttribute("class", "active");
-    
-    cMm.style = "display: none";
-    cHex.style = "";
-})
\ No newline at end of file
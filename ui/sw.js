@@ -0,0 +1,119 @@
+// The apero service worker.
+//
+// It makes the web client installable and usable offline, and receives the content shared
+// with the installed app through the Web Share Target API.
+//
+// Shared content is only kept in a local cache: the page encrypts and uploads it once it's
+// open, the shared plaintext never reaches the server.
+
+const appCacheName = "apero-app-v1";
+const shareCacheName = "apero-share";
+
+// appFiles are needed for the page to work offline, optionalAppFiles are nice to have.
+const appFiles = ["/", "/app.js", "/crypto.js"];
+const optionalAppFiles = ["/style.css", "/wordlist.json", "/manifest.webmanifest", "/icon-192.png", "/icon-512.png"];
+
+self.addEventListener("install", (ev) => {
+  ev.waitUntil(cacheAppFiles());
+  self.skipWaiting();
+});
+
+// cacheAppFiles caches the files one by one: a missing icon must not fail the installation,
+// networkFirst caches it the next time it's fetched anyway.
+async function cacheAppFiles() {
+  const cache = await caches.open(appCacheName);
+
+  const results = await Promise.all(
+    appFiles.concat(optionalAppFiles).map((file) =>
+      cache.add(file).then(
+        () => true,
+        () => !appFiles.includes(file)
+      )
+    )
+  );
+  if (results.includes(false)) throw new Error("unable to cache the app");
+}
+
+self.addEventListener("activate", (ev) => {
+  ev.waitUntil(
+    caches
+      .keys()
+      .then((names) =>
+        Promise.all(
+          names.filter((name) => name !== appCacheName && name !== shareCacheName).map((name) => caches.delete(name))
+        )
+      )
+      .then(() => self.clients.claim())
+  );
+});
+
+self.addEventListener("fetch", (ev) => {
+  const url = new URL(ev.request.url);
+  if (url.origin !== self.location.origin) return;
+
+  if (ev.request.method === "POST" && url.pathname === "/share") {
+    ev.respondWith(receiveShare(ev.request));
+    return;
+  }
+
+  // NOTE(vincent): the API is never cached, every request is signed and can only be used once.
+  if (ev.request.method !== "GET" || url.pathname.startsWith("/api/")) return;
+
+  ev.respondWith(networkFirst(ev.request));
+});
+
+// networkFirst serves the app from the network when possible so that updates are picked
+// up right away, and from the cache when offline.
+async function networkFirst(request) {
+  const cache = await caches.open(appCacheName);
+
+  try {
+    const resp = await fetch(request);
+    if (resp.ok) cache.put(request, resp.clone());
+    return resp;
+  } catch (err) {
+    const cached = await cache.match(request, { ignoreSearch: request.mode === "navigate" });
+    if (cached) return cached;
+    throw err;
+  }
+}
+
+// receiveShare stores the shared files, or the shared text if there are no files,
+// and redirects to the page which uploads them.
+async function receiveShare(request) {
+  const form = await request.formData();
+  const cache = await caches.open(shareCacheName);
+
+  const id = Date.now();
+  const files = form.getAll("files").filter((file) => file instanceof File && file.size > 0);
+
+  for (let i = 0; i < files.length; i++) {
+    const file = files[i];
+    await cache.put(
+      `/shared/${id}-${i}`,
+      new Response(file, {
+        headers: {
+          "Content-Type": file.type || "application/octet-stream",
+          "X-Filename": encodeURIComponent(file.name),
+        },
+      })
+    );
+  }
+
+  if (files.length === 0) {
+    // Apps share links in either field, sometimes in both. The title is only
+    // used when there's nothing else, it's usually the title of a shared page.
+    let values = ["text", "url"].map((name) => (form.get(name) || "").trim()).filter((v) => v !== "");
+    if (values.length === 0) values = [(form.get("title") || "").trim()];
+    const text = values.filter((v, i) => values.indexOf(v) === i).join("\n");
+
+    if (text !== "") {
+      await cache.put(
+        `/shared/${id}-0`,
+        new Response(text, { headers: { "Content-Type": "text/plain; charset=utf-8" } })
+      );
+    }
+  }
+
+  return Response.redirect("/?share", 303);
+}
@@ -0,0 +1,282 @@
+package main
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+
+	"rischmann.fr/apero/internal/ui"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestUI(t *testing.T) {
+	_, _, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	get := func(t *testing.T, path string) (*http.Response, []byte) {
+		resp, err := http.Get(httpServer.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		data, err := ioutil.ReadAll(resp.Body)
+		require.NoError(t, err)
+
+		return resp, data
+	}
+
+	t.Run("index", func(t *testing.T) {
+		resp, data := get(t, "/")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Type"), "text/html")
+		require.Equal(t, uiContentSecurityPolicy, resp.Header.Get("Content-Security-Policy"))
+		require.Contains(t, string(data), `<script type="module" src="/app.js"></script>`)
+		require.Contains(t, string(data), `<link rel="manifest" href="/manifest.webmanifest">`)
+	})
+
+	t.Run("style", func(t *testing.T) {
+		resp, data := get(t, "/style.css")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Contains(t, resp.Header.Get("Content-Type"), "text/css")
+		require.Equal(t, ui.MustGetFile("/style.css"), data)
+	})
+
+	t.Run("scripts", func(t *testing.T) {
+		for _, path := range []string{"/app.js", "/crypto.js", "/sw.js"} {
+			resp, data := get(t, path)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Contains(t, resp.Header.Get("Content-Type"), "javascript")
+			require.NotEmpty(t, data)
+		}
+	})
+
+	t.Run("service-worker-files", func(t *testing.T) {
+		// Every file cached by the service worker must exist
+
+		_, data := get(t, "/sw.js")
+
+		var n int
+		for _, m := range regexp.MustCompile(`(?m)^const (?:appFiles|optionalAppFiles) = \[(.*)\];$`).FindAllStringSubmatch(string(data), -1) {
+			for _, file := range strings.Split(m[1], ",") {
+				resp, _ := get(t, strings.Trim(strings.TrimSpace(file), `"`))
+				require.Equal(t, http.StatusOK, resp.StatusCode, file)
+				n++
+			}
+		}
+		require.Equal(t, 8, n)
+	})
+
+	t.Run("manifest", func(t *testing.T) {
+		resp, data := get(t, "/manifest.webmanifest")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+		require.Equal(t, "application/manifest+json", resp.Header.Get("Content-Type"))
+
+		var manifest struct {
+			StartURL string `json:"start_url"`
+			Icons    []struct {
+				Src string `json:"src"`
+			} `json:"icons"`
+			ShareTarget struct {
+				Action string `json:"action"`
+				Method string `json:"method"`
+			} `json:"share_target"`
+		}
+		require.NoError(t, json.Unmarshal(data, &manifest))
+		require.Equal(t, "/", manifest.StartURL)
+		require.Equal(t, "/share", manifest.ShareTarget.Action)
+		require.Equal(t, http.MethodPost, manifest.ShareTarget.Method)
+
+		for _, icon := range manifest.Icons {
+			resp, _ := get(t, icon.Src)
+			require.Equal(t, http.StatusOK, resp.StatusCode)
+			require.Equal(t, "image/png", resp.Header.Get("Content-Type"))
+		}
+	})
+
+	t.Run("share", func(t *testing.T) {
+		client := &http.Client{
+			CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse },
+		}
+
+		resp, err := client.Post(httpServer.URL+"/share", "text/plain", strings.NewReader("secret"))
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		require.Equal(t, http.StatusSeeOther, resp.StatusCode)
+		require.Equal(t, "/?share=unavailable", resp.Header.Get("Location"))
+	})
+
+	t.Run("wordlist", func(t *testing.T) {
+		resp, data := get(t, "/wordlist.json")
+		require.Equal(t, http.StatusOK, resp.StatusCode)
+
+		var words []string
+		require.NoError(t, json.Unmarshal(data, &words))
+		require.Len(t, words, 2048)
+		require.Equal(t, "abandon", words[0])
+	})
+
+	t.Run("not-found", func(t *testing.T) {
+		resp, _ := get(t, "/foobar")
+		require.Equal(t, http.StatusNotFound, resp.StatusCode)
+	})
+}
+
+// uiCryptoVectors are the test vectors exchanged with ui/crypto_test.mjs.
+type uiCryptoVectors struct {
+	SecretBoxes []uiSecretBoxVector    `json:"secretBoxes"`
+	Streams     []uiSecretStreamVector `json:"streams"`
+	Signatures  []uiSignatureVector    `json:"signatures"`
+	Hashes      []uiHashVector         `json:"hashes"`
+}
+
+type uiSecretBoxVector struct {
+	Key []byte `json:"key"`
+	Msg []byte `json:"msg"`
+	Box []byte `json:"box"`
+}
+
+type uiSecretStreamVector struct {
+	Key       []byte `json:"key"`
+	Plaintext []byte `json:"plaintext"`
+	Stream    []byte `json:"stream"`
+}
+
+type uiSignatureVector struct {
+	Seed      []byte `json:"seed"`
+	Msg       []byte `json:"msg"`
+	PublicKey []byte `json:"publicKey"`
+	Signature []byte `json:"signature"`
+}
+
+type uiHashVector struct {
+	Msg    []byte `json:"msg"`
+	SHA256 []byte `json:"sha256"`
+	SHA512 []byte `json:"sha512"`
+}
+
+// TestUICrypto checks that the JavaScript implementation of the web UI and the Go implementation
+// agree, in both directions, using node.
+func TestUICrypto(t *testing.T) {
+	node, err := exec.LookPath("node")
+	if err != nil {
+		t.Skip("node is not installed")
+	}
+
+	dir := mustTempDir(t)
+	defer os.RemoveAll(dir)
+
+	// The scripts are ES modules
+
+	for _, name := range []string{"crypto.js", "crypto_test.mjs"} {
+		data, err := ioutil.ReadFile(filepath.Join("ui", name))
+		require.NoError(t, err)
+		require.NoError(t, ioutil.WriteFile(filepath.Join(dir, name), data, 0644))
+	}
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "package.json"), []byte(`{"type": "module"}`), 0644))
+
+	// Generate the vectors
+
+	msg := func(n int) []byte {
+		b := make([]byte, n)
+		_, err := rand.Read(b)
+		require.NoError(t, err)
+		return b
+	}
+
+	var vectors uiCryptoVectors
+
+	for _, n := range []int{0, 1, 16, 32, 33, 100, 1000} {
+		key := newSecretBoxKey()
+		m := msg(n)
+
+		vectors.SecretBoxes = append(vectors.SecretBoxes, uiSecretBoxVector{key[:], m, secretBoxSeal(m, key)})
+
+		seed := msg(ed25519.SeedSize)
+		priv := ed25519.NewKeyFromSeed(seed)
+
+		vectors.Signatures = append(vectors.Signatures, uiSignatureVector{seed, m, priv.Public().(ed25519.PublicKey), ed25519.Sign(priv, m)})
+	}
+
+	for _, n := range []int{0, 1, secretStreamChunkSize - 1, secretStreamChunkSize, secretStreamChunkSize + 1, 2*secretStreamChunkSize + 7} {
+		key := newSecretBoxKey()
+		m := msg(n)
+
+		stream, err := ioutil.ReadAll(secretStreamEncrypt(bytes.NewReader(m), key))
+		require.NoError(t, err)
+
+		vectors.Streams = append(vectors.Streams, uiSecretStreamVector{key[:], m, stream})
+	}
+
+	// The padding of the hashes changes around the block sizes
+
+	for _, n := range []int{0, 1, 55, 56, 63, 64, 65, 111, 112, 127, 128, 129, 1000} {
+		m := msg(n)
+		h256 := sha256.Sum256(m)
+		h512 := sha512.Sum512(m)
+
+		vectors.Hashes = append(vectors.Hashes, uiHashVector{m, h256[:], h512[:]})
+	}
+
+	data, err := json.Marshal(vectors)
+	require.NoError(t, err)
+	require.NoError(t, ioutil.WriteFile(filepath.Join(dir, "vectors.json"), data, 0644))
+
+	// Check the vectors in JavaScript
+
+	var stderr bytes.Buffer
+
+	cmd := exec.Command(node, "crypto_test.mjs", "vectors.json")
+	cmd.Dir = dir
+	cmd.Stderr = &stderr
+
+	output, err := cmd.Output()
+	require.NoError(t, err, stderr.String())
+
+	// Check what JavaScript computed in Go
+
+	var js uiCryptoVectors
+	require.NoError(t, json.Unmarshal(output, &js))
+	require.Len(t, js.SecretBoxes, len(vectors.SecretBoxes))
+	require.Len(t, js.Streams, len(vectors.Streams))
+	require.Len(t, js.Signatures, len(vectors.Signatures))
+
+	for _, v := range js.SecretBoxes {
+		var key secretBoxKey
+		copy(key[:], v.Key)
+
+		plaintext, ok := secretBoxOpen(v.Box, key)
+		require.True(t, ok)
+		require.Equal(t, len(v.Msg), len(plaintext))
+		require.True(t, bytes.Equal(v.Msg, plaintext))
+	}
+
+	for _, v := range js.Streams {
+		var key secretBoxKey
+		copy(key[:], v.Key)
+
+		r, err := newSecretStreamReader(bytes.NewReader(v.Stream), key)
+		require.NoError(t, err)
+
+		plaintext, err := ioutil.ReadAll(r)
+		require.NoError(t, err)
+		require.True(t, bytes.Equal(v.Plaintext, plaintext))
+	}
+
+	for _, v := range js.Signatures {
+		priv := ed25519.NewKeyFromSeed(v.Seed)
+		require.Equal(t, priv.Public().(ed25519.PublicKey), ed25519.PublicKey(v.PublicKey))
+		require.True(t, verify(publicKey(v.PublicKey), v.Msg, v.Signature))
+	}
+}
@@ -0,0 +1,652 @@
+package main
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/vrischmann/hutil/v2"
+)
+
+const (
+	// uploadChunkSize is the size of every chunk of an upload session except the last one.
+	uploadChunkSize = 1 << 20
+
+	// uploadSessionTTL is how long an upload session is kept after its last activity.
+	uploadSessionTTL = time.Hour
+
+	// defaultMaxUploadSessions is the maximum number of upload sessions in progress
+	// when the configuration doesn't specify it.
+	defaultMaxUploadSessions = 16
+)
+
+var (
+	errUploadNotFound      = errors.New("upload session not found")
+	errUploadChunkMissing  = errors.New("upload chunk missing")
+	errUploadChunkTooLarge = errors.New("upload chunk too large")
+	errTooManyUploads      = errors.New("too many upload sessions in progress")
+)
+
+// uploadSessions holds the upload sessions in progress.
+//
+// An upload session lets a client send an entry in chunks over multiple requests
+// so that a network error only means sending the current chunk again instead of
+// the whole entry. Chunks must be sent in order; sending a chunk the session
+// already has is a no-op so a client can safely retry a chunk when it doesn't
+// know if the server got it.
+//
+// The content of a session is kept in a file in dir (or in the default temporary
+// directory if dir is empty) until the session is finished, at which point it's
+// added to the store.
+//
+// Sessions are removed after uploadSessionTTL without any activity.
+//
+// The sessions in progress count toward the store quota as if their content was already stored,
+// otherwise they could be used to fill the disk past the limits.
+// A session can only be used by the device which started it.
+type uploadSessions struct {
+	dir string
+
+	mu        sync.Mutex
+	sessions  map[ulid.ULID]*uploadSession
+	nextPrune time.Time
+
+	// quotaMu protects inProgress, the number of sessions not finished, and inProgressSize, the size of their content.
+	// It's taken while holding a session lock, nothing else must be locked while holding it.
+	quotaMu        sync.Mutex
+	inProgress     int
+	inProgressSize int64
+}
+
+// uploadLimits are the limits checked when starting a session and when writing a chunk.
+// Usage is the current usage of the store.
+type uploadLimits struct {
+	MaxSessions  int
+	MaxEntrySize int64
+	Quota        storeQuota
+	Usage        storeUsage
+}
+
+type uploadSession struct {
+	id       ulid.ULID
+	entryTTL time.Duration
+	// entry holds the metadata and device of the entry, its expiration is set when finishing.
+	entry addOptions
+
+	mu        sync.Mutex
+	f         *os.File
+	status    uploadStatus
+	expiresAt time.Time
+	// entryID is the ID of the entry once the session is finished.
+	entryID ulid.ULID
+}
+
+// uploadStatus is what a server knows of an upload session.
+type uploadStatus struct {
+	// Chunks is the number of chunks received.
+	Chunks int64 `json:"chunks"`
+	// Size is the size of the content received.
+	Size int64 `json:"size"`
+}
+
+// newUploadSessions creates the session manager; files left in dir by a previous run are removed.
+func newUploadSessions(dir string) (*uploadSessions, error) {
+	if dir != "" {
+		if err := os.RemoveAll(dir); err != nil {
+			return nil, err
+		}
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			return nil, err
+		}
+	}
+
+	return &uploadSessions{
+		dir:      dir,
+		sessions: make(map[ulid.ULID]*uploadSession),
+	}, nil
+}
+
+// Start creates a new session for an entry which will live for entryTTL once finished.
+// entry holds the sealed metadata of the entry and the device which created it.
+//
+// If there are already limits.MaxSessions sessions in progress errTooManyUploads is returned;
+// if the store with the sessions in progress can't take another entry errQuotaExceeded is returned.
+func (u *uploadSessions) Start(entryTTL time.Duration, entry addOptions, limits uploadLimits, now time.Time) (ulid.ULID, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	// NOTE(vincent): expired sessions still count until they're pruned, don't wait for the next prune to free them.
+	if now.After(u.nextPrune) || (limits.MaxSessions > 0 && u.usage().Entries >= limits.MaxSessions) {
+		u.prune(now)
+		u.nextPrune = now.Add(uploadSessionTTL)
+	}
+
+	if err := u.reserveSession(limits); err != nil {
+		return ulid.ULID{}, err
+	}
+
+	f, err := ioutil.TempFile(u.dir, "apero-upload")
+	if err != nil {
+		u.quotaMu.Lock()
+		u.inProgress--
+		u.quotaMu.Unlock()
+		return ulid.ULID{}, err
+	}
+
+	session := &uploadSession{
+		id:        newULID(),
+		entryTTL:  entryTTL,
+		entry:     entry,
+		f:         f,
+		expiresAt: now.Add(uploadSessionTTL),
+	}
+	u.sessions[session.id] = session
+
+	return session.id, nil
+}
+
+// usage returns the usage of the sessions in progress, each one counts as an entry.
+func (u *uploadSessions) usage() storeUsage {
+	u.quotaMu.Lock()
+	defer u.quotaMu.Unlock()
+
+	return storeUsage{Entries: u.inProgress, Size: u.inProgressSize}
+}
+
+// reserveSession counts a new session in progress if the limits allow it.
+func (u *uploadSessions) reserveSession(limits uploadLimits) error {
+	u.quotaMu.Lock()
+	defer u.quotaMu.Unlock()
+
+	if limits.MaxSessions > 0 && u.inProgress >= limits.MaxSessions {
+		return errTooManyUploads
+	}
+
+	usage := limits.Usage
+	usage.Entries += u.inProgress
+	usage.Size += u.inProgressSize
+	if !limits.Quota.allows(usage, 0) {
+		return errQuotaExceeded
+	}
+
+	u.inProgress++
+
+	return nil
+}
+
+// reserveSize counts n more bytes in the sessions in progress if the quota allows it.
+func (u *uploadSessions) reserveSize(n int64, limits uploadLimits) error {
+	u.quotaMu.Lock()
+	defer u.quotaMu.Unlock()
+
+	// NOTE(vincent): the session is already counted as an entry, only check the size.
+	quota := storeQuota{MaxSize: limits.Quota.MaxSize}
+	if !quota.allows(storeUsage{Size: limits.Usage.Size + u.inProgressSize}, n) {
+		return errQuotaExceeded
+	}
+
+	u.inProgressSize += n
+
+	return nil
+}
+
+// Put writes the chunk at index for the device. The session size is limited to limits.MaxEntrySize.
+//
+// If the session already has this chunk nothing is written; if it's missing
+// a previous chunk errUploadChunkMissing is returned.
+// If the store with the sessions in progress can't take the chunk errQuotaExceeded is returned.
+func (u *uploadSessions) Put(id ulid.ULID, device string, index int64, chunk []byte, limits uploadLimits, now time.Time) (uploadStatus, error) {
+	session, err := u.get(id, device, now)
+	if err != nil {
+		return uploadStatus{}, err
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if !isEmptyULID(session.entryID) {
+		return session.status, nil
+	}
+	if session.f == nil {
+		// Expired and removed since we got it
+		return uploadStatus{}, errUploadNotFound
+	}
+
+	switch {
+	case index < session.status.Chunks:
+		return session.status, nil
+	case index > session.status.Chunks:
+		return session.status, errUploadChunkMissing
+	case len(chunk) > uploadChunkSize:
+		return session.status, errUploadChunkTooLarge
+	case session.status.Size+int64(len(chunk)) > limits.MaxEntrySize:
+		return session.status, errRequestTooLarge
+	}
+
+	if err := u.reserveSize(int64(len(chunk)), limits); err != nil {
+		return session.status, err
+	}
+
+	// NOTE(vincent): if the write fails the garbage past the current size
+	// is overwritten by the next attempt.
+	if _, err := session.f.WriteAt(chunk, session.status.Size); err != nil {
+		u.quotaMu.Lock()
+		u.inProgressSize -= int64(len(chunk))
+		u.quotaMu.Unlock()
+		return session.status, err
+	}
+
+	session.status.Chunks++
+	session.status.Size += int64(len(chunk))
+	session.expiresAt = now.Add(uploadSessionTTL)
+
+	return session.status, nil
+}
+
+// Finish adds the content of the session to the store with add.
+// The options given to add have the expiration, metadata and device of the entry.
+//
+// chunks must match the number of chunks received, this ensures the client
+// and the server agree on the content.
+//
+// The session is kept until it expires so that finishing it again returns
+// the same entry ID, in case the client didn't get the response.
+func (u *uploadSessions) Finish(id ulid.ULID, device string, chunks int64, now time.Time, add func(content io.Reader, opts addOptions) (ulid.ULID, error)) (ulid.ULID, error) {
+	session, err := u.get(id, device, now)
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	session.mu.Lock()
+	defer session.mu.Unlock()
+
+	if !isEmptyULID(session.entryID) {
+		return session.entryID, nil
+	}
+	if session.f == nil {
+		return ulid.ULID{}, errUploadNotFound
+	}
+	if chunks != session.status.Chunks {
+		return ulid.ULID{}, errUploadChunkMissing
+	}
+	if session.status.Size == 0 {
+		return ulid.ULID{}, errContentEmpty
+	}
+
+	opts := session.entry
+	opts.ExpiresAt = now.Add(session.entryTTL)
+
+	entryID, err := add(io.NewSectionReader(session.f, 0, session.status.Size), opts)
+	if err != nil {
+		return ulid.ULID{}, err
+	}
+
+	session.entryID = entryID
+	session.expiresAt = now.Add(uploadSessionTTL)
+	u.release(session)
+
+	return entryID, nil
+}
+
+// get returns the session if it was started by the device.
+// The session of another device is treated as if it didn't exist.
+func (u *uploadSessions) get(id ulid.ULID, device string, now time.Time) (*uploadSession, error) {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	session, ok := u.sessions[id]
+	if !ok || now.After(session.expiresAt) || session.entry.Device != device {
+		return nil, errUploadNotFound
+	}
+
+	return session, nil
+}
+
+// prune removes the expired sessions.
+// The caller must hold the lock.
+func (u *uploadSessions) prune(now time.Time) {
+	for id, session := range u.sessions {
+		session.mu.Lock()
+		if now.After(session.expiresAt) {
+			u.release(session)
+			delete(u.sessions, id)
+		}
+		session.mu.Unlock()
+	}
+}
+
+// release removes the file of the session, it's no longer in progress.
+// The caller must hold the session lock.
+func (u *uploadSessions) release(s *uploadSession) {
+	if s.f == nil {
+		return
+	}
+	s.f.Close()
+	os.Remove(s.f.Name())
+	s.f = nil
+
+	u.quotaMu.Lock()
+	u.inProgress--
+	u.inProgressSize -= s.status.Size
+	u.quotaMu.Unlock()
+}
+
+// Close removes the sessions in progress.
+//
+// Sessions only live in memory, they can't be resumed after a restart so their files are useless.
+func (u *uploadSessions) Close() {
+	u.mu.Lock()
+	defer u.mu.Unlock()
+
+	for id, session := range u.sessions {
+		session.mu.Lock()
+		u.release(session)
+		session.mu.Unlock()
+		delete(u.sessions, id)
+	}
+}
+
+// uploadLimits returns the limits of the upload sessions with the current usage of the store.
+func (s *apiHandler) uploadLimits(limits limitsConfig) (uploadLimits, error) {
+	usage, err := s.st.Usage()
+	if err != nil {
+		return uploadLimits{}, err
+	}
+
+	return uploadLimits{
+		MaxSessions:  limits.maxUploadSessions(),
+		MaxEntrySize: limits.maxEntrySize(),
+		Quota:        limits.quota(),
+		Usage:        usage,
+	}, nil
+}
+
+// uploadMessage returns the data signed for a request on an entry or an upload session,
+// n and digest depend on the request.
+func uploadMessage(id ulid.ULID, n int64, digest []byte) []byte {
+	buf := make([]byte, 0, len(id)+8+len(digest))
+	buf = append(buf, id[:]...)
+
+	var tmp [8]byte
+	binary.BigEndian.PutUint64(tmp[:], uint64(n))
+	buf = append(buf, tmp[:]...)
+
+	return append(buf, digest...)
+}
+
+func (s *apiHandler) handleUpload(w http.ResponseWriter, req *http.Request, version string, path string) {
+	if version == apiVersion1 {
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+		return
+	}
+
+	head, _ := hutil.ShiftPath(path)
+
+	switch head {
+	case "start":
+		s.handleUploadStart(w, req)
+	case "chunk":
+		s.handleUploadChunk(w, req)
+	case "finish":
+		s.handleUploadFinish(w, req)
+	default:
+		http.Error(w, http.StatusText(http.StatusNotFound), http.StatusNotFound)
+	}
+}
+
+func (s *apiHandler) handleUploadStart(w http.ResponseWriter, req *http.Request) {
+	conf := s.config()
+
+	if req.Method != http.MethodPost {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		responseStatusCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	data, box, ok := s.openBox(conf, req, data)
+	if !ok {
+		log.Printf("unable to open box")
+		responseStatusCode(w, http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	var payload uploadStartRequest
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Printf("unable to unmarshal upload start request payload. err: %v", err)
+		responseString(w, "invalid upload start request", http.StatusBadRequest)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		log.Printf("upload start request payload invalid. err: %v", err)
+		responseString(w, "invalid upload start request", http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	device, err := s.verifyRequest(conf, box, apiVersion2, actionUploadStart, payload.Envelope, payload.Metadata, payload.Signature)
+	if err != nil {
+		log.Printf("unable to verify upload start request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limits, err := s.uploadLimits(conf.Limits)
+	if err != nil {
+		log.Printf("unable to get the store usage. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	ttl := conf.entryTTL(time.Duration(payload.TTL) * time.Second)
+
+	id, err := s.uploads.Start(ttl, addOptions{Metadata: payload.Metadata, Device: device}, limits, time.Now())
+	switch {
+	case err == errTooManyUploads:
+		log.Printf("unable to start upload session, too many sessions in progress")
+		responseString(w, "too many uploads in progress", http.StatusTooManyRequests)
+		return
+	case err == errQuotaExceeded:
+		log.Printf("unable to start upload session, quota exceeded")
+		responseString(w, "the staging server is full", http.StatusInsufficientStorage)
+		return
+	case err != nil:
+		log.Printf("unable to start upload session. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	responseSealedJSON(w, box, uploadStartResponse{ID: id, ChunkSize: uploadChunkSize}, http.StatusOK)
+}
+
+func (s *apiHandler) handleUploadChunk(w http.ResponseWriter, req *http.Request) {
+	conf := s.config()
+
+	if req.Method != http.MethodPut {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	if req.ContentLength > maxControlRequestSize+uploadChunkSize+8 {
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	}
+
+	body := req.Body
+	defer body.Close()
+
+	data, err := readFrame(body, maxControlRequestSize)
+	if err != nil {
+		log.Printf("unable to read upload chunk header. err: %v", err)
+		responseString(w, "invalid upload chunk request", http.StatusBadRequest)
+		return
+	}
+
+	data, box, ok := s.openBox(conf, req, data)
+	if !ok {
+		log.Printf("unable to open box")
+		responseStatusCode(w, http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	var header uploadChunkHeader
+	if err := json.Unmarshal(data, &header); err != nil {
+		log.Printf("unable to unmarshal upload chunk header. err: %v", err)
+		responseString(w, "invalid upload chunk request", http.StatusBadRequest)
+		return
+	}
+	if err := header.Validate(); err != nil {
+		log.Printf("upload chunk header invalid. err: %v", err)
+		responseString(w, "invalid upload chunk request", http.StatusBadRequest)
+		return
+	}
+
+	chunk, err := readFrame(body, uploadChunkSize)
+	if err != nil {
+		log.Printf("unable to read upload chunk. err: %v", err)
+		responseString(w, "invalid upload chunk request", http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	digest := sha256.Sum256(chunk)
+
+	device, err := s.verifyRequest(conf, box, apiVersion2, actionUploadChunk, header.Envelope, uploadMessage(header.ID, header.Index, digest[:]), header.Signature)
+	if err != nil {
+		log.Printf("unable to verify upload chunk request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	limits, err := s.uploadLimits(conf.Limits)
+	if err != nil {
+		log.Printf("unable to get the store usage. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	status, err := s.uploads.Put(header.ID, device, header.Index, chunk, limits, time.Now())
+	switch {
+	case err == errUploadNotFound:
+		responseStatusCode(w, http.StatusNotFound)
+		return
+	case err == errUploadChunkMissing:
+		responseString(w, fmt.Sprintf("expected chunk %d", status.Chunks), http.StatusConflict)
+		return
+	case err == errRequestTooLarge:
+		responseEntryTooLarge(w, conf.Limits.maxEntrySize())
+		return
+	case err == errQuotaExceeded:
+		log.Printf("unable to write upload chunk, quota exceeded")
+		responseString(w, "the staging server is full", http.StatusInsufficientStorage)
+		return
+	case err != nil:
+		log.Printf("unable to write upload chunk. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	responseSealedJSON(w, box, status, http.StatusOK)
+}
+
+func (s *apiHandler) handleUploadFinish(w http.ResponseWriter, req *http.Request) {
+	conf := s.config()
+
+	if req.Method != http.MethodPost {
+		responseStatusCode(w, http.StatusMethodNotAllowed)
+		return
+	}
+
+	data, err := readRequestBody(req, maxControlRequestSize)
+	switch {
+	case err == errRequestTooLarge:
+		responseStatusCode(w, http.StatusRequestEntityTooLarge)
+		return
+	case err != nil:
+		responseStatusCode(w, http.StatusInternalServerError)
+		return
+	}
+
+	data, box, ok := s.openBox(conf, req, data)
+	if !ok {
+		log.Printf("unable to open box")
+		responseStatusCode(w, http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	var payload uploadFinishRequest
+	if err := json.Unmarshal(data, &payload); err != nil {
+		log.Printf("unable to unmarshal upload finish request payload. err: %v", err)
+		responseString(w, "invalid upload finish request", http.StatusBadRequest)
+		return
+	}
+	if err := payload.Validate(); err != nil {
+		log.Printf("upload finish request payload invalid. err: %v", err)
+		responseString(w, "invalid upload finish request", http.StatusBadRequest)
+		return
+	}
+
+	//
+
+	device, err := s.verifyRequest(conf, box, apiVersion2, actionUploadFinish, payload.Envelope, uploadMessage(payload.ID, payload.Chunks, nil), payload.Signature)
+	if err != nil {
+		log.Printf("unable to verify upload finish request. err: %v", err)
+		responseString(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	id, err := s.uploads.Finish(payload.ID, device, payload.Chunks, time.Now(), func(content io.Reader, opts addOptions) (ulid.ULID, error) {
+		opts.Quota = conf.Limits.quota()
+		return s.addEntry(content, opts)
+	})
+	switch {
+	case err == errUploadNotFound:
+		responseStatusCode(w, http.StatusNotFound)
+		return
+	case err == errUploadChunkMissing:
+		responseString(w, "the upload session is missing chunks", http.StatusConflict)
+		return
+	case err == errContentEmpty:
+		responseString(w, "invalid upload finish request", http.StatusBadRequest)
+		return
+	case err == errQuotaExceeded:
+		log.Printf("unable to store payload, quota exceeded")
+		responseString(w, "the staging server is full", http.StatusInsufficientStorage)
+		return
+	case err != nil:
+		log.Printf("unable to store payload. err: %v", err)
+		responseString(w, "internal server error", http.StatusInternalServerError)
+		return
+	}
+
+	respData := box.seal(id[:])
+
+	w.WriteHeader(http.StatusAccepted)
+	w.Write(respData)
+}
@@ -0,0 +1,427 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUploadSessions(t *testing.T) {
+	uploads, err := newUploadSessions("")
+	require.NoError(t, err)
+
+	st := newMemStore()
+	add := func(content io.Reader, opts addOptions) (ulid.ULID, error) {
+		return st.Add(content, opts)
+	}
+
+	now := time.Now()
+	limits := uploadLimits{MaxEntrySize: 100}
+
+	t.Run("in-order", func(t *testing.T) {
+		id, err := uploads.Start(time.Hour, addOptions{Metadata: []byte("meta"), Device: "laptop"}, limits, now)
+		require.NoError(t, err)
+
+		status, err := uploads.Put(id, "laptop", 0, []byte("foo"), limits, now)
+		require.NoError(t, err)
+		require.Equal(t, uploadStatus{Chunks: 1, Size: 3}, status)
+
+		// Sending a chunk again is a no-op
+
+		status, err = uploads.Put(id, "laptop", 0, []byte("foo"), limits, now)
+		require.NoError(t, err)
+		require.Equal(t, uploadStatus{Chunks: 1, Size: 3}, status)
+
+		// Chunks can't be skipped
+
+		status, err = uploads.Put(id, "laptop", 2, []byte("baz"), limits, now)
+		require.Equal(t, errUploadChunkMissing, err)
+		require.Equal(t, int64(1), status.Chunks)
+
+		status, err = uploads.Put(id, "laptop", 1, []byte("bar"), limits, now)
+		require.NoError(t, err)
+		require.Equal(t, uploadStatus{Chunks: 2, Size: 6}, status)
+
+		// Finishing requires the right number of chunks
+
+		_, err = uploads.Finish(id, "laptop", 3, now, add)
+		require.Equal(t, errUploadChunkMissing, err)
+
+		entryID, err := uploads.Finish(id, "laptop", 2, now, add)
+		require.NoError(t, err)
+
+		// Finishing again returns the same entry
+
+		entryID2, err := uploads.Finish(id, "laptop", 2, now, add)
+		require.NoError(t, err)
+		require.Equal(t, entryID, entryID2)
+
+		info, content, err := st.Remove(entryID)
+		require.NoError(t, err)
+		require.Equal(t, "foobar", mustReadEntry(t, content))
+		require.Equal(t, "meta", string(info.Metadata))
+		require.Equal(t, "laptop", info.Device)
+		require.Equal(t, now.Add(time.Hour), info.ExpiresAt)
+		require.WithinDuration(t, time.Now().Add(time.Hour), info.ExpiresAt, time.Minute)
+	})
+
+	t.Run("other-device", func(t *testing.T) {
+		id, err := uploads.Start(time.Hour, addOptions{Device: "laptop"}, limits, now)
+		require.NoError(t, err)
+
+		_, err = uploads.Put(id, "phone", 0, []byte("foo"), limits, now)
+		require.Equal(t, errUploadNotFound, err)
+		_, err = uploads.Put(id, "", 0, []byte("foo"), limits, now)
+		require.Equal(t, errUploadNotFound, err)
+
+		_, err = uploads.Put(id, "laptop", 0, []byte("foo"), limits, now)
+		require.NoError(t, err)
+
+		_, err = uploads.Finish(id, "phone", 1, now, add)
+		require.Equal(t, errUploadNotFound, err)
+	})
+
+	t.Run("too-large", func(t *testing.T) {
+		id, err := uploads.Start(time.Hour, addOptions{}, limits, now)
+		require.NoError(t, err)
+
+		_, err = uploads.Put(id, "", 0, []byte("foo"), uploadLimits{MaxEntrySize: 5}, now)
+		require.NoError(t, err)
+		_, err = uploads.Put(id, "", 1, []byte("bar"), uploadLimits{MaxEntrySize: 5}, now)
+		require.Equal(t, errRequestTooLarge, err)
+	})
+
+	t.Run("expired", func(t *testing.T) {
+		id, err := uploads.Start(time.Hour, addOptions{}, limits, now)
+		require.NoError(t, err)
+
+		later := now.Add(2 * uploadSessionTTL)
+
+		_, err = uploads.Put(id, "", 0, []byte("foo"), limits, later)
+		require.Equal(t, errUploadNotFound, err)
+
+		// Starting a new session removes the expired ones
+
+		_, err = uploads.Start(time.Hour, addOptions{}, limits, later)
+		require.NoError(t, err)
+
+		uploads.mu.Lock()
+		_, ok := uploads.sessions[id]
+		uploads.mu.Unlock()
+		require.False(t, ok)
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := uploads.Put(newULID(), "", 0, []byte("foo"), limits, now)
+		require.Equal(t, errUploadNotFound, err)
+	})
+
+	t.Run("close", func(t *testing.T) {
+		id, err := uploads.Start(time.Hour, addOptions{}, limits, now)
+		require.NoError(t, err)
+
+		uploads.mu.Lock()
+		path := uploads.sessions[id].f.Name()
+		uploads.mu.Unlock()
+
+		uploads.Close()
+
+		_, err = os.Stat(path)
+		require.True(t, os.IsNotExist(err))
+
+		_, err = uploads.Put(id, "", 0, []byte("foo"), limits, now)
+		require.Equal(t, errUploadNotFound, err)
+	})
+}
+
+func TestUploadSessionsLimits(t *testing.T) {
+	uploads, err := newUploadSessions("")
+	require.NoError(t, err)
+	defer uploads.Close()
+
+	st := newMemStore()
+	add := func(content io.Reader, opts addOptions) (ulid.ULID, error) {
+		return st.Add(content, opts)
+	}
+
+	now := time.Now()
+
+	// The store already has an entry of 4 bytes
+
+	limits := uploadLimits{
+		MaxSessions:  2,
+		MaxEntrySize: 100,
+		Quota:        storeQuota{MaxEntries: 3, MaxSize: 10},
+		Usage:        storeUsage{Entries: 1, Size: 4},
+	}
+
+	id1, err := uploads.Start(time.Hour, addOptions{}, limits, now)
+	require.NoError(t, err)
+	id2, err := uploads.Start(time.Hour, addOptions{}, limits, now)
+	require.NoError(t, err)
+
+	_, err = uploads.Start(time.Hour, addOptions{}, limits, now)
+	require.Equal(t, errTooManyUploads, err)
+
+	// The content of every session counts toward the quota
+
+	_, err = uploads.Put(id1, "", 0, []byte("foo"), limits, now)
+	require.NoError(t, err)
+	_, err = uploads.Put(id2, "", 0, []byte("bar"), limits, now)
+	require.NoError(t, err)
+	_, err = uploads.Put(id1, "", 1, []byte("b"), limits, now)
+	require.Equal(t, errQuotaExceeded, err)
+
+	// A finished session is no longer in progress
+
+	_, err = uploads.Finish(id1, "", 1, now, add)
+	require.NoError(t, err)
+	require.Equal(t, storeUsage{Entries: 1, Size: 3}, uploads.usage())
+
+	limits.Usage = storeUsage{Entries: 2, Size: 7}
+
+	_, err = uploads.Start(time.Hour, addOptions{}, limits, now)
+	require.Equal(t, errQuotaExceeded, err)
+
+	limits.Quota.MaxEntries = 0
+
+	_, err = uploads.Start(time.Hour, addOptions{}, limits, now)
+	require.NoError(t, err)
+
+	// Expired sessions are pruned when there are too many
+
+	_, err = uploads.Start(time.Hour, addOptions{}, limits, now.Add(2*uploadSessionTTL))
+	require.NoError(t, err)
+	require.Equal(t, storeUsage{Entries: 1}, uploads.usage())
+}
+
+func TestServerClientResume(t *testing.T) {
+	api, client, httpServer := newTestServerClient(t)
+	defer httpServer.Close()
+
+	client.retryDelay = time.Millisecond
+
+	content := bytes.Repeat([]byte("abcdefgh"), uploadChunkSize*3/8+1000)
+
+	t.Run("upload", func(t *testing.T) {
+		// Every other response is lost even though the server handled the request
+
+		transport := &flakyTransport{loseEvery: 2}
+		client.httpClient.Transport = transport
+		defer func() { client.httpClient.Transport = nil }()
+
+		id, err := client.doUpload(uploadStartRequest{}, bytes.NewReader(content))
+		require.NoError(t, err)
+		require.True(t, transport.lost() > 0)
+
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		require.Len(t, entries, 1)
+		require.Equal(t, id, entries[0].ID)
+
+		_, data, err := api.st.Copy(id)
+		require.NoError(t, err)
+		require.Equal(t, string(content), mustReadEntry(t, data))
+	})
+
+	t.Run("paste", func(t *testing.T) {
+		// Every response is cut after some content
+
+		transport := &flakyTransport{cutAfter: uploadChunkSize / 2}
+		client.httpClient.Transport = transport
+		defer func() { client.httpClient.Transport = nil }()
+
+		info, body, err := client.doResumablePaste(pasteRequest{})
+		require.NoError(t, err)
+		require.Equal(t, int64(len(content)), info.Size)
+
+		data, err := ioutil.ReadAll(body)
+		require.NoError(t, err)
+		require.NoError(t, body.Close())
+		require.Equal(t, string(content), string(data))
+		require.True(t, transport.requestCount() > 1)
+	})
+
+	t.Run("paste-offset", func(t *testing.T) {
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		id := entries[0].ID
+
+		info, body, err := client.doPaste(pasteRequest{ID: id, Offset: 10})
+		require.NoError(t, err)
+		require.Equal(t, id, info.ID)
+		require.Equal(t, string(content[10:]), mustReadEntry(t, body))
+
+		_, _, err = client.doPaste(pasteRequest{ID: id, Offset: int64(len(content)) + 1})
+		require.Error(t, err)
+	})
+
+	t.Run("upload-v1", func(t *testing.T) {
+		var req uploadStartRequest
+		req.Signature = sign(client.conf.SignPrivateKey, nil)
+
+		_, err := client.doRequest(req, http.MethodPost, http.StatusOK, "/api/v1/upload/start")
+		require.Equal(t, errEntryNotFound, err)
+	})
+
+	uploadEntry := func(t *testing.T, transport *flakyTransport) {
+		client.httpClient.Transport = transport
+		defer func() { client.httpClient.Transport = nil }()
+
+		before, err := api.st.ListAll()
+		require.NoError(t, err)
+
+		id, err := client.doUpload(uploadStartRequest{}, bytes.NewReader(content))
+		require.NoError(t, err)
+
+		entries, err := api.st.ListAll()
+		require.NoError(t, err)
+		require.Len(t, entries, len(before)+1)
+		require.Equal(t, id, entries[len(entries)-1].ID)
+
+		_, data, err := api.st.Copy(id)
+		require.NoError(t, err)
+		require.Equal(t, string(content), mustReadEntry(t, data))
+	}
+
+	t.Run("upload-session-lost", func(t *testing.T) {
+		// The server restarts in the middle of the upload, the upload starts again with a new session
+
+		var chunks, starts int
+
+		uploadEntry(t, &flakyTransport{
+			before: func(req *http.Request) {
+				switch req.URL.Path {
+				case "/api/v2/upload/start":
+					starts++
+				case "/api/v2/upload/chunk":
+					chunks++
+					if chunks == 3 {
+						api.uploads.mu.Lock()
+						api.uploads.prune(time.Now().Add(uploadSessionTTL + time.Hour))
+						api.uploads.mu.Unlock()
+					}
+				}
+			},
+		})
+		require.Equal(t, 2, starts)
+	})
+
+	t.Run("upload-finish-lost", func(t *testing.T) {
+		// The response of the finish request is lost, finishing again doesn't create another entry
+
+		transport := &flakyTransport{losePath: "/api/v2/upload/finish"}
+		uploadEntry(t, transport)
+		require.Equal(t, 1, transport.lost())
+	})
+
+	t.Run("upload-small", func(t *testing.T) {
+		// Content which fits in a chunk is copied without a session
+
+		var paths []string
+
+		client.httpClient.Transport = &flakyTransport{
+			before: func(req *http.Request) { paths = append(paths, req.URL.Path) },
+		}
+		defer func() { client.httpClient.Transport = nil }()
+
+		_, err := client.doUpload(uploadStartRequest{}, strings.NewReader("hello"))
+		require.NoError(t, err)
+		require.Equal(t, []string{"/api/v2/copy"}, paths)
+	})
+
+	t.Run("upload-empty", func(t *testing.T) {
+		// Entries can't be empty
+
+		_, err := client.doUpload(uploadStartRequest{}, strings.NewReader(""))
+		require.Error(t, err)
+	})
+}
+
+// flakyTransport simulates a bad network connection.
+//
+// If loseEvery is set every loseEvery-th response is lost after the server handled the request.
+// If losePath is set the first response to a request for this path is lost.
+// If cutAfter is set every response body fails after cutAfter bytes.
+// If before is set it's called before sending each request.
+type flakyTransport struct {
+	loseEvery int
+	losePath  string
+	cutAfter  int
+	before    func(req *http.Request)
+
+	mu       sync.Mutex
+	requests int
+	losses   int
+}
+
+var errConnectionReset = errors.New("connection reset")
+
+func (t *flakyTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if t.before != nil {
+		t.before(req)
+	}
+
+	resp, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.requests++
+	if (t.loseEvery > 0 && t.requests%t.loseEvery == 0) || (t.losePath != "" && t.losePath == req.URL.Path) {
+		t.losses++
+		t.losePath = ""
+		resp.Body.Close()
+		return nil, errConnectionReset
+	}
+
+	if t.cutAfter > 0 {
+		resp.Body = &cutReadCloser{ReadCloser: resp.Body, n: t.cutAfter}
+	}
+
+	return resp, nil
+}
+
+func (t *flakyTransport) lost() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.losses
+}
+
+func (t *flakyTransport) requestCount() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.requests
+}
+
+type cutReadCloser struct {
+	io.ReadCloser
+	n int
+}
+
+func (r *cutReadCloser) Read(p []byte) (int, error) {
+	if r.n <= 0 {
+		return 0, errConnectionReset
+	}
+	if len(p) > r.n {
+		p = p[:r.n]
+	}
+
+	n, err := r.ReadCloser.Read(p)
+	r.n -= n
+
+	return n, err
+}